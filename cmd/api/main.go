@@ -1,15 +1,16 @@
 package main
 
 import (
-	"fmt"
 	"log"
+	"os"
+	_ "time/tzdata"
 
 	_ "github.com/fazilnbr/project-workey/cmd/api/docs"
 	_ "github.com/fazilnbr/project-workey/pkg/domain"
-	_ "github.com/fazilnbr/project-workey/pkg/utils"
 
 	"github.com/fazilnbr/project-workey/pkg/config"
 	"github.com/fazilnbr/project-workey/pkg/db"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 
 	"github.com/fazilnbr/project-workey/pkg/di"
 )
@@ -32,21 +33,39 @@ import (
 // @BasePath /
 // @query.collection.format multi
 func main() {
-	fmt.Println("starting my job-portal project in clean code architecture")
-
 	config, configErr := config.LoadConfig()
 	if configErr != nil {
 		log.Fatal("cannot load config: ", configErr)
 	}
-	// db.ConnectDB(config)
-	gorm, _ := db.ConnectGormDB(config)
-	fmt.Printf("\ngorm : %v\n\n", gorm)
 
-	server, diErr := di.InitializeAPI(config)
-	fmt.Printf("\n\n\nserver ; %v\n\n\n", server)
+	logger, err := utils.NewLogger(config)
+	if err != nil {
+		log.Fatal("cannot set up logging: ", err)
+	}
+	// What is still logged through the standard logger is redacted as well
+	log.SetFlags(0)
+	log.SetOutput(logger.Writer())
+
+	// One pool serves the migrations and the whole application
+	sqlDB := db.ConnectDB(config, logger)
+	defer sqlDB.Close()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(sqlDB, os.Args[2:]); err != nil {
+			logger.WithError(err).Fatal("migrate failed")
+		}
+		return
+	}
+
+	// The schema is only changed by migrate, so refuse to run on one that is behind
+	if err := checkSchema(sqlDB); err != nil {
+		logger.WithError(err).Fatal("refusing to start")
+	}
+
+	server, diErr := di.InitializeAPI(config, sqlDB, logger)
 	if diErr != nil {
-		log.Fatal("cannot start server: ", diErr)
-	} else {
-		server.Start()
+		logger.WithError(diErr).Fatal("cannot start server")
 	}
+	logger.Info("starting the server")
+	server.Start()
 }
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/db"
+)
+
+const migrateUsage = "usage: api migrate up | down [steps] | status | force <version>"
+
+// migrate runs the migrate subcommand. down reverts one migration unless
+// told how many.
+func migrate(sqlDB *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := db.NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Printf("the schema is up to date at version %d\n", migrator.Latest())
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.New("steps has to be a number above zero")
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Dirty {
+				state = "dirty"
+			}
+			fmt.Printf("%4d  %-32s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New("version has to be a number")
+		}
+		if err = migrator.Force(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("the schema is recorded as being at version %d\n", version)
+		return nil
+	}
+	return errors.New(migrateUsage)
+}
+
+// checkSchema fails when the schema is behind the migrations in the binary
+func checkSchema(sqlDB *sql.DB) error {
+	migrator, err := db.NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+	return migrator.Check(context.Background())
+}
@@ -1,15 +1,319 @@
 package handler
 
 import (
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
 )
 
 type AdminHandler struct {
-	adminService services.AdminUseCase
+	adminService        services.AdminUseCase
+	mailUseCase         services.MailUseCase
+	cancellationUseCase services.CancellationUseCase
+	cursorCodec         utils.CursorCodec
+}
+
+// @Summary List Dead Letter Mails
+// @ID ListDeadLetters
+// @Tags Admin Mail Outbox
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/mails/dead-letters [get]
+func (c *AdminHandler) ListDeadLetters(ctx *gin.Context) {
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	mails, meta, err := c.mailUseCase.ListDeadLetters(ctx, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Dead Letters")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, mails, meta)
+}
+
+// @Summary Retry Dead Letter Mail
+// @ID RetryDeadLetter
+// @Tags Admin Mail Outbox
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mail Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/mails/{id}/retry [patch]
+func (c *AdminHandler) RetryDeadLetter(ctx *gin.Context) {
+	mailId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.mailUseCase.RetryDeadLetter(ctx, mailId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Retry Mail")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Get Cancellation Policy
+// @ID GetCancellationPolicy
+// @Tags Admin Cancellation Policies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/categories/{id}/cancellation-policy [get]
+func (c *AdminHandler) GetCancellationPolicy(ctx *gin.Context) {
+	categoryId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	policy, err := c.cancellationUseCase.GetPolicy(ctx, categoryId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Policy")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", policy)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Set Cancellation Policy
+// @ID SetCancellationPolicy
+// @Tags Admin Cancellation Policies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category Id"
+// @Param policy body domain.CancellationPolicyInput{} true "Cancellation Policy"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/categories/{id}/cancellation-policy [put]
+func (c *AdminHandler) SetCancellationPolicy(ctx *gin.Context) {
+	var policy domain.CancellationPolicyInput
+
+	categoryId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &policy, "Failed to Fetch Data") {
+		return
+	}
+
+	saved, err := c.cancellationUseCase.SetPolicy(ctx, categoryId, policy)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Set Policy")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", saved)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Policy Decisions Of A Request
+// @ID ListPolicyDecisions
+// @Tags Admin Cancellation Policies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/requests/{id}/policy-decisions [get]
+func (c *AdminHandler) ListPolicyDecisions(ctx *gin.Context) {
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	decisions, err := c.cancellationUseCase.ListDecisions(ctx, requestId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Decisions")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", decisions)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Search Accounts
+// @ID SearchAccounts
+// @Tags Admin Accounts
+// @Description Matches part of the phone number, email or name. Paged by offset only.
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Phone, email or name"
+// @Param status query string false "newuser, active, blocked or suspended"
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/users [get]
+// @Router /admin/workers [get]
+func (c *AdminHandler) SearchAccounts(userType string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, ok := bindPage(ctx, c.cursorCodec)
+		if !ok {
+			return
+		}
+
+		accounts, meta, err := c.adminService.SearchAccounts(ctx, userType, ctx.Query("q"), ctx.Query("status"), filter)
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to Search Accounts")
+			return
+		}
+
+		writePage(ctx, c.cursorCodec, accounts, meta)
+	}
+}
+
+// @Summary Get Account
+// @ID GetAccount
+// @Tags Admin Accounts
+// @Description Profile, addresses, latest requests and sanctions, along with the jobs and rating of a worker
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/users/{id} [get]
+// @Router /admin/workers/{id} [get]
+func (c *AdminHandler) GetAccount(userType string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userId, ok := pathId(ctx)
+		if !ok {
+			return
+		}
+
+		account, err := c.adminService.GetAccount(ctx, userType, userId)
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to Get Account")
+			return
+		}
+
+		response := utils.SuccessResponse(true, "SUCCESS", account)
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		utils.ResponseJSON(ctx, response)
+	}
+}
+
+// @Summary Act On Account
+// @ID ActOnAccount
+// @Tags Admin Accounts
+// @Description Blocks, suspends for the given days, lifts a block or suspension or ends every session of an account
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User Id"
+// @Param action body domain.AccountActionInput{} true "Reason"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/accounts/{id}/block [patch]
+// @Router /admin/accounts/{id}/suspend [patch]
+// @Router /admin/accounts/{id}/unblock [patch]
+// @Router /admin/accounts/{id}/logout [post]
+func (c *AdminHandler) ActOnAccount(kind string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var input domain.AccountActionInput
+		id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+		userId, ok := pathId(ctx)
+		if !ok {
+			return
+		}
+
+		if !bind(ctx, &input, "Failed to Fetch Data") {
+			return
+		}
+
+		action, err := c.adminService.ActOnAccount(ctx, id, userId, kind, input)
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to Act On Account")
+			return
+		}
+
+		response := utils.SuccessResponse(true, "SUCCESS", action)
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		utils.ResponseJSON(ctx, response)
+	}
+}
+
+// @Summary Login History
+// @ID ListLogins
+// @Tags Admin Accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User Id"
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/accounts/{id}/logins [get]
+func (c *AdminHandler) ListLogins(ctx *gin.Context) {
+	userId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	logins, meta, err := c.adminService.ListLogins(ctx, userId, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Logins")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, logins, meta)
 }
 
-func NewAdminHandler(adminService services.AdminUseCase) AdminHandler {
+func NewAdminHandler(
+	adminService services.AdminUseCase,
+	mailUseCase services.MailUseCase,
+	cancellationUseCase services.CancellationUseCase,
+	cursorCodec utils.CursorCodec) AdminHandler {
 	return AdminHandler{
-		adminService: adminService,
+		adminService:        adminService,
+		mailUseCase:         mailUseCase,
+		cancellationUseCase: cancellationUseCase,
+		cursorCodec:         cursorCodec,
 	}
 }
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	analyticsUseCase services.AnalyticsUseCase
+}
+
+// @Summary Signups Per Day
+// @ID AnalyticsSignupsPerDay
+// @Tags Admin Analytics
+// @Description Accounts made each day by sign in channel and account type, city being the account's first address
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "From date, YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive, tomorrow by default"
+// @Param city query string false "City"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/signups [get]
+func (c *AnalyticsHandler) SignupsPerDay(ctx *gin.Context) {
+	query, ok := bindAnalyticsQuery(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := c.analyticsUseCase.SignupsPerDay(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Fetch Signups Per Day")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", stats)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Active Workers
+// @ID AnalyticsActiveWorkers
+// @Tags Admin Analytics
+// @Description Workers with an open job in each category, and how many of them were booked in the range. City is where the worker has an address.
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "From date, YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive, tomorrow by default"
+// @Param city query string false "City"
+// @Param category query int false "Category Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/workers [get]
+func (c *AnalyticsHandler) ActiveWorkers(ctx *gin.Context) {
+	query, ok := bindAnalyticsQuery(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := c.analyticsUseCase.ActiveWorkers(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Fetch Active Workers")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", stats)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Request Funnel
+// @ID AnalyticsRequestFunnel
+// @Tags Admin Analytics
+// @Description How the requests made in the range went, from pending to completed, with the conversion and cancellation rates
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "From date, YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive, tomorrow by default"
+// @Param city query string false "City"
+// @Param category query int false "Category Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/funnel [get]
+func (c *AnalyticsHandler) RequestFunnel(ctx *gin.Context) {
+	query, ok := bindAnalyticsQuery(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := c.analyticsUseCase.RequestFunnel(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Fetch Request Funnel")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", stats)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Cancellation Rates
+// @ID AnalyticsCancellations
+// @Tags Admin Analytics
+// @Description Cancelled and no-show requests against all requests made in the range, per category
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "From date, YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive, tomorrow by default"
+// @Param city query string false "City"
+// @Param category query int false "Category Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/cancellations [get]
+func (c *AnalyticsHandler) Cancellations(ctx *gin.Context) {
+	query, ok := bindAnalyticsQuery(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := c.analyticsUseCase.Cancellations(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Fetch Cancellation Rates")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", stats)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Revenue
+// @ID AnalyticsRevenue
+// @Tags Admin Analytics
+// @Description GMV, commission and discounts of released payments per day and currency, by the day the request was made
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "From date, YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive, tomorrow by default"
+// @Param city query string false "City"
+// @Param category query int false "Category Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/revenue [get]
+func (c *AnalyticsHandler) Revenue(ctx *gin.Context) {
+	query, ok := bindAnalyticsQuery(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := c.analyticsUseCase.Revenue(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Fetch Revenue")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", stats)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Ratings By Category
+// @ID AnalyticsRatingsByCategory
+// @Tags Admin Analytics
+// @Description Average rating per category, each rating going with the last request the rater completed with the worker. The range and city are those of that request, ratings without one are left out.
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "From date, YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive, tomorrow by default"
+// @Param city query string false "City"
+// @Param category query int false "Category Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/ratings [get]
+func (c *AnalyticsHandler) RatingsByCategory(ctx *gin.Context) {
+	query, ok := bindAnalyticsQuery(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := c.analyticsUseCase.RatingsByCategory(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Fetch Ratings By Category")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", stats)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Top Workers
+// @ID AnalyticsTopWorkers
+// @Tags Admin Analytics
+// @Description Workers ranked by the requests they completed in the range
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "From date, YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive, tomorrow by default"
+// @Param city query string false "City"
+// @Param category query int false "Category Id"
+// @Param limit query int false "How many workers, 10 by default"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/top-workers [get]
+func (c *AnalyticsHandler) TopWorkers(ctx *gin.Context) {
+	query, ok := bindAnalyticsQuery(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := c.analyticsUseCase.TopWorkers(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Fetch Top Workers")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", stats)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Refresh Analytics
+// @ID RefreshAnalytics
+// @Tags Admin Analytics
+// @Description Refreshes the summaries behind the analytics now rather than at the next scheduled refresh
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/analytics/refresh [post]
+func (c *AnalyticsHandler) RefreshSummaries(ctx *gin.Context) {
+	if err := c.analyticsUseCase.RefreshSummaries(ctx); err != nil {
+		ctx.Error(err).SetMeta("Failed to Refresh Analytics")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// bindAnalyticsQuery reads the analytics filters, answering with 400 and
+// returning false when they do not parse
+func bindAnalyticsQuery(ctx *gin.Context) (domain.AnalyticsQuery, bool) {
+	var query domain.AnalyticsQuery
+	if !bindQuery(ctx, &query, "Invalid Filters") {
+		return query, false
+	}
+	return query, true
+}
+
+func NewAnalyticsHandler(analyticsUseCase services.AnalyticsUseCase) AnalyticsHandler {
+	return AnalyticsHandler{
+		analyticsUseCase: analyticsUseCase,
+	}
+}
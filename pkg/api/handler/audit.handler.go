@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditUseCase services.AuditUseCase
+	cursorCodec  utils.CursorCodec
+}
+
+// @Summary List Audit Logs
+// @ID ListAuditLogs
+// @Tags Admin Audit
+// @Produce json
+// @Security BearerAuth
+// @Param actor query int false "Actor Id"
+// @Param action query string false "Action"
+// @Param targettype query string false "Target Type"
+// @Param targetid query int false "Target Id"
+// @Param from query string false "From date, YYYY-MM-DD"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive"
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/audit-logs [get]
+func (c *AuditHandler) ListAuditLogs(ctx *gin.Context) {
+	query, ok := bindAuditQuery(ctx)
+	if !ok {
+		return
+	}
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	entries, meta, err := c.auditUseCase.ListAuditLogs(ctx, query, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Audit Logs")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, entries, meta)
+}
+
+// @Summary Export Audit Logs
+// @ID ExportAuditLogs
+// @Tags Admin Audit
+// @Description The matching records in chain order as CSV, hashes included
+// @Produce text/csv
+// @Security BearerAuth
+// @Param actor query int false "Actor Id"
+// @Param action query string false "Action"
+// @Param targettype query string false "Target Type"
+// @Param targetid query int false "Target Id"
+// @Param from query string false "From date, YYYY-MM-DD"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/audit-logs/export [get]
+func (c *AuditHandler) ExportAuditLogs(ctx *gin.Context) {
+	query, ok := bindAuditQuery(ctx)
+	if !ok {
+		return
+	}
+
+	file, err := c.auditUseCase.ExportAuditLogs(ctx, query)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Export Audit Logs")
+		return
+	}
+
+	ctx.Writer.Header().Set("Content-Type", "text/csv")
+	ctx.Writer.Header().Set("Content-Disposition", `attachment; filename="audit-logs.csv"`)
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(file)
+}
+
+// @Summary Verify Audit Chain
+// @ID VerifyAuditChain
+// @Tags Admin Audit
+// @Description Recomputes every hash and reports the first record that was changed or follows a dropped one
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/audit-logs/verify [get]
+func (c *AuditHandler) VerifyChain(ctx *gin.Context) {
+	verification, err := c.auditUseCase.VerifyChain(ctx)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Verify Audit Chain")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", verification)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// bindAuditQuery reads the audit filters, answering with 400 and returning
+// false when they do not parse
+func bindAuditQuery(ctx *gin.Context) (domain.AuditQuery, bool) {
+	var query domain.AuditQuery
+	if !bindQuery(ctx, &query, "Invalid Filters") {
+		return query, false
+	}
+	return query, true
+}
+
+func NewAuditHandler(auditUseCase services.AuditUseCase, cursorCodec utils.CursorCodec) AuditHandler {
+	return AuditHandler{
+		auditUseCase: auditUseCase,
+		cursorCodec:  cursorCodec,
+	}
+}
@@ -2,11 +2,11 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	_ "github.com/fazilnbr/project-workey/cmd/api/docs"
 	"github.com/fazilnbr/project-workey/pkg/config"
@@ -14,17 +14,21 @@ import (
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
 	"github.com/fazilnbr/project-workey/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
 type AuthHandler struct {
-	adminUseCase  services.AdminUseCase
-	workerUseCase services.WorkerUseCase
-	userUseCase   services.UserUseCase
-	jwtUseCase    services.JWTUseCase
-	authUseCase   services.AuthUseCase
-	cfg           config.Config
+	adminUseCase    services.AdminUseCase
+	workerUseCase   services.WorkerUseCase
+	userUseCase     services.UserUseCase
+	jwtUseCase      services.JWTUseCase
+	authUseCase     services.AuthUseCase
+	referralUseCase services.ReferralUseCase
+	auditUseCase    services.AuditUseCase
+	cfg             config.Config
+	logger          *logrus.Logger
 }
 
 func NewAuthHandler(
@@ -33,16 +37,22 @@ func NewAuthHandler(
 	userusecase services.UserUseCase,
 	jwtUseCase services.JWTUseCase,
 	authUseCase services.AuthUseCase,
+	referralUseCase services.ReferralUseCase,
+	auditUseCase services.AuditUseCase,
 	cfg config.Config,
+	logger *logrus.Logger,
 
 ) AuthHandler {
 	return AuthHandler{
-		adminUseCase:  adminUseCase,
-		workerUseCase: workerUseCase,
-		userUseCase:   userusecase,
-		jwtUseCase:    jwtUseCase,
-		authUseCase:   authUseCase,
-		cfg:           cfg,
+		adminUseCase:    adminUseCase,
+		workerUseCase:   workerUseCase,
+		userUseCase:     userusecase,
+		jwtUseCase:      jwtUseCase,
+		authUseCase:     authUseCase,
+		referralUseCase: referralUseCase,
+		auditUseCase:    auditUseCase,
+		cfg:             cfg,
+		logger:          logger,
 	}
 }
 
@@ -87,6 +97,8 @@ func (cr *AuthHandler) InitializeOAuthGoogle() {
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} utils.Response{}
+// @Failure 401 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
 // @Failure 422 {object} utils.Response{}
 // @Router /refresh-token [get]
 func (cr *AuthHandler) RefreshToken(ctx *gin.Context) {
@@ -98,47 +110,47 @@ func (cr *AuthHandler) RefreshToken(ctx *gin.Context) {
 		response := utils.ErrorResponse("Request does't condain Refresh token", "", nil)
 		ctx.Writer.Header().Add("Content-Type", "application/json")
 		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		utils.ResponseJSON(ctx, response)
 		return
 	}
-	fmt.Printf("\n\ntocen : %v\n\n", autheader)
 	token := bearerToken[1]
 	ok, claims := cr.jwtUseCase.VerifyToken(token)
-	if !ok {
+	// An access token would otherwise renew itself for good
+	if !ok || claims.Source != "refreshtoken" {
 		response := utils.ErrorResponse("Your Refresh token is not valid Login again", "", nil)
 		ctx.Writer.Header().Add("Content-Type", "application/json")
 		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		utils.ResponseJSON(ctx, response)
 		return
 	}
 
-	fmt.Println("//////////////////////////////////", claims.UserName)
+	// A locked or logged out account keeps no session past its current access token
+	if err := cr.userUseCase.CheckSession(ctx, claims.UserId, time.Unix(claims.IssuedAt, 0)); err != nil {
+		ctx.Error(err).SetMeta("Your Refresh token is not valid Login again")
+		return
+	}
 	accesstoken, err := cr.jwtUseCase.GenerateAccessToken(claims.UserId, claims.UserName, claims.Role)
 
 	if err != nil {
-		response := utils.ErrorResponse("Failed to generating access token please login again", err.Error(), nil)
-		ctx.Writer.Header().Add("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Failed to generating access token please login again")
 		return
 	}
 
 	refreshToken, err := cr.jwtUseCase.GenerateRefreshToken(claims.UserId, claims.UserName, claims.Role)
 	if err != nil {
-		response := utils.ErrorResponse("Failed to generating refresh token please login again", err.Error(), nil)
-		ctx.Writer.Header().Add("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Failed to generating refresh token please login again")
 		return
 	}
 
+	cr.audit(ctx, claims.UserId, claims.Role, domain.AuditTokenRefresh)
+
 	ctx.Writer.Header().Set("access-token", accesstoken)
 	ctx.Writer.Header().Set("refresh-token", refreshToken)
 
 	response := utils.SuccessResponse(true, "SUCCESS", nil)
 	ctx.Writer.Header().Add("Content-Type", "application/json")
 	ctx.Writer.WriteHeader(http.StatusOK)
-	utils.ResponseJSON(*ctx, response)
+	utils.ResponseJSON(ctx, response)
 
 }
 
@@ -221,37 +233,42 @@ func (cr *AuthHandler) CallBackFromGoogle(ctx *gin.Context) {
 			response := utils.ErrorResponse("Failed to Login ", "Your email is not varified by google ", nil)
 			ctx.Writer.Header().Add("Content-Type", "application/json")
 			ctx.Writer.WriteHeader(http.StatusUnauthorized)
-			utils.ResponseJSON(*ctx, response)
+			utils.ResponseJSON(ctx, response)
 			return
 		}
 
 		userId, err := cr.userUseCase.RegisterAndVarifyWithEmail(ctx, gdata.Email)
 		if err != nil {
-			response := utils.ErrorResponse("Failed to create user", err.Error(), nil)
-			ctx.Writer.Header().Set("Content-Type", "application/json")
-			ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-			utils.ResponseJSON(*ctx, response)
+			ctx.Error(err).SetMeta("Failed to create user")
+			return
+		}
+
+		role, err := cr.userUseCase.UserRole(ctx, userId)
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to create user")
 			return
 		}
 
-		accessToken, err := cr.jwtUseCase.GenerateAccessToken(userId, "", "user")
+		accessToken, err := cr.jwtUseCase.GenerateAccessToken(userId, "", role)
 		if err != nil {
 			response := utils.ErrorResponse("Failed to generate access token", err.Error(), nil)
 			ctx.Writer.Header().Add("Content-Type", "application/json")
 			ctx.Writer.WriteHeader(http.StatusUnauthorized)
-			utils.ResponseJSON(*ctx, response)
+			utils.ResponseJSON(ctx, response)
 			return
 		}
 
-		refreshToken, err := cr.jwtUseCase.GenerateRefreshToken(userId, "", "user")
+		refreshToken, err := cr.jwtUseCase.GenerateRefreshToken(userId, "", role)
 
 		if err != nil {
 			response := utils.ErrorResponse("Failed to generate refresh token please login again", err.Error(), nil)
 			ctx.Writer.Header().Add("Content-Type", "application/json")
 			ctx.Writer.WriteHeader(http.StatusUnauthorized)
-			utils.ResponseJSON(*ctx, response)
+			utils.ResponseJSON(ctx, response)
 			return
 		}
+		cr.userUseCase.RecordLogin(ctx, userId, domain.LoginGoogle, ctx.ClientIP(), ctx.Request.UserAgent())
+		cr.audit(ctx, userId, role, domain.AuditLogin)
 
 		userResponse := domain.UserResponse{
 			AccessToken:  accessToken,
@@ -261,49 +278,48 @@ func (cr *AuthHandler) CallBackFromGoogle(ctx *gin.Context) {
 		response := utils.SuccessResponse(true, "SUCCESS", userResponse)
 		ctx.Writer.Header().Set("Content-Type", "application/json")
 		ctx.Writer.WriteHeader(http.StatusOK)
-		utils.ResponseJSON(*ctx, response)
+		utils.ResponseJSON(ctx, response)
 	}
 }
 
 // @Summary Send OTP for Users
 // @ID sendOtp
 // @Tags User Authentication
+// @Description The number can be written with spaces or dashes and with or without its country calling code. Only mobile numbers are sent codes.
 // @Produce json
-// @Param mobileNumber body domain.Signup{} true "Mobile Number"
+// @Param mobileNumber body domain.SendOTPInput{} true "Mobile Number"
 // @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
 // @Failure 422 {object} utils.Response{}
 // @Router /user/sent-otp [post]
 func (cr *AuthHandler) UserSendOTP(ctx *gin.Context) {
-	var newUser domain.Signup
+	var newUser domain.SendOTPInput
 
-	err := ctx.Bind(&newUser)
+	if !bind(ctx, &newUser, "Failed to create user") {
+		return
+	}
+	phone, err := cr.authUseCase.ParsePhone(newUser.CountryCode, newUser.PhoneNumber)
 	if err != nil {
-		response := utils.ErrorResponse("Failed to create user", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusBadRequest)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Invalid Phone Number")
 		return
 	}
-	phoneNumber := fmt.Sprintf(newUser.CountryCode + newUser.PhoneNumber)
-	err = cr.authUseCase.SendOTP(ctx, phoneNumber)
+	err = cr.authUseCase.SendOTP(ctx, phone)
 
 	if err != nil {
-		response := utils.ErrorResponse("Error while sending OTP to user", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Error while sending OTP to user")
 		return
 	}
 
 	response := utils.SuccessResponse(true, "SUCCESS", nil)
 	ctx.Writer.Header().Set("Content-Type", "application/json")
 	ctx.Writer.WriteHeader(http.StatusOK)
-	utils.ResponseJSON(*ctx, response)
+	utils.ResponseJSON(ctx, response)
 }
 
 // @Summary SignUp for users
 // @ID SignUp authentication
 // @Tags User Authentication
+// @Description A new account can give the referral code it was invited with along with the id of the device it signs up from
 // @Produce json
 // @Param mobileNumberAndOTP body domain.Signup{} true "Mobile Number And OTP"
 // @Success 200 {object} utils.Response{}
@@ -312,50 +328,63 @@ func (cr *AuthHandler) UserSendOTP(ctx *gin.Context) {
 func (cr *AuthHandler) UserRegisterAndLogin(ctx *gin.Context) {
 	var newUser domain.Signup
 
-	err := ctx.Bind(&newUser)
+	if !bind(ctx, &newUser, "Failed to Fetch Data") {
+		return
+	}
+	if newUser.ReferralCode != "" {
+		if err := cr.referralUseCase.CheckCode(ctx, newUser.ReferralCode); err != nil {
+			ctx.Error(err).SetMeta("Invalid Referral Code")
+			return
+		}
+	}
+	phone, err := cr.authUseCase.ParsePhone(newUser.CountryCode, newUser.PhoneNumber)
 	if err != nil {
-		response := utils.ErrorResponse("Failed to Fetch Data", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusBadRequest)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Invalid Phone Number")
 		return
 	}
-	phoneNumber := fmt.Sprintf(newUser.CountryCode + newUser.PhoneNumber)
-	err = cr.authUseCase.VarifyOTP(ctx, phoneNumber, newUser.Otp)
+	err = cr.authUseCase.VarifyOTP(ctx, phone, newUser.Otp)
 	if err != nil {
-		response := utils.ErrorResponse("Invalid OTP", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Invalid OTP")
 		return
 	}
-	userId, err := cr.userUseCase.RegisterAndVarifyWithNumber(ctx, phoneNumber)
+	userId, created, err := cr.userUseCase.RegisterAndVarifyWithNumber(ctx, phone)
 	if err != nil {
-		response := utils.ErrorResponse("Failed to create user", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Failed to create user")
+		return
+	}
+	// The account is there already, a failed referral is not worth failing the signup for
+	if created {
+		if err = cr.referralUseCase.SignedUp(ctx, userId, phone.E164, newUser); err != nil {
+			cr.logger.WithContext(ctx).WithError(err).WithField("user", userId).Error("failed to record signup referral")
+		}
+	}
+
+	role, err := cr.userUseCase.UserRole(ctx, userId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to create user")
 		return
 	}
 
-	accessToken, err := cr.jwtUseCase.GenerateAccessToken(userId, "", "user")
+	accessToken, err := cr.jwtUseCase.GenerateAccessToken(userId, "", role)
 	if err != nil {
 		response := utils.ErrorResponse("Failed to generate access token", err.Error(), nil)
 		ctx.Writer.Header().Add("Content-Type", "application/json")
 		ctx.Writer.WriteHeader(http.StatusUnauthorized)
-		utils.ResponseJSON(*ctx, response)
+		utils.ResponseJSON(ctx, response)
 		return
 	}
 
-	refreshToken, err := cr.jwtUseCase.GenerateRefreshToken(userId, "", "user")
+	refreshToken, err := cr.jwtUseCase.GenerateRefreshToken(userId, "", role)
 
 	if err != nil {
 		response := utils.ErrorResponse("Failed to generate refresh token please login again", err.Error(), nil)
 		ctx.Writer.Header().Add("Content-Type", "application/json")
 		ctx.Writer.WriteHeader(http.StatusUnauthorized)
-		utils.ResponseJSON(*ctx, response)
+		utils.ResponseJSON(ctx, response)
 		return
 	}
+	cr.userUseCase.RecordLogin(ctx, userId, domain.LoginPhone, ctx.ClientIP(), ctx.Request.UserAgent())
+	cr.audit(ctx, userId, role, domain.AuditLogin)
 
 	ctx.Writer.Header().Set("access-token", accessToken)
 	ctx.Writer.Header().Set("refresh-token", refreshToken)
@@ -363,5 +392,19 @@ func (cr *AuthHandler) UserRegisterAndLogin(ctx *gin.Context) {
 	response := utils.SuccessResponse(true, "SUCCESS", nil)
 	ctx.Writer.Header().Set("Content-Type", "application/json")
 	ctx.Writer.WriteHeader(http.StatusOK)
-	utils.ResponseJSON(*ctx, response)
+	utils.ResponseJSON(ctx, response)
+}
+
+// audit records a sign in or refresh, which happen before there is a token
+// to take the actor from
+func (cr *AuthHandler) audit(ctx *gin.Context, userId int, role string, action string) {
+	cr.auditUseCase.Record(ctx, domain.AuditLog{
+		ActorId:    userId,
+		ActorRole:  role,
+		Action:     action,
+		TargetType: domain.AuditTargetUser,
+		TargetId:   userId,
+		Ip:         ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+	})
 }
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type BookingHandler struct {
+	bookingUseCase services.BookingUseCase
+	cursorCodec    utils.CursorCodec
+}
+
+// @Summary Book A Worker
+// @ID BookWorker
+// @Tags User Bookings
+// @Produce json
+// @Security BearerAuth
+// @Param booking body domain.BookingInput{} true "Booking"
+// @Success 200 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests [post]
+func (c *BookingHandler) Book(ctx *gin.Context) {
+	var booking domain.BookingInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &booking, "Failed to Fetch Data") {
+		return
+	}
+
+	request, err := c.bookingUseCase.Book(ctx, id, booking)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Book Worker")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", request)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary List User Bookings
+// @ID ListUserBookings
+// @Tags User Bookings
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests [get]
+func (c *BookingHandler) ListUserBookings(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	bookings, meta, err := c.bookingUseCase.ListUserBookings(ctx, id, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Bookings")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, bookings, meta)
+}
+
+// @Summary Cancel Booking
+// @ID CancelBooking
+// @Tags User Bookings
+// @Description Cancels the request and refunds the payment as the cancellation policy of its category allows
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/cancel [patch]
+func (c *BookingHandler) CancelBooking(ctx *gin.Context) {
+	c.settle(ctx, c.bookingUseCase.CancelBooking)
+}
+
+// @Summary Report Worker No-Show
+// @ID ReportNoShow
+// @Tags User Bookings
+// @Description Reports that the worker did not turn up, an hour or more after the start, and refunds the payment in full
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/no-show [patch]
+func (c *BookingHandler) ReportNoShow(ctx *gin.Context) {
+	c.settle(ctx, c.bookingUseCase.ReportNoShow)
+}
+
+// @Summary List Incoming Requests
+// @ID ListWorkerBookings
+// @Tags Worker Bookings
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/requests [get]
+func (c *BookingHandler) ListWorkerBookings(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	bookings, meta, err := c.bookingUseCase.ListWorkerBookings(ctx, id, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Requests")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, bookings, meta)
+}
+
+// @Summary Accept Request
+// @ID AcceptBooking
+// @Tags Worker Bookings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/requests/{id}/accept [patch]
+func (c *BookingHandler) AcceptBooking(ctx *gin.Context) {
+	c.changeStatus(ctx, c.bookingUseCase.AcceptBooking)
+}
+
+// @Summary Reject Request
+// @ID RejectBooking
+// @Tags Worker Bookings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/requests/{id}/reject [patch]
+func (c *BookingHandler) RejectBooking(ctx *gin.Context) {
+	c.changeStatus(ctx, c.bookingUseCase.RejectBooking)
+}
+
+// @Summary Complete Request
+// @ID CompleteBooking
+// @Tags Worker Bookings
+// @Description Releases the payment to the worker and issues the invoices. Materials bought for the job are part of the price and listed on the user's invoice.
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Param completion body domain.CompletionInput{} false "Materials"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/requests/{id}/complete [patch]
+func (c *BookingHandler) CompleteBooking(ctx *gin.Context) {
+	var completion domain.CompletionInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if ctx.Request.ContentLength != 0 {
+		if !bind(ctx, &completion, "Failed to Fetch Data") {
+			return
+		}
+	}
+
+	err := c.bookingUseCase.CompleteBooking(ctx, id, requestId, completion)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Complete Request")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Cancel Accepted Request
+// @ID WorkerCancelBooking
+// @Tags Worker Bookings
+// @Description Calls off an accepted request, the user is refunded in full
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/requests/{id}/cancel [patch]
+func (c *BookingHandler) WorkerCancelBooking(ctx *gin.Context) {
+	c.settle(ctx, c.bookingUseCase.WorkerCancelBooking)
+}
+
+// settle calls off the request in the path on behalf of the logged in account
+// and answers with the policy decision taken on its payment
+func (c *BookingHandler) settle(ctx *gin.Context, cancel func(ctx context.Context, actorId int, requestId int) (domain.PolicyDecision, error)) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	decision, err := cancel(ctx, id, requestId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Cancel Request")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", decision)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// changeStatus runs a status transition of the request in the path on behalf of the logged in account
+func (c *BookingHandler) changeStatus(ctx *gin.Context, change func(ctx context.Context, actorId int, requestId int) error) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	err := change(ctx, id, requestId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Update Request")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewBookingHandler(bookingUseCase services.BookingUseCase, cursorCodec utils.CursorCodec) BookingHandler {
+	return BookingHandler{
+		bookingUseCase: bookingUseCase,
+		cursorCodec:    cursorCodec,
+	}
+}
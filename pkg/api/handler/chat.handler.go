@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+var errUnknownFrame = errors.New("unknown frame type")
+
+// chatSubprotocol is the subprotocol a browser, which can't set headers on
+// a socket handshake, offers along with its access token as the next one
+const chatSubprotocol = "bearer"
+
+type ChatHandler struct {
+	chatUseCase services.ChatUseCase
+	jwtUseCase  services.JWTUseCase
+	userUseCase services.UserUseCase
+	cursorCodec utils.CursorCodec
+}
+
+// @Summary Open Chat Socket
+// @ID ChatSocket
+// @Tags Chat
+// @Description Upgrades to a WebSocket carrying the chat of a request. Browsers cannot set headers on a socket handshake, so they offer the subprotocols bearer and the access token instead of the Authorization header. The socket is closed when the account is blocked, suspended or logged out.
+// @Param id path int true "Request Id"
+// @Param Sec-WebSocket-Protocol header string false "bearer, Access Token"
+// @Success 101
+// @Failure 401 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Router /chat/requests/{id}/ws [get]
+func (c *ChatHandler) Connect(ctx *gin.Context) {
+	token, subprotocol := socketToken(ctx.Request)
+
+	ok, claims := c.jwtUseCase.VerifyToken(token)
+	if !ok || claims.Source != "accesstoken" {
+		ctx.Error(domain.ErrInvalidToken).SetMeta("Error")
+		return
+	}
+	if err := c.userUseCase.CheckSession(ctx, claims.UserId, time.Unix(claims.IssuedAt, 0)); err != nil {
+		ctx.Error(err).SetMeta("Error")
+		return
+	}
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if _, err := c.chatUseCase.JoinChat(ctx, claims.UserId, requestId); err != nil {
+		ctx.Error(err).SetMeta("Failed to Join Chat")
+		return
+	}
+
+	websocket.Server{
+		// Only the subprotocol is answered with, never the token offered after it
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			config.Protocol = nil
+			if subprotocol {
+				config.Protocol = []string{chatSubprotocol}
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			c.serveSocket(ws, claims.UserId, requestId)
+		},
+	}.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// socketToken is the access token of a socket handshake, from the
+// Authorization header or offered as the subprotocol after bearer. It tells
+// whether the subprotocol was used.
+func socketToken(req *http.Request) (string, bool) {
+	var protocols []string
+	for _, header := range req.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+	if len(protocols) == 2 && protocols[0] == chatSubprotocol {
+		return protocols[1], true
+	}
+
+	bearerToken := strings.Split(req.Header.Get("Authorization"), " ")
+	if len(bearerToken) == 2 {
+		return bearerToken[1], false
+	}
+	return "", false
+}
+
+// serveSocket pumps hub events out to the socket and client frames into the
+// chat until the client goes away
+func (c *ChatHandler) serveSocket(ws *websocket.Conn, userId int, requestId int) {
+	defer ws.Close()
+	ctx := ws.Request().Context()
+
+	events, unsubscribe := c.chatUseCase.Subscribe(userId, requestId)
+	defer unsubscribe()
+
+	// The hub lets go of the socket when the account loses its sessions,
+	// which ends it
+	go func() {
+		defer ws.Close()
+		for event := range events {
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var frame domain.ChatFrame
+		if err := websocket.JSON.Receive(ws, &frame); err != nil {
+			return
+		}
+
+		var err error
+		switch frame.Type {
+		case domain.ChatEventMessage:
+			_, err = c.chatUseCase.SendMessage(ctx, userId, requestId, frame.Body)
+		case domain.ChatEventTyping:
+			err = c.chatUseCase.Typing(ctx, userId, requestId)
+		case domain.ChatEventRead:
+			err = c.chatUseCase.MarkRead(ctx, userId, requestId, frame.ReadTo)
+		default:
+			err = errUnknownFrame
+		}
+
+		if err != nil {
+			websocket.JSON.Send(ws, domain.ChatEvent{
+				Type:      domain.ChatEventError,
+				RequestId: requestId,
+				Error:     err.Error(),
+				At:        time.Now(),
+			})
+		}
+	}
+}
+
+// @Summary Chat History Of A Request
+// @ID ListMessages
+// @Tags Chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/messages [get]
+// @Router /worker/requests/{id}/messages [get]
+func (c *ChatHandler) ListMessages(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	messages, meta, err := c.chatUseCase.ListMessages(ctx, id, requestId, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Messages")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, messages, meta)
+}
+
+// @Summary Mark Messages Read
+// @ID MarkMessagesRead
+// @Tags Chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Param receipt body domain.ReadReceiptInput{} true "Last message read"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/messages/read [post]
+// @Router /worker/requests/{id}/messages/read [post]
+func (c *ChatHandler) MarkRead(ctx *gin.Context) {
+	var receipt domain.ReadReceiptInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &receipt, "Failed to Fetch Data") {
+		return
+	}
+
+	err := c.chatUseCase.MarkRead(ctx, id, requestId, receipt.ReadTo)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Mark Messages Read")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewChatHandler(chatUseCase services.ChatUseCase, jwtUseCase services.JWTUseCase, userUseCase services.UserUseCase, cursorCodec utils.CursorCodec) ChatHandler {
+	return ChatHandler{
+		chatUseCase: chatUseCase,
+		jwtUseCase:  jwtUseCase,
+		userUseCase: userUseCase,
+		cursorCodec: cursorCodec,
+	}
+}
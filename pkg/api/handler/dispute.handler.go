@@ -0,0 +1,458 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type DisputeHandler struct {
+	disputeUseCase services.DisputeUseCase
+	cursorCodec    utils.CursorCodec
+}
+
+// @Summary Open Dispute
+// @ID OpenDispute
+// @Tags Disputes
+// @Description Either party of a request that went ahead can dispute it, once at a time
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Param dispute body domain.DisputeInput{} true "Dispute"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/disputes [post]
+// @Router /worker/requests/{id}/disputes [post]
+func (c *DisputeHandler) OpenDispute(ctx *gin.Context) {
+	var dispute domain.DisputeInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &dispute, "Failed to Fetch Data") {
+		return
+	}
+
+	opened, err := c.disputeUseCase.OpenDispute(ctx, id, requestId, dispute)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Open Dispute")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", opened)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary List Own Disputes
+// @ID ListDisputes
+// @Tags Disputes
+// @Description Disputes opened by or against the caller
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/disputes [get]
+// @Router /worker/disputes [get]
+func (c *DisputeHandler) ListDisputes(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	disputes, meta, err := c.disputeUseCase.ListDisputes(ctx, id, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Disputes")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, disputes, meta)
+}
+
+// @Summary Get Dispute
+// @ID GetDispute
+// @Tags Disputes
+// @Description The dispute with its evidence and messages, without the notes admins keep among themselves
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/disputes/{id} [get]
+// @Router /worker/disputes/{id} [get]
+func (c *DisputeHandler) GetDispute(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	dispute, err := c.disputeUseCase.GetDispute(ctx, id, disputeId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Dispute")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", dispute)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Attach Evidence
+// @ID AddEvidence
+// @Tags Disputes
+// @Description A photo, PDF or MP4 video of up to 10 MB
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Param file formData file true "Evidence"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/disputes/{id}/evidence [post]
+// @Router /worker/disputes/{id}/evidence [post]
+func (c *DisputeHandler) AddEvidence(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	// Room for the multipart framing around the largest accepted file
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, domain.MaxEvidenceSize+1<<20)
+	data, name, err := readFormFile(ctx, "file", domain.MaxEvidenceSize)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Read File")
+		return
+	}
+
+	evidence, err := c.disputeUseCase.AddEvidence(ctx, id, disputeId, name, data)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Attach Evidence")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", evidence)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Download Evidence
+// @ID DownloadEvidence
+// @Tags Disputes
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Param evidenceId path int true "Evidence Id"
+// @Success 200 {file} file
+// @Failure 422 {object} utils.Response{}
+// @Router /user/disputes/{id}/evidence/{evidenceId} [get]
+// @Router /worker/disputes/{id}/evidence/{evidenceId} [get]
+func (c *DisputeHandler) DownloadEvidence(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+	c.writeEvidence(ctx, func(disputeId int, evidenceId int) (domain.DisputeEvidence, []byte, error) {
+		return c.disputeUseCase.EvidenceFile(ctx, id, disputeId, evidenceId)
+	})
+}
+
+// @Summary Post Dispute Message
+// @ID PostDisputeMessage
+// @Tags Disputes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Param message body domain.DisputeMessageInput{} true "Message"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/disputes/{id}/messages [post]
+// @Router /worker/disputes/{id}/messages [post]
+func (c *DisputeHandler) PostMessage(ctx *gin.Context) {
+	var message domain.DisputeMessageInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &message, "Failed to Fetch Data") {
+		return
+	}
+
+	posted, err := c.disputeUseCase.PostMessage(ctx, id, disputeId, message)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Post Message")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", posted)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Dispute Case Queue
+// @ID ListDisputeQueue
+// @Tags Admin Disputes
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "open (default), in_review or resolved"
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/disputes [get]
+func (c *DisputeHandler) ListQueue(ctx *gin.Context) {
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	disputes, meta, err := c.disputeUseCase.ListQueue(ctx, ctx.Query("status"), filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Disputes")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, disputes, meta)
+}
+
+// @Summary Get Dispute Case
+// @ID AdminGetDispute
+// @Tags Admin Disputes
+// @Description The dispute with its evidence, messages and internal notes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/disputes/{id} [get]
+func (c *DisputeHandler) AdminGetDispute(ctx *gin.Context) {
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	dispute, err := c.disputeUseCase.AdminGetDispute(ctx, disputeId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Dispute")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", dispute)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Download Dispute Evidence
+// @ID AdminDownloadEvidence
+// @Tags Admin Disputes
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Param evidenceId path int true "Evidence Id"
+// @Success 200 {file} file
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/disputes/{id}/evidence/{evidenceId} [get]
+func (c *DisputeHandler) AdminDownloadEvidence(ctx *gin.Context) {
+	c.writeEvidence(ctx, func(disputeId int, evidenceId int) (domain.DisputeEvidence, []byte, error) {
+		return c.disputeUseCase.AdminEvidenceFile(ctx, disputeId, evidenceId)
+	})
+}
+
+// @Summary Assign Dispute
+// @ID AssignDispute
+// @Tags Admin Disputes
+// @Description Hands the case to an admin, the caller when adminid is left out
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Param assign body domain.DisputeAssignInput{} true "Assignee"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/disputes/{id}/assign [patch]
+func (c *DisputeHandler) AssignDispute(ctx *gin.Context) {
+	var assign domain.DisputeAssignInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &assign, "Failed to Fetch Data") {
+		return
+	}
+
+	dispute, err := c.disputeUseCase.AssignDispute(ctx, id, disputeId, assign)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Assign Dispute")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", dispute)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Add Dispute Note
+// @ID AddDisputeNote
+// @Tags Admin Disputes
+// @Description An internal note stays among admins, any other note is a message to both parties
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Param note body domain.DisputeNoteInput{} true "Note"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/disputes/{id}/notes [post]
+func (c *DisputeHandler) AddNote(ctx *gin.Context) {
+	var note domain.DisputeNoteInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &note, "Failed to Fetch Data") {
+		return
+	}
+
+	message, err := c.disputeUseCase.AddNote(ctx, id, disputeId, note)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Add Note")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", message)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Resolve Dispute
+// @ID ResolveDispute
+// @Tags Admin Disputes
+// @Description A refund is paid back out of the released payment of the request, a warning or suspension is put on the given party
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Dispute Id"
+// @Param resolution body domain.DisputeResolutionInput{} true "Resolution"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/disputes/{id}/resolve [post]
+func (c *DisputeHandler) ResolveDispute(ctx *gin.Context) {
+	var resolution domain.DisputeResolutionInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &resolution, "Failed to Fetch Data") {
+		return
+	}
+
+	dispute, err := c.disputeUseCase.ResolveDispute(ctx, id, disputeId, resolution)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Resolve Dispute")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", dispute)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// writeEvidence answers with an evidence file loaded by load
+func (c *DisputeHandler) writeEvidence(ctx *gin.Context, load func(disputeId int, evidenceId int) (domain.DisputeEvidence, []byte, error)) {
+	disputeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+	evidenceId, ok := pathInt(ctx, "evidenceId")
+	if !ok {
+		return
+	}
+
+	evidence, file, err := load(disputeId, evidenceId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Evidence")
+		return
+	}
+
+	ctx.Writer.Header().Set("Content-Type", evidence.ContentType)
+	ctx.Writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": evidence.FileName}))
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(file)
+}
+
+// readFormFile reads an uploaded file of at most limit bytes
+func readFormFile(ctx *gin.Context, field string, limit int64) ([]byte, string, error) {
+	header, err := ctx.FormFile(field)
+	if err != nil {
+		return nil, "", utils.InvalidField(err, field, domain.FieldError{Code: "required", Message: "is required"})
+	}
+	if header.Size > limit {
+		return nil, "", utils.InvalidField(nil, field, domain.FieldError{
+			Code:    "max",
+			Param:   strconv.FormatInt(limit, 10),
+			Message: "has to be at most " + strconv.FormatInt(limit, 10) + " bytes",
+		})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, limit))
+	return data, header.Filename, err
+}
+
+func NewDisputeHandler(disputeUseCase services.DisputeUseCase, cursorCodec utils.CursorCodec) DisputeHandler {
+	return DisputeHandler{
+		disputeUseCase: disputeUseCase,
+		cursorCodec:    cursorCodec,
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ExportHandler struct {
+	exportUseCase services.ExportUseCase
+	logger        *logrus.Logger
+}
+
+// @Summary Export Table
+// @ID ExportTable
+// @Tags Admin Exports
+// @Description Streams the matching records as CSV or XLSX. Jobs carry no date, so from and to do not apply to them.
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "csv or xlsx, csv by default"
+// @Param q query string false "Phone, email or name of the account, user, worker or category, or the gateway id of a payment"
+// @Param status query string false "Status"
+// @Param category query int false "Category Id"
+// @Param from query string false "From date, YYYY-MM-DD"
+// @Param to query string false "To date, YYYY-MM-DD, exclusive"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/users/export [get]
+// @Router /admin/workers/export [get]
+// @Router /admin/jobs/export [get]
+// @Router /admin/requests/export [get]
+// @Router /admin/payments/export [get]
+func (c *ExportHandler) Export(table string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var query domain.ExportQuery
+		if !bindQuery(ctx, &query, "Invalid Filters") {
+			return
+		}
+		if query.Format == "" {
+			query.Format = utils.SheetCSV
+		}
+
+		file := &attachmentWriter{
+			ctx:         ctx,
+			contentType: utils.SheetContentType(query.Format),
+			filename:    table + "-" + time.Now().Format("2006-01-02") + "." + query.Format,
+		}
+		err := c.exportUseCase.Export(ctx, table, query, file)
+		if err != nil && !file.started {
+			ctx.Error(err).SetMeta("Failed to Export")
+			return
+		}
+		if err != nil {
+			// The download is under way, all that can be done is to cut it short
+			c.logger.WithContext(ctx).WithError(err).WithField("table", table).Error("export cut short")
+		}
+	}
+}
+
+// attachmentWriter sends the download headers along with the first bytes, so
+// a failure before anything is written can still be answered with an error
+type attachmentWriter struct {
+	ctx         *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.ctx.Writer.Header().Set("Content-Type", w.contentType)
+		w.ctx.Writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": w.filename}))
+		w.ctx.Writer.WriteHeader(http.StatusOK)
+	}
+	return w.ctx.Writer.Write(p)
+}
+
+func NewExportHandler(exportUseCase services.ExportUseCase, logger *logrus.Logger) ExportHandler {
+	return ExportHandler{
+		exportUseCase: exportUseCase,
+		logger:        logger,
+	}
+}
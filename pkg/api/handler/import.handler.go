@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	importUseCase services.ImportUseCase
+}
+
+// importFunc is an import of one kind of record
+type importFunc func(ctx context.Context, data []byte, format string, dryRun bool) (domain.ImportReport, error)
+
+// @Summary Import Categories
+// @ID ImportCategories
+// @Tags Admin Imports
+// @Description A CSV or XLSX file with category and category_icon columns. Nothing is imported while any row has errors.
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX of up to 5 MB"
+// @Param dryrun query bool false "Only check the rows"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/categories/import [post]
+func (c *ImportHandler) ImportCategories(ctx *gin.Context) {
+	c.runImport(ctx, c.importUseCase.ImportCategories)
+}
+
+// @Summary Import Workers
+// @ID ImportWorkers
+// @Tags Admin Imports
+// @Description A CSV or XLSX file with phone, email, first_name and last_name columns, and optionally category, experience, description, full_day_wage and half_day_wage to give each worker a job. Workers come in verified. Nothing is imported while any row has errors.
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX of up to 5 MB"
+// @Param dryrun query bool false "Only check the rows"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/workers/import [post]
+func (c *ImportHandler) ImportWorkers(ctx *gin.Context) {
+	c.runImport(ctx, c.importUseCase.ImportWorkers)
+}
+
+// runImport reads the uploaded file and answers with the report of the
+// import, or with the errors of its rows when any has one
+func (c *ImportHandler) runImport(ctx *gin.Context, run importFunc) {
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dryrun", "false"))
+	if err != nil {
+		ctx.Error(utils.InvalidField(err, "dryrun", domain.FieldError{Code: "type", Param: "bool", Message: "has to be true or false"})).SetMeta("Invalid Dry Run")
+		return
+	}
+
+	// Room for the multipart framing around the largest accepted file
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, domain.MaxImportSize+1<<20)
+	data, name, err := readFormFile(ctx, "file", domain.MaxImportSize)
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	if err == nil && format != utils.SheetCSV && format != utils.SheetXLSX {
+		err = utils.InvalidField(nil, "file", domain.FieldError{Code: "oneof", Param: "csv xlsx", Message: "has to be a .csv or .xlsx file"})
+	}
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Read File")
+		return
+	}
+
+	report, err := run(ctx, data, format, dryRun)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Import")
+		return
+	}
+	if len(report.Errors) > 0 {
+		ctx.Error(importError(report)).SetMeta("Import Has Errors")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", report)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// importError is the error of an import with rows that have errors, the
+// errors put down against rows[line].column as those of a request's fields
+func importError(report domain.ImportReport) error {
+	fields := make(map[string]domain.FieldError)
+	for _, rowErr := range report.Errors {
+		name := fmt.Sprintf("rows[%d]", rowErr.Row)
+		if rowErr.Column != "" {
+			name += "." + rowErr.Column
+		}
+		if _, ok := fields[name]; !ok {
+			fields[name] = domain.FieldError{Code: "invalid", Message: rowErr.Message}
+		}
+	}
+	return domain.ErrImportInvalid.WithFields(fields)
+}
+
+func NewImportHandler(importUseCase services.ImportUseCase) ImportHandler {
+	return ImportHandler{
+		importUseCase: importUseCase,
+	}
+}
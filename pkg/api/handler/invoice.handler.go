@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	invoiceUseCase services.InvoiceUseCase
+}
+
+// @Summary Invoices Of A Request
+// @ID ListInvoices
+// @Tags Invoices
+// @Description The user sees the job invoice and its credit notes, the worker also the commission invoice
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/invoices [get]
+// @Router /worker/requests/{id}/invoices [get]
+func (c *InvoiceHandler) ListInvoices(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	invoices, err := c.invoiceUseCase.ListInvoices(ctx, id, requestId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Invoices")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", invoices)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Download Invoice
+// @ID DownloadInvoice
+// @Tags Invoices
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Invoice Id"
+// @Success 200 {file} file
+// @Failure 422 {object} utils.Response{}
+// @Router /user/invoices/{id}/pdf [get]
+// @Router /worker/invoices/{id}/pdf [get]
+func (c *InvoiceHandler) DownloadInvoice(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	invoiceId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	invoice, file, err := c.invoiceUseCase.InvoicePDF(ctx, id, invoiceId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Invoice")
+		return
+	}
+
+	ctx.Writer.Header().Set("Content-Type", "application/pdf")
+	ctx.Writer.Header().Set("Content-Disposition", `attachment; filename="`+invoice.Number+`.pdf"`)
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(file)
+}
+
+func NewInvoiceHandler(invoiceUseCase services.InvoiceUseCase) InvoiceHandler {
+	return InvoiceHandler{
+		invoiceUseCase: invoiceUseCase,
+	}
+}
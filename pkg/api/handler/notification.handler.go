@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationUseCase services.NotificationUseCase
+	cursorCodec         utils.CursorCodec
+}
+
+// @Summary List Notifications
+// @ID ListNotifications
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/notifications [get]
+// @Router /worker/notifications [get]
+func (c *NotificationHandler) ListNotifications(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	notifications, meta, err := c.notificationUseCase.ListNotifications(ctx, id, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Notifications")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, notifications, meta)
+}
+
+// @Summary Unread Notification Count
+// @ID UnreadNotifications
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/notifications/unread-count [get]
+// @Router /worker/notifications/unread-count [get]
+func (c *NotificationHandler) UnreadCount(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	unread, err := c.notificationUseCase.UnreadCount(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Count Notifications")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", unread)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Mark Notification Read
+// @ID MarkNotificationRead
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/notifications/{id}/read [patch]
+// @Router /worker/notifications/{id}/read [patch]
+func (c *NotificationHandler) MarkRead(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	notificationId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.notificationUseCase.MarkRead(ctx, id, notificationId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Mark Notification Read")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Mark All Notifications Read
+// @ID MarkAllNotificationsRead
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/notifications/read-all [patch]
+// @Router /worker/notifications/read-all [patch]
+func (c *NotificationHandler) MarkAllRead(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	err := c.notificationUseCase.MarkAllRead(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Mark Notifications Read")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Get Notification Preferences
+// @ID GetNotificationPreferences
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/notification-preferences [get]
+// @Router /worker/notification-preferences [get]
+func (c *NotificationHandler) GetPreferences(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	preferences, err := c.notificationUseCase.GetPreferences(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Preferences")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", preferences)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Set Notification Preferences
+// @ID SetNotificationPreferences
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body domain.PreferencesInput{} true "Channel Preferences"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/notification-preferences [put]
+// @Router /worker/notification-preferences [put]
+func (c *NotificationHandler) SetPreferences(ctx *gin.Context) {
+	var preferences domain.PreferencesInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &preferences, "Failed to Fetch Data") {
+		return
+	}
+
+	err := c.notificationUseCase.SetPreferences(ctx, id, preferences)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Set Preferences")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Register Push Device
+// @ID RegisterDevice
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param device body domain.DeviceInput{} true "Device Token"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/devices [post]
+// @Router /worker/devices [post]
+func (c *NotificationHandler) RegisterDevice(ctx *gin.Context) {
+	var device domain.DeviceInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &device, "Failed to Fetch Data") {
+		return
+	}
+
+	err := c.notificationUseCase.RegisterDevice(ctx, id, device)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Register Device")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Remove Push Device
+// @ID RemoveDevice
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param token path string true "Device Token"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/devices/{token} [delete]
+// @Router /worker/devices/{token} [delete]
+func (c *NotificationHandler) RemoveDevice(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	err := c.notificationUseCase.RemoveDevice(ctx, id, ctx.Param("token"))
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Remove Device")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewNotificationHandler(notificationUseCase services.NotificationUseCase, cursorCodec utils.CursorCodec) NotificationHandler {
+	return NotificationHandler{
+		notificationUseCase: notificationUseCase,
+		cursorCodec:         cursorCodec,
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type OfferHandler struct {
+	offerUseCase services.OfferUseCase
+}
+
+// @Summary Propose A Price
+// @ID ProposeOffer
+// @Tags Price Negotiation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Param offer body domain.OfferInput{} true "Offer amount in minor units"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/offers [post]
+func (c *OfferHandler) ProposeOffer(ctx *gin.Context) {
+	var offer domain.OfferInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &offer, "Failed to Fetch Data") {
+		return
+	}
+
+	created, err := c.offerUseCase.ProposeOffer(ctx, id, requestId, offer)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Propose Offer")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", created)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Offer History Of A Request
+// @ID ListOffers
+// @Tags Price Negotiation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/offers [get]
+// @Router /worker/requests/{id}/offers [get]
+func (c *OfferHandler) ListOffers(party string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+		requestId, ok := pathId(ctx)
+		if !ok {
+			return
+		}
+
+		offers, err := c.offerUseCase.ListOffers(ctx, id, party, requestId)
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to List Offers")
+			return
+		}
+
+		response := utils.SuccessResponse(true, "SUCCESS", offers)
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		utils.ResponseJSON(ctx, response)
+	}
+}
+
+// @Summary Accept Offer
+// @ID AcceptOffer
+// @Tags Price Negotiation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Offer Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/offers/{id}/accept [patch]
+// @Router /worker/offers/{id}/accept [patch]
+func (c *OfferHandler) AcceptOffer(party string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.answerOffer(ctx, party, c.offerUseCase.AcceptOffer)
+	}
+}
+
+// @Summary Reject Offer
+// @ID RejectOffer
+// @Tags Price Negotiation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Offer Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/offers/{id}/reject [patch]
+// @Router /worker/offers/{id}/reject [patch]
+func (c *OfferHandler) RejectOffer(party string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.answerOffer(ctx, party, c.offerUseCase.RejectOffer)
+	}
+}
+
+// @Summary Counter Offer
+// @ID CounterOffer
+// @Tags Price Negotiation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Offer Id"
+// @Param offer body domain.OfferInput{} true "Counter amount in minor units"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/offers/{id}/counter [post]
+// @Router /worker/offers/{id}/counter [post]
+func (c *OfferHandler) CounterOffer(party string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var offer domain.OfferInput
+		id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+		offerId, ok := pathId(ctx)
+		if !ok {
+			return
+		}
+
+		if !bind(ctx, &offer, "Failed to Fetch Data") {
+			return
+		}
+
+		counter, err := c.offerUseCase.CounterOffer(ctx, id, party, offerId, offer)
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to Counter Offer")
+			return
+		}
+
+		response := utils.SuccessResponse(true, "SUCCESS", counter)
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		utils.ResponseJSON(ctx, response)
+	}
+}
+
+func (c *OfferHandler) answerOffer(ctx *gin.Context, party string, answer func(ctx context.Context, actorId int, party string, offerId int) error) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	offerId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	err := answer(ctx, id, party, offerId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Answer Offer")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewOfferHandler(offerUseCase services.OfferUseCase) OfferHandler {
+	return OfferHandler{
+		offerUseCase: offerUseCase,
+	}
+}
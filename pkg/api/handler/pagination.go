@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// bindPage parses the paging query of a list endpoint, passing on what is
+// wrong with it and returning false when it is invalid
+func bindPage(ctx *gin.Context, codec utils.CursorCodec) (utils.Filter, bool) {
+	filter, err := utils.ParsePageQuery(ctx, codec)
+	if err != nil {
+		ctx.Error(err).SetMeta("Invalid pagination")
+		return filter, false
+	}
+	return filter, true
+}
+
+// writePage answers a list endpoint with the standard page envelope
+func writePage(ctx *gin.Context, codec utils.CursorCodec, items interface{}, meta utils.Metadata) {
+	response, err := utils.PageResponse(codec, "SUCCESS", items, meta)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to build page")
+		return
+	}
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
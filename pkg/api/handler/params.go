@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// pathId reads the numeric :id path parameter, passing on that it is invalid
+// and returning false when it is not a number
+func pathId(ctx *gin.Context) (int, bool) {
+	return pathInt(ctx, "id")
+}
+
+// pathInt does the same for any numeric path parameter
+func pathInt(ctx *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		ctx.Error(utils.InvalidField(err, name, numberField)).SetMeta("Invalid Id")
+		return 0, false
+	}
+	return id, true
+}
+
+// numberField is what is wrong with a parameter that has to be a number
+var numberField = domain.FieldError{Code: "type", Param: "int", Message: "has to be a number"}
+
+// bind reads the body of a request into obj, passing on what is wrong with
+// its fields under message and returning false when it does not bind
+func bind(ctx *gin.Context, obj interface{}, message string) bool {
+	if err := ctx.ShouldBind(obj); err != nil {
+		ctx.Error(utils.BindError(err, obj)).SetMeta(message)
+		return false
+	}
+	return true
+}
+
+// bindQuery does the same for the query string
+func bindQuery(ctx *gin.Context, obj interface{}, message string) bool {
+	if err := ctx.ShouldBindQuery(obj); err != nil {
+		ctx.Error(utils.BindError(err, obj)).SetMeta(message)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody bounds the webhook payload read into memory
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	paymentUseCase services.PaymentUseCase
+}
+
+// @Summary Pay For A Request
+// @ID PayRequest
+// @Tags User Payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Param Idempotency-Key header string true "Idempotency Key"
+// @Success 200 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/pay [post]
+func (c *PaymentHandler) Pay(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentUseCase.Pay(ctx, id, requestId, ctx.GetHeader("Idempotency-Key"))
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Pay")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", payment)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Payment Of A Request
+// @ID GetPayment
+// @Tags User Payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/requests/{id}/payment [get]
+// @Router /worker/requests/{id}/payment [get]
+func (c *PaymentHandler) GetPayment(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentUseCase.GetPayment(ctx, id, requestId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Payment")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", payment)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Payment Gateway Webhook
+// @ID PaymentWebhook
+// @Tags Payments
+// @Produce json
+// @Param gateway path string true "razorpay, stripe or fake"
+// @Success 200 {object} utils.Response{}
+// @Failure 401 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /payments/webhook/{gateway} [post]
+func (c *PaymentHandler) Webhook(ctx *gin.Context) {
+	// The signature is over the exact bytes sent, so the body is read raw
+	payload, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxWebhookBody))
+	if err != nil {
+		ctx.Error(utils.InvalidField(err, "body", domain.FieldError{Code: "malformed", Message: "could not be read"})).SetMeta("Failed to Fetch Data")
+		return
+	}
+
+	err = c.paymentUseCase.HandleWebhook(ctx, ctx.Param("gateway"), ctx.Request.Header, payload)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Process Webhook")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Refund Completed Request
+// @ID RefundPayment
+// @Tags Admin Payments
+// @Description Sends part or all of a released payment back to the user, taken back from the worker and the commission, and issues a credit note
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request Id"
+// @Param refund body domain.RefundInput{} true "Refund"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/requests/{id}/refunds [post]
+func (c *PaymentHandler) Refund(ctx *gin.Context) {
+	var input domain.RefundInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	requestId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &input, "Failed to Fetch Data") {
+		return
+	}
+
+	refund, err := c.paymentUseCase.RefundReleased(ctx, id, requestId, input)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Refund")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", refund)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewPaymentHandler(paymentUseCase services.PaymentUseCase) PaymentHandler {
+	return PaymentHandler{
+		paymentUseCase: paymentUseCase,
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PromoHandler struct {
+	promoUseCase services.PromoUseCase
+	cursorCodec  utils.CursorCodec
+}
+
+// @Summary Check Promo Code
+// @ID CheckPromoCode
+// @Tags User Promo Codes
+// @Description Works out what a promo code takes off a booking without using it up
+// @Produce json
+// @Security BearerAuth
+// @Param check body domain.PromoCheckInput{} true "Promo Code And Booking"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/promo-codes/check [post]
+func (c *PromoHandler) CheckPromoCode(ctx *gin.Context) {
+	var check domain.PromoCheckInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &check, "Failed to Fetch Data") {
+		return
+	}
+
+	quote, err := c.promoUseCase.CheckPromoCode(ctx, id, check)
+	if err != nil {
+		ctx.Error(err).SetMeta("Invalid Promo Code")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", quote)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Create Promo Code
+// @ID CreatePromoCode
+// @Tags Admin Promo Codes
+// @Description Value is in basis points of the price for a percent code and in minor units for a flat one
+// @Produce json
+// @Security BearerAuth
+// @Param promo body domain.PromoCodeInput{} true "Promo Code"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/promo-codes [post]
+func (c *PromoHandler) CreatePromoCode(ctx *gin.Context) {
+	var promo domain.PromoCodeInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &promo, "Failed to Fetch Data") {
+		return
+	}
+
+	created, err := c.promoUseCase.CreatePromoCode(ctx, id, promo)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Create Promo Code")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", created)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Update Promo Code
+// @ID UpdatePromoCode
+// @Tags Admin Promo Codes
+// @Description The code itself cannot change, set active to false to stop a campaign
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Promo Code Id"
+// @Param promo body domain.PromoCodeInput{} true "Promo Code"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/promo-codes/{id} [put]
+func (c *PromoHandler) UpdatePromoCode(ctx *gin.Context) {
+	var promo domain.PromoCodeInput
+
+	promoCodeId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &promo, "Failed to Fetch Data") {
+		return
+	}
+
+	updated, err := c.promoUseCase.UpdatePromoCode(ctx, promoCodeId, promo)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Update Promo Code")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", updated)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary List Promo Codes
+// @ID ListPromoCodes
+// @Tags Admin Promo Codes
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/promo-codes [get]
+func (c *PromoHandler) ListPromoCodes(ctx *gin.Context) {
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	promos, meta, err := c.promoUseCase.ListPromoCodes(ctx, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Promo Codes")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, promos, meta)
+}
+
+func NewPromoHandler(promoUseCase services.PromoUseCase, cursorCodec utils.CursorCodec) PromoHandler {
+	return PromoHandler{
+		promoUseCase: promoUseCase,
+		cursorCodec:  cursorCodec,
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ReferralHandler struct {
+	referralUseCase services.ReferralUseCase
+}
+
+// @Summary Referral Code
+// @ID GetReferral
+// @Tags Referrals
+// @Description The account's referral code and how its referrals are doing. Both sides are rewarded once the referred account completes a first booking.
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/referral [get]
+// @Router /worker/referral [get]
+func (c *ReferralHandler) GetReferral(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	summary, err := c.referralUseCase.GetReferral(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Referral Code")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", summary)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewReferralHandler(referralUseCase services.ReferralUseCase) ReferralHandler {
+	return ReferralHandler{
+		referralUseCase: referralUseCase,
+	}
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SubscriptionHandler struct {
+	subscriptionUseCase services.SubscriptionUseCase
+}
+
+// @Summary Create Subscription Plan
+// @ID CreateSubscriptionPlan
+// @Tags Admin Subscription Plans
+// @Description The price is in minor units and charged per interval
+// @Produce json
+// @Security BearerAuth
+// @Param plan body domain.SubscriptionPlanInput{} true "Plan"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/subscription-plans [post]
+func (c *SubscriptionHandler) CreatePlan(ctx *gin.Context) {
+	var plan domain.SubscriptionPlanInput
+
+	if !bind(ctx, &plan, "Failed to Fetch Data") {
+		return
+	}
+
+	created, err := c.subscriptionUseCase.CreatePlan(ctx, plan)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Create Plan")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", created)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Update Subscription Plan
+// @ID UpdateSubscriptionPlan
+// @Tags Admin Subscription Plans
+// @Description Set active to false to stop offering a plan, its subscribers are downgraded when their period ends
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan Id"
+// @Param plan body domain.SubscriptionPlanInput{} true "Plan"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/subscription-plans/{id} [put]
+func (c *SubscriptionHandler) UpdatePlan(ctx *gin.Context) {
+	var plan domain.SubscriptionPlanInput
+
+	planId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &plan, "Failed to Fetch Data") {
+		return
+	}
+
+	updated, err := c.subscriptionUseCase.UpdatePlan(ctx, planId, plan)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Update Plan")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", updated)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary List Subscription Plans
+// @ID ListSubscriptionPlans
+// @Tags Subscription Plans
+// @Description Workers see the plans on offer, admins see all of them
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/subscription-plans [get]
+// @Router /admin/subscription-plans [get]
+func (c *SubscriptionHandler) ListPlans(activeOnly bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		plans, err := c.subscriptionUseCase.ListPlans(ctx, activeOnly)
+		if err != nil {
+			ctx.Error(err).SetMeta("Failed to List Plans")
+			return
+		}
+
+		response := utils.SuccessResponse(true, "SUCCESS", plans)
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		utils.ResponseJSON(ctx, response)
+	}
+}
+
+// @Summary Subscribe To A Plan
+// @ID Subscribe
+// @Tags Worker Subscription
+// @Description The first period is paid out of the wallet, later ones renew from it automatically
+// @Produce json
+// @Security BearerAuth
+// @Param subscribe body domain.SubscribeInput{} true "Plan"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/subscription [post]
+func (c *SubscriptionHandler) Subscribe(ctx *gin.Context) {
+	var subscribe domain.SubscribeInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &subscribe, "Failed to Fetch Data") {
+		return
+	}
+
+	subscription, err := c.subscriptionUseCase.Subscribe(ctx, id, subscribe)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Subscribe")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", subscription)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Get Subscription
+// @ID GetSubscription
+// @Tags Worker Subscription
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/subscription [get]
+func (c *SubscriptionHandler) GetSubscription(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	subscription, err := c.subscriptionUseCase.GetSubscription(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Subscription")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", subscription)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Turn Auto Renewal On Or Off
+// @ID SetAutoRenew
+// @Tags Worker Subscription
+// @Description Without auto renewal the plan ends with its current period
+// @Produce json
+// @Security BearerAuth
+// @Param renew body domain.AutoRenewInput{} true "Auto Renew"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/subscription/auto-renew [patch]
+func (c *SubscriptionHandler) SetAutoRenew(ctx *gin.Context) {
+	var renew domain.AutoRenewInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &renew, "Failed to Fetch Data") {
+		return
+	}
+
+	subscription, err := c.subscriptionUseCase.SetAutoRenew(ctx, id, renew.AutoRenew)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Update Subscription")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", subscription)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewSubscriptionHandler(subscriptionUseCase services.SubscriptionUseCase) SubscriptionHandler {
+	return SubscriptionHandler{
+		subscriptionUseCase: subscriptionUseCase,
+	}
+}
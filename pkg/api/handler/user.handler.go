@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -30,16 +29,13 @@ func (c *UserHandler) GetUserProfile(ctx *gin.Context) {
 	profile, err := c.userUseCase.GetProfile(ctx, id)
 
 	if err != nil {
-		response := utils.ErrorResponse("Failed to Update User Email", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Failed to Update User Email")
 		return
 	}
 	response := utils.SuccessResponse(true, "SUCCESS", profile)
 	ctx.Writer.Header().Set("Content-Type", "application/json")
 	ctx.Writer.WriteHeader(http.StatusOK)
-	utils.ResponseJSON(*ctx, response)
+	utils.ResponseJSON(ctx, response)
 }
 
 // @Summary Add User Profile And Update Mail
@@ -55,42 +51,22 @@ func (c *UserHandler) AddProfileAndUpdateMail(ctx *gin.Context) {
 	var userData domain.UserData
 	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
 
-	fmt.Println("userId ; ", id)
-
-	err := ctx.Bind(&userData)
-	if err != nil {
-		response := utils.ErrorResponse("Failed to Fetch Data", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusBadRequest)
-		utils.ResponseJSON(*ctx, response)
+	if !bind(ctx, &userData, "Failed to Fetch Data") {
 		return
 	}
 	userData.UserId = id
 
-	err = c.userUseCase.UpdateMail(ctx, userData.Email, userData.UserId)
-
-	if err != nil {
-		response := utils.ErrorResponse("Failed to Update User Email", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
-		return
-	}
-
-	err = c.userUseCase.AddProfile(ctx, userData)
+	err := c.userUseCase.AddProfileAndUpdateMail(ctx, userData)
 
 	if err != nil {
-		response := utils.ErrorResponse("Failed to Add User Profile", err.Error(), nil)
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.WriteHeader(http.StatusUnprocessableEntity)
-		utils.ResponseJSON(*ctx, response)
+		ctx.Error(err).SetMeta("Failed to Add User Profile")
 		return
 	}
 
 	response := utils.SuccessResponse(true, "SUCCESS", nil)
 	ctx.Writer.Header().Set("Content-Type", "application/json")
 	ctx.Writer.WriteHeader(http.StatusOK)
-	utils.ResponseJSON(*ctx, response)
+	utils.ResponseJSON(ctx, response)
 }
 
 func NewUserHandler(userUseCase services.UserUseCase) UserHandler {
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	walletUseCase services.WalletUseCase
+	cursorCodec   utils.CursorCodec
+}
+
+// @Summary Wallet Balance
+// @ID GetWallet
+// @Tags Worker Wallet
+// @Description Balance is what the ledger owes the worker, available leaves out payouts awaiting review
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/wallet [get]
+func (c *WalletHandler) GetWallet(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	wallet, err := c.walletUseCase.GetWallet(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Wallet")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", wallet)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Wallet Entries
+// @ID ListWalletEntries
+// @Tags Worker Wallet
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/wallet/entries [get]
+func (c *WalletHandler) ListWalletEntries(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	entries, meta, err := c.walletUseCase.ListEntries(ctx, id, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Entries")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, entries, meta)
+}
+
+// @Summary Monthly Earnings Statement
+// @ID GetStatement
+// @Tags Worker Wallet
+// @Produce json
+// @Security BearerAuth
+// @Param month path string true "Month as YYYY-MM"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/wallet/statements/{month} [get]
+func (c *WalletHandler) GetStatement(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	statement, err := c.walletUseCase.Statement(ctx, id, ctx.Param("month"))
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Statement")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", statement)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Request Payout
+// @ID RequestPayout
+// @Tags Worker Wallet
+// @Produce json
+// @Security BearerAuth
+// @Param payout body domain.PayoutInput{} true "Payout"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/payouts [post]
+func (c *WalletHandler) RequestPayout(ctx *gin.Context) {
+	var payout domain.PayoutInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &payout, "Failed to Fetch Data") {
+		return
+	}
+
+	requested, err := c.walletUseCase.RequestPayout(ctx, id, payout)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Request Payout")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", requested)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary List Payouts
+// @ID ListPayouts
+// @Tags Worker Wallet
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/payouts [get]
+func (c *WalletHandler) ListPayouts(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	payouts, meta, err := c.walletUseCase.ListPayouts(ctx, id, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Payouts")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, payouts, meta)
+}
+
+// @Summary List Payouts By Status
+// @ID ListPayoutRequests
+// @Tags Admin Payouts
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "requested (default), approved, rejected or paid"
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Param mode query string false "offset or cursor"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/payouts [get]
+func (c *WalletHandler) ListPayoutRequests(ctx *gin.Context) {
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	payouts, meta, err := c.walletUseCase.ListPayoutsByStatus(ctx, ctx.Query("status"), filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Payouts")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, payouts, meta)
+}
+
+// @Summary Approve Payout
+// @ID ApprovePayout
+// @Tags Admin Payouts
+// @Description Debits the worker's wallet, the payout goes out with the next batch
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Payout Id"
+// @Param review body domain.PayoutReviewInput{} false "Review"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/payouts/{id}/approve [patch]
+func (c *WalletHandler) ApprovePayout(ctx *gin.Context) {
+	c.review(ctx, c.walletUseCase.ApprovePayout)
+}
+
+// @Summary Reject Payout
+// @ID RejectPayout
+// @Tags Admin Payouts
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Payout Id"
+// @Param review body domain.PayoutReviewInput{} false "Review"
+// @Success 200 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/payouts/{id}/reject [patch]
+func (c *WalletHandler) RejectPayout(ctx *gin.Context) {
+	c.review(ctx, c.walletUseCase.RejectPayout)
+}
+
+// review decides on the payout in the path on behalf of the logged in admin.
+// The note is optional, so an empty body is fine.
+func (c *WalletHandler) review(ctx *gin.Context, decide func(ctx context.Context, adminId int, payoutId int, review domain.PayoutReviewInput) (domain.Payout, error)) {
+	var review domain.PayoutReviewInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	payoutId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if ctx.Request.ContentLength != 0 {
+		if !bind(ctx, &review, "Failed to Fetch Data") {
+			return
+		}
+	}
+
+	payout, err := decide(ctx, id, payoutId, review)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Review Payout")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", payout)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Export Payout Batch
+// @ID ExportPayoutBatch
+// @Tags Admin Payouts
+// @Description Puts every approved payout in a new batch, marks them paid and returns the batch as CSV
+// @Produce text/csv
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/payout-batches [post]
+func (c *WalletHandler) ExportPayoutBatch(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	batch, file, err := c.walletUseCase.ExportPayoutBatch(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Export Payouts")
+		return
+	}
+
+	writeBatch(ctx, batch, file)
+}
+
+// @Summary Download Payout Batch
+// @ID GetPayoutBatch
+// @Tags Admin Payouts
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path int true "Batch Id"
+// @Success 200 {file} file
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/payout-batches/{id} [get]
+func (c *WalletHandler) GetPayoutBatch(ctx *gin.Context) {
+	batchId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	batch, file, err := c.walletUseCase.GetPayoutBatch(ctx, batchId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Batch")
+		return
+	}
+
+	writeBatch(ctx, batch, file)
+}
+
+// writeBatch answers with a payout batch as a CSV download
+func writeBatch(ctx *gin.Context, batch domain.PayoutBatch, file []byte) {
+	ctx.Writer.Header().Set("Content-Type", "text/csv")
+	ctx.Writer.Header().Set("Content-Disposition", `attachment; filename="payout-batch-`+strconv.Itoa(batch.IdBatch)+`.csv"`)
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(file)
+}
+
+// @Summary Adjust Worker Wallet
+// @ID AdjustWallet
+// @Tags Admin Payouts
+// @Description Posts a correction to a worker's wallet, a negative amount takes money off
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Worker Id"
+// @Param adjustment body domain.AdjustmentInput{} true "Adjustment"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 403 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /admin/workers/{id}/adjustments [post]
+func (c *WalletHandler) AdjustWallet(ctx *gin.Context) {
+	var adjustment domain.AdjustmentInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	workerId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &adjustment, "Failed to Fetch Data") {
+		return
+	}
+
+	transaction, err := c.walletUseCase.Adjust(ctx, id, workerId, adjustment)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Adjust Wallet")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", transaction)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+func NewWalletHandler(walletUseCase services.WalletUseCase, cursorCodec utils.CursorCodec) WalletHandler {
+	return WalletHandler{
+		walletUseCase: walletUseCase,
+		cursorCodec:   cursorCodec,
+	}
+}
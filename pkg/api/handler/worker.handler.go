@@ -1,15 +1,287 @@
 package handler
 
 import (
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/gin-gonic/gin"
 )
 
 type WorkerHandler struct {
 	workerService services.WorkerUseCase
+	cursorCodec   utils.CursorCodec
+}
+
+// @Summary Set Weekly Availability
+// @ID SetAvailability
+// @Tags Worker Availability
+// @Produce json
+// @Security BearerAuth
+// @Param availability body domain.WeeklyAvailability{} true "Weekly Availability"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/availability [put]
+func (c *WorkerHandler) SetAvailability(ctx *gin.Context) {
+	var availability domain.WeeklyAvailability
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &availability, "Failed to Fetch Data") {
+		return
+	}
+
+	err := c.workerService.SetAvailability(ctx, id, availability)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Set Availability")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Get Weekly Availability
+// @ID GetAvailability
+// @Tags Worker Availability
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/availability [get]
+func (c *WorkerHandler) GetAvailability(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	availability, err := c.workerService.GetAvailability(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Get Availability")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", availability)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Add Blackout Day
+// @ID AddBlackout
+// @Tags Worker Availability
+// @Produce json
+// @Security BearerAuth
+// @Param blackout body domain.BlackoutInput{} true "Blackout Day"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/blackouts [post]
+func (c *WorkerHandler) AddBlackout(ctx *gin.Context) {
+	var blackout domain.BlackoutInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &blackout, "Failed to Fetch Data") {
+		return
+	}
+
+	blackoutId, err := c.workerService.AddBlackout(ctx, id, blackout)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Add Blackout")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", blackoutId)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary List Blackout Days
+// @ID ListBlackouts
+// @Tags Worker Availability
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/blackouts [get]
+func (c *WorkerHandler) ListBlackouts(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	blackouts, err := c.workerService.ListBlackouts(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Blackouts")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", blackouts)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Delete Blackout Day
+// @ID DeleteBlackout
+// @Tags Worker Availability
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Blackout Id"
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/blackouts/{id} [delete]
+func (c *WorkerHandler) DeleteBlackout(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	blackoutId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.workerService.DeleteBlackout(ctx, id, blackoutId)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Delete Blackout")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Add Job
+// @ID AddJob
+// @Tags Worker Jobs
+// @Description A new job is open at once and counts against the listing limit of the worker's plan
+// @Produce json
+// @Security BearerAuth
+// @Param job body domain.JobInput{} true "Job"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/jobs [post]
+func (c *WorkerHandler) AddJob(ctx *gin.Context) {
+	var job domain.JobInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	if !bind(ctx, &job, "Failed to Fetch Data") {
+		return
+	}
+
+	jobId, err := c.workerService.AddJob(ctx, id, job)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Add Job")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", jobId)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary List Own Jobs
+// @ID ListJobs
+// @Tags Worker Jobs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/jobs [get]
+func (c *WorkerHandler) ListJobs(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	jobs, err := c.workerService.ListJobs(ctx, id)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to List Jobs")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", jobs)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Open Or Close Job
+// @ID SetJobOpen
+// @Tags Worker Jobs
+// @Description Reopening a job counts against the listing limit of the worker's plan
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Job Id"
+// @Param open body domain.JobOpenInput{} true "Open"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 409 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /worker/jobs/{id}/open [patch]
+func (c *WorkerHandler) SetJobOpen(ctx *gin.Context) {
+	var open domain.JobOpenInput
+	id, _ := strconv.Atoi(ctx.Writer.Header().Get("id"))
+
+	jobId, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if !bind(ctx, &open, "Failed to Fetch Data") {
+		return
+	}
+
+	err := c.workerService.SetJobOpen(ctx, id, jobId, open)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Update Job")
+		return
+	}
+
+	response := utils.SuccessResponse(true, "SUCCESS", nil)
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	utils.ResponseJSON(ctx, response)
+}
+
+// @Summary Search Jobs
+// @ID SearchJobs
+// @Tags User Jobs
+// @Description Open jobs with the ones of priority plans first. Paged by offset only.
+// @Produce json
+// @Security BearerAuth
+// @Param category query int false "Category Id"
+// @Param page query int false "Page"
+// @Param page_size query int false "Page Size"
+// @Success 200 {object} utils.Response{}
+// @Failure 400 {object} utils.Response{}
+// @Failure 422 {object} utils.Response{}
+// @Router /user/jobs [get]
+func (c *WorkerHandler) SearchJobs(ctx *gin.Context) {
+	var categoryId int
+	if category := ctx.Query("category"); category != "" {
+		var err error
+		categoryId, err = strconv.Atoi(category)
+		if err != nil {
+			ctx.Error(utils.InvalidField(err, "category", numberField)).SetMeta("Invalid Category")
+			return
+		}
+	}
+
+	filter, ok := bindPage(ctx, c.cursorCodec)
+	if !ok {
+		return
+	}
+
+	jobs, meta, err := c.workerService.SearchJobs(ctx, categoryId, filter)
+	if err != nil {
+		ctx.Error(err).SetMeta("Failed to Search Jobs")
+		return
+	}
+
+	writePage(ctx, c.cursorCodec, jobs, meta)
 }
 
-func NewWorkerHandler(workerService services.WorkerUseCase) WorkerHandler {
+func NewWorkerHandler(workerService services.WorkerUseCase, cursorCodec utils.CursorCodec) WorkerHandler {
 	return WorkerHandler{
 		workerService: workerService,
+		cursorCodec:   cursorCodec,
 	}
 }
@@ -1,24 +1,40 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	service "github.com/fazilnbr/project-workey/pkg/usecase/interface"
 	"github.com/fazilnbr/project-workey/pkg/utils"
 	response "github.com/fazilnbr/project-workey/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 type Middleware interface {
 	AthoriseJWT(*gin.Context)
+	AuthoriseRole(roles ...string) gin.HandlerFunc
+	AuditRequest(*gin.Context)
+	QueryTimeout(*gin.Context)
+	HandleErrors(*gin.Context)
+	RequestID(*gin.Context)
+	LogRequest(*gin.Context)
 }
 
 type middlewar struct {
-	jwtUseCase service.JWTUseCase
+	jwtUseCase   service.JWTUseCase
+	auditUseCase service.AuditUseCase
+	sessions     *sessionCache
+	queryTimeout time.Duration
+	logger       *logrus.Logger
 }
 
 // AthoriseJWT implements Middileware
@@ -37,7 +53,7 @@ func (cr *middlewar) AthoriseJWT(c *gin.Context) {
 		c.Writer.Header().Set("Content-Type", "application/json")
 		c.Writer.WriteHeader(http.StatusUnauthorized)
 
-		utils.ResponseJSON(*c, response)
+		utils.ResponseJSON(c, response)
 		c.Abort()
 		return
 	}
@@ -47,15 +63,12 @@ func (cr *middlewar) AthoriseJWT(c *gin.Context) {
 	ok, claims := cr.jwtUseCase.VerifyToken(authtoken)
 	source := fmt.Sprint(claims.Source)
 
-	fmt.Printf("\n\nok : %v\n\n", time.Now().Unix()-claims.ExpiresAt)
-	fmt.Printf("\n\nsorce : %v\n\n", source)
-
 	if !ok {
 		err := errors.New("your access token is not valid")
 		response := response.ErrorResponse("Error", err.Error(), source)
 		c.Writer.Header().Add("Content-Type", "application/json")
 		c.Writer.WriteHeader(http.StatusUnauthorized)
-		utils.ResponseJSON(*c, response)
+		utils.ResponseJSON(c, response)
 		c.Abort()
 		return
 	}
@@ -65,32 +78,194 @@ func (cr *middlewar) AthoriseJWT(c *gin.Context) {
 		response := response.ErrorResponse("Error", err.Error(), source)
 		c.Writer.Header().Add("Content-Type", "application/json")
 		c.Writer.WriteHeader(http.StatusUnauthorized)
-		utils.ResponseJSON(*c, response)
+		utils.ResponseJSON(c, response)
+		c.Abort()
+		return
+	}
+
+	// A locked or logged out account loses the tokens it already holds
+	if err := cr.sessions.check(c, claims.UserId, claims.IssuedAt); err != nil {
+		status, code := utils.ErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			cr.logger.WithContext(c.Request.Context()).WithError(err).Error("failed to check the session")
+		} else {
+			status = http.StatusUnauthorized
+		}
+		response := response.ErrorResponse("Error", utils.ErrorMessage(err), nil)
+		response.Code = code
+		c.Writer.Header().Add("Content-Type", "application/json")
+		c.Writer.WriteHeader(status)
+		utils.ResponseJSON(c, response)
 		c.Abort()
 		return
 	}
 
 	user_email := fmt.Sprint(claims.UserName)
 	id := fmt.Sprint(claims.UserId)
-	fmt.Printf("\n\nid : %v\n\n", id)
 
 	c.Writer.Header().Set("email", user_email)
 	c.Writer.Header().Set("id", id)
+	c.Set(domain.AuditActorKey, domain.AuditActor{
+		Id:        claims.UserId,
+		Role:      claims.Role,
+		Ip:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
 
 }
 
-func NewUserMiddileware(jwtUserUseCase service.JWTUseCase) Middleware {
+// AuthoriseRole implements Middleware. It must run after AthoriseJWT, which
+// puts the role of the token on the context with the rest of the actor.
+func (cr *middlewar) AuthoriseRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, _ := c.Value(domain.AuditActorKey).(domain.AuditActor)
+		for _, allowed := range roles {
+			if actor.Role == allowed {
+				return
+			}
+		}
+
+		err := errors.New("your account is not allowed to do this")
+		response := response.ErrorResponse("Error", err.Error(), nil)
+		c.Writer.Header().Set("Content-Type", "application/json")
+		c.Writer.WriteHeader(http.StatusForbidden)
+		utils.ResponseJSON(c, response)
+		c.Abort()
+	}
+}
+
+// AuditRequest implements Middleware. It must run after AthoriseJWT and
+// records every change that goes through, reads are left out.
+func (cr *middlewar) AuditRequest(c *gin.Context) {
+	c.Next()
+
+	if c.Request.Method == http.MethodGet || c.Writer.Status() >= http.StatusBadRequest {
+		return
+	}
+	targetId, _ := strconv.Atoi(c.Param("id"))
+	request, _ := json.Marshal(map[string]string{"method": c.Request.Method, "route": c.FullPath()})
+	cr.auditUseCase.Record(c, domain.AuditLog{
+		Action:     domain.AuditRequest,
+		TargetType: domain.AuditTargetRoute,
+		TargetId:   targetId,
+		After:      string(request),
+	})
+}
+
+// QueryTimeout implements Middleware. It puts a deadline on the request,
+// which the queries made for it inherit, so a slow query gives up instead of
+// holding on to a connection.
+func (cr *middlewar) QueryTimeout(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), cr.queryTimeout)
+	defer cancel()
+
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// HandleErrors implements Middleware. Handlers pass the error a request
+// failed with to ctx.Error, with the message to answer with as its meta, and
+// it is answered here with the status and error code the error calls for. An
+// invalid request is answered with what is wrong with each of its fields. A
+// failure that is not a domain error is logged and answered with a 500 that
+// keeps its text to the logs.
+func (cr *middlewar) HandleErrors(c *gin.Context) {
+	c.Next()
+
+	last := c.Errors.Last()
+	if last == nil || c.Writer.Written() {
+		return
+	}
+	message, ok := last.Meta.(string)
+	if !ok {
+		message = "Error"
+	}
+
+	status, code := utils.ErrorStatus(last.Err)
+	if status >= http.StatusInternalServerError {
+		cr.logger.WithContext(c.Request.Context()).WithError(last.Err).WithField("path", c.Request.URL.Path).Error(message)
+	}
+	response := response.ErrorResponse(message, utils.ErrorMessage(last.Err), nil)
+	response.Code = code
+	if fields := utils.ErrorFields(last.Err); fields != nil {
+		response.Errors = fields
+	}
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.WriteHeader(status)
+	utils.ResponseJSON(c, response)
+}
+
+// RequestID implements Middleware. It keeps the id a request came with in
+// X-Request-ID, or gives it one, and puts it on the request's context, so
+// what is logged for the request carries it. The id is sent back in the same
+// header for clients to quote.
+func (cr *middlewar) RequestID(c *gin.Context) {
+	id := c.GetHeader(utils.RequestIDHeader)
+	if !utils.ValidRequestID(id) {
+		id = utils.NewRequestID()
+	}
+
+	c.Request = c.Request.WithContext(utils.WithRequestID(c.Request.Context(), id))
+	c.Writer.Header().Set(utils.RequestIDHeader, id)
+	c.Next()
+}
+
+// LogRequest implements Middleware. It logs every request once answered. The
+// query string is left out, it can carry what logs must not show.
+func (cr *middlewar) LogRequest(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	entry := cr.logger.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+		"method":  c.Request.Method,
+		"path":    c.Request.URL.Path,
+		"status":  status,
+		"latency": time.Since(start).String(),
+		"ip":      c.ClientIP(),
+	})
+	switch {
+	case status >= http.StatusInternalServerError:
+		entry.Error("request failed")
+	case status >= http.StatusBadRequest:
+		entry.Warn("request refused")
+	default:
+		entry.Info("request served")
+	}
+}
+
+// queryTimeout is how long a request may spend on its queries
+func queryTimeout(cfg config.Config) time.Duration {
+	if cfg.DBQueryTimeout <= 0 {
+		return domain.DefaultQueryTimeoutSeconds * time.Second
+	}
+	return time.Duration(cfg.DBQueryTimeout) * time.Second
+}
+
+func NewUserMiddileware(jwtUserUseCase service.JWTUseCase, auditUseCase service.AuditUseCase, userUseCase service.UserUseCase, cfg config.Config, logger *logrus.Logger) Middleware {
 	return &middlewar{
-		jwtUseCase: jwtUserUseCase,
+		jwtUseCase:   jwtUserUseCase,
+		auditUseCase: auditUseCase,
+		sessions:     newSessionCache(userUseCase),
+		queryTimeout: queryTimeout(cfg),
+		logger:       logger,
 	}
 }
-func NewWorkerMiddileware(jwtWorkerUsecase service.JWTUseCase) Middleware {
+func NewWorkerMiddileware(jwtWorkerUsecase service.JWTUseCase, auditUseCase service.AuditUseCase, userUseCase service.UserUseCase, cfg config.Config, logger *logrus.Logger) Middleware {
 	return &middlewar{
-		jwtUseCase: jwtWorkerUsecase,
+		jwtUseCase:   jwtWorkerUsecase,
+		auditUseCase: auditUseCase,
+		sessions:     newSessionCache(userUseCase),
+		queryTimeout: queryTimeout(cfg),
+		logger:       logger,
 	}
 }
-func NewAdminMiddileware(jwtAdminUseCase service.JWTUseCase) Middleware {
+func NewAdminMiddileware(jwtAdminUseCase service.JWTUseCase, auditUseCase service.AuditUseCase, userUseCase service.UserUseCase, cfg config.Config, logger *logrus.Logger) Middleware {
 	return &middlewar{
-		jwtUseCase: jwtAdminUseCase,
+		jwtUseCase:   jwtAdminUseCase,
+		auditUseCase: auditUseCase,
+		sessions:     newSessionCache(userUseCase),
+		queryTimeout: queryTimeout(cfg),
+		logger:       logger,
 	}
 }
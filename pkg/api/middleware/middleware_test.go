@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/usecase"
+	service "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// sessionUseCase answers session checks with err and counts them
+type sessionUseCase struct {
+	service.UserUseCase
+	err    error
+	checks int
+}
+
+func (s *sessionUseCase) CheckSession(ctx context.Context, userId int, issuedAt time.Time) error {
+	s.checks++
+	return s.err
+}
+
+func TestAthoriseJWTChecksSession(t *testing.T) {
+	t.Setenv("USER_KEY", "testkey")
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	jwtUseCase := usecase.NewJWTUserService(logger)
+
+	tests := []struct {
+		name           string
+		sessionErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "test a live session", expectedStatus: http.StatusOK},
+		{name: "test a blocked account", sessionErr: domain.ErrBlocked, expectedStatus: http.StatusUnauthorized, expectedCode: "account_blocked"},
+		{name: "test a suspended account", sessionErr: domain.ErrSuspended, expectedStatus: http.StatusUnauthorized, expectedCode: "account_suspended"},
+		{name: "test a forced logout", sessionErr: domain.ErrLoggedOut, expectedStatus: http.StatusUnauthorized, expectedCode: "session_ended"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &sessionUseCase{err: tt.sessionErr}
+			mw := NewUserMiddileware(jwtUseCase, nil, sessions, config.Config{}, logger)
+			engine := gin.New()
+			engine.GET("/profile", mw.AthoriseJWT, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			token, err := jwtUseCase.GenerateAccessToken(1, "", domain.RoleUser)
+			assert.NoError(t, err)
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				rec := httptest.NewRecorder()
+				engine.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), tt.expectedCode)
+				assert.Empty(t, rec.Header().Get("role"))
+			}
+			// The second request is answered from the cache
+			assert.Equal(t, 1, sessions.checks)
+		})
+	}
+}
+
+func TestHandleErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+		expectedLogged bool
+	}{
+		{name: "test a domain error", err: domain.ErrSlotBooked, expectedStatus: http.StatusConflict, expectedBody: "the selected slot is already booked"},
+		{name: "test a business rule", err: domain.ErrPayoutMinimum, expectedStatus: http.StatusUnprocessableEntity, expectedBody: "payout_too_small"},
+		{name: "test a database error", err: errors.New(`pq: relation "payouts" does not exist`), expectedStatus: http.StatusInternalServerError, expectedBody: "something went wrong", expectedLogged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&logs)
+			mw := NewUserMiddileware(nil, nil, nil, config.Config{}, logger)
+			engine := gin.New()
+			engine.Use(mw.HandleErrors)
+			engine.GET("/payouts", func(c *gin.Context) { c.Error(tt.err).SetMeta("failed to request payout") })
+
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/payouts", nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			assert.NotContains(t, rec.Body.String(), "relation")
+			assert.Equal(t, tt.expectedLogged, strings.Contains(logs.String(), "relation"))
+		})
+	}
+}
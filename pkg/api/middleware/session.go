@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	service "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+)
+
+// sessionCacheTTL is how long the outcome of a session check is reused. A
+// block, suspension or forced logout reaches tokens in use within it.
+const sessionCacheTTL = 15 * time.Second
+
+type sessionKey struct {
+	userId   int
+	issuedAt int64
+}
+
+type sessionOutcome struct {
+	err     error
+	expires time.Time
+}
+
+// sessionCache remembers for a short while whether the tokens of an account
+// are still good, so a check doesn't cost every request a query
+type sessionCache struct {
+	userUseCase service.UserUseCase
+	mu          sync.Mutex
+	outcomes    map[sessionKey]sessionOutcome
+	swept       time.Time
+}
+
+// check fails when the account of a token issued at issuedAt got locked or
+// logged out after it
+func (s *sessionCache) check(ctx context.Context, userId int, issuedAt int64) error {
+	key := sessionKey{userId: userId, issuedAt: issuedAt}
+	now := time.Now()
+
+	s.mu.Lock()
+	outcome, ok := s.outcomes[key]
+	s.mu.Unlock()
+	if ok && now.Before(outcome.expires) {
+		return outcome.err
+	}
+
+	err := s.userUseCase.CheckSession(ctx, userId, time.Unix(issuedAt, 0))
+	// Only a verdict on the session is kept, a failure to reach the database
+	// says nothing about it
+	var verdict *domain.Error
+	if err != nil && !errors.As(err, &verdict) {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.swept) >= sessionCacheTTL {
+		for key, outcome := range s.outcomes {
+			if !now.Before(outcome.expires) {
+				delete(s.outcomes, key)
+			}
+		}
+		s.swept = now
+	}
+	s.outcomes[key] = sessionOutcome{err: err, expires: now.Add(sessionCacheTTL)}
+	return err
+}
+
+func newSessionCache(userUseCase service.UserUseCase) *sessionCache {
+	return &sessionCache{
+		userUseCase: userUseCase,
+		outcomes:    make(map[sessionKey]sessionOutcome),
+	}
+}
@@ -1,32 +1,53 @@
 package api
 
 import (
-	"fmt"
-	"log"
+	"context"
 
 	"github.com/fazilnbr/project-workey/pkg/api/handler"
 	"github.com/fazilnbr/project-workey/pkg/api/middleware"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 type ServerHTTP struct {
-	engine *gin.Engine
+	engine              *gin.Engine
+	mailUseCase         services.MailUseCase
+	subscriptionUseCase services.SubscriptionUseCase
+	analyticsUseCase    services.AnalyticsUseCase
+	logger              *logrus.Logger
 }
 
-func NewServerHTTP(authHandler handler.AuthHandler, adminHandler handler.AdminHandler, UserHandler handler.UserHandler, WorkerHandler handler.WorkerHandler, middleware middleware.Middleware) *ServerHTTP {
+func NewServerHTTP(authHandler handler.AuthHandler, adminHandler handler.AdminHandler, UserHandler handler.UserHandler, WorkerHandler handler.WorkerHandler, BookingHandler handler.BookingHandler, OfferHandler handler.OfferHandler, ChatHandler handler.ChatHandler, NotificationHandler handler.NotificationHandler, PaymentHandler handler.PaymentHandler, WalletHandler handler.WalletHandler, InvoiceHandler handler.InvoiceHandler, PromoHandler handler.PromoHandler, ReferralHandler handler.ReferralHandler, SubscriptionHandler handler.SubscriptionHandler, DisputeHandler handler.DisputeHandler, AuditHandler handler.AuditHandler, AnalyticsHandler handler.AnalyticsHandler, ExportHandler handler.ExportHandler, ImportHandler handler.ImportHandler, middleware middleware.Middleware, mailUseCase services.MailUseCase, subscriptionUseCase services.SubscriptionUseCase, analyticsUseCase services.AnalyticsUseCase, logger *logrus.Logger) (*ServerHTTP, error) {
 	engine := gin.New()
+	// Handlers hand the gin context to the usecases, so it has to carry the
+	// deadline and cancellation of the request down to the queries
+	engine.ContextWithFallback = true
 	authHandler.InitializeOAuthGoogle()
 
-	// Use logger from Gin
-	engine.Use(gin.Logger())
+	// Requests are checked against the rules of the application as they bind
+	if err := utils.RegisterValidators(binding.Validator.Engine().(*validator.Validate)); err != nil {
+		return nil, err
+	}
+
+	// Give every request an id and log it once answered, under the same id as
+	// whatever is logged while serving it
+	engine.Use(middleware.RequestID, middleware.LogRequest)
+
+	// Answer the errors handlers pass on with the status their kind calls for
+	engine.Use(middleware.HandleErrors)
 
 	// Swagger docs
 	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
 	// Group users
-	user := engine.Group("user")
+	user := engine.Group("user", middleware.QueryTimeout)
 	{
 		// Phone number authentication
 		user.POST("/sent-otp", authHandler.UserSendOTP)
@@ -40,19 +61,229 @@ func NewServerHTTP(authHandler handler.AuthHandler, adminHandler handler.AdminHa
 		engine.GET("/refresh-token", authHandler.RefreshToken)
 
 		// Use Middileware
-		user.Use(middleware.AthoriseJWT)
+		user.Use(middleware.AthoriseJWT, middleware.AuthoriseRole(domain.RoleUser))
 
 		user.POST("/profile", UserHandler.AddProfileAndUpdateMail)
 		user.GET("/profile", UserHandler.GetUserProfile)
+		user.GET("/referral", ReferralHandler.GetReferral)
+
+		// Job search
+		user.GET("/jobs", WorkerHandler.SearchJobs)
+
+		// Bookings
+		user.POST("/requests", BookingHandler.Book)
+		user.GET("/requests", BookingHandler.ListUserBookings)
+		user.PATCH("/requests/:id/cancel", BookingHandler.CancelBooking)
+		user.PATCH("/requests/:id/no-show", BookingHandler.ReportNoShow)
+		user.POST("/promo-codes/check", PromoHandler.CheckPromoCode)
+
+		// Payments
+		user.POST("/requests/:id/pay", PaymentHandler.Pay)
+		user.GET("/requests/:id/payment", PaymentHandler.GetPayment)
+
+		// Disputes
+		user.POST("/requests/:id/disputes", DisputeHandler.OpenDispute)
+		user.GET("/disputes", DisputeHandler.ListDisputes)
+		user.GET("/disputes/:id", DisputeHandler.GetDispute)
+		user.POST("/disputes/:id/evidence", DisputeHandler.AddEvidence)
+		user.GET("/disputes/:id/evidence/:evidenceId", DisputeHandler.DownloadEvidence)
+		user.POST("/disputes/:id/messages", DisputeHandler.PostMessage)
+
+		// Invoices
+		user.GET("/requests/:id/invoices", InvoiceHandler.ListInvoices)
+		user.GET("/invoices/:id/pdf", InvoiceHandler.DownloadInvoice)
+
+		// Price negotiation
+		user.POST("/requests/:id/offers", OfferHandler.ProposeOffer)
+		user.GET("/requests/:id/offers", OfferHandler.ListOffers(domain.PartyUser))
+		user.PATCH("/offers/:id/accept", OfferHandler.AcceptOffer(domain.PartyUser))
+		user.PATCH("/offers/:id/reject", OfferHandler.RejectOffer(domain.PartyUser))
+		user.POST("/offers/:id/counter", OfferHandler.CounterOffer(domain.PartyUser))
+
+		// Chat
+		user.GET("/requests/:id/messages", ChatHandler.ListMessages)
+		user.POST("/requests/:id/messages/read", ChatHandler.MarkRead)
+
+		// Notifications
+		user.GET("/notifications", NotificationHandler.ListNotifications)
+		user.GET("/notifications/unread-count", NotificationHandler.UnreadCount)
+		user.PATCH("/notifications/:id/read", NotificationHandler.MarkRead)
+		user.PATCH("/notifications/read-all", NotificationHandler.MarkAllRead)
+		user.GET("/notification-preferences", NotificationHandler.GetPreferences)
+		user.PUT("/notification-preferences", NotificationHandler.SetPreferences)
+		user.POST("/devices", NotificationHandler.RegisterDevice)
+		user.DELETE("/devices/:token", NotificationHandler.RemoveDevice)
 	}
 
-	return &ServerHTTP{engine: engine}
+	// Group workers
+	worker := engine.Group("worker", middleware.QueryTimeout)
+	{
+		worker.Use(middleware.AthoriseJWT, middleware.AuthoriseRole(domain.RoleWorker))
+
+		// Availability calendar
+		worker.PUT("/availability", WorkerHandler.SetAvailability)
+		worker.GET("/availability", WorkerHandler.GetAvailability)
+		worker.POST("/blackouts", WorkerHandler.AddBlackout)
+		worker.GET("/blackouts", WorkerHandler.ListBlackouts)
+		worker.DELETE("/blackouts/:id", WorkerHandler.DeleteBlackout)
+
+		// Jobs
+		worker.POST("/jobs", WorkerHandler.AddJob)
+		worker.GET("/jobs", WorkerHandler.ListJobs)
+		worker.PATCH("/jobs/:id/open", WorkerHandler.SetJobOpen)
+
+		// Premium subscription
+		worker.GET("/subscription-plans", SubscriptionHandler.ListPlans(true))
+		worker.POST("/subscription", SubscriptionHandler.Subscribe)
+		worker.GET("/subscription", SubscriptionHandler.GetSubscription)
+		worker.PATCH("/subscription/auto-renew", SubscriptionHandler.SetAutoRenew)
+
+		// Incoming requests
+		worker.GET("/requests", BookingHandler.ListWorkerBookings)
+		worker.PATCH("/requests/:id/accept", BookingHandler.AcceptBooking)
+		worker.PATCH("/requests/:id/reject", BookingHandler.RejectBooking)
+		worker.PATCH("/requests/:id/complete", BookingHandler.CompleteBooking)
+		worker.PATCH("/requests/:id/cancel", BookingHandler.WorkerCancelBooking)
+		worker.GET("/requests/:id/payment", PaymentHandler.GetPayment)
+
+		// Disputes
+		worker.POST("/requests/:id/disputes", DisputeHandler.OpenDispute)
+		worker.GET("/disputes", DisputeHandler.ListDisputes)
+		worker.GET("/disputes/:id", DisputeHandler.GetDispute)
+		worker.POST("/disputes/:id/evidence", DisputeHandler.AddEvidence)
+		worker.GET("/disputes/:id/evidence/:evidenceId", DisputeHandler.DownloadEvidence)
+		worker.POST("/disputes/:id/messages", DisputeHandler.PostMessage)
+
+		// Invoices
+		worker.GET("/requests/:id/invoices", InvoiceHandler.ListInvoices)
+		worker.GET("/invoices/:id/pdf", InvoiceHandler.DownloadInvoice)
+
+		// Wallet and payouts
+		worker.GET("/wallet", WalletHandler.GetWallet)
+		worker.GET("/referral", ReferralHandler.GetReferral)
+		worker.GET("/wallet/entries", WalletHandler.ListWalletEntries)
+		worker.GET("/wallet/statements/:month", WalletHandler.GetStatement)
+		worker.POST("/payouts", WalletHandler.RequestPayout)
+		worker.GET("/payouts", WalletHandler.ListPayouts)
+
+		// Price negotiation
+		worker.GET("/requests/:id/offers", OfferHandler.ListOffers(domain.PartyWorker))
+		worker.PATCH("/offers/:id/accept", OfferHandler.AcceptOffer(domain.PartyWorker))
+		worker.PATCH("/offers/:id/reject", OfferHandler.RejectOffer(domain.PartyWorker))
+		worker.POST("/offers/:id/counter", OfferHandler.CounterOffer(domain.PartyWorker))
+
+		// Chat
+		worker.GET("/requests/:id/messages", ChatHandler.ListMessages)
+		worker.POST("/requests/:id/messages/read", ChatHandler.MarkRead)
+
+		// Notifications
+		worker.GET("/notifications", NotificationHandler.ListNotifications)
+		worker.GET("/notifications/unread-count", NotificationHandler.UnreadCount)
+		worker.PATCH("/notifications/:id/read", NotificationHandler.MarkRead)
+		worker.PATCH("/notifications/read-all", NotificationHandler.MarkAllRead)
+		worker.GET("/notification-preferences", NotificationHandler.GetPreferences)
+		worker.PUT("/notification-preferences", NotificationHandler.SetPreferences)
+		worker.POST("/devices", NotificationHandler.RegisterDevice)
+		worker.DELETE("/devices/:token", NotificationHandler.RemoveDevice)
+	}
+
+	// Group admins
+	admin := engine.Group("admin")
+	{
+		admin.Use(middleware.AthoriseJWT, middleware.AuthoriseRole(domain.RoleAdmin), middleware.AuditRequest)
+
+		// Exports stream for as long as the table takes, so they go without the query timeout
+		exports := admin.Group("")
+		exports.GET("/audit-logs/export", AuditHandler.ExportAuditLogs)
+		exports.GET("/users/export", ExportHandler.Export(domain.ExportUsers))
+		exports.GET("/workers/export", ExportHandler.Export(domain.ExportWorkers))
+		exports.GET("/jobs/export", ExportHandler.Export(domain.ExportJobs))
+		exports.GET("/requests/export", ExportHandler.Export(domain.ExportRequests))
+		exports.GET("/payments/export", ExportHandler.Export(domain.ExportPayments))
+
+		admin.Use(middleware.QueryTimeout)
+
+		// Audit log
+		admin.GET("/audit-logs", AuditHandler.ListAuditLogs)
+		admin.GET("/audit-logs/verify", AuditHandler.VerifyChain)
+
+		// Analytics
+		admin.GET("/analytics/signups", AnalyticsHandler.SignupsPerDay)
+		admin.GET("/analytics/workers", AnalyticsHandler.ActiveWorkers)
+		admin.GET("/analytics/funnel", AnalyticsHandler.RequestFunnel)
+		admin.GET("/analytics/cancellations", AnalyticsHandler.Cancellations)
+		admin.GET("/analytics/revenue", AnalyticsHandler.Revenue)
+		admin.GET("/analytics/ratings", AnalyticsHandler.RatingsByCategory)
+		admin.GET("/analytics/top-workers", AnalyticsHandler.TopWorkers)
+		admin.POST("/analytics/refresh", AnalyticsHandler.RefreshSummaries)
+
+		// Accounts
+		admin.GET("/users", adminHandler.SearchAccounts(domain.RoleUser))
+		admin.GET("/users/:id", adminHandler.GetAccount(domain.RoleUser))
+		admin.GET("/workers", adminHandler.SearchAccounts(domain.RoleWorker))
+		admin.GET("/workers/:id", adminHandler.GetAccount(domain.RoleWorker))
+		admin.PATCH("/accounts/:id/block", adminHandler.ActOnAccount(domain.ActionBlock))
+		admin.PATCH("/accounts/:id/suspend", adminHandler.ActOnAccount(domain.ActionSuspension))
+		admin.PATCH("/accounts/:id/unblock", adminHandler.ActOnAccount(domain.ActionUnblock))
+		admin.POST("/accounts/:id/logout", adminHandler.ActOnAccount(domain.ActionLogout))
+		admin.GET("/accounts/:id/logins", adminHandler.ListLogins)
+
+		// Imports
+		admin.POST("/categories/import", ImportHandler.ImportCategories)
+		admin.POST("/workers/import", ImportHandler.ImportWorkers)
+
+		// Mail outbox
+		admin.GET("/mails/dead-letters", adminHandler.ListDeadLetters)
+		admin.PATCH("/mails/:id/retry", adminHandler.RetryDeadLetter)
+
+		// Cancellation policies
+		admin.GET("/categories/:id/cancellation-policy", adminHandler.GetCancellationPolicy)
+		admin.PUT("/categories/:id/cancellation-policy", adminHandler.SetCancellationPolicy)
+		admin.GET("/requests/:id/policy-decisions", adminHandler.ListPolicyDecisions)
+		admin.POST("/requests/:id/refunds", PaymentHandler.Refund)
+
+		// Payouts
+		admin.GET("/payouts", WalletHandler.ListPayoutRequests)
+		admin.PATCH("/payouts/:id/approve", WalletHandler.ApprovePayout)
+		admin.PATCH("/payouts/:id/reject", WalletHandler.RejectPayout)
+		admin.POST("/payout-batches", WalletHandler.ExportPayoutBatch)
+		admin.GET("/payout-batches/:id", WalletHandler.GetPayoutBatch)
+		admin.POST("/workers/:id/adjustments", WalletHandler.AdjustWallet)
+
+		// Promo codes
+		admin.POST("/promo-codes", PromoHandler.CreatePromoCode)
+		admin.GET("/promo-codes", PromoHandler.ListPromoCodes)
+		admin.PUT("/promo-codes/:id", PromoHandler.UpdatePromoCode)
+
+		// Subscription plans
+		admin.POST("/subscription-plans", SubscriptionHandler.CreatePlan)
+		admin.GET("/subscription-plans", SubscriptionHandler.ListPlans(false))
+		admin.PUT("/subscription-plans/:id", SubscriptionHandler.UpdatePlan)
+
+		// Dispute case queue
+		admin.GET("/disputes", DisputeHandler.ListQueue)
+		admin.GET("/disputes/:id", DisputeHandler.AdminGetDispute)
+		admin.GET("/disputes/:id/evidence/:evidenceId", DisputeHandler.AdminDownloadEvidence)
+		admin.PATCH("/disputes/:id/assign", DisputeHandler.AssignDispute)
+		admin.POST("/disputes/:id/notes", DisputeHandler.AddNote)
+		admin.POST("/disputes/:id/resolve", DisputeHandler.ResolveDispute)
+	}
+
+	// Gateways authenticate their webhooks with a signature instead of a token
+	engine.POST("/payments/webhook/:gateway", middleware.QueryTimeout, PaymentHandler.Webhook)
+
+	// Chat socket authenticates the token itself as it may come as a subprotocol
+	engine.GET("/chat/requests/:id/ws", ChatHandler.Connect)
+
+	return &ServerHTTP{engine: engine, mailUseCase: mailUseCase, subscriptionUseCase: subscriptionUseCase, analyticsUseCase: analyticsUseCase, logger: logger}, nil
 }
 
 func (sh *ServerHTTP) Start() {
-	fmt.Print("\n\nddddddddd\n\n")
+	go sh.mailUseCase.Run(context.Background())
+	go sh.subscriptionUseCase.Run(context.Background())
+	go sh.analyticsUseCase.Run(context.Background())
 	err := sh.engine.Run(":9090")
 	if err != nil {
-		log.Fatalln(err)
+		sh.logger.WithError(err).Fatal("server stopped")
 	}
 }
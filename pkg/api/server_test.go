@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/api/handler"
+	"github.com/fazilnbr/project-workey/pkg/api/middleware"
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/usecase"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// liveSessions finds every session good
+type liveSessions struct {
+	services.UserUseCase
+}
+
+func (liveSessions) CheckSession(ctx context.Context, userId int, issuedAt time.Time) error {
+	return nil
+}
+
+// newTestServer is the server with handlers that have nothing behind them,
+// enough for requests the middleware turns away before they reach one
+func newTestServer(t *testing.T) (*ServerHTTP, func(role string) string) {
+	t.Setenv("USER_KEY", "testkey")
+	gin.SetMode(gin.TestMode)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	jwtUseCase := usecase.NewJWTUserService(logger)
+	mw := middleware.NewUserMiddileware(jwtUseCase, nil, liveSessions{}, config.Config{}, logger)
+
+	server, err := NewServerHTTP(handler.AuthHandler{}, handler.AdminHandler{}, handler.UserHandler{}, handler.WorkerHandler{}, handler.BookingHandler{}, handler.OfferHandler{}, handler.ChatHandler{}, handler.NotificationHandler{}, handler.PaymentHandler{}, handler.WalletHandler{}, handler.InvoiceHandler{}, handler.PromoHandler{}, handler.ReferralHandler{}, handler.SubscriptionHandler{}, handler.DisputeHandler{}, handler.AuditHandler{}, handler.AnalyticsHandler{}, handler.ExportHandler{}, handler.ImportHandler{}, mw, nil, nil, nil, logger)
+	assert.NoError(t, err)
+
+	token := func(role string) string {
+		token, err := jwtUseCase.GenerateAccessToken(1, "", role)
+		assert.NoError(t, err)
+		return token
+	}
+	return server, token
+}
+
+func TestRoutesNeedTheirRole(t *testing.T) {
+	server, token := newTestServer(t)
+
+	tests := []struct {
+		name           string
+		path           string
+		role           string
+		expectedStatus int
+	}{
+		{name: "test no token", path: "/worker/availability", expectedStatus: http.StatusUnauthorized},
+		{name: "test a user token on the calendar", path: "/worker/availability", role: domain.RoleUser, expectedStatus: http.StatusForbidden},
+		{name: "test a user token on the wallet", path: "/worker/wallet", role: domain.RoleUser, expectedStatus: http.StatusForbidden},
+		{name: "test an admin token on the jobs", path: "/worker/jobs", role: domain.RoleAdmin, expectedStatus: http.StatusForbidden},
+		{name: "test a worker token on the bookings", path: "/user/requests", role: domain.RoleWorker, expectedStatus: http.StatusForbidden},
+		{name: "test an admin token on the profile", path: "/user/profile", role: domain.RoleAdmin, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.role != "" {
+				req.Header.Set("Authorization", "Bearer "+token(tt.role))
+			}
+			rec := httptest.NewRecorder()
+			server.engine.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestInvalidQueriesAnswerWithFields(t *testing.T) {
+	server, token := newTestServer(t)
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedField string
+	}{
+		{name: "test a category that is not a number", path: "/user/jobs?category=plumbing", expectedField: "category"},
+		{name: "test a page size too large", path: "/user/jobs?page_size=1000", expectedField: "page_size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token(domain.RoleUser))
+			rec := httptest.NewRecorder()
+			server.engine.ServeHTTP(rec, req)
+
+			var response struct {
+				Code   string                       `json:"code"`
+				Errors map[string]domain.FieldError `json:"errors"`
+			}
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, "invalid_request", response.Code)
+			assert.Contains(t, response.Errors, tt.expectedField)
+		})
+	}
+}
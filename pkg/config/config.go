@@ -1,39 +1,60 @@
 package config
 
 import (
-	"fmt"
-
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBHost             string `mapstructure:"DB_HOST"`
-	DBName             string `mapstructure:"DB_NAME"`
-	DBUser             string `mapstructure:"DB_USER"`
-	DBPort             string `mapstructure:"DB_PORT"`
-	DBPassword         string `mapstructure:"DB_PASSWORD"`
-	DBSOURCE           string `mapstructure:"DB_SOURCE"`
-	SMTPPORT           string `mapstructure:"SMTP_PORT"`
-	SMTPHOST           string `mapstructure:"SMTP_HOST"`
-	SMTPPASSWORD       string `mapstructure:"SMTP_PASSWORD"`
-	SMTPUSERNAME       string `mapstructure:"SMTP_USERNAME"`
-	OauthStateString   string `mapstructure:"OauthStateString"`
-	ClientID           string `mapstructure:"ClientID"`
-	ClientSecret       string `mapstructure:"ClientSecret"`
-	TWAccountSID       string `mapstructure:"ACCOUNT_SID"`
-	TWVerifyServiseSID string `mapstructure:"VERIFY_SERVICE_SID"`
-	TWAuthTocken       string `mapstructure:"AUTH_TOKEN"`
-	TWFromPhone        string `mapstructure:"FROM_PHONE"`
+	DBHost                  string `mapstructure:"DB_HOST"`
+	DBName                  string `mapstructure:"DB_NAME"`
+	DBUser                  string `mapstructure:"DB_USER"`
+	DBPort                  string `mapstructure:"DB_PORT"`
+	DBPassword              string `mapstructure:"DB_PASSWORD"`
+	DBSOURCE                string `mapstructure:"DB_SOURCE"`
+	SMTPPORT                string `mapstructure:"SMTP_PORT"`
+	SMTPHOST                string `mapstructure:"SMTP_HOST"`
+	SMTPPASSWORD            string `mapstructure:"SMTP_PASSWORD"`
+	SMTPUSERNAME            string `mapstructure:"SMTP_USERNAME"`
+	OauthStateString        string `mapstructure:"OauthStateString"`
+	ClientID                string `mapstructure:"ClientID"`
+	ClientSecret            string `mapstructure:"ClientSecret"`
+	TWAccountSID            string `mapstructure:"ACCOUNT_SID"`
+	TWVerifyServiseSID      string `mapstructure:"VERIFY_SERVICE_SID"`
+	TWAuthTocken            string `mapstructure:"AUTH_TOKEN"`
+	TWFromPhone             string `mapstructure:"FROM_PHONE"`
+	CursorSecret            string `mapstructure:"CURSOR_SECRET"`
+	NotifyDriver            string `mapstructure:"NOTIFY_DRIVER"`
+	FCMServerKey            string `mapstructure:"FCM_SERVER_KEY"`
+	MailDriver              string `mapstructure:"MAIL_DRIVER"`
+	MailDir                 string `mapstructure:"MAIL_DIR"`
+	PaymentGateway          string `mapstructure:"PAYMENT_GATEWAY"`
+	PaymentKeyID            string `mapstructure:"PAYMENT_KEY_ID"`
+	PaymentKeySecret        string `mapstructure:"PAYMENT_KEY_SECRET"`
+	PaymentWebhookKey       string `mapstructure:"PAYMENT_WEBHOOK_SECRET"`
+	CommissionBps           int    `mapstructure:"COMMISSION_BPS"`
+	PayoutMinimum           int64  `mapstructure:"PAYOUT_MINIMUM"`
+	TaxBps                  int    `mapstructure:"TAX_BPS"`
+	ReferralReward          int64  `mapstructure:"REFERRAL_REWARD"`
+	GraceDays               int    `mapstructure:"SUBSCRIPTION_GRACE_DAYS"`
+	FileStoreDir            string `mapstructure:"FILE_STORE_DIR"`
+	AnalyticsRefreshMinutes int    `mapstructure:"ANALYTICS_REFRESH_MINUTES"`
+	DBMaxOpenConns          int    `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns          int    `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime       int    `mapstructure:"DB_CONN_MAX_LIFETIME_MINUTES"`
+	DBConnMaxIdleTime       int    `mapstructure:"DB_CONN_MAX_IDLE_MINUTES"`
+	DBQueryTimeout          int    `mapstructure:"DB_QUERY_TIMEOUT_SECONDS"`
+	DefaultPhoneRegion      string `mapstructure:"DEFAULT_PHONE_REGION"`
+	LogLevel                string `mapstructure:"LOG_LEVEL"`
+	LogFormat               string `mapstructure:"LOG_FORMAT"`
 }
 
 var envs = []string{
-	"DB_HOST", "DB_NAME", "DB_USER", "DB_PORT", "DB_PASSWORD", "DB_SOURCE", "SMTP_PORT", "SMTP_HOST", "SMTP_PASSWORD", "SMTP_USERNAME", "OauthStateString", "ClientID", "ClientSecret", "ACCOUNT_SID", "VERIFY_SERVICE_SID", "AUTH_TOKEN", "FROM_PHONE",
+	"DB_HOST", "DB_NAME", "DB_USER", "DB_PORT", "DB_PASSWORD", "DB_SOURCE", "SMTP_PORT", "SMTP_HOST", "SMTP_PASSWORD", "SMTP_USERNAME", "OauthStateString", "ClientID", "ClientSecret", "ACCOUNT_SID", "VERIFY_SERVICE_SID", "AUTH_TOKEN", "FROM_PHONE", "CURSOR_SECRET", "NOTIFY_DRIVER", "FCM_SERVER_KEY", "MAIL_DRIVER", "MAIL_DIR", "PAYMENT_GATEWAY", "PAYMENT_KEY_ID", "PAYMENT_KEY_SECRET", "PAYMENT_WEBHOOK_SECRET", "COMMISSION_BPS", "PAYOUT_MINIMUM", "TAX_BPS", "REFERRAL_REWARD", "SUBSCRIPTION_GRACE_DAYS", "FILE_STORE_DIR", "ANALYTICS_REFRESH_MINUTES", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME_MINUTES", "DB_CONN_MAX_IDLE_MINUTES", "DB_QUERY_TIMEOUT_SECONDS", "DEFAULT_PHONE_REGION", "LOG_LEVEL", "LOG_FORMAT",
 }
 
 func LoadConfig() (Config, error) {
 	var config Config
-	fmt.Printf("\n\n\ncfg : %v\n\n\n", config)
 
 	viper.AddConfigPath("./")
 	viper.SetConfigFile(".env")
@@ -52,6 +73,5 @@ func LoadConfig() (Config, error) {
 	if err := validator.New().Struct(&config); err != nil {
 		return config, err
 	}
-	fmt.Printf("\n\nconfig : %v\n\n", config)
 	return config, nil
 }
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FakeDriver selects the fake delivery providers through NOTIFY_DRIVER, for
+// local development and tests where nothing should leave the machine
+const FakeDriver = "fake"
+
+// FakeDelivery is one message a fake provider was asked to deliver
+type FakeDelivery struct {
+	Channel string
+	To      string
+	Subject string
+	Body    string
+}
+
+// FakeSender implements MailConfig, SMSConfig and PushConfig by logging and
+// remembering every delivery instead of sending it. Bodies carry codes and
+// links, so they are remembered but never logged.
+type FakeSender struct {
+	mu     sync.Mutex
+	sent   []FakeDelivery
+	logger *logrus.Logger
+}
+
+// SendMail implements MailConfig
+func (f *FakeSender) SendMail(cfg Config, to string, message []byte) error {
+	f.record(FakeDelivery{Channel: "email", To: to, Body: string(message)})
+	return nil
+}
+
+// SendSMS implements SMSConfig
+func (f *FakeSender) SendSMS(cfg Config, to string, body string) error {
+	f.record(FakeDelivery{Channel: "sms", To: to, Body: body})
+	return nil
+}
+
+// SendPush implements PushConfig
+func (f *FakeSender) SendPush(cfg Config, deviceToken string, title string, body string, data map[string]string) error {
+	f.record(FakeDelivery{Channel: "push", To: deviceToken, Subject: title, Body: body})
+	return nil
+}
+
+// Sent returns the deliveries recorded so far
+func (f *FakeSender) Sent() []FakeDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeDelivery(nil), f.sent...)
+}
+
+func (f *FakeSender) record(delivery FakeDelivery) {
+	f.mu.Lock()
+	f.sent = append(f.sent, delivery)
+	f.mu.Unlock()
+	f.logger.WithFields(logrus.Fields{"channel": delivery.Channel, "to": delivery.To}).Info("fake delivery")
+}
+
+func NewFakeSender(logger *logrus.Logger) *FakeSender {
+	return &FakeSender{logger: logger}
+}
+
+// FakeGateway implements PaymentGateway in memory. Its webhooks are JSON
+// GatewayEvents signed like Razorpay's, in the X-Fake-Signature header.
+type FakeGateway struct {
+	mu      sync.Mutex
+	orders  map[string]GatewayOrder
+	refunds map[string]string
+}
+
+// Name implements PaymentGateway
+func (g *FakeGateway) Name() string {
+	return GatewayFake
+}
+
+// CreateOrder implements PaymentGateway
+func (g *FakeGateway) CreateOrder(cfg Config, order PaymentOrder) (GatewayOrder, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.orders[order.Reference]; ok {
+		return existing, nil
+	}
+	created := GatewayOrder{OrderId: fmt.Sprintf("fake_order_%d", len(g.orders)+1)}
+	g.orders[order.Reference] = created
+	return created, nil
+}
+
+// Refund implements PaymentGateway
+func (g *FakeGateway) Refund(cfg Config, refund RefundOrder) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.refunds[refund.Reference]; ok {
+		return existing, nil
+	}
+	id := fmt.Sprintf("fake_refund_%d", len(g.refunds)+1)
+	g.refunds[refund.Reference] = id
+	return id, nil
+}
+
+// ParseWebhook implements PaymentGateway
+func (g *FakeGateway) ParseWebhook(cfg Config, header http.Header, payload []byte) (GatewayEvent, error) {
+	if !signatureMatches(hmacHex(cfg.PaymentWebhookKey, payload), header.Get("X-Fake-Signature")) {
+		return GatewayEvent{}, ErrInvalidSignature
+	}
+	var event GatewayEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return event, err
+	}
+	if event.Status != GatewayPaymentCaptured && event.Status != GatewayPaymentFailed {
+		return event, ErrIgnoredEvent
+	}
+	return event, nil
+}
+
+// Sign returns the X-Fake-Signature of a webhook payload
+func (g *FakeGateway) Sign(cfg Config, payload []byte) string {
+	return hmacHex(cfg.PaymentWebhookKey, payload)
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		orders:  map[string]GatewayOrder{},
+		refunds: map[string]string{},
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore keeps uploaded files. Keys are slash separated relative paths.
+type FileStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+}
+
+// diskFileStore keeps files under FILE_STORE_DIR
+type diskFileStore struct {
+	dir string
+}
+
+func NewFileStore(cfg Config) FileStore {
+	dir := cfg.FileStoreDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "workey-files")
+	}
+	return &diskFileStore{dir: dir}
+}
+
+// Put implements FileStore
+func (c *diskFileStore) Put(key string, data []byte) error {
+	path, err := c.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Get implements FileStore
+func (c *diskFileStore) Get(key string) ([]byte, error) {
+	path, err := c.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// path keeps a key from reaching outside the store
+func (c *diskFileStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", errors.New("invalid file key " + key)
+	}
+	return filepath.Join(c.dir, clean), nil
+}
@@ -1,9 +1,9 @@
 package config
 
 import (
-	"fmt"
-	"log"
 	"net/smtp"
+
+	"github.com/sirupsen/logrus"
 )
 
 type MailConfig interface {
@@ -12,14 +12,27 @@ type MailConfig interface {
 
 type mailConfig struct{}
 
-func NewMailConfig() MailConfig {
+// Mail transports selectable through MAIL_DRIVER, SMTP is used when it is empty
+const (
+	MailDriverSMTP    = "smtp"
+	MailDriverFile    = "file"
+	MailDriverConsole = "console"
+)
+
+func NewMailConfig(cfg Config, logger *logrus.Logger) MailConfig {
+	if cfg.NotifyDriver == FakeDriver {
+		return NewFakeSender(logger)
+	}
+	switch cfg.MailDriver {
+	case MailDriverFile:
+		return &fileMailConfig{}
+	case MailDriverConsole:
+		return &consoleMailConfig{logger: logger}
+	}
 	return &mailConfig{}
 }
 
 func (c *mailConfig) SendMail(cfg Config, to string, message []byte) error {
-
-	fmt.Printf("\n\nemail :  %v\n\n", to)
-	log.Println("Email Id to send message : ", to)
 	userName := cfg.SMTPUSERNAME
 	password := cfg.SMTPPASSWORD
 	smtpHost := cfg.SMTPHOST
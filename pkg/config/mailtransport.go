@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fileMailConfig writes every mail as an .eml file under MAIL_DIR so it can be
+// opened in a mail client during development
+type fileMailConfig struct{}
+
+// SendMail implements MailConfig
+func (c *fileMailConfig) SendMail(cfg Config, to string, message []byte) error {
+	dir := cfg.MailDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "workey-mail")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(to))
+	return os.WriteFile(filepath.Join(dir, name), message, 0o644)
+}
+
+// consoleMailConfig logs every mail instead of sending it. Mails carry codes
+// and links that let anyone into an account, so only who a mail is for and
+// how large it is are logged, the file driver keeps whole mails to read.
+type consoleMailConfig struct {
+	logger *logrus.Logger
+}
+
+// SendMail implements MailConfig
+func (c *consoleMailConfig) SendMail(cfg Config, to string, message []byte) error {
+	c.logger.WithFields(logrus.Fields{"to": to, "bytes": len(message)}).Info("mail not sent, the console driver only logs it")
+	return nil
+}
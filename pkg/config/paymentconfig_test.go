@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPaymentGateway(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          Config
+		expectedName string
+		expectedErr  bool
+	}{
+		{name: "test razorpay", cfg: Config{PaymentGateway: GatewayRazorpay, PaymentWebhookKey: "secret"}, expectedName: GatewayRazorpay},
+		{name: "test stripe", cfg: Config{PaymentGateway: GatewayStripe, PaymentWebhookKey: "secret"}, expectedName: GatewayStripe},
+		{name: "test the fake gateway asked for", cfg: Config{PaymentGateway: GatewayFake, PaymentWebhookKey: "secret"}, expectedName: GatewayFake},
+		{name: "test no gateway", cfg: Config{PaymentWebhookKey: "secret"}, expectedErr: true},
+		{name: "test an unknown gateway", cfg: Config{PaymentGateway: "paypal", PaymentWebhookKey: "secret"}, expectedErr: true},
+		{name: "test no webhook secret", cfg: Config{PaymentGateway: GatewayFake}, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, err := NewPaymentGateway(tt.cfg)
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+				assert.Nil(t, gateway)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedName, gateway.Name())
+		})
+	}
+}
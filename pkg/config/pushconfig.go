@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const fcmSendURL = "https://fcm.googleapis.com/fcm/send"
+
+type PushConfig interface {
+	SendPush(cfg Config, deviceToken string, title string, body string, data map[string]string) error
+}
+
+type pushConfig struct {
+	client *http.Client
+}
+
+// SendPush implements PushConfig using the FCM HTTP API
+func (c *pushConfig) SendPush(cfg Config, deviceToken string, title string, body string, data map[string]string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"to": deviceToken,
+		"notification": map[string]string{
+			"title": title,
+			"body":  body,
+		},
+		"data": data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fcmSendURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "key="+cfg.FCMServerKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("push provider answered %s", resp.Status)
+	}
+	return nil
+}
+
+func NewPushConfig(cfg Config, logger *logrus.Logger) PushConfig {
+	if cfg.NotifyDriver == FakeDriver {
+		return NewFakeSender(logger)
+	}
+	return &pushConfig{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
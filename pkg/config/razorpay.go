@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	razorpayOrdersURL   = "https://api.razorpay.com/v1/orders"
+	razorpayPaymentsURL = "https://api.razorpay.com/v1/payments/"
+)
+
+type razorpayGateway struct {
+	client *http.Client
+}
+
+// Name implements PaymentGateway
+func (g *razorpayGateway) Name() string {
+	return GatewayRazorpay
+}
+
+// CreateOrder implements PaymentGateway
+func (g *razorpayGateway) CreateOrder(cfg Config, order PaymentOrder) (GatewayOrder, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"amount":   order.Amount,
+		"currency": order.Currency,
+		"receipt":  order.Reference,
+	})
+	if err != nil {
+		return GatewayOrder{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, razorpayOrdersURL, bytes.NewReader(body))
+	if err != nil {
+		return GatewayOrder{}, err
+	}
+	req.SetBasicAuth(cfg.PaymentKeyID, cfg.PaymentKeySecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return GatewayOrder{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return GatewayOrder{}, fmt.Errorf("razorpay answered %s", resp.Status)
+	}
+
+	var created struct {
+		Id string `json:"id"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return GatewayOrder{}, err
+	}
+	return GatewayOrder{OrderId: created.Id, KeyId: cfg.PaymentKeyID}, nil
+}
+
+// Refund implements PaymentGateway
+func (g *razorpayGateway) Refund(cfg Config, refund RefundOrder) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"amount":  refund.Amount,
+		"receipt": refund.Reference,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, razorpayPaymentsURL+refund.PaymentId+"/refund", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(cfg.PaymentKeyID, cfg.PaymentKeySecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("razorpay answered %s", resp.Status)
+	}
+
+	var created struct {
+		Id string `json:"id"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.Id, nil
+}
+
+// ParseWebhook implements PaymentGateway
+func (g *razorpayGateway) ParseWebhook(cfg Config, header http.Header, payload []byte) (GatewayEvent, error) {
+	if !signatureMatches(hmacHex(cfg.PaymentWebhookKey, payload), header.Get("X-Razorpay-Signature")) {
+		return GatewayEvent{}, ErrInvalidSignature
+	}
+
+	var webhook struct {
+		Event   string `json:"event"`
+		Payload struct {
+			Payment struct {
+				Entity struct {
+					Id      string `json:"id"`
+					OrderId string `json:"order_id"`
+				} `json:"entity"`
+			} `json:"payment"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return GatewayEvent{}, err
+	}
+
+	event := GatewayEvent{
+		EventId:   header.Get("X-Razorpay-Event-Id"),
+		OrderId:   webhook.Payload.Payment.Entity.OrderId,
+		PaymentId: webhook.Payload.Payment.Entity.Id,
+	}
+	if event.EventId == "" {
+		event.EventId = webhook.Event + ":" + event.PaymentId
+	}
+	switch webhook.Event {
+	case "payment.captured":
+		event.Status = GatewayPaymentCaptured
+	case "payment.failed":
+		event.Status = GatewayPaymentFailed
+	default:
+		return event, ErrIgnoredEvent
+	}
+	return event, nil
+}
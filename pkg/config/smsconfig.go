@@ -0,0 +1,36 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/twilio/twilio-go"
+	openapi "github.com/twilio/twilio-go/rest/api/v2010"
+)
+
+type SMSConfig interface {
+	SendSMS(cfg Config, to string, body string) error
+}
+
+type smsConfig struct{}
+
+// SendSMS implements SMSConfig
+func (c *smsConfig) SendSMS(cfg Config, to string, body string) error {
+	client := twilio.NewRestClientWithParams(twilio.ClientParams{
+		Username: cfg.TWAccountSID,
+		Password: cfg.TWAuthTocken,
+	})
+
+	params := &openapi.CreateMessageParams{}
+	params.SetTo(to)
+	params.SetFrom(cfg.TWFromPhone)
+	params.SetBody(body)
+
+	_, err := client.Api.CreateMessage(params)
+	return err
+}
+
+func NewSMSConfig(cfg Config, logger *logrus.Logger) SMSConfig {
+	if cfg.NotifyDriver == FakeDriver {
+		return NewFakeSender(logger)
+	}
+	return &smsConfig{}
+}
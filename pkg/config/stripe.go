@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stripePaymentIntentsURL = "https://api.stripe.com/v1/payment_intents"
+	stripeRefundsURL        = "https://api.stripe.com/v1/refunds"
+	// stripeTolerance is how old a signed webhook may be before it is taken for a replay
+	stripeTolerance = 5 * time.Minute
+)
+
+type stripeGateway struct {
+	client *http.Client
+}
+
+// Name implements PaymentGateway
+func (g *stripeGateway) Name() string {
+	return GatewayStripe
+}
+
+// CreateOrder implements PaymentGateway
+func (g *stripeGateway) CreateOrder(cfg Config, order PaymentOrder) (GatewayOrder, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(order.Amount, 10))
+	form.Set("currency", strings.ToLower(order.Currency))
+	form.Set("metadata[reference]", order.Reference)
+
+	req, err := http.NewRequest(http.MethodPost, stripePaymentIntentsURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return GatewayOrder{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.PaymentKeySecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", order.Reference)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return GatewayOrder{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return GatewayOrder{}, fmt.Errorf("stripe answered %s", resp.Status)
+	}
+
+	var intent struct {
+		Id           string `json:"id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&intent); err != nil {
+		return GatewayOrder{}, err
+	}
+	return GatewayOrder{OrderId: intent.Id, ClientSecret: intent.ClientSecret, KeyId: cfg.PaymentKeyID}, nil
+}
+
+// Refund implements PaymentGateway
+func (g *stripeGateway) Refund(cfg Config, refund RefundOrder) (string, error) {
+	form := url.Values{}
+	form.Set("payment_intent", refund.OrderId)
+	form.Set("amount", strconv.FormatInt(refund.Amount, 10))
+
+	req, err := http.NewRequest(http.MethodPost, stripeRefundsURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.PaymentKeySecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", refund.Reference)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("stripe answered %s", resp.Status)
+	}
+
+	var created struct {
+		Id string `json:"id"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.Id, nil
+}
+
+// ParseWebhook implements PaymentGateway
+func (g *stripeGateway) ParseWebhook(cfg Config, header http.Header, payload []byte) (GatewayEvent, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header.Get("Stripe-Signature"), ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(signedAt, 0)) > stripeTolerance {
+		return GatewayEvent{}, ErrInvalidSignature
+	}
+	expected := hmacHex(cfg.PaymentWebhookKey, []byte(timestamp+"."+string(payload)))
+	valid := false
+	for _, signature := range signatures {
+		if signatureMatches(expected, signature) {
+			valid = true
+		}
+	}
+	if !valid {
+		return GatewayEvent{}, ErrInvalidSignature
+	}
+
+	var webhook struct {
+		Id   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				Id            string `json:"id"`
+				LatestCharge  string `json:"latest_charge"`
+				PaymentIntent string `json:"payment_intent"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(payload, &webhook); err != nil {
+		return GatewayEvent{}, err
+	}
+
+	event := GatewayEvent{
+		EventId:   webhook.Id,
+		OrderId:   webhook.Data.Object.Id,
+		PaymentId: webhook.Data.Object.LatestCharge,
+	}
+	switch webhook.Type {
+	case "payment_intent.succeeded":
+		event.Status = GatewayPaymentCaptured
+	case "payment_intent.payment_failed":
+		event.Status = GatewayPaymentFailed
+	default:
+		return event, ErrIgnoredEvent
+	}
+	return event, nil
+}
@@ -1,16 +1,29 @@
 package db
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
-	"log"
+	"time"
 
 	config "github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/sirupsen/logrus"
 
 	_ "github.com/lib/pq"
 )
 
-func ConnectDB(cfg config.Config) *sql.DB {
+// The pool settings used unless configured otherwise. Idle connections are
+// given back before the server would close them, and kept connections are
+// renewed now and then so a failed over database is picked up.
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+	defaultConnMaxIdleTime = 5 * time.Minute
+	pingTimeout            = 10 * time.Second
+)
+
+// ConnectDB opens the pool every part of the application shares
+func ConnectDB(cfg config.Config, logger *logrus.Logger) *sql.DB {
 
 	databaseName := cfg.DBName
 
@@ -20,19 +33,38 @@ func ConnectDB(cfg config.Config) *sql.DB {
 	db, err := sql.Open("postgres", dbURI)
 
 	if err != nil {
-		log.Fatal(err)
+		logger.WithError(err).Fatal("cannot open the database")
 	}
 
+	db.SetMaxOpenConns(orDefault(cfg.DBMaxOpenConns, defaultMaxOpenConns))
+	db.SetMaxIdleConns(orDefault(cfg.DBMaxIdleConns, defaultMaxIdleConns))
+	db.SetConnMaxLifetime(minutesOrDefault(cfg.DBConnMaxLifetime, defaultConnMaxLifetime))
+	db.SetConnMaxIdleTime(minutesOrDefault(cfg.DBConnMaxIdleTime, defaultConnMaxIdleTime))
+
 	// verifies connection to the database is still alive
-	err = db.Ping()
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	err = db.PingContext(ctx)
 	if err != nil {
-		fmt.Println("error in pinging")
-		log.Fatal(err)
-
+		logger.WithError(err).Fatal("cannot reach the database")
 	}
 
-	log.Println("\nConnected to database:", databaseName)
+	logger.WithField("database", databaseName).Info("connected to database")
 
 	return db
 
 }
+
+func orDefault(value int, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func minutesOrDefault(minutes int, fallback time.Duration) time.Duration {
+	if minutes <= 0 {
+		return fallback
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,329 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey is the advisory lock a migrator holds on schema_migrations
+// while it works, so two of them never change the schema at once
+const migrationLockKey = 7_305_002
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a numbered change to the schema along with the way back
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration and when it was applied, if it was. A dirty
+// migration failed part way and needs a forced version before going on.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+	Dirty     bool
+}
+
+// Migrator applies the migrations embedded in the binary and keeps track of
+// them in schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// LoadMigrations reads the embedded migrations in order. Each version needs
+// an up and a down file, and versions run from 1 without gaps.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s is not named version_name.up.sql or version_name.down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d needs both an up and a down file", migration.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Latest is the version the code expects the schema to be at
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Up applies the pending migrations in order and returns the ones applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.statuses(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err = checkClean(statuses); err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.AppliedAt != nil {
+				continue
+			}
+			err = m.run(ctx, conn, status.Migration, status.Up,
+				`UPDATE schema_migrations SET dirty=false, applied_at=NOW() WHERE version=$1;`)
+			if err != nil {
+				return err
+			}
+			applied = append(applied, status.Migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps migrations applied, newest first, and returns
+// the ones reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.statuses(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err = checkClean(statuses); err != nil {
+			return err
+		}
+
+		for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+			if statuses[i].AppliedAt == nil {
+				continue
+			}
+			err = m.run(ctx, conn, statuses[i].Migration, statuses[i].Down,
+				`DELETE FROM schema_migrations WHERE version=$1;`)
+			if err != nil {
+				return err
+			}
+			reverted = append(reverted, statuses[i].Migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status is every migration along with whether it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		var err error
+		statuses, err = m.statuses(ctx, conn)
+		return err
+	})
+	return statuses, err
+}
+
+// Force records the schema as being at version without running anything,
+// which clears a dirty migration once the schema has been put right by hand
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version < 0 || version > m.Latest() {
+		return fmt.Errorf("version has to be between 0 and %d", m.Latest())
+	}
+
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version>$1 OR dirty;`, version); err != nil {
+			return err
+		}
+		for _, migration := range m.migrations[:version] {
+			query := `INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES ($1,$2,false,NOW())
+						ON CONFLICT (version) DO NOTHING;`
+			if _, err = tx.ExecContext(ctx, query, migration.Version, migration.Name); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+}
+
+// Check fails when the schema is behind the code or a migration is dirty. It
+// only reads, so it needs no lock.
+func (m *Migrator) Check(ctx context.Context) error {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL;`).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the schema has no migrations applied but the code needs version %d, run migrate up", m.Latest())
+	}
+
+	var version int
+	var dirty bool
+	query := `SELECT COALESCE(MAX(version), 0), COALESCE(BOOL_OR(dirty), false) FROM schema_migrations;`
+	if err := m.db.QueryRowContext(ctx, query).Scan(&version, &dirty); err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed part way, put the schema right and run migrate force", version)
+	}
+	if version < m.Latest() {
+		return fmt.Errorf("the schema is at version %d but the code needs version %d, run migrate up", version, m.Latest())
+	}
+	return nil
+}
+
+// locked runs fn on a connection holding the migration lock, creating
+// schema_migrations first if need be
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+				version bigint PRIMARY KEY,
+				name text NOT NULL,
+				dirty boolean NOT NULL DEFAULT false,
+				applied_at timestamptz
+			);`
+	if _, err = conn.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1);`, migrationLockKey); err != nil {
+		return err
+	}
+	// The lock goes with the session, so it has to be given back before the
+	// connection returns to the pool
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1);`, migrationLockKey)
+
+	return fn(conn)
+}
+
+// statuses reads which of the migrations were applied
+func (m *Migrator) statuses(ctx context.Context, conn *sql.Conn) ([]MigrationStatus, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, dirty, applied_at FROM schema_migrations ORDER BY version;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recorded := make(map[int]MigrationStatus)
+	for rows.Next() {
+		var status MigrationStatus
+		var appliedAt sql.NullTime
+		if err = rows.Scan(&status.Version, &status.Dirty, &appliedAt); err != nil {
+			return nil, err
+		}
+		if appliedAt.Valid {
+			status.AppliedAt = &appliedAt.Time
+		}
+		recorded[status.Version] = status
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = recorded[migration.Version]
+		statuses[i].Migration = migration
+	}
+	return statuses, nil
+}
+
+// run runs one direction of a migration in a transaction along with record,
+// which updates its row in schema_migrations. The row is marked dirty
+// beforehand, so a migration that fails leaves the mark behind.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, script string, record string) error {
+	query := `INSERT INTO schema_migrations (version, name, dirty) VALUES ($1,$2,true)
+				ON CONFLICT (version) DO UPDATE SET dirty=true;`
+	if _, err := conn.ExecContext(ctx, query, migration.Version, migration.Name); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err = tx.ExecContext(ctx, record, migration.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkClean fails when a migration is dirty
+func checkClean(statuses []MigrationStatus) error {
+	for _, status := range statuses {
+		if status.Dirty {
+			return fmt.Errorf("migration %d failed part way, put the schema right and run migrate force", status.Version)
+		}
+	}
+	return nil
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
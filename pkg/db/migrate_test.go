@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, migrations) {
+		assert.Equal(t, "initial_schema", migrations[0].Name)
+	}
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "initial_schema", Up: "CREATE TABLE a (id bigint);", Down: "DROP TABLE a;"},
+	{Version: 2, Name: "add_b", Up: "CREATE TABLE b (id bigint);", Down: "DROP TABLE b;"},
+}
+
+// expectLock expects the migration lock to be taken, statuses reading
+// the versions applied
+func expectLock(mock sqlmock.Sqlmock, applied ...int) {
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT pg_advisory_lock\\(\\$1\\);").WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version", "dirty", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, false, time.Now())
+	}
+	mock.ExpectQuery("SELECT version, dirty, applied_at FROM schema_migrations ORDER BY version;").WillReturnRows(rows)
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("SELECT pg_advisory_unlock\\(\\$1\\);").WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	migrator := &Migrator{db: db, migrations: testMigrations}
+
+	tests := []struct {
+		name            string
+		beforeTest      func()
+		expectedApplied []Migration
+		expectedErr     error
+	}{
+		{
+			name: "test the pending migration is applied",
+			beforeTest: func() {
+				expectLock(mock, 1)
+				mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(2, "add_b").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE TABLE b").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("UPDATE schema_migrations SET dirty=false").WithArgs(2).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				expectUnlock(mock)
+			},
+			expectedApplied: testMigrations[1:],
+		},
+		{
+			name: "test a failed migration is left dirty",
+			beforeTest: func() {
+				expectLock(mock)
+				mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(1, "initial_schema").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE TABLE a").WillReturnError(errors.New(`relation "a" already exists`))
+				mock.ExpectRollback()
+				expectUnlock(mock)
+			},
+			expectedErr: errors.New(`migration 1_initial_schema: relation "a" already exists`),
+		},
+		{
+			name: "test an up to date schema is left alone",
+			beforeTest: func() {
+				expectLock(mock, 1, 2)
+				expectUnlock(mock)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+			ctx := context.Background()
+
+			actualApplied, actualErr := migrator.Up(ctx)
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, actualErr, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, actualErr)
+			}
+			assert.Equal(t, tt.expectedApplied, actualApplied)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestMigrator_Check(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	migrator := &Migrator{db: db, migrations: testMigrations}
+
+	tests := []struct {
+		name        string
+		beforeTest  func()
+		expectedErr error
+	}{
+		{
+			name: "test a schema never migrated is behind",
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedErr: errors.New("the schema has no migrations applied but the code needs version 2, run migrate up"),
+		},
+		{
+			name: "test a schema behind the code",
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+			},
+			expectedErr: errors.New("the schema is at version 1 but the code needs version 2, run migrate up"),
+		},
+		{
+			name: "test a dirty schema",
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, true))
+			},
+			expectedErr: errors.New("migration 2 failed part way, put the schema right and run migrate force"),
+		},
+		{
+			name: "test an up to date schema",
+			beforeTest: func() {
+				mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, false))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+			ctx := context.Background()
+
+			actualErr := migrator.Check(ctx)
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+DROP TABLE IF EXISTS "audit_logs";
+DROP TABLE IF EXISTS "login_events";
+DROP TABLE IF EXISTS "account_actions";
+DROP TABLE IF EXISTS "dispute_messages";
+DROP TABLE IF EXISTS "dispute_evidences";
+DROP TABLE IF EXISTS "disputes";
+DROP TABLE IF EXISTS "subscriptions";
+DROP TABLE IF EXISTS "subscription_plans";
+DROP TABLE IF EXISTS "signup_devices";
+DROP TABLE IF EXISTS "referrals";
+DROP TABLE IF EXISTS "referral_codes";
+DROP TABLE IF EXISTS "promo_redemptions";
+DROP TABLE IF EXISTS "request_materials";
+DROP TABLE IF EXISTS "invoice_sequences";
+DROP TABLE IF EXISTS "invoice_lines";
+DROP TABLE IF EXISTS "invoices";
+DROP TABLE IF EXISTS "payouts";
+DROP TABLE IF EXISTS "payout_batches";
+DROP TABLE IF EXISTS "ledger_entries";
+DROP TABLE IF EXISTS "ledger_transactions";
+DROP TABLE IF EXISTS "policy_decisions";
+DROP TABLE IF EXISTS "refunds";
+DROP TABLE IF EXISTS "cancellation_policies";
+DROP TABLE IF EXISTS "payment_events";
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "outbox_mails";
+DROP TABLE IF EXISTS "device_tokens";
+DROP TABLE IF EXISTS "notification_preferences";
+DROP TABLE IF EXISTS "notifications";
+DROP TABLE IF EXISTS "messages";
+DROP TABLE IF EXISTS "offers";
+DROP TABLE IF EXISTS "blackouts";
+DROP TABLE IF EXISTS "availabilities";
+DROP TABLE IF EXISTS "banners";
+DROP TABLE IF EXISTS "ratings";
+DROP TABLE IF EXISTS "verifications";
+DROP TABLE IF EXISTS "favorites";
+DROP TABLE IF EXISTS "requests";
+DROP TABLE IF EXISTS "promo_codes";
+DROP TABLE IF EXISTS "jobs";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "addresses";
+DROP TABLE IF EXISTS "profiles";
+DROP TABLE IF EXISTS "users";
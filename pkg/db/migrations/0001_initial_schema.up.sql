@@ -0,0 +1,635 @@
+-- The whole schema before versioned migrations, which every later migration
+-- builds on: the original tables along with those of every feature added
+-- while AutoMigrate still ran, as the last release running it left them.
+-- Tables are only created when missing and existing ones are left as they
+-- are, so what this does depends on the database it runs on:
+--
+--   - an empty database gets the whole schema.
+--   - a database AutoMigrate kept up to date until that last release is
+--     left as it is.
+--   - an older database only gets the tables it lacks. Its users, addresses
+--     and requests tables keep their old columns, and the index on
+--     addresses.city fails for want of the column. Start the last release
+--     running AutoMigrate on such a database once before migrating it.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id_user" bigserial UNIQUE,
+    "phone" text NOT NULL UNIQUE,
+    "email" text NOT NULL UNIQUE,
+    "password" text,
+    "user_type" text NOT NULL,
+    "verification" boolean DEFAULT false,
+    "status" text DEFAULT 'newuser',
+    "suspended_until" timestamptz,
+    "logged_out_at" timestamptz,
+    "signup_channel" text NOT NULL DEFAULT 'phone',
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_user")
+);
+
+CREATE TABLE IF NOT EXISTS "profiles" (
+    "id_profie" bigserial UNIQUE,
+    "user_id" bigint,
+    "first_name" text,
+    "last_name" text,
+    "gender" text,
+    "dob" text,
+    "profile_photo" text,
+    PRIMARY KEY ("id_profie"),
+    CONSTRAINT "fk_profiles_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+
+CREATE TABLE IF NOT EXISTS "addresses" (
+    "id_address" bigserial UNIQUE,
+    "user_id" bigint,
+    "address_category" text,
+    "mapcoordinates" text,
+    "housenumber" text,
+    "floor" text,
+    "blockor_tower" text,
+    "landmark" text,
+    "city" text,
+    PRIMARY KEY ("id_address"),
+    CONSTRAINT "fk_addresses_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+CREATE INDEX IF NOT EXISTS "idx_addresses_city" ON "addresses" ("city");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id_category" bigserial UNIQUE,
+    "category" text UNIQUE,
+    "category_icon" text UNIQUE,
+    PRIMARY KEY ("id_category")
+);
+
+CREATE TABLE IF NOT EXISTS "jobs" (
+    "id_job" bigserial UNIQUE,
+    "id_worker" bigint NOT NULL,
+    "category_id" bigint NOT NULL,
+    "expirience" text,
+    "description" text,
+    "full_day_wage" bigint NOT NULL,
+    "half_day_wage" bigint NOT NULL,
+    "openwork" boolean DEFAULT true,
+    "priority" boolean,
+    PRIMARY KEY ("id_job"),
+    CONSTRAINT "fk_jobs_user" FOREIGN KEY ("id_worker") REFERENCES "users"("id_user"),
+    CONSTRAINT "fk_jobs_category" FOREIGN KEY ("category_id") REFERENCES "categories"("id_category")
+);
+
+CREATE TABLE IF NOT EXISTS "promo_codes" (
+    "id_promo_code" bigserial UNIQUE,
+    "code" text NOT NULL UNIQUE,
+    "description" text,
+    "kind" text NOT NULL,
+    "value" bigint NOT NULL,
+    "max_discount" bigint NOT NULL DEFAULT 0,
+    "min_amount" bigint NOT NULL DEFAULT 0,
+    "category_id" bigint,
+    "first_booking_only" boolean NOT NULL DEFAULT false,
+    "usage_limit" bigint NOT NULL DEFAULT 0,
+    "per_user_limit" bigint NOT NULL DEFAULT 1,
+    "used" bigint NOT NULL DEFAULT 0,
+    "starts_at" timestamptz NOT NULL,
+    "ends_at" timestamptz NOT NULL,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_by" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_promo_code"),
+    CONSTRAINT "fk_promo_codes_category" FOREIGN KEY ("category_id") REFERENCES "categories"("id_category")
+);
+
+CREATE TABLE IF NOT EXISTS "requests" (
+    "id_requset" bigserial UNIQUE,
+    "user_id" bigint,
+    "job_id" bigint,
+    "address_id" bigint,
+    "status" text DEFAULT 'pending',
+    "date" date NOT NULL,
+    "slot" text NOT NULL,
+    "timezone" text NOT NULL,
+    "start_at" timestamptz NOT NULL,
+    "end_at" timestamptz NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" text NOT NULL DEFAULT 'INR',
+    "price_locked" boolean DEFAULT false,
+    "discount" bigint NOT NULL DEFAULT 0,
+    "promo_code_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_requset"),
+    CONSTRAINT "fk_requests_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user"),
+    CONSTRAINT "fk_requests_job" FOREIGN KEY ("job_id") REFERENCES "jobs"("id_job"),
+    CONSTRAINT "fk_requests_address" FOREIGN KEY ("address_id") REFERENCES "addresses"("id_address"),
+    CONSTRAINT "fk_requests_promo_code" FOREIGN KEY ("promo_code_id") REFERENCES "promo_codes"("id_promo_code")
+);
+
+CREATE TABLE IF NOT EXISTS "favorites" (
+    "id_favorite" bigserial UNIQUE,
+    "user_id" bigint,
+    "job_id" bigint,
+    PRIMARY KEY ("id_favorite"),
+    CONSTRAINT "fk_favorites_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+
+CREATE TABLE IF NOT EXISTS "verifications" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "email" text,
+    "code" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_verifications_deleted_at" ON "verifications" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "ratings" (
+    "id_ratings" bigserial UNIQUE,
+    "worker_id" bigint,
+    "user_id" bigint,
+    "rating" bigint NOT NULL,
+    PRIMARY KEY ("id_ratings"),
+    CONSTRAINT "fk_ratings_user" FOREIGN KEY ("worker_id") REFERENCES "users"("id_user"),
+    CONSTRAINT "fk_ratings_users" FOREIGN KEY ("worker_id") REFERENCES "users"("id_user")
+);
+
+CREATE TABLE IF NOT EXISTS "banners" (
+    "id_banner" bigserial UNIQUE,
+    "image" text NOT NULL,
+    "deeplink" text NOT NULL,
+    PRIMARY KEY ("id_banner")
+);
+
+CREATE TABLE IF NOT EXISTS "availabilities" (
+    "id_availability" bigserial UNIQUE,
+    "worker_id" bigint NOT NULL,
+    "weekday" bigint NOT NULL,
+    "first_half" boolean,
+    "second_half" boolean,
+    "timezone" text NOT NULL,
+    PRIMARY KEY ("id_availability"),
+    CONSTRAINT "fk_availabilities_user" FOREIGN KEY ("worker_id") REFERENCES "users"("id_user")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_availability_worker_weekday" ON "availabilities" ("worker_id","weekday");
+
+CREATE TABLE IF NOT EXISTS "blackouts" (
+    "id_blackout" bigserial UNIQUE,
+    "worker_id" bigint NOT NULL,
+    "day" date NOT NULL,
+    "reason" text,
+    PRIMARY KEY ("id_blackout"),
+    CONSTRAINT "fk_blackouts_user" FOREIGN KEY ("worker_id") REFERENCES "users"("id_user")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_blackout_worker_day" ON "blackouts" ("worker_id","day");
+
+CREATE TABLE IF NOT EXISTS "offers" (
+    "id_offer" bigserial UNIQUE,
+    "request_id" bigint NOT NULL,
+    "parent_id" bigint,
+    "proposed_by" text NOT NULL,
+    "proposer_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'open',
+    "expires_at" timestamptz NOT NULL,
+    "responded_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_offer"),
+    CONSTRAINT "fk_offers_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_offers_request_id" ON "offers" ("request_id");
+
+CREATE TABLE IF NOT EXISTS "messages" (
+    "id_message" bigserial UNIQUE,
+    "request_id" bigint NOT NULL,
+    "sender_id" bigint NOT NULL,
+    "sender_role" text NOT NULL,
+    "body" text NOT NULL,
+    "read_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_message"),
+    CONSTRAINT "fk_messages_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_messages_request_id" ON "messages" ("request_id");
+
+CREATE TABLE IF NOT EXISTS "notifications" (
+    "id_notification" bigserial UNIQUE,
+    "user_id" bigint NOT NULL,
+    "event" text NOT NULL,
+    "title" text NOT NULL,
+    "body" text NOT NULL,
+    "request_id" bigint,
+    "read_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_notification"),
+    CONSTRAINT "fk_notifications_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_id" ON "notifications" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "notification_preferences" (
+    "id_preference" bigserial UNIQUE,
+    "user_id" bigint NOT NULL,
+    "event" text NOT NULL,
+    "channel" text NOT NULL,
+    "enabled" boolean,
+    PRIMARY KEY ("id_preference"),
+    CONSTRAINT "fk_notification_preferences_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_preference_user_event_channel" ON "notification_preferences" ("user_id","event","channel");
+
+CREATE TABLE IF NOT EXISTS "device_tokens" (
+    "id_device" bigserial UNIQUE,
+    "user_id" bigint NOT NULL,
+    "token" text NOT NULL UNIQUE,
+    "platform" text NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_device"),
+    CONSTRAINT "fk_device_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+CREATE INDEX IF NOT EXISTS "idx_device_tokens_user_id" ON "device_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "outbox_mails" (
+    "id_mail" bigserial UNIQUE,
+    "to_address" text NOT NULL,
+    "template" text NOT NULL,
+    "locale" text NOT NULL,
+    "subject" text NOT NULL,
+    "text_body" text NOT NULL,
+    "html_body" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'queued',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL,
+    "last_error" text,
+    "sent_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_mail")
+);
+CREATE INDEX IF NOT EXISTS "idx_outbox_status_next" ON "outbox_mails" ("status","next_attempt_at");
+
+CREATE TABLE IF NOT EXISTS "payments" (
+    "id_payment" bigserial UNIQUE,
+    "request_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "worker_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "discount" bigint NOT NULL DEFAULT 0,
+    "commission" bigint NOT NULL DEFAULT 0,
+    "refunded" bigint NOT NULL DEFAULT 0,
+    "currency" text NOT NULL,
+    "gateway" text NOT NULL,
+    "gateway_order_id" text,
+    "gateway_payment_id" text,
+    "idempotency_key" text NOT NULL UNIQUE,
+    "status" text NOT NULL DEFAULT 'created',
+    "held_at" timestamptz,
+    "released_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_payment"),
+    CONSTRAINT "fk_payments_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_payments_request_id" ON "payments" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_gateway_order_id" ON "payments" ("gateway_order_id");
+
+CREATE TABLE IF NOT EXISTS "payment_events" (
+    "id_event" bigserial UNIQUE,
+    "gateway" text NOT NULL,
+    "event_id" text NOT NULL,
+    "received_at" timestamptz,
+    PRIMARY KEY ("id_event")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_payment_event_gateway_event" ON "payment_events" ("gateway","event_id");
+
+CREATE TABLE IF NOT EXISTS "cancellation_policies" (
+    "id_policy" bigserial UNIQUE,
+    "category_id" bigint NOT NULL UNIQUE,
+    "free_hours" bigint NOT NULL,
+    "late_fee_bps" bigint NOT NULL,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_policy"),
+    CONSTRAINT "fk_cancellation_policies_category" FOREIGN KEY ("category_id") REFERENCES "categories"("id_category")
+);
+
+CREATE TABLE IF NOT EXISTS "refunds" (
+    "id_refund" bigserial UNIQUE,
+    "payment_id" bigint NOT NULL,
+    "request_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'pending',
+    "gateway_refund_id" text,
+    "last_error" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_refund"),
+    CONSTRAINT "fk_refunds_payment" FOREIGN KEY ("payment_id") REFERENCES "payments"("id_payment")
+);
+CREATE INDEX IF NOT EXISTS "idx_refunds_payment_id" ON "refunds" ("payment_id");
+
+CREATE TABLE IF NOT EXISTS "policy_decisions" (
+    "id_decision" bigserial UNIQUE,
+    "request_id" bigint NOT NULL,
+    "policy_id" bigint,
+    "free_hours" bigint,
+    "late_fee_bps" bigint,
+    "reason" text NOT NULL,
+    "actor_id" bigint NOT NULL,
+    "minutes_before" bigint,
+    "outcome" text NOT NULL,
+    "refund_bps" bigint,
+    "paid" bigint,
+    "refunded" bigint,
+    "kept" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_decision"),
+    CONSTRAINT "fk_policy_decisions_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_policy_decisions_request_id" ON "policy_decisions" ("request_id");
+
+CREATE TABLE IF NOT EXISTS "ledger_transactions" (
+    "id_transaction" bigserial UNIQUE,
+    "kind" text NOT NULL,
+    "request_id" bigint,
+    "payout_id" bigint,
+    "memo" text,
+    "actor_id" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_transaction")
+);
+CREATE INDEX IF NOT EXISTS "idx_ledger_transactions_request_id" ON "ledger_transactions" ("request_id");
+
+CREATE TABLE IF NOT EXISTS "ledger_entries" (
+    "id_entry" bigserial UNIQUE,
+    "transaction_id" bigint NOT NULL,
+    "account" text NOT NULL,
+    "owner_id" bigint NOT NULL DEFAULT 0,
+    "amount" bigint NOT NULL,
+    "currency" text NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_entry"),
+    CONSTRAINT "fk_ledger_entries_transaction" FOREIGN KEY ("transaction_id") REFERENCES "ledger_transactions"("id_transaction")
+);
+CREATE INDEX IF NOT EXISTS "idx_ledger_entry_account" ON "ledger_entries" ("account","owner_id");
+CREATE INDEX IF NOT EXISTS "idx_ledger_entries_transaction_id" ON "ledger_entries" ("transaction_id");
+
+CREATE TABLE IF NOT EXISTS "payout_batches" (
+    "id_batch" bigserial UNIQUE,
+    "created_by" bigint NOT NULL,
+    "count" bigint NOT NULL,
+    "total" bigint NOT NULL,
+    "currency" text NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_batch")
+);
+
+CREATE TABLE IF NOT EXISTS "payouts" (
+    "id_payout" bigserial UNIQUE,
+    "worker_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" text NOT NULL,
+    "destination" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'requested',
+    "note" text,
+    "batch_id" bigint,
+    "reviewed_by" bigint,
+    "reviewed_at" timestamptz,
+    "paid_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_payout"),
+    CONSTRAINT "fk_payouts_worker" FOREIGN KEY ("worker_id") REFERENCES "users"("id_user"),
+    CONSTRAINT "fk_payouts_batch" FOREIGN KEY ("batch_id") REFERENCES "payout_batches"("id_batch")
+);
+CREATE INDEX IF NOT EXISTS "idx_payouts_status" ON "payouts" ("status");
+CREATE INDEX IF NOT EXISTS "idx_payouts_worker_id" ON "payouts" ("worker_id");
+CREATE INDEX IF NOT EXISTS "idx_payouts_batch_id" ON "payouts" ("batch_id");
+
+CREATE TABLE IF NOT EXISTS "invoices" (
+    "id_invoice" bigserial UNIQUE,
+    "number" text NOT NULL UNIQUE,
+    "kind" text NOT NULL,
+    "request_id" bigint NOT NULL,
+    "credited_id" bigint,
+    "refund_id" bigint,
+    "issuer_id" bigint,
+    "issuer_name" text NOT NULL,
+    "recipient_id" bigint NOT NULL,
+    "recipient_name" text NOT NULL,
+    "currency" text NOT NULL,
+    "tax_bps" bigint NOT NULL,
+    "subtotal" bigint NOT NULL,
+    "tax" bigint NOT NULL,
+    "total" bigint NOT NULL,
+    "issued_at" timestamptz,
+    PRIMARY KEY ("id_invoice"),
+    CONSTRAINT "fk_invoices_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_invoice_request_kind" ON "invoices" ("kind","request_id");
+
+CREATE TABLE IF NOT EXISTS "invoice_lines" (
+    "id_line" bigserial UNIQUE,
+    "invoice_id" bigint NOT NULL,
+    "kind" text NOT NULL,
+    "description" text NOT NULL,
+    "amount" bigint NOT NULL,
+    PRIMARY KEY ("id_line"),
+    CONSTRAINT "fk_invoices_lines" FOREIGN KEY ("invoice_id") REFERENCES "invoices"("id_invoice")
+);
+CREATE INDEX IF NOT EXISTS "idx_invoice_lines_invoice_id" ON "invoice_lines" ("invoice_id");
+
+CREATE TABLE IF NOT EXISTS "invoice_sequences" (
+    "series" text,
+    "last" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("series")
+);
+
+CREATE TABLE IF NOT EXISTS "request_materials" (
+    "id_material" bigserial UNIQUE,
+    "request_id" bigint NOT NULL,
+    "description" text NOT NULL,
+    "amount" bigint NOT NULL,
+    PRIMARY KEY ("id_material"),
+    CONSTRAINT "fk_request_materials_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_request_materials_request_id" ON "request_materials" ("request_id");
+
+CREATE TABLE IF NOT EXISTS "promo_redemptions" (
+    "id_redemption" bigserial UNIQUE,
+    "promo_code_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "request_id" bigint NOT NULL UNIQUE,
+    "discount" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_redemption"),
+    CONSTRAINT "fk_promo_redemptions_promo_code" FOREIGN KEY ("promo_code_id") REFERENCES "promo_codes"("id_promo_code"),
+    CONSTRAINT "fk_promo_redemptions_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_promo_redemptions_user_id" ON "promo_redemptions" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_promo_redemptions_promo_code_id" ON "promo_redemptions" ("promo_code_id");
+
+CREATE TABLE IF NOT EXISTS "referral_codes" (
+    "user_id" bigserial,
+    "code" text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    PRIMARY KEY ("user_id"),
+    CONSTRAINT "fk_referral_codes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+
+CREATE TABLE IF NOT EXISTS "referrals" (
+    "id_referral" bigserial UNIQUE,
+    "referrer_id" bigint NOT NULL,
+    "referee_id" bigint NOT NULL UNIQUE,
+    "code" text NOT NULL,
+    "device_id" text,
+    "status" text NOT NULL DEFAULT 'pending',
+    "reject_reason" text,
+    "reward" bigint NOT NULL DEFAULT 0,
+    "request_id" bigint,
+    "rewarded_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_referral"),
+    CONSTRAINT "fk_referrals_referrer" FOREIGN KEY ("referrer_id") REFERENCES "users"("id_user"),
+    CONSTRAINT "fk_referrals_referee" FOREIGN KEY ("referee_id") REFERENCES "users"("id_user")
+);
+CREATE INDEX IF NOT EXISTS "idx_referrals_device_id" ON "referrals" ("device_id");
+CREATE INDEX IF NOT EXISTS "idx_referrals_referrer_id" ON "referrals" ("referrer_id");
+
+CREATE TABLE IF NOT EXISTS "signup_devices" (
+    "id_signup_device" bigserial UNIQUE,
+    "user_id" bigint NOT NULL,
+    "device_id" text NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_signup_device"),
+    CONSTRAINT "fk_signup_devices_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+CREATE INDEX IF NOT EXISTS "idx_signup_devices_device_id" ON "signup_devices" ("device_id");
+CREATE INDEX IF NOT EXISTS "idx_signup_devices_user_id" ON "signup_devices" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "subscription_plans" (
+    "id_plan" bigserial UNIQUE,
+    "name" text NOT NULL UNIQUE,
+    "billing_interval" text NOT NULL,
+    "price" bigint NOT NULL,
+    "currency" text NOT NULL,
+    "job_limit" bigint NOT NULL,
+    "priority" boolean NOT NULL DEFAULT false,
+    "badge" text,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_plan")
+);
+
+CREATE TABLE IF NOT EXISTS "subscriptions" (
+    "id_subscription" bigserial UNIQUE,
+    "worker_id" bigint NOT NULL,
+    "plan_id" bigint NOT NULL,
+    "status" text NOT NULL,
+    "auto_renew" boolean NOT NULL DEFAULT true,
+    "period_start" timestamptz NOT NULL,
+    "period_end" timestamptz NOT NULL,
+    "grace_ends_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_subscription"),
+    CONSTRAINT "fk_subscriptions_worker" FOREIGN KEY ("worker_id") REFERENCES "users"("id_user"),
+    CONSTRAINT "fk_subscriptions_plan" FOREIGN KEY ("plan_id") REFERENCES "subscription_plans"("id_plan")
+);
+CREATE INDEX IF NOT EXISTS "idx_subscriptions_period_end" ON "subscriptions" ("period_end");
+CREATE INDEX IF NOT EXISTS "idx_subscriptions_worker_id" ON "subscriptions" ("worker_id");
+
+CREATE TABLE IF NOT EXISTS "disputes" (
+    "id_dispute" bigserial UNIQUE,
+    "request_id" bigint NOT NULL,
+    "opened_by" bigint NOT NULL,
+    "respondent" bigint NOT NULL,
+    "reason" text NOT NULL,
+    "description" text NOT NULL,
+    "status" text NOT NULL,
+    "assigned_to" bigint,
+    "outcome" text,
+    "refund_id" bigint,
+    "resolution" text,
+    "resolved_by" bigint,
+    "resolved_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id_dispute"),
+    CONSTRAINT "fk_disputes_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id_requset")
+);
+CREATE INDEX IF NOT EXISTS "idx_disputes_assigned_to" ON "disputes" ("assigned_to");
+CREATE INDEX IF NOT EXISTS "idx_disputes_status" ON "disputes" ("status");
+CREATE INDEX IF NOT EXISTS "idx_disputes_respondent" ON "disputes" ("respondent");
+CREATE INDEX IF NOT EXISTS "idx_disputes_opened_by" ON "disputes" ("opened_by");
+CREATE INDEX IF NOT EXISTS "idx_disputes_request_id" ON "disputes" ("request_id");
+
+CREATE TABLE IF NOT EXISTS "dispute_evidences" (
+    "id_evidence" bigserial UNIQUE,
+    "dispute_id" bigint NOT NULL,
+    "uploaded_by" bigint NOT NULL,
+    "file_name" text NOT NULL,
+    "content_type" text NOT NULL,
+    "size" bigint NOT NULL,
+    "storage_key" text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_evidence"),
+    CONSTRAINT "fk_dispute_evidences_dispute" FOREIGN KEY ("dispute_id") REFERENCES "disputes"("id_dispute")
+);
+CREATE INDEX IF NOT EXISTS "idx_dispute_evidences_dispute_id" ON "dispute_evidences" ("dispute_id");
+
+CREATE TABLE IF NOT EXISTS "dispute_messages" (
+    "id_message" bigserial UNIQUE,
+    "dispute_id" bigint NOT NULL,
+    "author_id" bigint NOT NULL,
+    "body" text NOT NULL,
+    "internal" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_message"),
+    CONSTRAINT "fk_dispute_messages_dispute" FOREIGN KEY ("dispute_id") REFERENCES "disputes"("id_dispute")
+);
+CREATE INDEX IF NOT EXISTS "idx_dispute_messages_dispute_id" ON "dispute_messages" ("dispute_id");
+
+CREATE TABLE IF NOT EXISTS "account_actions" (
+    "id_action" bigserial UNIQUE,
+    "user_id" bigint NOT NULL,
+    "kind" text NOT NULL,
+    "dispute_id" bigint,
+    "reason" text NOT NULL,
+    "until" timestamptz,
+    "actor_id" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_action"),
+    CONSTRAINT "fk_account_actions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+CREATE INDEX IF NOT EXISTS "idx_account_actions_user_id" ON "account_actions" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "login_events" (
+    "id_login" bigserial UNIQUE,
+    "user_id" bigint NOT NULL,
+    "method" text NOT NULL,
+    "ip" text,
+    "user_agent" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_login"),
+    CONSTRAINT "fk_login_events_user" FOREIGN KEY ("user_id") REFERENCES "users"("id_user")
+);
+CREATE INDEX IF NOT EXISTS "idx_login_events_user_id" ON "login_events" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+    "id_audit" bigserial UNIQUE,
+    "actor_id" bigint NOT NULL,
+    "actor_role" text NOT NULL,
+    "action" text NOT NULL,
+    "target_type" text NOT NULL,
+    "target_id" bigint NOT NULL,
+    "before" text,
+    "after" text,
+    "ip" text,
+    "user_agent" text,
+    "prev_hash" text NOT NULL,
+    "hash" text NOT NULL UNIQUE,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id_audit")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_action" ON "audit_logs" ("action");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
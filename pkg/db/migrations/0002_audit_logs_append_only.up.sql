@@ -0,0 +1,8 @@
+-- Audit records are append only, the hash chain shows if that is got around
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+    BEGIN RAISE EXCEPTION 'audit_logs is append only'; END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
@@ -0,0 +1,2 @@
+DROP MATERIALIZED VIEW IF EXISTS signup_daily_summary;
+DROP MATERIALIZED VIEW IF EXISTS request_daily_summary;
@@ -0,0 +1,38 @@
+-- Analytics read these summaries, refreshed in the background, rather than
+-- aggregating every request each time. The unique indexes let them be
+-- refreshed concurrently.
+CREATE MATERIALIZED VIEW IF NOT EXISTS request_daily_summary AS
+SELECT r.created_at::date AS day, COALESCE(a.city, '') AS city, j.category_id, r.currency,
+    COUNT(*) AS requests,
+    COUNT(*) FILTER (WHERE r.status IN ('accepted', 'completed', 'no_show') OR pay.paid) AS accepted,
+    COUNT(*) FILTER (WHERE pay.paid) AS paid,
+    COUNT(*) FILTER (WHERE r.status='completed') AS completed,
+    COUNT(*) FILTER (WHERE r.status='rejected') AS rejected,
+    COUNT(*) FILTER (WHERE r.status='cancelled') AS cancelled,
+    COUNT(*) FILTER (WHERE r.status='no_show') AS no_show,
+    COALESCE(SUM(pay.gmv), 0) AS gmv,
+    COALESCE(SUM(pay.commission), 0) AS commission,
+    COALESCE(SUM(pay.discount), 0) AS discount
+FROM requests r
+JOIN jobs j ON j.id_job=r.job_id
+LEFT JOIN addresses a ON a.id_address=r.address_id
+LEFT JOIN (SELECT request_id,
+        BOOL_OR(status IN ('held', 'released', 'refunded')) AS paid,
+        SUM(amount+discount-refunded) FILTER (WHERE status='released') AS gmv,
+        SUM(commission) FILTER (WHERE status='released') AS commission,
+        SUM(discount) FILTER (WHERE status='released') AS discount
+    FROM payments GROUP BY request_id) pay ON pay.request_id=r.id_requset
+GROUP BY 1, 2, 3, 4;
+
+CREATE UNIQUE INDEX IF NOT EXISTS request_daily_summary_key ON request_daily_summary (day, city, category_id, currency);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS signup_daily_summary AS
+SELECT u.created_at::date AS day, u.signup_channel AS channel,
+    COALESCE(NULLIF(u.user_type, ''), 'user') AS user_type, COALESCE(a.city, '') AS city,
+    COUNT(*) AS signups
+FROM users u
+LEFT JOIN LATERAL (SELECT city FROM addresses WHERE user_id=u.id_user ORDER BY id_address LIMIT 1) a ON true
+WHERE u.created_at IS NOT NULL
+GROUP BY 1, 2, 3, 4;
+
+CREATE UNIQUE INDEX IF NOT EXISTS signup_daily_summary_key ON signup_daily_summary (day, channel, user_type, city);
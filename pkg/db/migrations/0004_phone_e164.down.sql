@@ -0,0 +1,12 @@
+-- The numbers go back the way they were written. They are moved out of the
+-- way first, as a number kept may be the one a duplicate had.
+UPDATE users u SET phone='restoring:' || u.id_user
+FROM phone_normalisations n
+WHERE n.user_id=u.id_user;
+
+UPDATE users u SET phone=n.old_phone
+FROM phone_normalisations n
+WHERE n.user_id=u.id_user;
+
+DROP TABLE IF EXISTS phone_normalisations;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_country;
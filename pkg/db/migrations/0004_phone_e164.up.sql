@@ -0,0 +1,57 @@
+-- Phone numbers are kept in E.164 format along with their region. Numbers
+-- written with spaces, dashes or brackets, or without the + or calling code,
+-- made separate accounts for the same person, so they are put in E.164 format
+-- here, national numbers being read as Indian, the default region. Accounts
+-- left sharing a number are deduplicated: the oldest keeps the number and the
+-- others have theirs retired. Every change is recorded in
+-- phone_normalisations, so admins can merge what the retired accounts hold.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_country text;
+
+CREATE TABLE IF NOT EXISTS phone_normalisations (
+    user_id bigint PRIMARY KEY,
+    old_phone text NOT NULL,
+    new_phone text NOT NULL,
+    kept_user_id bigint,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_phone_normalisations_user FOREIGN KEY (user_id) REFERENCES users(id_user),
+    CONSTRAINT fk_phone_normalisations_kept_user FOREIGN KEY (kept_user_id) REFERENCES users(id_user)
+);
+
+-- The calling codes of the regions phone numbers are read for
+CREATE TEMPORARY TABLE phone_regions (region text PRIMARY KEY, calling_code text NOT NULL) ON COMMIT DROP;
+INSERT INTO phone_regions (region, calling_code) VALUES
+    ('IN', '91'), ('US', '1'), ('GB', '44'), ('AE', '971'), ('SA', '966'), ('QA', '974'), ('KW', '965'), ('OM', '968'),
+    ('BH', '973'), ('SG', '65'), ('AU', '61'), ('PK', '92'), ('BD', '880'), ('LK', '94'), ('NP', '977');
+
+-- Accounts signed up through Google have placeholder numbers, which match
+-- none of the patterns and are left alone
+CREATE TEMPORARY TABLE phone_candidates ON COMMIT DROP AS
+SELECT n.id_user, n.phone AS old_phone, n.e164, r.region,
+    FIRST_VALUE(n.id_user) OVER (PARTITION BY n.e164 ORDER BY n.id_user) AS kept_user_id
+FROM (
+    SELECT id_user, phone,
+        CASE
+            WHEN cleaned ~ '^\+[1-9][0-9]{6,14}$' THEN cleaned
+            WHEN cleaned ~ '^00[1-9][0-9]{6,14}$' THEN '+' || substr(cleaned, 3)
+            WHEN cleaned ~ '^[1-9][0-9]{9}$' THEN '+91' || cleaned
+            WHEN cleaned ~ '^0[1-9][0-9]{9}$' THEN '+91' || substr(cleaned, 2)
+            WHEN cleaned ~ '^91[1-9][0-9]{9}$' THEN '+' || cleaned
+        END AS e164
+    FROM (SELECT id_user, phone, regexp_replace(phone, '[[:space:]().-]', '', 'g') AS cleaned FROM users) c
+) n
+JOIN phone_regions r ON n.e164 LIKE '+' || r.calling_code || '%';
+
+INSERT INTO phone_normalisations (user_id, old_phone, new_phone, kept_user_id)
+SELECT id_user, old_phone, e164, NULLIF(kept_user_id, id_user)
+FROM phone_candidates
+WHERE old_phone<>e164 OR kept_user_id<>id_user
+ON CONFLICT (user_id) DO NOTHING;
+
+-- Duplicates give up their numbers first, so the numbers kept never clash
+UPDATE users u SET phone='retired:' || u.id_user || ':' || c.old_phone
+FROM phone_candidates c
+WHERE c.id_user=u.id_user AND c.kept_user_id<>c.id_user;
+
+UPDATE users u SET phone=c.e164, phone_country=c.region
+FROM phone_candidates c
+WHERE c.id_user=u.id_user AND c.kept_user_id=c.id_user;
@@ -4,35 +4,92 @@
 package di
 
 import (
+	"database/sql"
+
 	http "github.com/fazilnbr/project-workey/pkg/api"
 	"github.com/fazilnbr/project-workey/pkg/api/handler"
 	"github.com/fazilnbr/project-workey/pkg/api/middleware"
 	config "github.com/fazilnbr/project-workey/pkg/config"
-	"github.com/fazilnbr/project-workey/pkg/db"
 	repository "github.com/fazilnbr/project-workey/pkg/repository"
 	usecase "github.com/fazilnbr/project-workey/pkg/usecase"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 
 	"github.com/google/wire"
+	"github.com/sirupsen/logrus"
 )
 
-func InitializeAPI(cfg config.Config) (*http.ServerHTTP, error) {
+func InitializeAPI(cfg config.Config, sqlDB *sql.DB, logger *logrus.Logger) (*http.ServerHTTP, error) {
 	// fmt.Printf("\n\n\nv\n\n\n")
 	wire.Build(
-		db.ConnectDB,
 		repository.NewAdminRepo,
 		repository.NewUserRepo,
 		repository.NewWorkerRepo,
+		repository.NewBookingRepo,
+		repository.NewOfferRepo,
+		repository.NewChatRepo,
+		repository.NewNotificationRepo,
+		repository.NewMailRepo,
+		repository.NewPaymentRepo,
+		repository.NewCancellationRepo,
+		repository.NewWalletRepo,
+		repository.NewInvoiceRepo,
+		repository.NewPromoRepo,
+		repository.NewReferralRepo,
+		repository.NewSubscriptionRepo,
+		repository.NewDisputeRepo,
+		repository.NewAuditRepo,
+		repository.NewAnalyticsRepo,
+		repository.NewExportRepo,
+		repository.NewImportRepo,
+		repository.NewTransactor,
 		config.NewMailConfig,
 		config.NewTwilioConfig,
+		config.NewSMSConfig,
+		config.NewPushConfig,
+		config.NewPaymentGateway,
+		config.NewFileStore,
 		usecase.NewAdminService,
 		usecase.NewJWTUserService,
 		usecase.NewWorkerService,
 		usecase.NewUserService,
 		usecase.NewAuthService,
+		usecase.NewBookingService,
+		usecase.NewOfferService,
+		usecase.NewChatService,
+		usecase.NewNotificationService,
+		usecase.NewMailService,
+		usecase.NewPaymentService,
+		usecase.NewCancellationService,
+		usecase.NewWalletService,
+		usecase.NewInvoiceService,
+		usecase.NewPromoService,
+		usecase.NewReferralService,
+		usecase.NewSubscriptionService,
+		usecase.NewDisputeService,
+		usecase.NewAuditService,
+		usecase.NewAnalyticsService,
+		usecase.NewExportService,
+		usecase.NewImportService,
+		utils.NewCursorCodec,
 		handler.NewAdminHandler,
 		handler.NewAuthHandler,
 		handler.NewUserHandler,
 		handler.NewWorkerHandler,
+		handler.NewBookingHandler,
+		handler.NewOfferHandler,
+		handler.NewChatHandler,
+		handler.NewNotificationHandler,
+		handler.NewPaymentHandler,
+		handler.NewWalletHandler,
+		handler.NewInvoiceHandler,
+		handler.NewPromoHandler,
+		handler.NewReferralHandler,
+		handler.NewSubscriptionHandler,
+		handler.NewDisputeHandler,
+		handler.NewAuditHandler,
+		handler.NewAnalyticsHandler,
+		handler.NewExportHandler,
+		handler.NewImportHandler,
 		middleware.NewUserMiddileware,
 		http.NewServerHTTP)
 
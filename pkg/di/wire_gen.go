@@ -7,34 +7,100 @@
 package di
 
 import (
+	"database/sql"
+
 	"github.com/fazilnbr/project-workey/pkg/api"
 	"github.com/fazilnbr/project-workey/pkg/api/handler"
 	"github.com/fazilnbr/project-workey/pkg/api/middleware"
 	"github.com/fazilnbr/project-workey/pkg/config"
-	"github.com/fazilnbr/project-workey/pkg/db"
 	"github.com/fazilnbr/project-workey/pkg/repository"
 	"github.com/fazilnbr/project-workey/pkg/usecase"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
 )
 
 // Injectors from wire.go:
 
-func InitializeAPI(cfg config.Config) (*api.ServerHTTP, error) {
-	sqlDB := db.ConnectDB(cfg)
+func InitializeAPI(cfg config.Config, sqlDB *sql.DB, logger *logrus.Logger) (*api.ServerHTTP, error) {
 	adminRepository := repository.NewAdminRepo(sqlDB)
-	mailConfig := config.NewMailConfig()
-	adminUseCase := usecase.NewAdminService(adminRepository, mailConfig)
 	workerRepository := repository.NewWorkerRepo(sqlDB)
-	workerUseCase := usecase.NewWorkerService(workerRepository)
 	userRepository := repository.NewUserRepo(sqlDB)
-	userUseCase := usecase.NewUserService(userRepository)
-	jwtUseCase := usecase.NewJWTUserService()
+	mailConfig := config.NewMailConfig(cfg, logger)
+	bookingRepository := repository.NewBookingRepo(sqlDB)
+	chatRepository := repository.NewChatRepo(sqlDB)
+	chatUseCase := usecase.NewChatService(chatRepository, bookingRepository)
+	adminUseCase := usecase.NewAdminService(adminRepository, workerRepository, userRepository, mailConfig, chatUseCase)
+	subscriptionRepository := repository.NewSubscriptionRepo(sqlDB)
+	workerUseCase := usecase.NewWorkerService(workerRepository, subscriptionRepository)
+	auditRepository := repository.NewAuditRepo(sqlDB)
+	auditUseCase := usecase.NewAuditService(auditRepository, logger)
+	transactor := repository.NewTransactor(sqlDB)
+	userUseCase := usecase.NewUserService(userRepository, transactor, auditUseCase, logger)
+	jwtUseCase := usecase.NewJWTUserService(logger)
 	twilioConfig := config.NewTwilioConfig()
 	authUseCase := usecase.NewAuthService(adminRepository, workerRepository, userRepository, mailConfig, twilioConfig, cfg)
-	authHandler := handler.NewAuthHandler(adminUseCase, workerUseCase, userUseCase, jwtUseCase, authUseCase, cfg)
-	adminHandler := handler.NewAdminHandler(adminUseCase)
+	referralRepository := repository.NewReferralRepo(sqlDB)
+	referralUseCase := usecase.NewReferralService(referralRepository, cfg, logger)
+	authHandler := handler.NewAuthHandler(adminUseCase, workerUseCase, userUseCase, jwtUseCase, authUseCase, referralUseCase, auditUseCase, cfg, logger)
 	userHandler := handler.NewUserHandler(userUseCase)
-	workerHandler := handler.NewWorkerHandler(workerUseCase)
-	middlewareMiddleware := middleware.NewUserMiddileware(jwtUseCase)
-	serverHTTP := api.NewServerHTTP(authHandler, adminHandler, userHandler, workerHandler, middlewareMiddleware)
+	notificationRepository := repository.NewNotificationRepo(sqlDB)
+	smsConfig := config.NewSMSConfig(cfg, logger)
+	pushConfig := config.NewPushConfig(cfg, logger)
+	mailRepository := repository.NewMailRepo(sqlDB)
+	mailUseCase := usecase.NewMailService(mailRepository, mailConfig, cfg, logger)
+	notificationUseCase := usecase.NewNotificationService(notificationRepository, userRepository, mailUseCase, smsConfig, pushConfig, cfg, logger)
+	paymentRepository := repository.NewPaymentRepo(sqlDB)
+	paymentGateway, err := config.NewPaymentGateway(cfg)
+	if err != nil {
+		return nil, err
+	}
+	invoiceRepository := repository.NewInvoiceRepo(sqlDB)
+	invoiceUseCase := usecase.NewInvoiceService(invoiceRepository, bookingRepository, paymentRepository, userRepository, cfg)
+	paymentUseCase := usecase.NewPaymentService(paymentRepository, bookingRepository, invoiceUseCase, paymentGateway, cfg, logger)
+	cancellationRepository := repository.NewCancellationRepo(sqlDB)
+	cancellationUseCase := usecase.NewCancellationService(cancellationRepository, paymentRepository, paymentGateway, cfg, logger)
+	promoRepository := repository.NewPromoRepo(sqlDB)
+	promoUseCase := usecase.NewPromoService(promoRepository, bookingRepository)
+	bookingUseCase := usecase.NewBookingService(bookingRepository, workerRepository, notificationUseCase, paymentUseCase, cancellationUseCase, invoiceUseCase, promoUseCase, referralUseCase, transactor, logger)
+	cursorCodec, err := utils.NewCursorCodec(cfg)
+	if err != nil {
+		return nil, err
+	}
+	workerHandler := handler.NewWorkerHandler(workerUseCase, cursorCodec)
+	adminHandler := handler.NewAdminHandler(adminUseCase, mailUseCase, cancellationUseCase, cursorCodec)
+	bookingHandler := handler.NewBookingHandler(bookingUseCase, cursorCodec)
+	offerRepository := repository.NewOfferRepo(sqlDB)
+	offerUseCase := usecase.NewOfferService(offerRepository, bookingRepository)
+	offerHandler := handler.NewOfferHandler(offerUseCase)
+	chatHandler := handler.NewChatHandler(chatUseCase, jwtUseCase, userUseCase, cursorCodec)
+	notificationHandler := handler.NewNotificationHandler(notificationUseCase, cursorCodec)
+	paymentHandler := handler.NewPaymentHandler(paymentUseCase)
+	walletRepository := repository.NewWalletRepo(sqlDB)
+	walletUseCase := usecase.NewWalletService(walletRepository, cfg)
+	walletHandler := handler.NewWalletHandler(walletUseCase, cursorCodec)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceUseCase)
+	promoHandler := handler.NewPromoHandler(promoUseCase, cursorCodec)
+	referralHandler := handler.NewReferralHandler(referralUseCase)
+	subscriptionUseCase := usecase.NewSubscriptionService(subscriptionRepository, cfg, logger)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionUseCase)
+	disputeRepository := repository.NewDisputeRepo(sqlDB)
+	fileStore := config.NewFileStore(cfg)
+	disputeUseCase := usecase.NewDisputeService(disputeRepository, bookingRepository, paymentRepository, userRepository, paymentUseCase, notificationUseCase, fileStore, logger)
+	disputeHandler := handler.NewDisputeHandler(disputeUseCase, cursorCodec)
+	auditHandler := handler.NewAuditHandler(auditUseCase, cursorCodec)
+	analyticsRepository := repository.NewAnalyticsRepo(sqlDB)
+	analyticsUseCase := usecase.NewAnalyticsService(analyticsRepository, cfg, logger)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsUseCase)
+	exportRepository := repository.NewExportRepo(sqlDB)
+	exportUseCase := usecase.NewExportService(exportRepository)
+	exportHandler := handler.NewExportHandler(exportUseCase, logger)
+	importRepository := repository.NewImportRepo(sqlDB)
+	importUseCase := usecase.NewImportService(importRepository, cfg)
+	importHandler := handler.NewImportHandler(importUseCase)
+	middlewareMiddleware := middleware.NewUserMiddileware(jwtUseCase, auditUseCase, userUseCase, cfg, logger)
+	serverHTTP, err := api.NewServerHTTP(authHandler, adminHandler, userHandler, workerHandler, bookingHandler, offerHandler, chatHandler, notificationHandler, paymentHandler, walletHandler, invoiceHandler, promoHandler, referralHandler, subscriptionHandler, disputeHandler, auditHandler, analyticsHandler, exportHandler, importHandler, middlewareMiddleware, mailUseCase, subscriptionUseCase, analyticsUseCase, logger)
+	if err != nil {
+		return nil, err
+	}
 	return serverHTTP, nil
 }
@@ -1,6 +1,14 @@
 package domain
 
-import "gorm.io/gorm"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // user schema for user table to get listed all users
 type User struct {
@@ -11,7 +19,28 @@ type User struct {
 	UserType     string `json:"usertype" postgres:"type:ENUM('admin', 'worker', 'user')" gorm:"not null"`
 	Verification bool   `json:"-" gorm:"default:false"`
 	Status       string `json:"-" gorm:"default:newuser"`
+	// SuspendedUntil ends a suspension, one without an end lasts until lifted
+	SuspendedUntil *time.Time `json:"-"`
+	// LoggedOutAt voids every token issued before it
+	LoggedOutAt *time.Time `json:"-"`
+	// PhoneCountry is the region Phone, kept in E.164 format, belongs to
+	PhoneCountry string `json:"phonecountry"`
+	// SignupChannel is the sign in method the account was made through
+	SignupChannel string    `json:"-" gorm:"not null;default:phone"`
+	CreatedAt     time.Time `json:"-"`
+}
+
+// Locked tells whether the account is kept from signing in at now
+func (u User) Locked(now time.Time) bool {
+	switch u.Status {
+	case UserBlocked:
+		return true
+	case UserSuspended:
+		return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+	}
+	return false
 }
+
 type Profile struct {
 	IdProfie     int `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
 	UserId       int
@@ -28,11 +57,12 @@ type Address struct {
 	UserId          int
 	User            *User  `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
 	AddressCategory string `json:"category"`
-	Mapcoordinates  string `json:"mapcoordinates"`
+	Mapcoordinates  string `json:"mapcoordinates" binding:"omitempty,coordinates"`
 	Housenumber     string `json:"housenumber"`
 	Floor           string `json:"floor"`
 	BlockorTower    string `json:"blockortower"`
 	Landmark        string `json:"landmark"`
+	City            string `json:"city" gorm:"index"`
 }
 
 type Verification struct {
@@ -47,6 +77,8 @@ type Category struct {
 	CategoryIcon string `gorm:"unique" json:"categoryicon" binding:"required"`
 }
 
+// Job is a service a worker offers. Priority ranks it first in search while
+// the worker has a premium plan that gives priority.
 type Job struct {
 	IdJob       int       `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
 	IdWorker    int       `json:"-" gorm:"not null"`
@@ -70,16 +102,887 @@ type Favorite struct {
 }
 
 type Request struct {
-	IdRequset int `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
-	UserId    int
-	User      *User `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
-	JobId     int
-	Job       *Job     `json:"-" gorm:"foreignKey:JobId;references:IdJob"`
-	AddressId int      `binding:"required"`
-	Address   *Address `json:"-" gorm:"foreignKey:AddressId;references:IdAddress"`
-	Status    string   `json:"-" gorm:"default:pending"`
-	Date      string   `jsom:"-"`
-	BidAmount string   `jsom:"bidamount"`
+	IdRequset int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	UserId    int       `json:"userid"`
+	User      *User     `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	JobId     int       `json:"jobid"`
+	Job       *Job      `json:"-" gorm:"foreignKey:JobId;references:IdJob"`
+	AddressId int       `json:"addressid" binding:"required"`
+	Address   *Address  `json:"-" gorm:"foreignKey:AddressId;references:IdAddress"`
+	Status    string    `json:"status" gorm:"default:pending"`
+	Date      time.Time `json:"date" gorm:"type:date;not null"`
+	Slot      string    `json:"slot" gorm:"not null"`
+	Timezone  string    `json:"timezone" gorm:"not null"`
+	StartAt   time.Time `json:"startat" gorm:"not null"`
+	EndAt     time.Time `json:"endat" gorm:"not null"`
+	Amount    int64     `json:"amount" gorm:"not null"`
+	Currency  string    `json:"currency" gorm:"not null;default:INR"`
+	// PriceLocked is set once an offer is accepted and Amount holds the agreed price
+	PriceLocked bool `json:"pricelocked" gorm:"default:false"`
+	// Discount is what a promo code takes off Amount. The user pays Amount less
+	// Discount and the platform makes the worker whole on release.
+	Discount    int64      `json:"discount" gorm:"not null;default:0"`
+	PromoCodeId *int       `json:"promocodeid,omitempty"`
+	PromoCode   *PromoCode `json:"-" gorm:"foreignKey:PromoCodeId;references:IdPromoCode"`
+	CreatedAt   time.Time  `json:"createdat"`
+	UpdatedAt   time.Time  `json:"updatedat"`
+}
+
+const DefaultCurrency = "INR"
+
+// Booking slots a request can reserve on a day
+const (
+	SlotFullDay    = "full_day"
+	SlotFirstHalf  = "first_half"
+	SlotSecondHalf = "second_half"
+)
+
+// Request status values
+const (
+	RequestPending   = "pending"
+	RequestAccepted  = "accepted"
+	RequestRejected  = "rejected"
+	RequestCancelled = "cancelled"
+	RequestCompleted = "completed"
+	RequestNoShow    = "no_show"
+)
+
+// Offer is one step of the price negotiation on a request. A counter offer
+// closes the offer it answers and points back to it through ParentId.
+type Offer struct {
+	IdOffer     int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	RequestId   int        `json:"requestid" gorm:"not null;index"`
+	Request     *Request   `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	ParentId    *int       `json:"parentid,omitempty"`
+	ProposedBy  string     `json:"proposedby" gorm:"not null"`
+	ProposerId  int        `json:"proposerid" gorm:"not null"`
+	Amount      int64      `json:"amount" gorm:"not null"`
+	Currency    string     `json:"currency" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;default:open"`
+	ExpiresAt   time.Time  `json:"expiresat" gorm:"not null"`
+	RespondedAt *time.Time `json:"respondedat,omitempty"`
+	CreatedAt   time.Time  `json:"createdat"`
+}
+
+// Parties of a negotiation
+const (
+	PartyUser   = "user"
+	PartyWorker = "worker"
+)
+
+// Offer status values
+const (
+	OfferOpen      = "open"
+	OfferAccepted  = "accepted"
+	OfferRejected  = "rejected"
+	OfferCountered = "countered"
+	OfferExpired   = "expired"
+)
+
+// Message is a chat message exchanged on a request. Contact details in Body
+// are masked when the message is sent before the booking is accepted.
+type Message struct {
+	IdMessage  int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	RequestId  int        `json:"requestid" gorm:"not null;index"`
+	Request    *Request   `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	SenderId   int        `json:"senderid" gorm:"not null"`
+	SenderRole string     `json:"senderrole" gorm:"not null"`
+	Body       string     `json:"body" gorm:"not null"`
+	ReadAt     *time.Time `json:"readat,omitempty"`
+	CreatedAt  time.Time  `json:"createdat"`
+}
+
+// Payment is the money a user pays for a request. It is held by the platform
+// once the gateway captures it and released to the worker, less the platform
+// commission, when the request is completed. Discount is the part of the
+// price a promo code took off, which the platform pays the worker itself.
+type Payment struct {
+	IdPayment        int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	RequestId        int        `json:"requestid" gorm:"not null;index"`
+	Request          *Request   `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	UserId           int        `json:"userid" gorm:"not null"`
+	WorkerId         int        `json:"workerid" gorm:"not null"`
+	Amount           int64      `json:"amount" gorm:"not null"`
+	Discount         int64      `json:"discount" gorm:"not null;default:0"`
+	Commission       int64      `json:"commission" gorm:"not null;default:0"`
+	Refunded         int64      `json:"refunded" gorm:"not null;default:0"`
+	Currency         string     `json:"currency" gorm:"not null"`
+	Gateway          string     `json:"gateway" gorm:"not null"`
+	GatewayOrderId   string     `json:"gatewayorderid" gorm:"index"`
+	GatewayPaymentId string     `json:"gatewaypaymentid"`
+	IdempotencyKey   string     `json:"-" gorm:"not null;unique"`
+	Status           string     `json:"status" gorm:"not null;default:created"`
+	HeldAt           *time.Time `json:"heldat,omitempty"`
+	ReleasedAt       *time.Time `json:"releasedat,omitempty"`
+	CreatedAt        time.Time  `json:"createdat"`
+	UpdatedAt        time.Time  `json:"updatedat"`
+}
+
+// PaymentEvent records a processed gateway webhook so a redelivered one is not applied twice
+type PaymentEvent struct {
+	IdEvent    int       `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
+	Gateway    string    `json:"gateway" gorm:"not null;uniqueIndex:idx_payment_event_gateway_event"`
+	EventId    string    `json:"eventid" gorm:"not null;uniqueIndex:idx_payment_event_gateway_event"`
+	ReceivedAt time.Time `json:"receivedat"`
+}
+
+// Payment status values
+const (
+	PaymentCreated  = "created"
+	PaymentHeld     = "held"
+	PaymentReleased = "released"
+	PaymentFailed   = "failed"
+	PaymentRefunded = "refunded"
+)
+
+// DefaultCommissionBps is the platform commission, in basis points of the
+// payment, used when COMMISSION_BPS is not set
+const DefaultCommissionBps = 1000
+
+// CancellationPolicy decides what a user gets back when they cancel a booking
+// of a category: everything up to FreeHours before the start, the payment less
+// LateFeeBps basis points after that, and nothing once the booking started.
+type CancellationPolicy struct {
+	IdPolicy   int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	CategoryId int       `json:"categoryid" gorm:"not null;unique"`
+	Category   *Category `json:"-" gorm:"foreignKey:CategoryId;references:IdCategory"`
+	FreeHours  int       `json:"freehours" gorm:"not null"`
+	LateFeeBps int       `json:"latefeebps" gorm:"not null"`
+	UpdatedAt  time.Time `json:"updatedat"`
+}
+
+// DefaultCancellationPolicy applies to categories without a policy of their own
+var DefaultCancellationPolicy = CancellationPolicy{FreeHours: 24, LateFeeBps: 5000}
+
+// Refund is money sent back to the user through the gateway the payment came from
+type Refund struct {
+	IdRefund        int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	PaymentId       int       `json:"paymentid" gorm:"not null;index"`
+	Payment         *Payment  `json:"-" gorm:"foreignKey:PaymentId;references:IdPayment"`
+	RequestId       int       `json:"requestid" gorm:"not null"`
+	Amount          int64     `json:"amount" gorm:"not null"`
+	Currency        string    `json:"currency" gorm:"not null"`
+	Status          string    `json:"status" gorm:"not null;default:pending"`
+	GatewayRefundId string    `json:"gatewayrefundid"`
+	LastError       string    `json:"lasterror"`
+	CreatedAt       time.Time `json:"createdat"`
+	UpdatedAt       time.Time `json:"updatedat"`
+}
+
+// Refund status values
+const (
+	RefundPending   = "pending"
+	RefundProcessed = "processed"
+	RefundFailed    = "failed"
+)
+
+// PolicyDecision is the audit record of how a cancellation was settled: which
+// policy applied, how far ahead of the start it happened and where the money went
+type PolicyDecision struct {
+	IdDecision    int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	RequestId     int       `json:"requestid" gorm:"not null;index"`
+	Request       *Request  `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	PolicyId      *int      `json:"policyid,omitempty"`
+	FreeHours     int       `json:"freehours"`
+	LateFeeBps    int       `json:"latefeebps"`
+	Reason        string    `json:"reason" gorm:"not null"`
+	ActorId       int       `json:"actorid" gorm:"not null"`
+	MinutesBefore int       `json:"minutesbefore"`
+	Outcome       string    `json:"outcome" gorm:"not null"`
+	RefundBps     int       `json:"refundbps"`
+	Paid          int64     `json:"paid"`
+	Refunded      int64     `json:"refunded"`
+	Kept          int64     `json:"kept"`
+	CreatedAt     time.Time `json:"createdat"`
+}
+
+// Reasons a booking is called off
+const (
+	CancelByUser   = "user_cancel"
+	CancelByWorker = "worker_cancel"
+	WorkerNoShow   = "worker_no_show"
+)
+
+// Outcomes of a cancellation policy
+const (
+	OutcomeFullRefund    = "full_refund"
+	OutcomePartialRefund = "partial_refund"
+	OutcomeNoRefund      = "no_refund"
+	OutcomeNothingPaid   = "nothing_paid"
+)
+
+// LedgerTransaction groups the entries of one movement of money. Its entries
+// always sum to zero, money only ever moves from one account to another.
+type LedgerTransaction struct {
+	IdTransaction int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	Kind          string    `json:"kind" gorm:"not null"`
+	RequestId     *int      `json:"requestid,omitempty" gorm:"index"`
+	PayoutId      *int      `json:"payoutid,omitempty"`
+	Memo          string    `json:"memo"`
+	ActorId       int       `json:"actorid"`
+	CreatedAt     time.Time `json:"createdat"`
+}
+
+// LedgerEntry credits an account with Amount, a negative amount debits it.
+// Worker accounts are told apart by OwnerId, platform accounts have none.
+type LedgerEntry struct {
+	IdEntry       int                `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	TransactionId int                `json:"transactionid" gorm:"not null;index"`
+	Transaction   *LedgerTransaction `json:"-" gorm:"foreignKey:TransactionId;references:IdTransaction"`
+	Account       string             `json:"account" gorm:"not null;index:idx_ledger_entry_account"`
+	OwnerId       int                `json:"ownerid" gorm:"not null;default:0;index:idx_ledger_entry_account"`
+	Amount        int64              `json:"amount" gorm:"not null"`
+	Currency      string             `json:"currency" gorm:"not null"`
+	CreatedAt     time.Time          `json:"createdat"`
+}
+
+// Ledger accounts
+const (
+	// AccountGateway is money paid in through a payment gateway
+	AccountGateway = "gateway"
+	// AccountEscrow is money held between capture and release or refund
+	AccountEscrow     = "escrow"
+	AccountWorker     = "worker"
+	AccountCommission = "commission"
+	AccountRefunds    = "refunds"
+	// AccountAdjustments is the platform side of manual corrections to a worker's balance
+	AccountAdjustments = "adjustments"
+	// AccountPayouts is money approved to leave for a worker's bank
+	AccountPayouts = "payouts"
+	// AccountPromotions is what the platform spends on promo code discounts
+	AccountPromotions = "promotions"
+	// AccountCredit is credit a user has been given, owned by the user
+	AccountCredit = "credit"
+	// AccountReferrals is what the platform spends on referral rewards
+	AccountReferrals = "referrals"
+	// AccountSubscriptions is what workers paid for premium plans
+	AccountSubscriptions = "subscriptions"
+)
+
+// Ledger transaction kinds
+const (
+	LedgerCapture      = "capture"
+	LedgerEarning      = "earning"
+	LedgerCommission   = "commission"
+	LedgerRefund       = "refund"
+	LedgerAdjustment   = "adjustment"
+	LedgerPayout       = "payout"
+	LedgerPromotion    = "promotion"
+	LedgerReferral     = "referral"
+	LedgerSubscription = "subscription"
+)
+
+// Payout is a worker asking for their balance to be paid out. The ledger is
+// debited when an admin approves it, it is paid once exported in a batch.
+type Payout struct {
+	IdPayout    int          `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	WorkerId    int          `json:"workerid" gorm:"not null;index"`
+	Worker      *User        `json:"-" gorm:"foreignKey:WorkerId;references:IdUser"`
+	Amount      int64        `json:"amount" gorm:"not null"`
+	Currency    string       `json:"currency" gorm:"not null"`
+	Destination string       `json:"destination" gorm:"not null"`
+	Status      string       `json:"status" gorm:"not null;default:requested;index"`
+	Note        string       `json:"note"`
+	BatchId     *int         `json:"batchid,omitempty" gorm:"index"`
+	Batch       *PayoutBatch `json:"-" gorm:"foreignKey:BatchId;references:IdBatch"`
+	ReviewedBy  *int         `json:"reviewedby,omitempty"`
+	ReviewedAt  *time.Time   `json:"reviewedat,omitempty"`
+	PaidAt      *time.Time   `json:"paidat,omitempty"`
+	CreatedAt   time.Time    `json:"createdat"`
+	UpdatedAt   time.Time    `json:"updatedat"`
+}
+
+// Payout status values
+const (
+	PayoutRequested = "requested"
+	PayoutApproved  = "approved"
+	PayoutRejected  = "rejected"
+	PayoutPaid      = "paid"
+)
+
+// PayoutBatch is one export of approved payouts handed to the bank
+type PayoutBatch struct {
+	IdBatch   int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	CreatedBy int       `json:"createdby" gorm:"not null"`
+	Count     int       `json:"count" gorm:"not null"`
+	Total     int64     `json:"total" gorm:"not null"`
+	Currency  string    `json:"currency" gorm:"not null"`
+	CreatedAt time.Time `json:"createdat"`
+}
+
+// DefaultPayoutMinimum is the smallest payout a worker can ask for, in minor
+// units, used when PAYOUT_MINIMUM is not set
+const DefaultPayoutMinimum int64 = 50000
+
+// Invoice is a tax document issued for a completed request. The user gets an
+// invoice from the worker for the job, the worker one from the platform for
+// its commission, and refunds after completion are documented with credit
+// notes against the user's invoice. Amounts are tax inclusive, Subtotal is
+// what is left of Total once Tax is taken out.
+type Invoice struct {
+	IdInvoice     int           `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	Number        string        `json:"number" gorm:"not null;unique"`
+	Kind          string        `json:"kind" gorm:"not null;index:idx_invoice_request_kind"`
+	RequestId     int           `json:"requestid" gorm:"not null;index:idx_invoice_request_kind"`
+	Request       *Request      `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	CreditedId    *int          `json:"creditedid,omitempty"`
+	RefundId      *int          `json:"refundid,omitempty"`
+	IssuerId      int           `json:"issuerid"`
+	IssuerName    string        `json:"issuername" gorm:"not null"`
+	RecipientId   int           `json:"recipientid" gorm:"not null"`
+	RecipientName string        `json:"recipientname" gorm:"not null"`
+	Currency      string        `json:"currency" gorm:"not null"`
+	TaxBps        int           `json:"taxbps" gorm:"not null"`
+	Subtotal      int64         `json:"subtotal" gorm:"not null"`
+	Tax           int64         `json:"tax" gorm:"not null"`
+	Total         int64         `json:"total" gorm:"not null"`
+	IssuedAt      time.Time     `json:"issuedat"`
+	Lines         []InvoiceLine `json:"lines" gorm:"foreignKey:InvoiceId;references:IdInvoice"`
+}
+
+type InvoiceLine struct {
+	IdLine      int    `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
+	InvoiceId   int    `json:"-" gorm:"not null;index"`
+	Kind        string `json:"kind" gorm:"not null"`
+	Description string `json:"description" gorm:"not null"`
+	Amount      int64  `json:"amount" gorm:"not null"`
+}
+
+// InvoiceSequence hands out invoice numbers. Numbers are taken in the
+// transaction that issues the invoice so a series never has gaps.
+type InvoiceSequence struct {
+	Series string `gorm:"primaryKey"`
+	Last   int    `gorm:"not null;default:0"`
+}
+
+// RequestMaterial is material the worker bought for a request. It is part of
+// the agreed price and shows as its own line on the user's invoice.
+type RequestMaterial struct {
+	IdMaterial  int      `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
+	RequestId   int      `json:"-" gorm:"not null;index"`
+	Request     *Request `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	Description string   `json:"description" gorm:"not null"`
+	Amount      int64    `json:"amount" gorm:"not null"`
+}
+
+// Invoice kinds
+const (
+	InvoiceJob        = "job"
+	InvoiceCommission = "commission"
+	InvoiceCreditNote = "credit_note"
+)
+
+// Invoice line kinds
+const (
+	LineWage       = "wage"
+	LineMaterials  = "materials"
+	LineCommission = "commission"
+	LineRefund     = "refund"
+	LineDiscount   = "discount"
+	LineTax        = "tax"
+)
+
+// PlatformName is the issuer of the platform's own invoices
+const PlatformName = "FixItNow"
+
+// DefaultTaxBps is the tax rate prices include, in basis points, used when
+// TAX_BPS is not set
+const DefaultTaxBps = 1800
+
+// PromoCode takes money off the price of a booking. Value is in basis points
+// of the price for a percentage code and in minor units for a flat one. Zero
+// limits, caps and minimums mean there is none.
+type PromoCode struct {
+	IdPromoCode      int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	Code             string    `json:"code" gorm:"not null;unique"`
+	Description      string    `json:"description"`
+	Kind             string    `json:"kind" gorm:"not null"`
+	Value            int64     `json:"value" gorm:"not null"`
+	MaxDiscount      int64     `json:"maxdiscount" gorm:"not null;default:0"`
+	MinAmount        int64     `json:"minamount" gorm:"not null;default:0"`
+	CategoryId       *int      `json:"categoryid,omitempty"`
+	Category         *Category `json:"-" gorm:"foreignKey:CategoryId;references:IdCategory"`
+	FirstBookingOnly bool      `json:"firstbookingonly" gorm:"not null;default:false"`
+	UsageLimit       int       `json:"usagelimit" gorm:"not null;default:0"`
+	PerUserLimit     int       `json:"peruserlimit" gorm:"not null;default:1"`
+	Used             int       `json:"used" gorm:"not null;default:0"`
+	StartsAt         time.Time `json:"startsat" gorm:"not null"`
+	EndsAt           time.Time `json:"endsat" gorm:"not null"`
+	Active           bool      `json:"active" gorm:"not null;default:true"`
+	CreatedBy        int       `json:"createdby" gorm:"not null"`
+	CreatedAt        time.Time `json:"createdat"`
+	UpdatedAt        time.Time `json:"updatedat"`
+}
+
+// Promo code kinds
+const (
+	PromoPercent = "percent"
+	PromoFlat    = "flat"
+)
+
+// PromoRedemption is a promo code used on a request. It is removed again if
+// the request is rejected or cancelled so the use is given back.
+type PromoRedemption struct {
+	IdRedemption int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	PromoCodeId  int        `json:"promocodeid" gorm:"not null;index"`
+	PromoCode    *PromoCode `json:"-" gorm:"foreignKey:PromoCodeId;references:IdPromoCode"`
+	UserId       int        `json:"userid" gorm:"not null;index"`
+	RequestId    int        `json:"requestid" gorm:"not null;unique"`
+	Request      *Request   `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	Discount     int64      `json:"discount" gorm:"not null"`
+	CreatedAt    time.Time  `json:"createdat"`
+}
+
+// ReferralCode is the code an account hands out to bring others in
+type ReferralCode struct {
+	UserId    int       `json:"-" gorm:"primaryKey"`
+	User      *User     `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	Code      string    `json:"code" gorm:"not null;unique"`
+	CreatedAt time.Time `json:"createdat"`
+}
+
+// Referral is an account that signed up with another's code. Both are
+// rewarded once the referee completes a first booking. A referral that looks
+// like the referrer signing up again is kept, rejected, for the record.
+type Referral struct {
+	IdReferral   int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	ReferrerId   int        `json:"referrerid" gorm:"not null;index"`
+	Referrer     *User      `json:"-" gorm:"foreignKey:ReferrerId;references:IdUser"`
+	RefereeId    int        `json:"refereeid" gorm:"not null;unique"`
+	Referee      *User      `json:"-" gorm:"foreignKey:RefereeId;references:IdUser"`
+	Code         string     `json:"code" gorm:"not null"`
+	DeviceId     string     `json:"-" gorm:"index"`
+	Status       string     `json:"status" gorm:"not null;default:pending"`
+	RejectReason string     `json:"rejectreason,omitempty"`
+	Reward       int64      `json:"reward" gorm:"not null;default:0"`
+	RequestId    *int       `json:"requestid,omitempty"`
+	RewardedAt   *time.Time `json:"rewardedat,omitempty"`
+	CreatedAt    time.Time  `json:"createdat"`
+}
+
+// Referral status values
+const (
+	ReferralPending  = "pending"
+	ReferralRewarded = "rewarded"
+	ReferralRejected = "rejected"
+)
+
+// DefaultReferralReward is what each side of a referral gets, in minor units,
+// when REFERRAL_REWARD is not set
+const DefaultReferralReward = 10000
+
+// SignupDevice is a device an account signed up from. It tells a referrer
+// signing up again on their own phone apart from a real referral.
+type SignupDevice struct {
+	IdSignupDevice int       `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
+	UserId         int       `json:"-" gorm:"not null;index"`
+	User           *User     `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	DeviceId       string    `json:"-" gorm:"not null;index"`
+	CreatedAt      time.Time `json:"createdat"`
+}
+
+// SubscriptionPlan is a premium plan workers pay for out of their wallet.
+// JobLimit is how many open jobs a subscriber may list.
+type SubscriptionPlan struct {
+	IdPlan    int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	Name      string    `json:"name" gorm:"not null;unique"`
+	Interval  string    `json:"interval" gorm:"column:billing_interval;not null"`
+	Price     int64     `json:"price" gorm:"not null"`
+	Currency  string    `json:"currency" gorm:"not null"`
+	JobLimit  int       `json:"joblimit" gorm:"not null"`
+	Priority  bool      `json:"priority" gorm:"not null;default:false"`
+	Badge     string    `json:"badge"`
+	Active    bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"createdat"`
+	UpdatedAt time.Time `json:"updatedat"`
+}
+
+// PeriodEnd is when a period of the plan starting at start ends
+func (p SubscriptionPlan) PeriodEnd(start time.Time) time.Time {
+	if p.Interval == IntervalQuarterly {
+		return start.AddDate(0, 3, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// Subscription plan intervals
+const (
+	IntervalMonthly   = "monthly"
+	IntervalQuarterly = "quarterly"
+)
+
+// Subscription is a worker's premium plan. It renews out of the wallet at the
+// end of each period. A renewal the wallet cannot cover leaves the plan in
+// grace until GraceEndsAt, after which the worker is downgraded.
+type Subscription struct {
+	IdSubscription int               `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	WorkerId       int               `json:"workerid" gorm:"not null;index"`
+	Worker         *User             `json:"-" gorm:"foreignKey:WorkerId;references:IdUser"`
+	PlanId         int               `json:"planid" gorm:"not null"`
+	Plan           *SubscriptionPlan `json:"plan,omitempty" gorm:"foreignKey:PlanId;references:IdPlan"`
+	Status         string            `json:"status" gorm:"not null"`
+	AutoRenew      bool              `json:"autorenew" gorm:"not null;default:true"`
+	PeriodStart    time.Time         `json:"periodstart" gorm:"not null"`
+	PeriodEnd      time.Time         `json:"periodend" gorm:"not null;index"`
+	GraceEndsAt    *time.Time        `json:"graceendsat,omitempty"`
+	CreatedAt      time.Time         `json:"createdat"`
+	UpdatedAt      time.Time         `json:"updatedat"`
+}
+
+// Subscription status values. Active and grace subscriptions give the plan's benefits.
+const (
+	SubscriptionActive  = "active"
+	SubscriptionGrace   = "grace"
+	SubscriptionExpired = "expired"
+)
+
+// FreeJobLimit is how many open jobs a worker without a plan may list
+const FreeJobLimit = 2
+
+// DefaultGraceDays is how long a plan is kept after a failed renewal when
+// SUBSCRIPTION_GRACE_DAYS is not set
+const DefaultGraceDays = 3
+
+// Dispute is a complaint a party of a request takes to the platform. The
+// respondent is the other party. A request has at most one unresolved dispute.
+type Dispute struct {
+	IdDispute   int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	RequestId   int        `json:"requestid" gorm:"not null;index"`
+	Request     *Request   `json:"-" gorm:"foreignKey:RequestId;references:IdRequset"`
+	OpenedBy    int        `json:"openedby" gorm:"not null;index"`
+	Respondent  int        `json:"respondent" gorm:"not null;index"`
+	Reason      string     `json:"reason" gorm:"not null"`
+	Description string     `json:"description" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;index"`
+	AssignedTo  *int       `json:"assignedto,omitempty" gorm:"index"`
+	Outcome     string     `json:"outcome,omitempty"`
+	RefundId    *int       `json:"refundid,omitempty"`
+	Resolution  string     `json:"resolution,omitempty"`
+	ResolvedBy  *int       `json:"resolvedby,omitempty"`
+	ResolvedAt  *time.Time `json:"resolvedat,omitempty"`
+	CreatedAt   time.Time  `json:"createdat"`
+	UpdatedAt   time.Time  `json:"updatedat"`
+}
+
+// Dispute reasons
+const (
+	DisputeNoShow      = "no_show"
+	DisputeQuality     = "poor_quality"
+	DisputeDamage      = "damage"
+	DisputeOvercharge  = "overcharge"
+	DisputeConduct     = "conduct"
+	DisputeOtherReason = "other"
+)
+
+// Dispute status values. A dispute is open until an admin takes it up.
+const (
+	DisputeOpen     = "open"
+	DisputeInReview = "in_review"
+	DisputeResolved = "resolved"
+)
+
+// Dispute outcomes besides OutcomeFullRefund and OutcomePartialRefund
+const (
+	OutcomeWarning    = "warning"
+	OutcomeSuspension = "suspension"
+	OutcomeDismissed  = "dismissed"
+)
+
+// DisputeEvidence is a file a party attached to a dispute. StorageKey
+// locates its content in the file store.
+type DisputeEvidence struct {
+	IdEvidence  int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	DisputeId   int       `json:"disputeid" gorm:"not null;index"`
+	Dispute     *Dispute  `json:"-" gorm:"foreignKey:DisputeId;references:IdDispute"`
+	UploadedBy  int       `json:"uploadedby" gorm:"not null"`
+	FileName    string    `json:"filename" gorm:"not null"`
+	ContentType string    `json:"contenttype" gorm:"not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	StorageKey  string    `json:"-" gorm:"not null;unique"`
+	CreatedAt   time.Time `json:"createdat"`
+}
+
+// MaxEvidenceSize is the largest evidence file accepted, in bytes
+const MaxEvidenceSize = 10 << 20
+
+// DisputeMessage is a message on a dispute. Internal ones are notes admins
+// keep among themselves, the others are seen by both parties.
+type DisputeMessage struct {
+	IdMessage int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	DisputeId int       `json:"disputeid" gorm:"not null;index"`
+	Dispute   *Dispute  `json:"-" gorm:"foreignKey:DisputeId;references:IdDispute"`
+	AuthorId  int       `json:"authorid" gorm:"not null"`
+	Body      string    `json:"body" gorm:"not null"`
+	Internal  bool      `json:"internal" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"createdat"`
+}
+
+// AccountAction is a sanction put on an account. A suspension also marks the
+// user suspended, which keeps them from signing in.
+type AccountAction struct {
+	IdAction  int    `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	UserId    int    `json:"userid" gorm:"not null;index"`
+	User      *User  `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	Kind      string `json:"kind" gorm:"not null"`
+	DisputeId *int   `json:"disputeid,omitempty"`
+	Reason    string `json:"reason" gorm:"not null"`
+	// Until is when a suspension ends
+	Until     *time.Time `json:"until,omitempty"`
+	ActorId   int        `json:"actorid" gorm:"not null"`
+	CreatedAt time.Time  `json:"createdat"`
+}
+
+// Account action kinds. Every kind but a warning changes the account.
+const (
+	ActionWarning    = "warning"
+	ActionSuspension = "suspension"
+	ActionBlock      = "block"
+	ActionUnblock    = "unblock"
+	ActionLogout     = "force_logout"
+)
+
+// Account statuses. An account that was never sanctioned keeps the status it
+// signed up with, one that had a sanction lifted is active.
+const (
+	UserNew       = "newuser"
+	UserActive    = "active"
+	UserBlocked   = "blocked"
+	UserSuspended = "suspended"
+)
+
+// LoginEvent is a successful sign in of an account
+type LoginEvent struct {
+	IdLogin   int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	UserId    int       `json:"userid" gorm:"not null;index"`
+	User      *User     `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	Method    string    `json:"method" gorm:"not null"`
+	Ip        string    `json:"ip"`
+	UserAgent string    `json:"useragent"`
+	CreatedAt time.Time `json:"createdat"`
+}
+
+// Sign in methods, which are also the channels accounts sign up through
+const (
+	LoginPhone  = "phone"
+	LoginGoogle = "google"
+)
+
+// SignupImport is the channel of accounts an admin imported
+const SignupImport = "import"
+
+// Tables admins can export
+const (
+	ExportUsers    = "users"
+	ExportWorkers  = "workers"
+	ExportJobs     = "jobs"
+	ExportRequests = "requests"
+	ExportPayments = "payments"
+)
+
+// Job statuses an export can narrow jobs down to
+const (
+	JobOpen   = "open"
+	JobClosed = "closed"
+)
+
+// MaxImportSize is the largest import file accepted, in bytes, and
+// MaxImportRows the most rows it may hold
+const (
+	MaxImportSize = 5 << 20
+	MaxImportRows = 5000
+)
+
+// DefaultAnalyticsRefreshMinutes is how often the analytics summaries are
+// refreshed unless configured otherwise
+const DefaultAnalyticsRefreshMinutes = 15
+
+// DefaultPhoneRegion is the region of phone numbers given without their
+// country calling code
+const DefaultPhoneRegion = "IN"
+
+// Kinds of phone number. Some regions number mobile and fixed lines alike, so
+// there is no telling which of the two their numbers are.
+const (
+	PhoneMobile            = "mobile"
+	PhoneFixedLine         = "fixed_line"
+	PhoneFixedLineOrMobile = "fixed_line_or_mobile"
+)
+
+// DefaultQueryTimeoutSeconds is how long a request may spend on its queries
+// unless configured otherwise
+const DefaultQueryTimeoutSeconds = 15
+
+// AuditLog is an append only record of a privileged or security relevant
+// action. Before and After hold just the fields the action changed. Each
+// record carries the hash of the one before it, so changing or dropping a
+// record breaks the chain from there on.
+type AuditLog struct {
+	IdAudit    int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	ActorId    int       `json:"actorid" gorm:"not null;index"`
+	ActorRole  string    `json:"actorrole" gorm:"not null"`
+	Action     string    `json:"action" gorm:"not null;index"`
+	TargetType string    `json:"targettype" gorm:"not null"`
+	TargetId   int       `json:"targetid" gorm:"not null"`
+	Before     string    `json:"before"`
+	After      string    `json:"after"`
+	Ip         string    `json:"ip"`
+	UserAgent  string    `json:"useragent"`
+	PrevHash   string    `json:"prevhash" gorm:"not null"`
+	Hash       string    `json:"hash" gorm:"not null;unique"`
+	CreatedAt  time.Time `json:"createdat"`
+}
+
+// Digest is the hash the record should carry given its PrevHash
+func (a AuditLog) Digest() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		a.PrevHash,
+		strconv.Itoa(a.ActorId),
+		a.ActorRole,
+		a.Action,
+		a.TargetType,
+		strconv.Itoa(a.TargetId),
+		a.Before,
+		a.After,
+		a.Ip,
+		a.UserAgent,
+		a.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Audited actions besides the account action kinds. A request is what the
+// middleware records of every change an admin makes through the API.
+const (
+	AuditLogin        = "login"
+	AuditTokenRefresh = "token_refresh"
+	AuditEmailChange  = "email_change"
+	AuditRequest      = "request"
+)
+
+// Audit target types
+const (
+	AuditTargetUser  = "user"
+	AuditTargetRoute = "route"
+)
+
+// AuditActor is who is behind a request, put on the request context under
+// AuditActorKey once the token is checked
+type AuditActor struct {
+	Id        int
+	Role      string
+	Ip        string
+	UserAgent string
+}
+
+const AuditActorKey = "audit.actor"
+
+// Notification is an entry of a user's in-app inbox. Every event lands here
+// whatever the user's preferences, the other channels are opt in or out.
+type Notification struct {
+	IdNotification int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	UserId         int        `json:"-" gorm:"not null;index"`
+	User           *User      `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	Event          string     `json:"event" gorm:"not null"`
+	Title          string     `json:"title" gorm:"not null"`
+	Body           string     `json:"body" gorm:"not null"`
+	RequestId      *int       `json:"requestid,omitempty"`
+	ReadAt         *time.Time `json:"readat,omitempty"`
+	CreatedAt      time.Time  `json:"createdat"`
+}
+
+// NotificationPreference turns one delivery channel of one event on or off for
+// a user. Missing rows fall back to the channel default.
+type NotificationPreference struct {
+	IdPreference int    `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
+	UserId       int    `json:"-" gorm:"not null;uniqueIndex:idx_preference_user_event_channel"`
+	User         *User  `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	Event        string `json:"event" gorm:"not null;uniqueIndex:idx_preference_user_event_channel"`
+	Channel      string `json:"channel" gorm:"not null;uniqueIndex:idx_preference_user_event_channel"`
+	Enabled      bool   `json:"enabled"`
+}
+
+// DeviceToken is a push token registered by one of a user's devices
+type DeviceToken struct {
+	IdDevice  int       `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
+	UserId    int       `json:"-" gorm:"not null;index"`
+	User      *User     `json:"-" gorm:"foreignKey:UserId;references:IdUser"`
+	Token     string    `json:"token" gorm:"not null;unique"`
+	Platform  string    `json:"platform" gorm:"not null"`
+	CreatedAt time.Time `json:"createdat"`
+}
+
+// Notification events
+const (
+	EventBookingCreated   = "booking_created"
+	EventBookingAccepted  = "booking_accepted"
+	EventBookingRejected  = "booking_rejected"
+	EventBookingCancelled = "booking_cancelled"
+	EventDisputeOpened    = "dispute_opened"
+	EventDisputeMessage   = "dispute_message"
+	EventDisputeResolved  = "dispute_resolved"
+)
+
+// Notification delivery channels
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// OutboxMail is an email waiting in, or done with, the outbound queue. It is
+// rendered when queued so a retry sends exactly what was first attempted.
+type OutboxMail struct {
+	IdMail        int        `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	ToAddress     string     `json:"to" gorm:"not null"`
+	Template      string     `json:"template" gorm:"not null"`
+	Locale        string     `json:"locale" gorm:"not null"`
+	Subject       string     `json:"subject" gorm:"not null"`
+	TextBody      string     `json:"textbody" gorm:"not null"`
+	HTMLBody      string     `json:"htmlbody" gorm:"not null"`
+	Status        string     `json:"status" gorm:"not null;default:queued;index:idx_outbox_status_next"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"nextattemptat" gorm:"not null;index:idx_outbox_status_next"`
+	LastError     string     `json:"lasterror"`
+	SentAt        *time.Time `json:"sentat,omitempty"`
+	CreatedAt     time.Time  `json:"createdat"`
+}
+
+// Outbox mail status values
+const (
+	MailQueued = "queued"
+	MailSent   = "sent"
+	MailDead   = "dead"
+)
+
+// DefaultLocale is the locale mails fall back to when there is no template for the asked one
+const DefaultLocale = "en"
+
+// Account roles carried in the access token
+const (
+	RoleAdmin  = "admin"
+	RoleWorker = "worker"
+	RoleUser   = "user"
+)
+
+// Availability is one weekday of the weekly calendar a worker publishes
+type Availability struct {
+	IdAvailability int    `json:"-" gorm:"primaryKey;autoIncrement:true;unique"`
+	WorkerId       int    `json:"-" gorm:"not null;uniqueIndex:idx_availability_worker_weekday"`
+	User           *User  `json:"-" gorm:"foreignKey:WorkerId;references:IdUser"`
+	Weekday        int    `json:"weekday" gorm:"not null;uniqueIndex:idx_availability_worker_weekday"`
+	FirstHalf      bool   `json:"firsthalf"`
+	SecondHalf     bool   `json:"secondhalf"`
+	Timezone       string `json:"timezone" gorm:"not null"`
+}
+
+// Blackout is a day on which a worker takes no bookings whatever the weekly calendar says
+type Blackout struct {
+	IdBlackout int       `json:"id" gorm:"primaryKey;autoIncrement:true;unique"`
+	WorkerId   int       `json:"-" gorm:"not null;uniqueIndex:idx_blackout_worker_day"`
+	User       *User     `json:"-" gorm:"foreignKey:WorkerId;references:IdUser"`
+	Day        time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_blackout_worker_day"`
+	Reason     string    `json:"reason"`
 }
 
 type Ratings struct {
@@ -0,0 +1,222 @@
+package domain
+
+// Kinds of error. The kind decides the status code a request failing with
+// the error is answered with. Unprocessable is a well formed request a rule
+// of the business turns down.
+const (
+	ErrKindNotFound      = "not_found"
+	ErrKindConflict      = "conflict"
+	ErrKindValidation    = "validation"
+	ErrKindUnprocessable = "unprocessable"
+	ErrKindUnauthorized  = "unauthorized"
+	ErrKindForbidden     = "forbidden"
+	ErrKindRateLimited   = "rate_limited"
+)
+
+// Error is a failure the client can act on. Code is stable and meant for
+// machines, Message for people. Errors with the same code are the same error,
+// so errors.Is still matches a sentinel once it wraps a cause. Fields holds
+// what is wrong with each field of an invalid request.
+type Error struct {
+	Kind    string
+	Code    string
+	Message string
+	Fields  map[string]FieldError
+	Err     error
+}
+
+// FieldError is why a field of a request is invalid. Code names the rule the
+// field breaks, so clients can word it in their own language, and Param is
+// what the rule goes by, like the most characters a field may have.
+type FieldError struct {
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap is the error with err as its cause
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithMessage is the error telling more about the case at hand than its
+// usual message does
+func (e *Error) WithMessage(message string) *Error {
+	detailed := *e
+	detailed.Message = message
+	return &detailed
+}
+
+// WithFields is the error with what is wrong with each field of a request
+func (e *Error) WithFields(fields map[string]FieldError) *Error {
+	invalid := *e
+	invalid.Fields = fields
+	return &invalid
+}
+
+func NotFound(code string, message string) *Error {
+	return &Error{Kind: ErrKindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code string, message string) *Error {
+	return &Error{Kind: ErrKindConflict, Code: code, Message: message}
+}
+
+func Validation(code string, message string) *Error {
+	return &Error{Kind: ErrKindValidation, Code: code, Message: message}
+}
+
+func Unprocessable(code string, message string) *Error {
+	return &Error{Kind: ErrKindUnprocessable, Code: code, Message: message}
+}
+
+func Unauthorized(code string, message string) *Error {
+	return &Error{Kind: ErrKindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code string, message string) *Error {
+	return &Error{Kind: ErrKindForbidden, Code: code, Message: message}
+}
+
+func RateLimited(code string, message string) *Error {
+	return &Error{Kind: ErrKindRateLimited, Code: code, Message: message}
+}
+
+var (
+	ErrSlotUnavailable = Conflict("slot_unavailable", "worker is not available for the selected slot")
+	ErrSlotBooked      = Conflict("slot_booked", "the selected slot is already booked")
+	ErrOfferOpen       = Conflict("offer_open", "there is already an open offer on this request")
+	ErrOfferClosed     = Conflict("offer_closed", "the offer is no longer open")
+	ErrNotPayable      = Conflict("not_payable", "only an accepted request can be paid")
+	ErrPaymentNotHeld  = Conflict("payment_not_held", "the request has no payment held in escrow")
+	ErrLowBalance      = Conflict("low_balance", "the wallet balance does not cover the payout")
+	ErrPromoUsedUp     = Conflict("promo_used_up", "the promo code has been used up")
+	ErrJobLimit        = Conflict("job_limit", "the listing limit of your plan is reached")
+	ErrDisputeOpen     = Conflict("dispute_open", "the request already has an unresolved dispute")
+	ErrNothingToRefund = Conflict("nothing_to_refund", "there is nothing to refund")
+	ErrReferralTaken   = Conflict("referral_code_taken", "the referral code is taken")
+	ErrPlanNameTaken   = Conflict("plan_name_taken", "the plan name is taken")
+	ErrSuspended       = Forbidden("account_suspended", "the account is suspended")
+	ErrBlocked         = Forbidden("account_blocked", "the account is blocked")
+	ErrLoggedOut       = Unauthorized("session_ended", "the session has been ended, login again")
+	ErrInvalidToken    = Unauthorized("invalid_token", "your access token is not valid")
+	ErrInvalidRequest  = Validation("invalid_request", "the request is invalid")
+	ErrInvalidPhone    = Validation("invalid_phone", "the phone number is not valid")
+	ErrPhoneRegion     = Validation("phone_region_unsupported", "phone numbers of the region are not supported")
+	ErrNotMobile       = Validation("phone_not_mobile", "codes can only be sent to mobile numbers")
+)
+
+// Requests that don't fit the state of what they act on
+var (
+	ErrStatusChanged      = Conflict("request_status_changed", "request status has changed, reload and try again")
+	ErrPlanRetired        = Conflict("plan_retired", "the plan is no longer offered")
+	ErrHasPlan            = Conflict("plan_active", "you already have a plan, it can be changed once it ends")
+	ErrAlreadyReferred    = Conflict("already_referred", "the account was already referred")
+	ErrPromoCodeTaken     = Conflict("promo_code_taken", "the promo code already exists")
+	ErrPromoUsed          = Conflict("promo_code_used", "you have already used this promo code")
+	ErrNotNegotiable      = Conflict("not_negotiable", "the price of this request can no longer be negotiated")
+	ErrDisputeResolved    = Conflict("dispute_resolved", "the dispute is resolved")
+	ErrNotCompleted       = Conflict("request_not_completed", "invoices are issued once the request is completed")
+	ErrNotSanctioned      = Conflict("account_not_sanctioned", "the account is neither blocked nor suspended")
+	ErrNoApprovedPayouts  = Conflict("no_approved_payouts", "there are no approved payouts")
+	ErrIdempotencyReused  = Conflict("idempotency_key_reused", "the idempotency key was used for another request")
+	ErrPaymentNotReleased = Conflict("payment_not_released", "the request has no released payment")
+	ErrNoShowTooEarly     = Conflict("no_show_too_early", "a no-show can be reported an hour after the booking starts")
+	ErrSlotStarted        = Validation("slot_started", "the selected slot has already started")
+)
+
+// Things an account may not do
+var (
+	ErrOwnOffer      = Forbidden("own_offer", "you cannot answer your own offer")
+	ErrOwnJob        = Forbidden("own_job", "you cannot book your own job")
+	ErrJobNotOpen    = Forbidden("job_not_open", "the job is not open for booking")
+	ErrAdminAccount  = Forbidden("admin_account", "admin accounts cannot be acted on")
+	ErrNotAdmin      = Validation("assignee_not_admin", "disputes can only be assigned to admins")
+	ErrPromoNotFirst = Forbidden("promo_first_booking", "the promo code is only for a first booking")
+)
+
+// Requests that are invalid in ways binding can't tell
+var (
+	ErrInvalidRange      = Validation("invalid_range", "from must be before to")
+	ErrRangeTooLong      = Validation("range_too_long", "the range can be a year at most")
+	ErrIdempotencyKey    = Validation("idempotency_key_required", "an Idempotency-Key of at most 64 characters is required")
+	ErrInvalidDate       = Validation("invalid_date", "date must be in YYYY-MM-DD format")
+	ErrInvalidMonth      = Validation("invalid_month", "month must be given as YYYY-MM")
+	ErrOffsetOnly        = Validation("offset_paging_only", "the list is paged by offset only")
+	ErrSameCounter       = Validation("counter_unchanged", "counter offer must change the amount, accept the offer instead")
+	ErrExportTooLarge    = Validation("export_too_large", "the export is too large, narrow it down by date")
+	ErrSuspensionDays    = Validation("suspension_too_short", "a suspension lasts at least a day")
+	ErrMaterialsCost     = Unprocessable("materials_too_costly", "materials must cost less than the price of the request")
+	ErrEmptyMessage      = Validation("message_empty", "message cannot be empty")
+	ErrMessageTooLong    = Validation("message_too_long", "message is too long")
+	ErrPromoNotActive    = Validation("promo_code_inactive", "the promo code is not valid now")
+	ErrPromoCategory     = Validation("promo_code_category", "the promo code does not apply to this category")
+	ErrPromoPercent      = Validation("promo_percent_too_high", "a percent promo code takes at most 10000 basis points off")
+	ErrRefundTooLarge    = Unprocessable("refund_too_large", "the refund is more than what is left of the payment")
+	ErrEmptyImport       = Validation("import_empty", "the file is empty")
+	ErrUnknownStatus     = Validation("unknown_status", "the status is not known")
+	ErrUnknownGateway    = Validation("unknown_gateway", "the payment gateway is not known")
+	ErrUnknownEvent      = Validation("unknown_event", "the notification event is not known")
+	ErrUnknownReason     = Validation("unknown_reason", "the cancellation reason is not known")
+	ErrUnknownFormat     = Validation("unknown_format", "the format is not known, use csv or xlsx")
+	ErrUnknownTimezone   = Validation("unknown_timezone", "the timezone is not known")
+	ErrRepeatedWeekday   = Validation("weekday_repeated", "a weekday is listed more than once")
+	ErrEvidenceSize      = Validation("evidence_size", "the evidence is empty or too large")
+	ErrEvidenceType      = Validation("evidence_type", "the type of the file is not accepted as evidence")
+	ErrOutcomeParty      = Validation("outcome_party", "the outcome needs the party it is for")
+	ErrOfferCurrency     = Validation("offer_currency", "the offer is in another currency than the request")
+	ErrPayoutMinimum     = Unprocessable("payout_too_small", "the payout is below the minimum")
+	ErrImportInvalid     = Unprocessable("import_invalid", "fix the rows listed and upload the file again")
+	ErrPromoMinimum      = Unprocessable("promo_code_minimum", "the booking is below the minimum of the promo code")
+	ErrImportRows        = Validation("import_too_large", "the import has too many rows")
+	ErrNotDisputable     = Conflict("not_disputable", "the request cannot be disputed")
+	ErrInvalidTransition = Conflict("invalid_transition", "the request cannot move to that status")
+)
+
+// Records that are missing
+var (
+	ErrNoUser         = NotFound("user_not_found", "there is no user")
+	ErrNoWorker       = NotFound("worker_not_found", "there is no worker")
+	ErrNoRequest      = NotFound("request_not_found", "there is no request")
+	ErrNoJob          = NotFound("job_not_found", "there is no job")
+	ErrNoBlackout     = NotFound("blackout_not_found", "there is no blackout")
+	ErrNoOffer        = NotFound("offer_not_found", "there is no offer")
+	ErrNoPayment      = NotFound("payment_not_found", "there is no payment")
+	ErrNoInvoice      = NotFound("invoice_not_found", "there is no invoice")
+	ErrNoPolicy       = NotFound("policy_not_found", "there is no policy")
+	ErrNoPayout       = NotFound("payout_not_found", "there is no payout awaiting review")
+	ErrNoPayoutBatch  = NotFound("payout_batch_not_found", "there is no payout batch")
+	ErrNoPromoCode    = NotFound("promo_code_not_found", "there is no promo code")
+	ErrNoReferralCode = NotFound("referral_code_not_found", "there is no referral code")
+	ErrNoPlan         = NotFound("plan_not_found", "there is no plan")
+	ErrNoSubscription = NotFound("subscription_not_found", "there is no subscription")
+	ErrNoDispute      = NotFound("dispute_not_found", "there is no dispute")
+	ErrNoOpenDispute  = NotFound("open_dispute_not_found", "there is no unresolved dispute")
+	ErrNoEvidence     = NotFound("evidence_not_found", "there is no evidence")
+	ErrNoNotification = NotFound("notification_not_found", "there is no notification")
+	ErrNoDevice       = NotFound("device_not_found", "there is no device")
+	ErrNoDeadMail     = NotFound("dead_mail_not_found", "there is no dead mail")
+	ErrNoExport       = NotFound("export_not_found", "there is no such export")
+)
+
+// What the database refused, as translated by the repositories
+var (
+	ErrAlreadyExists     = Conflict("already_exists", "a record with the same details already exists")
+	ErrReferenceNotFound = NotFound("reference_not_found", "a record it refers to does not exist")
+	ErrStillReferenced   = Conflict("still_referenced", "other records still refer to it")
+)
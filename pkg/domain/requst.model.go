@@ -1,17 +1,297 @@
 package domain
 
+import "time"
+
+// SendOTPInput is the phone number an OTP is sent to. The country calling
+// code can be given apart or left out, in which case the number carries it or
+// is one of the default region.
+type SendOTPInput struct {
+	CountryCode string `json:"countrycode" binding:"omitempty,callingcode"`
+	PhoneNumber string `json:"phonenumber" binding:"required,phone"`
+}
+
+// PhoneNumber is a phone number in E.164 format along with the region it
+// belongs to and its kind
+type PhoneNumber struct {
+	E164   string
+	Region string
+	Type   string
+}
+
 type Signup struct {
-	CountryCode string `json:"countrycode"`
-	PhoneNumber string `json:"phonenumber"`
-	Otp         string `json:"otp"`
+	CountryCode string `json:"countrycode" binding:"omitempty,callingcode"`
+	PhoneNumber string `json:"phonenumber" binding:"required,phone"`
+	Otp         string `json:"otp" binding:"required,otp"`
+	// ReferralCode and DeviceId are only looked at when the account is new
+	ReferralCode string `json:"referralcode" binding:"max=32"`
+	DeviceId     string `json:"deviceid" binding:"max=100"`
 }
 
 type UserData struct {
 	UserId       int
+	Email        string `binding:"required,email"`
+	FirstName    string `binding:"required,max=50"`
+	LastName     string `binding:"max=50"`
+	Gender       string `binding:"max=20"`
+	Dob          string `binding:"omitempty,date"`
+	ProfilePhoto string `json:"profilephoto"  binding:"required"`
+}
+
+type DayAvailability struct {
+	Weekday    int  `json:"weekday" binding:"min=0,max=6"`
+	FirstHalf  bool `json:"firsthalf"`
+	SecondHalf bool `json:"secondhalf"`
+}
+
+type WeeklyAvailability struct {
+	Timezone string            `json:"timezone" binding:"required,timezone"`
+	Days     []DayAvailability `json:"days" binding:"required,max=7,dive"`
+}
+
+type BlackoutInput struct {
+	Day    string `json:"day" binding:"required,date"`
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+type BookingInput struct {
+	JobId     int    `json:"jobid" binding:"required"`
+	AddressId int    `json:"addressid" binding:"required"`
+	Date      string `json:"date" binding:"required,date"`
+	Slot      string `json:"slot" binding:"required,oneof=full_day first_half second_half"`
+	PromoCode string `json:"promocode" binding:"max=32"`
+}
+
+type OfferInput struct {
+	Amount   int64  `json:"amount" binding:"required,min=1"`
+	Currency string `json:"currency" binding:"omitempty,iso4217"`
+}
+
+// ChatFrame is a frame a client sends over the chat socket
+type ChatFrame struct {
+	Type   string `json:"type"`
+	Body   string `json:"body,omitempty"`
+	ReadTo int    `json:"readto,omitempty"`
+}
+
+type ReadReceiptInput struct {
+	ReadTo int `json:"readto" binding:"required,min=1"`
+}
+
+type PreferenceInput struct {
+	Event   string `json:"event" binding:"required,oneof=booking_created booking_accepted booking_rejected booking_cancelled dispute_opened dispute_message dispute_resolved"`
+	Channel string `json:"channel" binding:"required,oneof=email sms push"`
+	Enabled bool   `json:"enabled"`
+}
+
+type PreferencesInput struct {
+	Preferences []PreferenceInput `json:"preferences" binding:"required,dive"`
+}
+
+type DeviceInput struct {
+	Token    string `json:"token" binding:"required,max=4096"`
+	Platform string `json:"platform" binding:"required,oneof=android ios web"`
+}
+
+// NotificationData carries the values a notification template is rendered with
+type NotificationData struct {
+	RequestId int
+	Date      string
+	Slot      string
+}
+
+type CancellationPolicyInput struct {
+	FreeHours  int `json:"freehours" binding:"min=0"`
+	LateFeeBps int `json:"latefeebps" binding:"min=0,max=10000"`
+}
+
+// Settlement moves a request out of one of the From statuses and settles its
+// held payment, refunding RefundBps basis points and releasing the rest
+type Settlement struct {
+	RequestId     int
+	From          []string
+	To            string
+	CommissionBps int
+	Decision      PolicyDecision
+}
+
+type PayoutInput struct {
+	Amount      int64  `json:"amount" binding:"required,min=1"`
+	Destination string `json:"destination" binding:"required,max=100"`
+}
+
+type PayoutReviewInput struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// AdjustmentInput corrects a worker's balance, a negative amount takes money off
+type AdjustmentInput struct {
+	Amount int64  `json:"amount" binding:"required"`
+	Memo   string `json:"memo" binding:"required,max=500"`
+}
+
+type MaterialInput struct {
+	Description string `json:"description" binding:"required,max=200"`
+	Amount      int64  `json:"amount" binding:"required,min=1"`
+}
+
+// CompletionInput lists the materials included in the price of a request as it is completed
+type CompletionInput struct {
+	Materials []MaterialInput `json:"materials" binding:"max=20,dive"`
+}
+
+// RefundInput sends part of a completed request's payment back to the user
+type RefundInput struct {
+	Amount int64  `json:"amount" binding:"required,min=1"`
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// PromoCodeInput is written by admins. Value is in basis points for a percent
+// code and in minor units for a flat one.
+type PromoCodeInput struct {
+	Code             string    `json:"code" binding:"required,min=3,max=32,alphanum"`
+	Description      string    `json:"description" binding:"max=200"`
+	Kind             string    `json:"kind" binding:"required,oneof=percent flat"`
+	Value            int64     `json:"value" binding:"required,min=1"`
+	MaxDiscount      int64     `json:"maxdiscount" binding:"min=0"`
+	MinAmount        int64     `json:"minamount" binding:"min=0"`
+	CategoryId       *int      `json:"categoryid"`
+	FirstBookingOnly bool      `json:"firstbookingonly"`
+	UsageLimit       int       `json:"usagelimit" binding:"min=0"`
+	PerUserLimit     int       `json:"peruserlimit" binding:"min=0"`
+	StartsAt         time.Time `json:"startsat" binding:"required"`
+	EndsAt           time.Time `json:"endsat" binding:"required,gtfield=StartsAt"`
+	Active           bool      `json:"active"`
+}
+
+// PromoCheckInput asks what a promo code takes off a booking before it is made
+type PromoCheckInput struct {
+	Code  string `json:"code" binding:"required,max=32"`
+	JobId int    `json:"jobid" binding:"required"`
+	Slot  string `json:"slot" binding:"required,oneof=full_day first_half second_half"`
+}
+
+type JobInput struct {
+	CategoryId  int    `json:"categoryid" binding:"required"`
+	Expirience  string `json:"expirience" binding:"required,max=200"`
+	Description string `json:"desctription" binding:"required,max=1000"`
+	FullDayWage int    `json:"fuldaywage" binding:"required,min=1"`
+	HalfDayWage int    `json:"halfdaywage" binding:"required,min=1"`
+}
+
+type JobOpenInput struct {
+	Openwork bool `json:"openwork"`
+}
+
+// SubscriptionPlanInput is written by admins, the price is in minor units
+type SubscriptionPlanInput struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	Interval string `json:"interval" binding:"required,oneof=monthly quarterly"`
+	Price    int64  `json:"price" binding:"required,min=1"`
+	JobLimit int    `json:"joblimit" binding:"required,min=1"`
+	Priority bool   `json:"priority"`
+	Badge    string `json:"badge" binding:"max=30"`
+	Active   bool   `json:"active"`
+}
+
+type SubscribeInput struct {
+	PlanId int `json:"planid" binding:"required"`
+}
+
+type AutoRenewInput struct {
+	AutoRenew bool `json:"autorenew"`
+}
+
+type DisputeInput struct {
+	Reason      string `json:"reason" binding:"required,oneof=no_show poor_quality damage overcharge conduct other"`
+	Description string `json:"description" binding:"required,max=2000"`
+}
+
+type DisputeMessageInput struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+// DisputeAssignInput hands a dispute to an admin, the one asking when AdminId is left out
+type DisputeAssignInput struct {
+	AdminId int `json:"adminid" binding:"min=0"`
+}
+
+type DisputeNoteInput struct {
+	Body     string `json:"body" binding:"required,max=2000"`
+	Internal bool   `json:"internal"`
+}
+
+// DisputeResolutionInput closes a dispute. Amount is the refund of a partial
+// refund in minor units, Party whom a warning or suspension is for.
+type DisputeResolutionInput struct {
+	Outcome    string `json:"outcome" binding:"required,oneof=full_refund partial_refund warning suspension dismissed"`
+	Amount     int64  `json:"amount" binding:"min=0"`
+	Party      string `json:"party" binding:"omitempty,oneof=user worker"`
+	Resolution string `json:"resolution" binding:"required,max=2000"`
+}
+
+// DisputeResolution is what ResolveDispute records
+type DisputeResolution struct {
+	DisputeId  int
+	Outcome    string
+	RefundId   *int
+	Resolution string
+	ResolvedBy int
+	// SanctionedId is the account a warning or suspension is put on
+	SanctionedId int
+}
+
+// AccountActionInput is why an admin blocks, suspends or lifts an account.
+// Days is how long a suspension lasts.
+type AccountActionInput struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+	Days   int    `json:"days" binding:"min=0,max=365"`
+}
+
+// AuditQuery narrows the audit log down. Zero values match everything, To
+// is exclusive.
+type AuditQuery struct {
+	ActorId    int        `form:"actor" binding:"min=0"`
+	Action     string     `form:"action"`
+	TargetType string     `form:"targettype"`
+	TargetId   int        `form:"targetid" binding:"min=0"`
+	From       *time.Time `form:"from" time_format:"2006-01-02"`
+	To         *time.Time `form:"to" time_format:"2006-01-02" binding:"omitempty,after=From"`
+}
+
+// AnalyticsQuery narrows analytics down to a date range, To being exclusive,
+// and to the requests or accounts of a city. Limit caps a ranking.
+type AnalyticsQuery struct {
+	From       *time.Time `form:"from" time_format:"2006-01-02"`
+	To         *time.Time `form:"to" time_format:"2006-01-02" binding:"omitempty,after=From"`
+	City       string     `form:"city" binding:"max=100"`
+	CategoryId int        `form:"category" binding:"min=0"`
+	Limit      int        `form:"limit" binding:"min=0,max=100"`
+}
+
+// ExportQuery narrows an export down with the filters of the admin search.
+// Search matches what identifies a record, From and To, To being exclusive,
+// go by when it was made.
+type ExportQuery struct {
+	Format     string     `form:"format" binding:"omitempty,oneof=csv xlsx"`
+	Search     string     `form:"q" binding:"max=100"`
+	Status     string     `form:"status"`
+	CategoryId int        `form:"category" binding:"min=0"`
+	From       *time.Time `form:"from" time_format:"2006-01-02"`
+	To         *time.Time `form:"to" time_format:"2006-01-02" binding:"omitempty,after=From"`
+}
+
+// WorkerImport is a row of a worker import. A worker with a category is also
+// given a job in it.
+type WorkerImport struct {
+	Row          int
+	Phone        string
+	PhoneCountry string
 	Email        string
 	FirstName    string
 	LastName     string
-	Gender       string
-	Dob          string
-	ProfilePhoto string `json:"profilephoto"  binding:"required"`
+	CategoryId   int
+	Expirience   string
+	Description  string
+	FullDayWage  int
+	HalfDayWage  int
 }
@@ -1,6 +1,10 @@
 package domain
 
-import "github.com/golang-jwt/jwt/v4"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
 
 type AdminResponse struct {
 	ID           int    `json:"id_login"`
@@ -87,3 +91,277 @@ type RequestUserResponse struct {
 	RequestStatus string
 	Address       Address
 }
+
+type BookingResponse struct {
+	IdRequest   int       `json:"id"`
+	UserId      int       `json:"userid"`
+	WorkerId    int       `json:"workerid"`
+	JobId       int       `json:"jobid"`
+	JobCategory string    `json:"jobcategory"`
+	CategoryId  int       `json:"categoryid"`
+	AddressId   int       `json:"addressid"`
+	Date        string    `json:"date"`
+	Slot        string    `json:"slot"`
+	Timezone    string    `json:"timezone"`
+	StartAt     time.Time `json:"startat"`
+	EndAt       time.Time `json:"endat"`
+	Status      string    `json:"status"`
+	Amount      int64     `json:"amount"`
+	Currency    string    `json:"currency"`
+	PriceLocked bool      `json:"pricelocked"`
+	Discount    int64     `json:"discount"`
+	PromoCodeId *int      `json:"promocodeid,omitempty"`
+	CreatedAt   time.Time `json:"createdat"`
+}
+
+// Payable is what the user pays for the request
+func (b BookingResponse) Payable() int64 {
+	return b.Amount - b.Discount
+}
+
+// Chat event types pushed to clients of a request's chat
+const (
+	ChatEventMessage = "message"
+	ChatEventRead    = "read"
+	ChatEventTyping  = "typing"
+	ChatEventError   = "error"
+)
+
+// ChatEvent is pushed over the chat socket to everyone in a request's chat
+type ChatEvent struct {
+	Type      string    `json:"type"`
+	RequestId int       `json:"requestid"`
+	SenderId  int       `json:"senderid"`
+	Message   *Message  `json:"message,omitempty"`
+	ReadTo    int       `json:"readto,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+type UnreadCount struct {
+	Unread int `json:"unread"`
+}
+
+// PromoQuote is what a promo code takes off the price of a booking
+type PromoQuote struct {
+	Code     string `json:"code"`
+	Amount   int64  `json:"amount"`
+	Discount int64  `json:"discount"`
+	Payable  int64  `json:"payable"`
+	Currency string `json:"currency"`
+}
+
+// ReferralSummary is an account's referral code and how far its referrals got.
+// Credit is the user credit balance, workers are rewarded into their wallet.
+type ReferralSummary struct {
+	Code     string `json:"code"`
+	Pending  int    `json:"pending"`
+	Rewarded int    `json:"rewarded"`
+	Earned   int64  `json:"earned"`
+	Credit   int64  `json:"credit"`
+	Currency string `json:"currency"`
+}
+
+// JobListing is a job as it shows in search. Badge is the one of the
+// worker's premium plan, if any.
+type JobListing struct {
+	IdJob       int    `json:"id"`
+	WorkerId    int    `json:"workerid"`
+	CategoryId  int    `json:"categoryid"`
+	Category    string `json:"category"`
+	Expirience  string `json:"expirience"`
+	Description string `json:"desctription"`
+	FullDayWage int    `json:"fuldaywage"`
+	HalfDayWage int    `json:"halfdaywage"`
+	Openwork    bool   `json:"openwork"`
+	Priority    bool   `json:"priority"`
+	Badge       string `json:"badge,omitempty"`
+}
+
+// DisputeDetail is a dispute with its evidence and messages
+type DisputeDetail struct {
+	Dispute
+	Evidence []DisputeEvidence `json:"evidence"`
+	Messages []DisputeMessage  `json:"messages"`
+}
+
+// AccountSummary is an account as admins find it. Status is the one in
+// effect, a suspension that ran out reads active.
+type AccountSummary struct {
+	Id             int        `json:"id"`
+	Phone          string     `json:"phonenumber"`
+	Email          string     `json:"email"`
+	UserType       string     `json:"usertype"`
+	Verification   bool       `json:"verification"`
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspendeduntil,omitempty"`
+	FirstName      string     `json:"firstname"`
+	LastName       string     `json:"lastname"`
+}
+
+// AccountDetail is everything an admin looks at about an account. Jobs and
+// the rating are the ones of a worker, Requests the latest ones either made
+// or taken.
+type AccountDetail struct {
+	AccountSummary
+	Profile     *Profile          `json:"profile,omitempty"`
+	Addresses   []Address         `json:"addresses"`
+	Jobs        []JobListing      `json:"jobs"`
+	Requests    []BookingResponse `json:"requests"`
+	Rating      float64           `json:"rating"`
+	RatingCount int               `json:"ratingcount"`
+	Actions     []AccountAction   `json:"actions"`
+}
+
+// AuditVerification is the outcome of walking the audit chain. BrokenAt is
+// the first record whose hashes do not hold.
+type AuditVerification struct {
+	Checked  int  `json:"checked"`
+	Valid    bool `json:"valid"`
+	BrokenAt *int `json:"brokenat,omitempty"`
+}
+
+// ImportError is what is wrong with a cell of an import. Rows are numbered
+// the way a spreadsheet does, the header being row 1.
+type ImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column"`
+	Message string `json:"message"`
+}
+
+// ImportReport is the outcome of an import. Nothing is imported while any
+// row has errors, and a dry run only checks the rows.
+type ImportReport struct {
+	DryRun   bool          `json:"dryrun"`
+	Rows     int           `json:"rows"`
+	Valid    int           `json:"valid"`
+	Imported int           `json:"imported"`
+	Errors   []ImportError `json:"errors"`
+}
+
+// SignupStat is how many accounts signed up through a channel on a day
+type SignupStat struct {
+	Day      string `json:"day"`
+	Channel  string `json:"channel"`
+	UserType string `json:"usertype"`
+	Signups  int    `json:"signups"`
+}
+
+// WorkerActivity is how many workers list a category and how many of them
+// were booked in the range
+type WorkerActivity struct {
+	CategoryId int    `json:"categoryid"`
+	Category   string `json:"category"`
+	Listed     int    `json:"listed"`
+	Booked     int    `json:"booked"`
+}
+
+// RequestFunnel follows the requests made in the range from being made to
+// being completed. Accepted counts every request that got past the worker,
+// whatever became of it after.
+type RequestFunnel struct {
+	Requests         int     `json:"requests"`
+	Accepted         int     `json:"accepted"`
+	Paid             int     `json:"paid"`
+	Completed        int     `json:"completed"`
+	Rejected         int     `json:"rejected"`
+	Cancelled        int     `json:"cancelled"`
+	NoShow           int     `json:"noshow"`
+	Conversion       float64 `json:"conversion"`
+	CancellationRate float64 `json:"cancellationrate"`
+}
+
+// CancellationStat is how often requests of a category fell through
+type CancellationStat struct {
+	CategoryId int     `json:"categoryid"`
+	Category   string  `json:"category"`
+	Requests   int     `json:"requests"`
+	Cancelled  int     `json:"cancelled"`
+	NoShow     int     `json:"noshow"`
+	Rate       float64 `json:"rate"`
+}
+
+// RevenueStat is what released payments of requests made on a day came to
+type RevenueStat struct {
+	Day        string `json:"day"`
+	Currency   string `json:"currency"`
+	Gmv        int64  `json:"gmv"`
+	Commission int64  `json:"commission"`
+	Discount   int64  `json:"discount"`
+}
+
+type CategoryRating struct {
+	CategoryId int     `json:"categoryid"`
+	Category   string  `json:"category"`
+	Average    float64 `json:"average"`
+	Ratings    int     `json:"ratings"`
+}
+
+// TopWorker is a worker ranked by the requests they completed in the range
+type TopWorker struct {
+	WorkerId  int     `json:"workerid"`
+	FirstName string  `json:"firstname"`
+	LastName  string  `json:"lastname"`
+	Completed int     `json:"completed"`
+	Gmv       int64   `json:"gmv"`
+	Currency  string  `json:"currency"`
+	Rating    float64 `json:"rating"`
+}
+
+// PaymentResponse is a payment along with what the client needs to finish
+// the checkout while the payment is still to be captured
+type PaymentResponse struct {
+	Payment  Payment   `json:"payment"`
+	Checkout *Checkout `json:"checkout,omitempty"`
+}
+
+type Checkout struct {
+	Gateway      string `json:"gateway"`
+	OrderId      string `json:"orderid"`
+	ClientSecret string `json:"clientsecret,omitempty"`
+	KeyId        string `json:"keyid,omitempty"`
+}
+
+// Wallet is a worker's ledger balance. Available leaves out what is already
+// asked for in payouts awaiting review.
+type Wallet struct {
+	WorkerId  int    `json:"workerid"`
+	Currency  string `json:"currency"`
+	Balance   int64  `json:"balance"`
+	Pending   int64  `json:"pending"`
+	Available int64  `json:"available"`
+}
+
+// WalletEntry is a line of a worker's account along with what moved the money
+type WalletEntry struct {
+	IdEntry       int       `json:"id"`
+	TransactionId int       `json:"transactionid"`
+	Kind          string    `json:"kind"`
+	RequestId     *int      `json:"requestid,omitempty"`
+	PayoutId      *int      `json:"payoutid,omitempty"`
+	Memo          string    `json:"memo"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	CreatedAt     time.Time `json:"createdat"`
+}
+
+// Statement sums up a worker's account over a month. Every entry falls in
+// one of the sums, those of a kind without its own in Other, so the opening
+// balance and the sums always add up to the closing one.
+type Statement struct {
+	WorkerId      int           `json:"workerid"`
+	Month         string        `json:"month"`
+	Currency      string        `json:"currency"`
+	Opening       int64         `json:"opening"`
+	Earnings      int64         `json:"earnings"`
+	Commissions   int64         `json:"commissions"`
+	Refunds       int64         `json:"refunds"`
+	Adjustments   int64         `json:"adjustments"`
+	Payouts       int64         `json:"payouts"`
+	Promotions    int64         `json:"promotions"`
+	Referrals     int64         `json:"referrals"`
+	Subscriptions int64         `json:"subscriptions"`
+	Other         int64         `json:"other"`
+	Closing       int64         `json:"closing"`
+	Entries       []WalletEntry `json:"entries"`
+}
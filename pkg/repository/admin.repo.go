@@ -1,15 +1,316 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 )
 
 type adminRepo struct {
 	db *sql.DB
 }
 
+// accountStatus is the status in effect, a suspension that ran out reads active
+const accountStatus = `CASE WHEN u.status='` + domain.UserSuspended + `' AND u.suspended_until<=NOW() THEN '` + domain.UserActive + `' ELSE u.status END`
+
+// accountType reads the accounts made before user_type was set as users
+const accountType = `COALESCE(NULLIF(u.user_type, ''), '` + domain.RoleUser + `')`
+
+const accountColumns = `u.id_user, u.phone, u.email, ` + accountType + `, u.verification, ` + accountStatus + `, u.suspended_until, COALESCE(p.first_name, ''), COALESCE(p.last_name, '')`
+
+const accountTables = `users u LEFT JOIN profiles p ON p.user_id=u.id_user`
+
+const accountActionColumns = `id_action, user_id, kind, dispute_id, reason, until, actor_id, created_at`
+
+const loginColumns = `id_login, user_id, method, ip, user_agent, created_at`
+
+// SearchAccounts implements interfaces.AdminRepository. search matches part
+// of the phone number, email or name, status the status in effect.
+func (c *adminRepo) SearchAccounts(ctx context.Context, userType string, search string, status string, filter utils.Filter) ([]domain.AccountSummary, utils.Metadata, error) {
+	var accounts []domain.AccountSummary
+	var total int
+
+	args := []interface{}{userType, likePattern(search), status}
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + accountColumns + `, COUNT(*) OVER() FROM ` + accountTables + `
+				WHERE ` + accountType + `=$1
+				AND ($2='%%' OR u.phone ILIKE $2 OR u.email ILIKE $2 OR p.first_name ILIKE $2 OR p.last_name ILIKE $2
+					OR CONCAT(p.first_name, ' ', p.last_name) ILIKE $2)
+				AND ($3='' OR ` + accountStatus + `=$3)
+				ORDER BY u.id_user DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return accounts, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		account, err := scanAccount(rows, &total)
+		if err != nil {
+			return accounts, utils.Metadata{}, err
+		}
+		accounts = append(accounts, account)
+	}
+	if err = rows.Err(); err != nil {
+		return accounts, utils.Metadata{}, err
+	}
+	return accounts, pageMetadata(filter, len(accounts), total, utils.Cursor{}), nil
+}
+
+// FindAccount implements interfaces.AdminRepository
+func (c *adminRepo) FindAccount(ctx context.Context, userId int) (domain.AccountSummary, error) {
+	query := `SELECT ` + accountColumns + ` FROM ` + accountTables + ` WHERE u.id_user=$1;`
+	account, err := scanAccount(conn(ctx, c.db).QueryRowContext(ctx, query, userId))
+	if err != nil && err == sql.ErrNoRows {
+		return account, domain.ErrNoUser
+	}
+	return account, err
+}
+
+// ListAddresses implements interfaces.AdminRepository
+func (c *adminRepo) ListAddresses(ctx context.Context, userId int) ([]domain.Address, error) {
+	var addresses []domain.Address
+
+	query := `SELECT id_address, user_id, address_category, mapcoordinates, housenumber, floor, blockor_tower, landmark, city
+				FROM addresses WHERE user_id=$1 ORDER BY id_address;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return addresses, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var address domain.Address
+		err = rows.Scan(
+			&address.IdAddress,
+			&address.UserId,
+			&address.AddressCategory,
+			&address.Mapcoordinates,
+			&address.Housenumber,
+			&address.Floor,
+			&address.BlockorTower,
+			&address.Landmark,
+			&address.City,
+		)
+		if err != nil {
+			return addresses, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, rows.Err()
+}
+
+// ListRecentRequests implements interfaces.AdminRepository. It takes both the
+// requests the account made and the ones it was booked for.
+func (c *adminRepo) ListRecentRequests(ctx context.Context, userId int, limit int) ([]domain.BookingResponse, error) {
+	var bookings []domain.BookingResponse
+
+	query := `SELECT ` + bookingColumns + ` FROM ` + bookingTables + `
+				WHERE r.user_id=$1 OR j.id_worker=$1 ORDER BY r.created_at DESC, r.id_requset DESC LIMIT $2;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, userId, limit)
+	if err != nil {
+		return bookings, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return bookings, err
+		}
+		bookings = append(bookings, booking)
+	}
+	return bookings, rows.Err()
+}
+
+// RatingSummary implements interfaces.AdminRepository
+func (c *adminRepo) RatingSummary(ctx context.Context, workerId int) (float64, int, error) {
+	var average float64
+	var count int
+	query := `SELECT COALESCE(AVG(rating), 0), COUNT(*) FROM ratings WHERE worker_id=$1;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, workerId).Scan(&average, &count)
+	return average, count, err
+}
+
+// ListAccountActions implements interfaces.AdminRepository
+func (c *adminRepo) ListAccountActions(ctx context.Context, userId int) ([]domain.AccountAction, error) {
+	var actions []domain.AccountAction
+
+	query := `SELECT ` + accountActionColumns + ` FROM account_actions WHERE user_id=$1 ORDER BY created_at DESC, id_action DESC;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return actions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		action, err := scanAccountAction(rows)
+		if err != nil {
+			return actions, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
+
+// ApplyAccountAction implements interfaces.AdminRepository. The action and
+// its audit record are written together.
+func (c *adminRepo) ApplyAccountAction(ctx context.Context, action domain.AccountAction, audit domain.AuditLog) (domain.AccountAction, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return action, err
+	}
+	defer tx.Rollback()
+
+	action, err = insertAccountAction(ctx, tx, action)
+	if err != nil {
+		return action, err
+	}
+	if _, err = appendAudit(ctx, tx, audit); err != nil {
+		return action, err
+	}
+	return action, tx.Commit()
+}
+
+// ListLogins implements interfaces.AdminRepository
+func (c *adminRepo) ListLogins(ctx context.Context, userId int, filter utils.Filter) ([]domain.LoginEvent, utils.Metadata, error) {
+	var logins []domain.LoginEvent
+	var total int
+
+	args := []interface{}{userId}
+	keyset, keysetArgs := filter.KeysetCondition("created_at", "id_login", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + loginColumns + `, COUNT(*) OVER() FROM login_events
+				WHERE user_id=$1` + keyset + ` ORDER BY created_at DESC, id_login DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return logins, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var login domain.LoginEvent
+		err = rows.Scan(
+			&login.IdLogin,
+			&login.UserId,
+			&login.Method,
+			&login.Ip,
+			&login.UserAgent,
+			&login.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return logins, utils.Metadata{}, err
+		}
+		logins = append(logins, login)
+	}
+	if err = rows.Err(); err != nil {
+		return logins, utils.Metadata{}, err
+	}
+
+	fetched := len(logins)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		logins = logins[:filter.PageSize]
+	}
+	if len(logins) > 0 {
+		last = utils.Cursor{Id: logins[len(logins)-1].IdLogin, CreatedAt: logins[len(logins)-1].CreatedAt}
+	}
+	return logins, pageMetadata(filter, fetched, total, last), nil
+}
+
+// insertAccountAction records an action on an account and makes the change
+// it stands for. A suspension without an end lasts until it is lifted.
+func insertAccountAction(ctx context.Context, tx *sql.Tx, action domain.AccountAction) (domain.AccountAction, error) {
+	query := `INSERT INTO account_actions (user_id, kind, dispute_id, reason, until, actor_id, created_at) VALUES ($1,$2,$3,$4,$5,$6,NOW())
+				RETURNING id_action, created_at;`
+	err := tx.QueryRowContext(ctx, query,
+		action.UserId,
+		action.Kind,
+		action.DisputeId,
+		action.Reason,
+		action.Until,
+		action.ActorId,
+	).Scan(&action.IdAction, &action.CreatedAt)
+	if err != nil {
+		return action, err
+	}
+
+	switch action.Kind {
+	case domain.ActionSuspension:
+		_, err = tx.ExecContext(ctx, `UPDATE users SET status=$1, suspended_until=$2 WHERE id_user=$3;`, domain.UserSuspended, action.Until, action.UserId)
+	case domain.ActionBlock:
+		_, err = tx.ExecContext(ctx, `UPDATE users SET status=$1, suspended_until=NULL WHERE id_user=$2;`, domain.UserBlocked, action.UserId)
+	case domain.ActionUnblock:
+		_, err = tx.ExecContext(ctx, `UPDATE users SET status=$1, suspended_until=NULL WHERE id_user=$2;`, domain.UserActive, action.UserId)
+	case domain.ActionLogout:
+		_, err = tx.ExecContext(ctx, `UPDATE users SET logged_out_at=NOW() WHERE id_user=$1;`, action.UserId)
+	}
+	return action, err
+}
+
+// likePattern turns a search into an ILIKE pattern matching it anywhere
+func likePattern(search string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	return "%" + escaped + "%"
+}
+
+func scanAccount(row rowScanner, extra ...interface{}) (domain.AccountSummary, error) {
+	var account domain.AccountSummary
+	var suspendedUntil sql.NullTime
+	dest := []interface{}{
+		&account.Id,
+		&account.Phone,
+		&account.Email,
+		&account.UserType,
+		&account.Verification,
+		&account.Status,
+		&suspendedUntil,
+		&account.FirstName,
+		&account.LastName,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if suspendedUntil.Valid && account.Status == domain.UserSuspended {
+		account.SuspendedUntil = &suspendedUntil.Time
+	}
+	return account, err
+}
+
+func scanAccountAction(row rowScanner) (domain.AccountAction, error) {
+	var action domain.AccountAction
+	var disputeId sql.NullInt64
+	var until sql.NullTime
+	err := row.Scan(
+		&action.IdAction,
+		&action.UserId,
+		&action.Kind,
+		&disputeId,
+		&action.Reason,
+		&until,
+		&action.ActorId,
+		&action.CreatedAt,
+	)
+	if disputeId.Valid {
+		id := int(disputeId.Int64)
+		action.DisputeId = &id
+	}
+	if until.Valid {
+		action.Until = &until.Time
+	}
+	return action, err
+}
+
 func NewAdminRepo(db *sql.DB) interfaces.AdminRepository {
 	return &adminRepo{
 		db: db,
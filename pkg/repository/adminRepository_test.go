@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminRepo_ApplyAccountAction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	adminRepo := NewAdminRepo(db)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := now.AddDate(0, 0, 7)
+	actionQuery := "INSERT INTO account_actions"
+
+	tests := []struct {
+		name          string
+		action        domain.AccountAction
+		mockQueryFunc func()
+	}{
+		{
+			name:   "test a block locks the account",
+			action: domain.AccountAction{UserId: 5, Kind: domain.ActionBlock, Reason: "fraud", ActorId: 1},
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(actionQuery).WithArgs(5, domain.ActionBlock, nil, "fraud", nil, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id_action", "created_at"}).AddRow(9, now))
+				mock.ExpectExec("UPDATE users SET status=\\$1, suspended_until=NULL WHERE id_user=\\$2;").
+					WithArgs(domain.UserBlocked, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAudit(mock, "", 1, domain.ActionBlock)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "test a suspension keeps its end",
+			action: domain.AccountAction{UserId: 5, Kind: domain.ActionSuspension, Reason: "abuse", Until: &until, ActorId: 1},
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(actionQuery).WithArgs(5, domain.ActionSuspension, nil, "abuse", until, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id_action", "created_at"}).AddRow(9, now))
+				mock.ExpectExec("UPDATE users SET status=\\$1, suspended_until=\\$2 WHERE id_user=\\$3;").
+					WithArgs(domain.UserSuspended, until, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAudit(mock, "", 1, domain.ActionSuspension)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "test a forced logout voids the sessions",
+			action: domain.AccountAction{UserId: 5, Kind: domain.ActionLogout, Reason: "stolen phone", ActorId: 1},
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(actionQuery).WithArgs(5, domain.ActionLogout, nil, "stolen phone", nil, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id_action", "created_at"}).AddRow(9, now))
+				mock.ExpectExec("UPDATE users SET logged_out_at=NOW\\(\\) WHERE id_user=\\$1;").
+					WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAudit(mock, "", 1, domain.ActionLogout)
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			action, actualErr := adminRepo.ApplyAccountAction(ctx, tt.action, domain.AuditLog{
+				ActorId:    1,
+				Action:     tt.action.Kind,
+				TargetType: "user",
+				TargetId:   5,
+				After:      "{}",
+			})
+
+			assert.NoError(t, actualErr)
+			assert.Equal(t, 9, action.IdAction)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+type analyticsRepo struct {
+	db *sql.DB
+}
+
+// releasedPayments is what the released payments of each request came to
+const releasedPayments = `SELECT request_id, SUM(amount+discount-refunded) AS gmv FROM payments
+				WHERE status='` + domain.PaymentReleased + `' GROUP BY request_id`
+
+// summaryRange narrows the summaries down to the range and city of the query,
+// taking $1 to $3
+const summaryRange = `day>=$1 AND day<$2 AND ($3='' OR city=$3)`
+
+// SignupsPerDay implements interfaces.AnalyticsRepository
+func (c *analyticsRepo) SignupsPerDay(ctx context.Context, query domain.AnalyticsQuery) ([]domain.SignupStat, error) {
+	var stats []domain.SignupStat
+
+	sqlQuery := `SELECT TO_CHAR(day, 'YYYY-MM-DD'), channel, user_type, SUM(signups) FROM signup_daily_summary
+					WHERE ` + summaryRange + ` GROUP BY day, channel, user_type ORDER BY day, channel, user_type;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, query.From, query.To, query.City)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat domain.SignupStat
+		if err = rows.Scan(&stat.Day, &stat.Channel, &stat.UserType, &stat.Signups); err != nil {
+			return stats, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
+
+// ActiveWorkers implements interfaces.AnalyticsRepository. A worker is listed
+// under a category while they have an open job in it, the city is where the
+// worker has an address.
+func (c *analyticsRepo) ActiveWorkers(ctx context.Context, query domain.AnalyticsQuery) ([]domain.WorkerActivity, error) {
+	var activity []domain.WorkerActivity
+
+	sqlQuery := `SELECT c.id_category, c.category,
+					COUNT(DISTINCT j.id_worker) FILTER (WHERE j.openwork),
+					COUNT(DISTINCT j.id_worker) FILTER (WHERE EXISTS (SELECT 1 FROM requests r
+						WHERE r.job_id=j.id_job AND r.created_at>=$1 AND r.created_at<$2))
+				FROM categories c
+				LEFT JOIN jobs j ON j.category_id=c.id_category AND ` + cityWorker(3) + `
+					AND EXISTS (SELECT 1 FROM users u WHERE u.id_user=j.id_worker AND u.status=$4)
+				WHERE ($5=0 OR c.id_category=$5)
+				GROUP BY c.id_category, c.category ORDER BY c.category;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, query.From, query.To, query.City, domain.UserActive, query.CategoryId)
+	if err != nil {
+		return activity, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category domain.WorkerActivity
+		if err = rows.Scan(&category.CategoryId, &category.Category, &category.Listed, &category.Booked); err != nil {
+			return activity, err
+		}
+		activity = append(activity, category)
+	}
+	return activity, rows.Err()
+}
+
+// RequestFunnel implements interfaces.AnalyticsRepository
+func (c *analyticsRepo) RequestFunnel(ctx context.Context, query domain.AnalyticsQuery) (domain.RequestFunnel, error) {
+	var funnel domain.RequestFunnel
+
+	sqlQuery := `SELECT COALESCE(SUM(requests), 0), COALESCE(SUM(accepted), 0), COALESCE(SUM(paid), 0), COALESCE(SUM(completed), 0),
+					COALESCE(SUM(rejected), 0), COALESCE(SUM(cancelled), 0), COALESCE(SUM(no_show), 0)
+				FROM request_daily_summary WHERE ` + summaryRange + ` AND ($4=0 OR category_id=$4);`
+	err := conn(ctx, c.db).QueryRowContext(ctx, sqlQuery, query.From, query.To, query.City, query.CategoryId).Scan(
+		&funnel.Requests,
+		&funnel.Accepted,
+		&funnel.Paid,
+		&funnel.Completed,
+		&funnel.Rejected,
+		&funnel.Cancelled,
+		&funnel.NoShow,
+	)
+	return funnel, err
+}
+
+// Cancellations implements interfaces.AnalyticsRepository
+func (c *analyticsRepo) Cancellations(ctx context.Context, query domain.AnalyticsQuery) ([]domain.CancellationStat, error) {
+	var stats []domain.CancellationStat
+
+	sqlQuery := `SELECT s.category_id, c.category, SUM(s.requests), SUM(s.cancelled), SUM(s.no_show)
+				FROM request_daily_summary s JOIN categories c ON c.id_category=s.category_id
+				WHERE ` + summaryRange + ` AND ($4=0 OR s.category_id=$4)
+				GROUP BY s.category_id, c.category ORDER BY c.category;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, query.From, query.To, query.City, query.CategoryId)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat domain.CancellationStat
+		if err = rows.Scan(&stat.CategoryId, &stat.Category, &stat.Requests, &stat.Cancelled, &stat.NoShow); err != nil {
+			return stats, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
+
+// Revenue implements interfaces.AnalyticsRepository
+func (c *analyticsRepo) Revenue(ctx context.Context, query domain.AnalyticsQuery) ([]domain.RevenueStat, error) {
+	var stats []domain.RevenueStat
+
+	sqlQuery := `SELECT TO_CHAR(day, 'YYYY-MM-DD'), currency, SUM(gmv), SUM(commission), SUM(discount)
+				FROM request_daily_summary WHERE ` + summaryRange + ` AND ($4=0 OR category_id=$4)
+				GROUP BY day, currency ORDER BY day, currency;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, query.From, query.To, query.City, query.CategoryId)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat domain.RevenueStat
+		if err = rows.Scan(&stat.Day, &stat.Currency, &stat.Gmv, &stat.Commission, &stat.Discount); err != nil {
+			return stats, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
+
+// RatingsByCategory implements interfaces.AnalyticsRepository. Ratings carry
+// no request of their own, so each one goes with the last request the rater
+// completed with the worker: it counts towards the category and city of that
+// request, and falls in the range when the request ended in it. Ratings with
+// no such request are left out.
+func (c *analyticsRepo) RatingsByCategory(ctx context.Context, query domain.AnalyticsQuery) ([]domain.CategoryRating, error) {
+	var ratings []domain.CategoryRating
+
+	sqlQuery := `SELECT c.id_category, c.category, AVG(ra.rating), COUNT(ra.id_ratings)
+				FROM ratings ra
+				JOIN LATERAL (SELECT j.category_id, r.end_at, a.city FROM requests r
+					JOIN jobs j ON j.id_job=r.job_id
+					LEFT JOIN addresses a ON a.id_address=r.address_id
+					WHERE r.user_id=ra.user_id AND j.id_worker=ra.worker_id AND r.status=$4
+					ORDER BY r.end_at DESC LIMIT 1) rated ON true
+				JOIN categories c ON c.id_category=rated.category_id
+				WHERE rated.end_at>=$1 AND rated.end_at<$2 AND ($3='' OR rated.city=$3) AND ($5=0 OR c.id_category=$5)
+				GROUP BY c.id_category, c.category ORDER BY c.category;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, query.From, query.To, query.City, domain.RequestCompleted, query.CategoryId)
+	if err != nil {
+		return ratings, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rating domain.CategoryRating
+		if err = rows.Scan(&rating.CategoryId, &rating.Category, &rating.Average, &rating.Ratings); err != nil {
+			return ratings, err
+		}
+		ratings = append(ratings, rating)
+	}
+	return ratings, rows.Err()
+}
+
+// TopWorkers implements interfaces.AnalyticsRepository. It runs on the
+// requests themselves, so the ranking is current rather than as of the last
+// refresh.
+func (c *analyticsRepo) TopWorkers(ctx context.Context, query domain.AnalyticsQuery) ([]domain.TopWorker, error) {
+	var workers []domain.TopWorker
+
+	sqlQuery := `SELECT j.id_worker, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COUNT(*), r.currency,
+					COALESCE(SUM(pay.gmv), 0), COALESCE((SELECT AVG(rating) FROM ratings WHERE worker_id=j.id_worker), 0)
+				FROM requests r
+				JOIN jobs j ON j.id_job=r.job_id
+				LEFT JOIN profiles p ON p.user_id=j.id_worker
+				LEFT JOIN addresses a ON a.id_address=r.address_id
+				LEFT JOIN (` + releasedPayments + `) pay ON pay.request_id=r.id_requset
+				WHERE r.status=$4 AND r.created_at>=$1 AND r.created_at<$2 AND ($3='' OR a.city=$3) AND ($5=0 OR j.category_id=$5)
+				GROUP BY j.id_worker, p.first_name, p.last_name, r.currency
+				ORDER BY COUNT(*) DESC, SUM(pay.gmv) DESC NULLS LAST, j.id_worker LIMIT $6;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, query.From, query.To, query.City, domain.RequestCompleted, query.CategoryId, query.Limit)
+	if err != nil {
+		return workers, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var worker domain.TopWorker
+		err = rows.Scan(
+			&worker.WorkerId,
+			&worker.FirstName,
+			&worker.LastName,
+			&worker.Completed,
+			&worker.Currency,
+			&worker.Gmv,
+			&worker.Rating,
+		)
+		if err != nil {
+			return workers, err
+		}
+		workers = append(workers, worker)
+	}
+	return workers, rows.Err()
+}
+
+// RefreshSummaries implements interfaces.AnalyticsRepository. The summaries
+// are refreshed concurrently so they can be read meanwhile.
+func (c *analyticsRepo) RefreshSummaries(ctx context.Context) error {
+	for _, view := range []string{"request_daily_summary", "signup_daily_summary"} {
+		if _, err := conn(ctx, c.db).ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+view+`;`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cityWorker holds for the jobs of workers with an address in the city given
+// in the argument at argIdx
+func cityWorker(argIdx int) string {
+	arg := "$" + strconv.Itoa(argIdx)
+	return `(` + arg + `='' OR EXISTS (SELECT 1 FROM addresses a WHERE a.user_id=j.id_worker AND a.city=` + arg + `))`
+}
+
+func NewAnalyticsRepo(db *sql.DB) interfaces.AnalyticsRepository {
+	return &analyticsRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyticsRepo_RequestFunnel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	analyticsRepo := NewAnalyticsRepo(db)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"requests", "accepted", "paid", "completed", "rejected", "cancelled", "no_show"}
+
+	tests := []struct {
+		name           string
+		query          domain.AnalyticsQuery
+		rows           *sqlmock.Rows
+		expectedFunnel domain.RequestFunnel
+		expectedErr    error
+	}{
+		{
+			name:           "test the funnel of a city and category",
+			query:          domain.AnalyticsQuery{From: &from, To: &to, City: "Kochi", CategoryId: 2},
+			rows:           sqlmock.NewRows(columns).AddRow(40, 30, 26, 20, 6, 3, 1),
+			expectedFunnel: domain.RequestFunnel{Requests: 40, Accepted: 30, Paid: 26, Completed: 20, Rejected: 6, Cancelled: 3, NoShow: 1},
+		},
+		{
+			name:           "test a range without requests",
+			query:          domain.AnalyticsQuery{From: &from, To: &to},
+			rows:           sqlmock.NewRows(columns).AddRow(0, 0, 0, 0, 0, 0, 0),
+			expectedFunnel: domain.RequestFunnel{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery("FROM request_daily_summary WHERE day>=\\$1 AND day<\\$2").
+				WithArgs(tt.query.From, tt.query.To, tt.query.City, tt.query.CategoryId).
+				WillReturnRows(tt.rows)
+			ctx := context.Background()
+
+			actualFunnel, actualErr := analyticsRepo.RequestFunnel(ctx, tt.query)
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+			assert.Equal(t, tt.expectedFunnel, actualFunnel)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestAnalyticsRepo_RatingsByCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	analyticsRepo := NewAnalyticsRepo(db)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id_category", "category", "avg", "count"}
+
+	tests := []struct {
+		name            string
+		query           domain.AnalyticsQuery
+		rows            *sqlmock.Rows
+		expectedRatings []domain.CategoryRating
+	}{
+		{
+			name:  "test the ratings of a city",
+			query: domain.AnalyticsQuery{From: &from, To: &to, City: "Kochi"},
+			rows:  sqlmock.NewRows(columns).AddRow(1, "Electrician", 4.5, 8).AddRow(2, "Plumber", 3.75, 4),
+			expectedRatings: []domain.CategoryRating{
+				{CategoryId: 1, Category: "Electrician", Average: 4.5, Ratings: 8},
+				{CategoryId: 2, Category: "Plumber", Average: 3.75, Ratings: 4},
+			},
+		},
+		{
+			name:  "test a range without rated requests",
+			query: domain.AnalyticsQuery{From: &from, To: &to, CategoryId: 2},
+			rows:  sqlmock.NewRows(columns),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery("WHERE rated.end_at>=\\$1 AND rated.end_at<\\$2").
+				WithArgs(tt.query.From, tt.query.To, tt.query.City, domain.RequestCompleted, tt.query.CategoryId).
+				WillReturnRows(tt.rows)
+			ctx := context.Background()
+
+			actualRatings, actualErr := analyticsRepo.RatingsByCategory(ctx, tt.query)
+
+			assert.NoError(t, actualErr)
+			assert.Equal(t, tt.expectedRatings, actualRatings)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestAnalyticsRepo_RefreshSummaries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	analyticsRepo := NewAnalyticsRepo(db)
+
+	tests := []struct {
+		name        string
+		beforeTest  func()
+		expectedErr error
+	}{
+		{
+			name: "test both summaries are refreshed",
+			beforeTest: func() {
+				mock.ExpectExec("REFRESH MATERIALIZED VIEW CONCURRENTLY request_daily_summary;").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("REFRESH MATERIALIZED VIEW CONCURRENTLY signup_daily_summary;").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name: "test a failed refresh stops there",
+			beforeTest: func() {
+				mock.ExpectExec("REFRESH MATERIALIZED VIEW CONCURRENTLY request_daily_summary;").WillReturnError(errors.New("canceling statement due to statement timeout"))
+			},
+			expectedErr: errors.New("canceling statement due to statement timeout"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+			ctx := context.Background()
+
+			actualErr := analyticsRepo.RefreshSummaries(ctx)
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type auditRepo struct {
+	db *sql.DB
+}
+
+const auditColumns = `id_audit, actor_id, actor_role, action, target_type, target_id, before, after, ip, user_agent, prev_hash, hash, created_at`
+
+// auditLockKey is the advisory lock appends take so that no two records
+// chain onto the same one
+const auditLockKey = 7_305_001
+
+// AppendAudit implements interfaces.AuditRepository
+func (c *auditRepo) AppendAudit(ctx context.Context, entry domain.AuditLog) (domain.AuditLog, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entry, err
+	}
+	defer tx.Rollback()
+
+	entry, err = appendAudit(ctx, tx, entry)
+	if err != nil {
+		return entry, err
+	}
+	return entry, tx.Commit()
+}
+
+// ListAuditLogs implements interfaces.AuditRepository
+func (c *auditRepo) ListAuditLogs(ctx context.Context, query domain.AuditQuery, filter utils.Filter) ([]domain.AuditLog, utils.Metadata, error) {
+	var entries []domain.AuditLog
+	var total int
+
+	condition, args := auditCondition(query)
+	keyset, keysetArgs := filter.KeysetCondition("created_at", "id_audit", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	sqlQuery := `SELECT ` + auditColumns + `, COUNT(*) OVER() FROM audit_logs
+				WHERE TRUE` + condition + keyset + ` ORDER BY created_at DESC, id_audit DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return entries, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAudit(rows, &total)
+		if err != nil {
+			return entries, utils.Metadata{}, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return entries, utils.Metadata{}, err
+	}
+
+	fetched := len(entries)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		entries = entries[:filter.PageSize]
+	}
+	if len(entries) > 0 {
+		last = utils.Cursor{Id: entries[len(entries)-1].IdAudit, CreatedAt: entries[len(entries)-1].CreatedAt}
+	}
+	return entries, pageMetadata(filter, fetched, total, last), nil
+}
+
+// ListAuditRange implements interfaces.AuditRepository. It reads the chain in
+// order, limit records after afterId.
+func (c *auditRepo) ListAuditRange(ctx context.Context, query domain.AuditQuery, afterId int, limit int) ([]domain.AuditLog, error) {
+	var entries []domain.AuditLog
+
+	condition, args := auditCondition(query)
+	args = append(args, afterId, limit)
+	sqlQuery := `SELECT ` + auditColumns + ` FROM audit_logs
+				WHERE id_audit>$` + fmt.Sprint(len(args)-1) + condition + ` ORDER BY id_audit LIMIT $` + fmt.Sprint(len(args)) + `;`
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return entries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAudit(rows)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// appendAudit chains entry onto the latest record inside tx. The time is
+// taken here at the precision the database keeps, as it goes into the hash.
+func appendAudit(ctx context.Context, tx *sql.Tx, entry domain.AuditLog) (domain.AuditLog, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1);`, auditLockKey); err != nil {
+		return entry, err
+	}
+
+	err := tx.QueryRowContext(ctx, `SELECT hash FROM audit_logs ORDER BY id_audit DESC LIMIT 1;`).Scan(&entry.PrevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return entry, err
+	}
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.Digest()
+
+	query := `INSERT INTO audit_logs (actor_id, actor_role, action, target_type, target_id, before, after, ip, user_agent, prev_hash, hash, created_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING id_audit;`
+	err = tx.QueryRowContext(ctx, query,
+		entry.ActorId,
+		entry.ActorRole,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetId,
+		entry.Before,
+		entry.After,
+		entry.Ip,
+		entry.UserAgent,
+		entry.PrevHash,
+		entry.Hash,
+		entry.CreatedAt,
+	).Scan(&entry.IdAudit)
+	return entry, err
+}
+
+// auditCondition turns a query into AND clauses numbered from $1
+func auditCondition(query domain.AuditQuery) (string, []interface{}) {
+	var condition string
+	var args []interface{}
+	add := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		condition += fmt.Sprintf(" AND "+clause, len(args))
+	}
+	if query.ActorId != 0 {
+		add("actor_id=$%d", query.ActorId)
+	}
+	if query.Action != "" {
+		add("action=$%d", query.Action)
+	}
+	if query.TargetType != "" {
+		add("target_type=$%d", query.TargetType)
+	}
+	if query.TargetId != 0 {
+		add("target_id=$%d", query.TargetId)
+	}
+	if query.From != nil {
+		add("created_at>=$%d", *query.From)
+	}
+	if query.To != nil {
+		add("created_at<$%d", *query.To)
+	}
+	return condition, args
+}
+
+func scanAudit(row rowScanner, extra ...interface{}) (domain.AuditLog, error) {
+	var entry domain.AuditLog
+	dest := []interface{}{
+		&entry.IdAudit,
+		&entry.ActorId,
+		&entry.ActorRole,
+		&entry.Action,
+		&entry.TargetType,
+		&entry.TargetId,
+		&entry.Before,
+		&entry.After,
+		&entry.Ip,
+		&entry.UserAgent,
+		&entry.PrevHash,
+		&entry.Hash,
+		&entry.CreatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return entry, err
+}
+
+func NewAuditRepo(db *sql.DB) interfaces.AuditRepository {
+	return &auditRepo{
+		db: db,
+	}
+}
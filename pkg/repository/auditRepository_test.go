@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectAudit expects an audit record of action chained onto prevHash, an
+// empty prevHash standing for an empty log
+func expectAudit(mock sqlmock.Sqlmock, prevHash string, id int, action string) {
+	mock.ExpectExec("SELECT pg_advisory_xact_lock\\(\\$1\\);").WithArgs(auditLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	last := mock.ExpectQuery("SELECT hash FROM audit_logs ORDER BY id_audit DESC LIMIT 1;")
+	if prevHash == "" {
+		last.WillReturnError(sql.ErrNoRows)
+	} else {
+		last.WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(prevHash))
+	}
+	mock.ExpectQuery("INSERT INTO audit_logs").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), action, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), prevHash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id_audit"}).AddRow(id))
+}
+
+func TestAuditRepo_AppendAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	auditRepo := NewAuditRepo(db)
+
+	tests := []struct {
+		name     string
+		prevHash string
+	}{
+		{
+			name:     "test the first record starts the chain",
+			prevHash: "",
+		},
+		{
+			name:     "test a record chains onto the latest one",
+			prevHash: "5f2b4c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			expectAudit(mock, tt.prevHash, 4, domain.AuditEmailChange)
+			mock.ExpectCommit()
+			ctx := context.Background()
+
+			entry, actualErr := auditRepo.AppendAudit(ctx, domain.AuditLog{
+				ActorId:    5,
+				ActorRole:  domain.RoleUser,
+				Action:     domain.AuditEmailChange,
+				TargetType: domain.AuditTargetUser,
+				TargetId:   5,
+				Before:     `{"email":"old@example.com"}`,
+				After:      `{"email":"new@example.com"}`,
+			})
+
+			assert.NoError(t, actualErr)
+			assert.Equal(t, 4, entry.IdAudit)
+			assert.Equal(t, tt.prevHash, entry.PrevHash)
+			assert.Equal(t, entry.Digest(), entry.Hash)
+
+			// The hash covers the record, changing any of it shows
+			tampered := entry
+			tampered.After = `{"email":"other@example.com"}`
+			assert.NotEqual(t, entry.Hash, tampered.Digest())
+			tampered = entry
+			tampered.CreatedAt = entry.CreatedAt.Add(time.Microsecond)
+			assert.NotEqual(t, entry.Hash, tampered.Digest())
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type bookingRepo struct {
+	db *sql.DB
+}
+
+const bookingColumns = `r.id_requset, r.user_id, j.id_worker, r.job_id, c.category, j.category_id, r.address_id, r.date, r.slot, r.timezone, r.start_at, r.end_at, r.status, r.amount, r.currency, r.price_locked, r.discount, r.promo_code_id, r.created_at`
+
+const bookingTables = `requests r JOIN jobs j ON j.id_job=r.job_id JOIN categories c ON c.id_category=j.category_id`
+
+// FindJob implements interfaces.BookingRepository
+func (c *bookingRepo) FindJob(ctx context.Context, jobId int) (domain.Job, error) {
+	var job domain.Job
+	query := `SELECT id_job, id_worker, category_id, full_day_wage, half_day_wage, openwork FROM jobs WHERE id_job=$1;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, jobId).Scan(
+		&job.IdJob,
+		&job.IdWorker,
+		&job.CategoryId,
+		&job.FullDayWage,
+		&job.HalfDayWage,
+		&job.Openwork,
+	)
+	if err != nil && err == sql.ErrNoRows {
+		return job, domain.ErrNoJob
+	}
+	return job, err
+}
+
+// CreateBooking implements interfaces.BookingRepository
+//
+// The worker row is locked for the life of the transaction so two bookings
+// for the same worker are checked and inserted one after the other. A promo
+// code on the request is redeemed in the same transaction.
+func (c *bookingRepo) CreateBooking(ctx context.Context, request domain.Request, workerId int) (int, error) {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, `SELECT id_user FROM users WHERE id_user=$1 FOR UPDATE;`, workerId).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return 0, domain.ErrNoWorker
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var firstHalf, secondHalf bool
+	query := `SELECT first_half, second_half FROM availabilities WHERE worker_id=$1 AND weekday=$2;`
+	err = tx.QueryRowContext(ctx, query, workerId, int(request.Date.Weekday())).Scan(&firstHalf, &secondHalf)
+	if err != nil && err == sql.ErrNoRows {
+		return 0, domain.ErrSlotUnavailable
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !slotOpen(request.Slot, firstHalf, secondHalf) {
+		return 0, domain.ErrSlotUnavailable
+	}
+
+	var blackouts int
+	query = `SELECT COUNT(*) FROM blackouts WHERE worker_id=$1 AND day=$2;`
+	if err = tx.QueryRowContext(ctx, query, workerId, request.Date).Scan(&blackouts); err != nil {
+		return 0, err
+	}
+	if blackouts > 0 {
+		return 0, domain.ErrSlotUnavailable
+	}
+
+	var conflicts int
+	query = `SELECT COUNT(*) FROM requests r JOIN jobs j ON j.id_job=r.job_id
+				WHERE j.id_worker=$1 AND r.date=$2 AND r.status IN ($3,$4)
+				AND (r.slot=$5 OR r.slot=$6 OR $5=$6);`
+	err = tx.QueryRowContext(ctx, query,
+		workerId,
+		request.Date,
+		domain.RequestPending,
+		domain.RequestAccepted,
+		request.Slot,
+		domain.SlotFullDay,
+	).Scan(&conflicts)
+	if err != nil {
+		return 0, err
+	}
+	if conflicts > 0 {
+		return 0, domain.ErrSlotBooked
+	}
+
+	query = `INSERT INTO requests (user_id, job_id, address_id, status, date, slot, timezone, start_at, end_at, amount, currency, discount, promo_code_id, created_at, updated_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NOW(),NOW()) RETURNING id_requset;`
+	err = tx.QueryRowContext(ctx, query,
+		request.UserId,
+		request.JobId,
+		request.AddressId,
+		request.Status,
+		request.Date,
+		request.Slot,
+		request.Timezone,
+		request.StartAt,
+		request.EndAt,
+		request.Amount,
+		request.Currency,
+		request.Discount,
+		request.PromoCodeId,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if request.PromoCodeId != nil {
+		if err = redeemPromo(ctx, tx.Tx, *request.PromoCodeId, request.UserId, id, request.Discount); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+// FindBooking implements interfaces.BookingRepository
+func (c *bookingRepo) FindBooking(ctx context.Context, requestId int) (domain.BookingResponse, error) {
+	query := `SELECT ` + bookingColumns + ` FROM ` + bookingTables + ` WHERE r.id_requset=$1;`
+	booking, err := scanBooking(conn(ctx, c.db).QueryRowContext(ctx, query, requestId))
+	if err != nil && err == sql.ErrNoRows {
+		return booking, domain.ErrNoRequest
+	}
+	return booking, err
+}
+
+// UpdateStatus implements interfaces.BookingRepository
+func (c *bookingRepo) UpdateStatus(ctx context.Context, requestId int, from string, to string) error {
+	var id int
+	query := `UPDATE requests SET status=$1, updated_at=NOW() WHERE id_requset=$2 AND status=$3 RETURNING id_requset;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, to, requestId, from).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrStatusChanged
+	}
+	return err
+}
+
+// ListUserBookings implements interfaces.BookingRepository
+func (c *bookingRepo) ListUserBookings(ctx context.Context, userId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error) {
+	return c.listBookings(ctx, `r.user_id=$1`, userId, filter)
+}
+
+// ListWorkerBookings implements interfaces.BookingRepository
+func (c *bookingRepo) ListWorkerBookings(ctx context.Context, workerId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error) {
+	return c.listBookings(ctx, `j.id_worker=$1`, workerId, filter)
+}
+
+func (c *bookingRepo) listBookings(ctx context.Context, condition string, id int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error) {
+	var bookings []domain.BookingResponse
+	var total int
+
+	args := []interface{}{id}
+	keyset, keysetArgs := filter.KeysetCondition("r.created_at", "r.id_requset", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + bookingColumns + `, COUNT(*) OVER() FROM ` + bookingTables +
+		` WHERE ` + condition + keyset + ` ORDER BY r.created_at DESC, r.id_requset DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return bookings, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		booking, err := scanBooking(rows, &total)
+		if err != nil {
+			return bookings, utils.Metadata{}, err
+		}
+		bookings = append(bookings, booking)
+	}
+	if err = rows.Err(); err != nil {
+		return bookings, utils.Metadata{}, err
+	}
+
+	fetched := len(bookings)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		bookings = bookings[:filter.PageSize]
+	}
+	if len(bookings) > 0 {
+		last = utils.Cursor{Id: bookings[len(bookings)-1].IdRequest, CreatedAt: bookings[len(bookings)-1].CreatedAt}
+	}
+
+	return bookings, pageMetadata(filter, fetched, total, last), nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBooking(row rowScanner, extra ...interface{}) (domain.BookingResponse, error) {
+	var booking domain.BookingResponse
+	var date sql.NullTime
+	var promoCodeId sql.NullInt64
+
+	dest := []interface{}{
+		&booking.IdRequest,
+		&booking.UserId,
+		&booking.WorkerId,
+		&booking.JobId,
+		&booking.JobCategory,
+		&booking.CategoryId,
+		&booking.AddressId,
+		&date,
+		&booking.Slot,
+		&booking.Timezone,
+		&booking.StartAt,
+		&booking.EndAt,
+		&booking.Status,
+		&booking.Amount,
+		&booking.Currency,
+		&booking.PriceLocked,
+		&booking.Discount,
+		&promoCodeId,
+		&booking.CreatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if date.Valid {
+		booking.Date = date.Time.Format("2006-01-02")
+	}
+	if promoCodeId.Valid {
+		id := int(promoCodeId.Int64)
+		booking.PromoCodeId = &id
+	}
+	return booking, err
+}
+
+// slotOpen reports whether a slot fits in the halves a worker is available for
+func slotOpen(slot string, firstHalf bool, secondHalf bool) bool {
+	switch slot {
+	case domain.SlotFullDay:
+		return firstHalf && secondHalf
+	case domain.SlotFirstHalf:
+		return firstHalf
+	case domain.SlotSecondHalf:
+		return secondHalf
+	}
+	return false
+}
+
+func NewBookingRepo(db *sql.DB) interfaces.BookingRepository {
+	return &bookingRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookingRepo_CreateBooking(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	bookingRepo := NewBookingRepo(db)
+
+	day := time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+	request := domain.Request{
+		UserId:    1,
+		JobId:     3,
+		AddressId: 4,
+		Status:    domain.RequestPending,
+		Date:      day,
+		Slot:      domain.SlotFirstHalf,
+		Timezone:  "Asia/Kolkata",
+		StartAt:   day.Add(9 * time.Hour),
+		EndAt:     day.Add(13 * time.Hour),
+		Amount:    50000,
+		Currency:  domain.DefaultCurrency,
+	}
+
+	lockQuery := "SELECT id_user FROM users WHERE id_user=\\$1 FOR UPDATE;"
+	availabilityQuery := "SELECT first_half, second_half FROM availabilities WHERE worker_id=\\$1 AND weekday=\\$2;"
+	blackoutQuery := "SELECT COUNT\\(\\*\\) FROM blackouts WHERE worker_id=\\$1 AND day=\\$2;"
+	conflictQuery := "SELECT COUNT\\(\\*\\) FROM requests r JOIN jobs j"
+	insertQuery := "INSERT INTO requests"
+
+	tests := []struct {
+		name          string
+		mockQueryFunc func()
+		expectedId    int
+		expectedErr   error
+	}{
+		{
+			name: "test success booking a free slot",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(2))
+				mock.ExpectQuery(availabilityQuery).WithArgs(2, int(time.Wednesday)).
+					WillReturnRows(sqlmock.NewRows([]string{"first_half", "second_half"}).AddRow(true, false))
+				mock.ExpectQuery(blackoutQuery).WithArgs(2, day).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(conflictQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows([]string{"id_requset"}).AddRow(9))
+				mock.ExpectCommit()
+			},
+			expectedId:  9,
+			expectedErr: nil,
+		},
+		{
+			name: "test worker does not work that half",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(2))
+				mock.ExpectQuery(availabilityQuery).WithArgs(2, int(time.Wednesday)).
+					WillReturnRows(sqlmock.NewRows([]string{"first_half", "second_half"}).AddRow(false, true))
+				mock.ExpectRollback()
+			},
+			expectedId:  0,
+			expectedErr: domain.ErrSlotUnavailable,
+		},
+		{
+			name: "test slot already booked",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(2))
+				mock.ExpectQuery(availabilityQuery).WithArgs(2, int(time.Wednesday)).
+					WillReturnRows(sqlmock.NewRows([]string{"first_half", "second_half"}).AddRow(true, true))
+				mock.ExpectQuery(blackoutQuery).WithArgs(2, day).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(conflictQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			expectedId:  0,
+			expectedErr: domain.ErrSlotBooked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			actualId, actualerr := bookingRepo.CreateBooking(ctx, request, 2)
+
+			assert.Equal(t, tt.expectedErr, actualerr)
+			assert.Equal(t, tt.expectedId, actualId)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+
+	promoCodeId := 6
+	request.Discount, request.PromoCodeId = 5000, &promoCodeId
+	redeemQuery := "UPDATE promo_codes SET used=used\\+1"
+	perUserQuery := "SELECT COUNT\\(\\*\\) FROM promo_redemptions WHERE promo_code_id=\\$1 AND user_id=\\$2;"
+
+	promoTests := []struct {
+		name          string
+		mockQueryFunc func()
+		expectedId    int
+		expectedErr   error
+	}{
+		{
+			name: "test promo code is redeemed with the booking",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(2))
+				mock.ExpectQuery(availabilityQuery).WithArgs(2, int(time.Wednesday)).
+					WillReturnRows(sqlmock.NewRows([]string{"first_half", "second_half"}).AddRow(true, true))
+				mock.ExpectQuery(blackoutQuery).WithArgs(2, day).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(conflictQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows([]string{"id_requset"}).AddRow(9))
+				mock.ExpectQuery(redeemQuery).WithArgs(6).WillReturnRows(sqlmock.NewRows([]string{"per_user_limit"}).AddRow(1))
+				mock.ExpectQuery(perUserQuery).WithArgs(6, 1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec("INSERT INTO promo_redemptions").WithArgs(6, 1, 9, int64(5000)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedId:  9,
+			expectedErr: nil,
+		},
+		{
+			name: "test promo code used up by other bookings",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(2))
+				mock.ExpectQuery(availabilityQuery).WithArgs(2, int(time.Wednesday)).
+					WillReturnRows(sqlmock.NewRows([]string{"first_half", "second_half"}).AddRow(true, true))
+				mock.ExpectQuery(blackoutQuery).WithArgs(2, day).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(conflictQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows([]string{"id_requset"}).AddRow(9))
+				mock.ExpectQuery(redeemQuery).WithArgs(6).WillReturnRows(sqlmock.NewRows([]string{"per_user_limit"}))
+				mock.ExpectRollback()
+			},
+			expectedId:  0,
+			expectedErr: domain.ErrPromoUsedUp,
+		},
+	}
+
+	for _, tt := range promoTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			actualId, actualerr := bookingRepo.CreateBooking(ctx, request, 2)
+
+			assert.Equal(t, tt.expectedErr, actualerr)
+			assert.Equal(t, tt.expectedId, actualId)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
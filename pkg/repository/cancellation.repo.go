@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+const decisionColumns = `id_decision, request_id, policy_id, free_hours, late_fee_bps, reason, actor_id, minutes_before, outcome, refund_bps, paid, refunded, kept, created_at`
+
+type cancellationRepo struct {
+	db *sql.DB
+}
+
+// FindPolicy implements interfaces.CancellationRepository
+func (c *cancellationRepo) FindPolicy(ctx context.Context, categoryId int) (domain.CancellationPolicy, error) {
+	var policy domain.CancellationPolicy
+	query := `SELECT id_policy, category_id, free_hours, late_fee_bps, updated_at FROM cancellation_policies WHERE category_id=$1;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, categoryId).Scan(
+		&policy.IdPolicy,
+		&policy.CategoryId,
+		&policy.FreeHours,
+		&policy.LateFeeBps,
+		&policy.UpdatedAt,
+	)
+	if err != nil && err == sql.ErrNoRows {
+		return policy, domain.ErrNoPolicy
+	}
+	return policy, err
+}
+
+// SetPolicy implements interfaces.CancellationRepository
+func (c *cancellationRepo) SetPolicy(ctx context.Context, policy domain.CancellationPolicy) (domain.CancellationPolicy, error) {
+	query := `INSERT INTO cancellation_policies (category_id, free_hours, late_fee_bps, updated_at) VALUES ($1,$2,$3,NOW())
+				ON CONFLICT (category_id) DO UPDATE SET free_hours=EXCLUDED.free_hours, late_fee_bps=EXCLUDED.late_fee_bps, updated_at=NOW()
+				RETURNING id_policy, updated_at;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
+		policy.CategoryId,
+		policy.FreeHours,
+		policy.LateFeeBps,
+	).Scan(
+		&policy.IdPolicy,
+		&policy.UpdatedAt,
+	)
+	return policy, err
+}
+
+// Settle implements interfaces.CancellationRepository. The status change, the
+// split of the held payment, the refund to send and the decision behind it
+// are written together or not at all.
+func (c *cancellationRepo) Settle(ctx context.Context, settlement domain.Settlement) (domain.PolicyDecision, *domain.Refund, error) {
+	decision := settlement.Decision
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return decision, nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	query := `SELECT status FROM requests WHERE id_requset=$1 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, settlement.RequestId).Scan(&status)
+	if err != nil && err == sql.ErrNoRows {
+		return decision, nil, domain.ErrNoRequest
+	}
+	if err != nil {
+		return decision, nil, err
+	}
+	allowed := false
+	for _, from := range settlement.From {
+		allowed = allowed || status == from
+	}
+	if !allowed {
+		return decision, nil, domain.ErrInvalidTransition.WithMessage("cannot move a " + status + " request to " + settlement.To)
+	}
+
+	query = `UPDATE requests SET status=$1, updated_at=NOW() WHERE id_requset=$2;`
+	if _, err = tx.ExecContext(ctx, query, settlement.To, settlement.RequestId); err != nil {
+		return decision, nil, err
+	}
+
+	// A checkout left half way must not be captured into a dead request
+	query = `UPDATE payments SET status=$1, updated_at=NOW() WHERE request_id=$2 AND status=$3;`
+	if _, err = tx.ExecContext(ctx, query, domain.PaymentFailed, settlement.RequestId, domain.PaymentCreated); err != nil {
+		return decision, nil, err
+	}
+
+	var refund *domain.Refund
+	var paymentId, workerId int
+	var currency string
+	query = `SELECT id_payment, worker_id, amount, currency FROM payments WHERE request_id=$1 AND status=$2 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, settlement.RequestId, domain.PaymentHeld).Scan(&paymentId, &workerId, &decision.Paid, &currency)
+	switch {
+	case err == sql.ErrNoRows:
+		decision.Outcome = domain.OutcomeNothingPaid
+		decision.Paid, decision.Refunded, decision.Kept = 0, 0, 0
+	case err != nil:
+		return decision, nil, err
+	default:
+		decision.Refunded = decision.Paid * int64(decision.RefundBps) / 10000
+		decision.Kept = decision.Paid - decision.Refunded
+
+		// What is not refunded goes to the worker as if the job was done
+		if decision.Kept == 0 {
+			query = `UPDATE payments SET status=$1, refunded=$2, updated_at=NOW() WHERE id_payment=$3;`
+			_, err = tx.ExecContext(ctx, query, domain.PaymentRefunded, decision.Refunded, paymentId)
+		} else {
+			commission := decision.Kept * int64(settlement.CommissionBps) / 10000
+			query = `UPDATE payments SET status=$1, refunded=$2, commission=$3, released_at=NOW(), updated_at=NOW() WHERE id_payment=$4;`
+			_, err = tx.ExecContext(ctx, query, domain.PaymentReleased, decision.Refunded, commission, paymentId)
+			if err == nil {
+				err = postRelease(ctx, tx, settlement.RequestId, workerId, decision.Kept, commission, currency)
+			}
+		}
+		if err != nil {
+			return decision, nil, err
+		}
+
+		if decision.Refunded > 0 {
+			refund = &domain.Refund{
+				PaymentId: paymentId,
+				RequestId: settlement.RequestId,
+				Amount:    decision.Refunded,
+				Currency:  currency,
+				Status:    domain.RefundPending,
+			}
+			if err = insertRefund(ctx, tx, refund); err != nil {
+				return decision, nil, err
+			}
+
+			_, err = postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerRefund, RequestId: &settlement.RequestId},
+				transfer(domain.LedgerEntry{Account: domain.AccountEscrow}, domain.LedgerEntry{Account: domain.AccountRefunds}, refund.Amount, currency)...)
+			if err != nil {
+				return decision, nil, err
+			}
+		}
+	}
+
+	query = `INSERT INTO policy_decisions (request_id, policy_id, free_hours, late_fee_bps, reason, actor_id, minutes_before, outcome, refund_bps, paid, refunded, kept, created_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,NOW()) RETURNING id_decision, created_at;`
+	err = tx.QueryRowContext(ctx, query,
+		settlement.RequestId,
+		decision.PolicyId,
+		decision.FreeHours,
+		decision.LateFeeBps,
+		decision.Reason,
+		decision.ActorId,
+		decision.MinutesBefore,
+		decision.Outcome,
+		decision.RefundBps,
+		decision.Paid,
+		decision.Refunded,
+		decision.Kept,
+	).Scan(
+		&decision.IdDecision,
+		&decision.CreatedAt,
+	)
+	if err != nil {
+		return decision, nil, err
+	}
+	decision.RequestId = settlement.RequestId
+
+	return decision, refund, tx.Commit()
+}
+
+// ListDecisions implements interfaces.CancellationRepository
+func (c *cancellationRepo) ListDecisions(ctx context.Context, requestId int) ([]domain.PolicyDecision, error) {
+	var decisions []domain.PolicyDecision
+
+	query := `SELECT ` + decisionColumns + ` FROM policy_decisions WHERE request_id=$1 ORDER BY id_decision;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, requestId)
+	if err != nil {
+		return decisions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var decision domain.PolicyDecision
+		var policyId sql.NullInt64
+		err = rows.Scan(
+			&decision.IdDecision,
+			&decision.RequestId,
+			&policyId,
+			&decision.FreeHours,
+			&decision.LateFeeBps,
+			&decision.Reason,
+			&decision.ActorId,
+			&decision.MinutesBefore,
+			&decision.Outcome,
+			&decision.RefundBps,
+			&decision.Paid,
+			&decision.Refunded,
+			&decision.Kept,
+			&decision.CreatedAt,
+		)
+		if err != nil {
+			return decisions, err
+		}
+		if policyId.Valid {
+			id := int(policyId.Int64)
+			decision.PolicyId = &id
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, rows.Err()
+}
+
+func NewCancellationRepo(db *sql.DB) interfaces.CancellationRepository {
+	return &cancellationRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancellationRepo_Settle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	cancellationRepo := NewCancellationRepo(db)
+
+	lockQuery := "SELECT status FROM requests WHERE id_requset=\\$1 FOR UPDATE;"
+	statusQuery := "UPDATE requests SET status=\\$1"
+	abandonQuery := "UPDATE payments SET status=\\$1, updated_at=NOW\\(\\) WHERE request_id=\\$2 AND status=\\$3;"
+	heldQuery := "SELECT id_payment, worker_id, amount, currency FROM payments"
+	decisionQuery := "INSERT INTO policy_decisions"
+
+	settlement := domain.Settlement{
+		RequestId:     1,
+		From:          []string{domain.RequestPending, domain.RequestAccepted},
+		To:            domain.RequestCancelled,
+		CommissionBps: 1000,
+		Decision: domain.PolicyDecision{
+			Reason:    domain.CancelByUser,
+			ActorId:   2,
+			RefundBps: 5000,
+			Outcome:   domain.OutcomePartialRefund,
+		},
+	}
+
+	tests := []struct {
+		name             string
+		mockQueryFunc    func()
+		expectedOutcome  string
+		expectedRefunded int64
+		expectedRefund   bool
+		expectedErr      bool
+	}{
+		{
+			name: "test nothing paid records the decision alone",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.RequestPending))
+				mock.ExpectExec(statusQuery).WithArgs(domain.RequestCancelled, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(abandonQuery).WithArgs(domain.PaymentFailed, 1, domain.PaymentCreated).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(heldQuery).WithArgs(1, domain.PaymentHeld).WillReturnRows(sqlmock.NewRows([]string{"id_payment", "worker_id", "amount", "currency"}))
+				mock.ExpectQuery(decisionQuery).WillReturnRows(sqlmock.NewRows([]string{"id_decision", "created_at"}).AddRow(1, time.Now()))
+				mock.ExpectCommit()
+			},
+			expectedOutcome:  domain.OutcomeNothingPaid,
+			expectedRefunded: 0,
+			expectedRefund:   false,
+		},
+		{
+			name: "test late cancel splits the held payment",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.RequestAccepted))
+				mock.ExpectExec(statusQuery).WithArgs(domain.RequestCancelled, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(abandonQuery).WithArgs(domain.PaymentFailed, 1, domain.PaymentCreated).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(heldQuery).WithArgs(1, domain.PaymentHeld).
+					WillReturnRows(sqlmock.NewRows([]string{"id_payment", "worker_id", "amount", "currency"}).AddRow(3, 5, int64(10000), "INR"))
+				mock.ExpectExec("UPDATE payments SET status=\\$1, refunded=\\$2, commission").
+					WithArgs(domain.PaymentReleased, int64(5000), int64(500), 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectLedger(mock, domain.LedgerEarning, domain.AccountEscrow, 0, domain.AccountWorker, 5, 5000)
+				expectLedger(mock, domain.LedgerCommission, domain.AccountWorker, 5, domain.AccountCommission, 0, 500)
+				mock.ExpectQuery("INSERT INTO refunds").WillReturnRows(sqlmock.NewRows([]string{"id_refund", "created_at"}).AddRow(4, time.Now()))
+				expectLedger(mock, domain.LedgerRefund, domain.AccountEscrow, 0, domain.AccountRefunds, 0, 5000)
+				mock.ExpectQuery(decisionQuery).WillReturnRows(sqlmock.NewRows([]string{"id_decision", "created_at"}).AddRow(2, time.Now()))
+				mock.ExpectCommit()
+			},
+			expectedOutcome:  domain.OutcomePartialRefund,
+			expectedRefunded: 5000,
+			expectedRefund:   true,
+		},
+		{
+			name: "test completed request cannot be cancelled",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.RequestCompleted))
+				mock.ExpectRollback()
+			},
+			expectedOutcome: domain.OutcomePartialRefund,
+			expectedErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			decision, refund, actualErr := cancellationRepo.Settle(ctx, settlement)
+
+			assert.Equal(t, tt.expectedErr, actualErr != nil)
+			assert.Equal(t, tt.expectedOutcome, decision.Outcome)
+			assert.Equal(t, tt.expectedRefunded, decision.Refunded)
+			assert.Equal(t, tt.expectedRefund, refund != nil)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// expectLedger expects a ledger transaction moving amount between two accounts
+func expectLedger(mock sqlmock.Sqlmock, kind string, from string, fromOwner int, to string, toOwner int, amount int64) {
+	mock.ExpectQuery("INSERT INTO ledger_transactions").WithArgs(kind, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id_transaction", "created_at"}).AddRow(1, time.Now()))
+	mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, from, fromOwner, -amount, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, to, toOwner, amount, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+}
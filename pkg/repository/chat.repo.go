@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type chatRepo struct {
+	db *sql.DB
+}
+
+// CreateMessage implements interfaces.ChatRepository
+func (c *chatRepo) CreateMessage(ctx context.Context, message domain.Message) (domain.Message, error) {
+	query := `INSERT INTO messages (request_id, sender_id, sender_role, body, created_at)
+				VALUES ($1,$2,$3,$4,NOW()) RETURNING id_message, created_at;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
+		message.RequestId,
+		message.SenderId,
+		message.SenderRole,
+		message.Body,
+	).Scan(
+		&message.IdMessage,
+		&message.CreatedAt,
+	)
+	return message, err
+}
+
+// ListMessages implements interfaces.ChatRepository
+func (c *chatRepo) ListMessages(ctx context.Context, requestId int, filter utils.Filter) ([]domain.Message, utils.Metadata, error) {
+	var messages []domain.Message
+	var total int
+
+	args := []interface{}{requestId}
+	keyset, keysetArgs := filter.KeysetCondition("created_at", "id_message", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT id_message, request_id, sender_id, sender_role, body, read_at, created_at, COUNT(*) OVER()
+				FROM messages WHERE request_id=$1` + keyset + ` ORDER BY created_at DESC, id_message DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return messages, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message domain.Message
+		var readAt sql.NullTime
+		err = rows.Scan(
+			&message.IdMessage,
+			&message.RequestId,
+			&message.SenderId,
+			&message.SenderRole,
+			&message.Body,
+			&readAt,
+			&message.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return messages, utils.Metadata{}, err
+		}
+		if readAt.Valid {
+			message.ReadAt = &readAt.Time
+		}
+		messages = append(messages, message)
+	}
+	if err = rows.Err(); err != nil {
+		return messages, utils.Metadata{}, err
+	}
+
+	fetched := len(messages)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		messages = messages[:filter.PageSize]
+	}
+	if len(messages) > 0 {
+		last = utils.Cursor{Id: messages[len(messages)-1].IdMessage, CreatedAt: messages[len(messages)-1].CreatedAt}
+	}
+
+	return messages, pageMetadata(filter, fetched, total, last), nil
+}
+
+// MarkRead implements interfaces.ChatRepository
+func (c *chatRepo) MarkRead(ctx context.Context, requestId int, readerId int, readTo int) (int64, error) {
+	query := `UPDATE messages SET read_at=NOW() WHERE request_id=$1 AND sender_id<>$2 AND id_message<=$3 AND read_at IS NULL;`
+	result, err := conn(ctx, c.db).ExecContext(ctx, query, requestId, readerId, readTo)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func NewChatRepo(db *sql.DB) interfaces.ChatRepository {
+	return &chatRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+const disputeColumns = `id_dispute, request_id, opened_by, respondent, reason, description, status, assigned_to, outcome, refund_id, resolution, resolved_by, resolved_at, created_at, updated_at`
+
+const evidenceColumns = `id_evidence, dispute_id, uploaded_by, file_name, content_type, size, storage_key, created_at`
+
+const disputeMessageColumns = `id_message, dispute_id, author_id, body, internal, created_at`
+
+type disputeRepo struct {
+	db *sql.DB
+}
+
+// OpenDispute implements interfaces.DisputeRepository
+func (c *disputeRepo) OpenDispute(ctx context.Context, dispute domain.Dispute) (domain.Dispute, error) {
+	query := `INSERT INTO disputes (request_id, opened_by, respondent, reason, description, status, outcome, resolution, created_at, updated_at)
+				SELECT $1,$2,$3,$4,$5,$6,'','',NOW(),NOW()
+				WHERE NOT EXISTS (SELECT 1 FROM disputes WHERE request_id=$1 AND status<>$7)
+				RETURNING ` + disputeColumns + `;`
+	opened, err := scanDispute(conn(ctx, c.db).QueryRowContext(ctx, query,
+		dispute.RequestId,
+		dispute.OpenedBy,
+		dispute.Respondent,
+		dispute.Reason,
+		dispute.Description,
+		domain.DisputeOpen,
+		domain.DisputeResolved,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return opened, domain.ErrDisputeOpen
+	}
+	return opened, err
+}
+
+// FindDispute implements interfaces.DisputeRepository
+func (c *disputeRepo) FindDispute(ctx context.Context, disputeId int) (domain.Dispute, error) {
+	query := `SELECT ` + disputeColumns + ` FROM disputes WHERE id_dispute=$1;`
+	dispute, err := scanDispute(conn(ctx, c.db).QueryRowContext(ctx, query, disputeId))
+	if err != nil && err == sql.ErrNoRows {
+		return dispute, domain.ErrNoDispute
+	}
+	return dispute, err
+}
+
+// ListPartyDisputes implements interfaces.DisputeRepository
+func (c *disputeRepo) ListPartyDisputes(ctx context.Context, userId int, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error) {
+	return c.listDisputes(ctx, `(opened_by=$1 OR respondent=$1)`, userId, filter)
+}
+
+// ListDisputesByStatus implements interfaces.DisputeRepository
+func (c *disputeRepo) ListDisputesByStatus(ctx context.Context, status string, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error) {
+	return c.listDisputes(ctx, `status=$1`, status, filter)
+}
+
+func (c *disputeRepo) listDisputes(ctx context.Context, condition string, arg interface{}, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error) {
+	var disputes []domain.Dispute
+	var total int
+
+	args := []interface{}{arg}
+	keyset, keysetArgs := filter.KeysetCondition("created_at", "id_dispute", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + disputeColumns + `, COUNT(*) OVER() FROM disputes
+				WHERE ` + condition + keyset + ` ORDER BY created_at DESC, id_dispute DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return disputes, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dispute, err := scanDispute(rows, &total)
+		if err != nil {
+			return disputes, utils.Metadata{}, err
+		}
+		disputes = append(disputes, dispute)
+	}
+	if err = rows.Err(); err != nil {
+		return disputes, utils.Metadata{}, err
+	}
+
+	fetched := len(disputes)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		disputes = disputes[:filter.PageSize]
+	}
+	if len(disputes) > 0 {
+		last = utils.Cursor{Id: disputes[len(disputes)-1].IdDispute, CreatedAt: disputes[len(disputes)-1].CreatedAt}
+	}
+	return disputes, pageMetadata(filter, fetched, total, last), nil
+}
+
+// AssignDispute implements interfaces.DisputeRepository. An unresolved case
+// can be handed to another admin at any time.
+func (c *disputeRepo) AssignDispute(ctx context.Context, disputeId int, adminId int) (domain.Dispute, error) {
+	query := `UPDATE disputes SET assigned_to=$1, status=$2, updated_at=NOW()
+				WHERE id_dispute=$3 AND status<>$4 RETURNING ` + disputeColumns + `;`
+	dispute, err := scanDispute(conn(ctx, c.db).QueryRowContext(ctx, query, adminId, domain.DisputeInReview, disputeId, domain.DisputeResolved))
+	if err != nil && err == sql.ErrNoRows {
+		return dispute, domain.ErrNoOpenDispute
+	}
+	return dispute, err
+}
+
+// AddEvidence implements interfaces.DisputeRepository
+func (c *disputeRepo) AddEvidence(ctx context.Context, evidence domain.DisputeEvidence) (domain.DisputeEvidence, error) {
+	query := `INSERT INTO dispute_evidences (dispute_id, uploaded_by, file_name, content_type, size, storage_key, created_at)
+				VALUES ($1,$2,$3,$4,$5,$6,NOW()) RETURNING ` + evidenceColumns + `;`
+	return scanEvidence(conn(ctx, c.db).QueryRowContext(ctx, query,
+		evidence.DisputeId,
+		evidence.UploadedBy,
+		evidence.FileName,
+		evidence.ContentType,
+		evidence.Size,
+		evidence.StorageKey,
+	))
+}
+
+// ListEvidence implements interfaces.DisputeRepository
+func (c *disputeRepo) ListEvidence(ctx context.Context, disputeId int) ([]domain.DisputeEvidence, error) {
+	var evidence []domain.DisputeEvidence
+
+	query := `SELECT ` + evidenceColumns + ` FROM dispute_evidences WHERE dispute_id=$1 ORDER BY id_evidence;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, disputeId)
+	if err != nil {
+		return evidence, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		file, err := scanEvidence(rows)
+		if err != nil {
+			return evidence, err
+		}
+		evidence = append(evidence, file)
+	}
+	return evidence, rows.Err()
+}
+
+// FindEvidence implements interfaces.DisputeRepository
+func (c *disputeRepo) FindEvidence(ctx context.Context, disputeId int, evidenceId int) (domain.DisputeEvidence, error) {
+	query := `SELECT ` + evidenceColumns + ` FROM dispute_evidences WHERE id_evidence=$1 AND dispute_id=$2;`
+	evidence, err := scanEvidence(conn(ctx, c.db).QueryRowContext(ctx, query, evidenceId, disputeId))
+	if err != nil && err == sql.ErrNoRows {
+		return evidence, domain.ErrNoEvidence
+	}
+	return evidence, err
+}
+
+// AddMessage implements interfaces.DisputeRepository
+func (c *disputeRepo) AddMessage(ctx context.Context, message domain.DisputeMessage) (domain.DisputeMessage, error) {
+	query := `INSERT INTO dispute_messages (dispute_id, author_id, body, internal, created_at)
+				VALUES ($1,$2,$3,$4,NOW()) RETURNING ` + disputeMessageColumns + `;`
+	return scanDisputeMessage(conn(ctx, c.db).QueryRowContext(ctx, query,
+		message.DisputeId,
+		message.AuthorId,
+		message.Body,
+		message.Internal,
+	))
+}
+
+// ListMessages implements interfaces.DisputeRepository. Internal notes are
+// only listed when withInternal is set.
+func (c *disputeRepo) ListMessages(ctx context.Context, disputeId int, withInternal bool) ([]domain.DisputeMessage, error) {
+	var messages []domain.DisputeMessage
+
+	query := `SELECT ` + disputeMessageColumns + ` FROM dispute_messages
+				WHERE dispute_id=$1 AND (NOT internal OR $2) ORDER BY id_message;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, disputeId, withInternal)
+	if err != nil {
+		return messages, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		message, err := scanDisputeMessage(rows)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// ResolveDispute implements interfaces.DisputeRepository. A warning or a
+// suspension is put on the sanctioned account in the same transaction.
+func (c *disputeRepo) ResolveDispute(ctx context.Context, resolution domain.DisputeResolution) (domain.Dispute, error) {
+	var dispute domain.Dispute
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dispute, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE disputes SET status=$1, outcome=$2, refund_id=$3, resolution=$4, resolved_by=$5, resolved_at=NOW(), updated_at=NOW()
+				WHERE id_dispute=$6 AND status<>$1 RETURNING ` + disputeColumns + `;`
+	dispute, err = scanDispute(tx.QueryRowContext(ctx, query,
+		domain.DisputeResolved,
+		resolution.Outcome,
+		resolution.RefundId,
+		resolution.Resolution,
+		resolution.ResolvedBy,
+		resolution.DisputeId,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return dispute, domain.ErrNoOpenDispute
+	}
+	if err != nil {
+		return dispute, err
+	}
+
+	var kind string
+	switch resolution.Outcome {
+	case domain.OutcomeWarning:
+		kind = domain.ActionWarning
+	case domain.OutcomeSuspension:
+		kind = domain.ActionSuspension
+	default:
+		return dispute, tx.Commit()
+	}
+
+	action := domain.AccountAction{
+		UserId:    resolution.SanctionedId,
+		Kind:      kind,
+		DisputeId: &dispute.IdDispute,
+		Reason:    resolution.Resolution,
+		ActorId:   resolution.ResolvedBy,
+	}
+	if _, err = insertAccountAction(ctx, tx, action); err != nil {
+		return dispute, err
+	}
+	return dispute, tx.Commit()
+}
+
+func scanDispute(row rowScanner, extra ...interface{}) (domain.Dispute, error) {
+	var dispute domain.Dispute
+	var assignedTo, refundId, resolvedBy sql.NullInt64
+	var resolvedAt sql.NullTime
+	dest := []interface{}{
+		&dispute.IdDispute,
+		&dispute.RequestId,
+		&dispute.OpenedBy,
+		&dispute.Respondent,
+		&dispute.Reason,
+		&dispute.Description,
+		&dispute.Status,
+		&assignedTo,
+		&dispute.Outcome,
+		&refundId,
+		&dispute.Resolution,
+		&resolvedBy,
+		&resolvedAt,
+		&dispute.CreatedAt,
+		&dispute.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if assignedTo.Valid {
+		id := int(assignedTo.Int64)
+		dispute.AssignedTo = &id
+	}
+	if refundId.Valid {
+		id := int(refundId.Int64)
+		dispute.RefundId = &id
+	}
+	if resolvedBy.Valid {
+		id := int(resolvedBy.Int64)
+		dispute.ResolvedBy = &id
+	}
+	if resolvedAt.Valid {
+		dispute.ResolvedAt = &resolvedAt.Time
+	}
+	return dispute, err
+}
+
+func scanEvidence(row rowScanner) (domain.DisputeEvidence, error) {
+	var evidence domain.DisputeEvidence
+	err := row.Scan(
+		&evidence.IdEvidence,
+		&evidence.DisputeId,
+		&evidence.UploadedBy,
+		&evidence.FileName,
+		&evidence.ContentType,
+		&evidence.Size,
+		&evidence.StorageKey,
+		&evidence.CreatedAt,
+	)
+	return evidence, err
+}
+
+func scanDisputeMessage(row rowScanner) (domain.DisputeMessage, error) {
+	var message domain.DisputeMessage
+	err := row.Scan(
+		&message.IdMessage,
+		&message.DisputeId,
+		&message.AuthorId,
+		&message.Body,
+		&message.Internal,
+		&message.CreatedAt,
+	)
+	return message, err
+}
+
+func NewDisputeRepo(db *sql.DB) interfaces.DisputeRepository {
+	return &disputeRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisputeRepo_ResolveDispute(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	disputeRepo := NewDisputeRepo(db)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	updateQuery := "UPDATE disputes SET status=\\$1, outcome=\\$2"
+	actionQuery := "INSERT INTO account_actions"
+	suspendQuery := "UPDATE users SET status=\\$1, suspended_until=\\$2 WHERE id_user=\\$3;"
+
+	disputeRow := func(outcome string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id_dispute", "request_id", "opened_by", "respondent", "reason", "description", "status", "assigned_to", "outcome", "refund_id", "resolution", "resolved_by", "resolved_at", "created_at", "updated_at"}).
+			AddRow(3, 11, 4, 5, domain.DisputeNoShow, "never came", domain.DisputeResolved, 1, outcome, nil, "settled", 1, now, now, now)
+	}
+
+	tests := []struct {
+		name          string
+		outcome       string
+		mockQueryFunc func()
+		expectedErr   error
+	}{
+		{
+			name:    "test a dismissed dispute puts no action on anyone",
+			outcome: domain.OutcomeDismissed,
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateQuery).WithArgs(domain.DisputeResolved, domain.OutcomeDismissed, nil, "settled", 1, 3).
+					WillReturnRows(disputeRow(domain.OutcomeDismissed))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "test a warning is recorded against the account",
+			outcome: domain.OutcomeWarning,
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateQuery).WithArgs(domain.DisputeResolved, domain.OutcomeWarning, nil, "settled", 1, 3).
+					WillReturnRows(disputeRow(domain.OutcomeWarning))
+				mock.ExpectQuery(actionQuery).WithArgs(5, domain.ActionWarning, 3, "settled", nil, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id_action", "created_at"}).AddRow(1, now))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "test a suspension also suspends the account",
+			outcome: domain.OutcomeSuspension,
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateQuery).WithArgs(domain.DisputeResolved, domain.OutcomeSuspension, nil, "settled", 1, 3).
+					WillReturnRows(disputeRow(domain.OutcomeSuspension))
+				mock.ExpectQuery(actionQuery).WithArgs(5, domain.ActionSuspension, 3, "settled", nil, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id_action", "created_at"}).AddRow(1, now))
+				mock.ExpectExec(suspendQuery).WithArgs(domain.UserSuspended, nil, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			dispute, actualErr := disputeRepo.ResolveDispute(ctx, domain.DisputeResolution{
+				DisputeId:    3,
+				Outcome:      tt.outcome,
+				Resolution:   "settled",
+				ResolvedBy:   1,
+				SanctionedId: 5,
+			})
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+			assert.Equal(t, tt.outcome, dispute.Outcome)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/lib/pq"
+)
+
+// Postgres error codes the repositories translate or retry on
+const (
+	pqUniqueViolation      = "23505"
+	pqForeignKeyViolation  = "23503"
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// querier runs queries on the pool or a transaction, translating the errors
+// the database answers with
+type querier struct {
+	q sqlQuerier
+}
+
+func (c querier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := c.q.ExecContext(ctx, query, args...)
+	return result, translate(err)
+}
+
+func (c querier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := c.q.QueryContext(ctx, query, args...)
+	return rows, translate(err)
+}
+
+func (c querier) QueryRowContext(ctx context.Context, query string, args ...interface{}) rowScanner {
+	return translatedRow{c.q.QueryRowContext(ctx, query, args...)}
+}
+
+type translatedRow struct {
+	row *sql.Row
+}
+
+func (r translatedRow) Scan(dest ...interface{}) error {
+	return translate(r.row.Scan(dest...))
+}
+
+// translate turns the constraint violations Postgres reports into domain
+// errors wrapping them, other errors are left as they are
+func translate(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		return domain.ErrAlreadyExists.Wrap(err)
+	case pqForeignKeyViolation:
+		// Deleting a row others refer to reports "is still referenced",
+		// referring to a row that is missing "is not present"
+		if strings.Contains(pqErr.Detail, "still referenced") {
+			return domain.ErrStillReferenced.Wrap(err)
+		}
+		return domain.ErrReferenceNotFound.Wrap(err)
+	}
+	return err
+}
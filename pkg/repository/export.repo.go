@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+// exportRepo reads exports in batches after the last id read, the way the
+// audit export does, so a large table streams out without being held in memory
+type exportRepo struct {
+	db *sql.DB
+}
+
+// ExportAccounts implements interfaces.ExportRepository. It takes the same
+// search and status as SearchAccounts.
+func (c *exportRepo) ExportAccounts(ctx context.Context, userType string, query domain.ExportQuery, afterId int, limit int) ([]domain.AccountSummary, error) {
+	var accounts []domain.AccountSummary
+
+	sqlQuery := `SELECT ` + accountColumns + ` FROM ` + accountTables + `
+					WHERE ` + accountType + `=$1
+					AND ($2='%%' OR u.phone ILIKE $2 OR u.email ILIKE $2 OR p.first_name ILIKE $2 OR p.last_name ILIKE $2
+						OR CONCAT(p.first_name, ' ', p.last_name) ILIKE $2)
+					AND ($3='' OR ` + accountStatus + `=$3)
+					AND ($4::timestamptz IS NULL OR u.created_at>=$4) AND ($5::timestamptz IS NULL OR u.created_at<$5)
+					AND u.id_user>$6 ORDER BY u.id_user LIMIT $7;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, userType, likePattern(query.Search), query.Status, query.From, query.To, afterId, limit)
+	if err != nil {
+		return accounts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return accounts, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+// ExportJobs implements interfaces.ExportRepository. The search matches the
+// worker or the category, and jobs carry no date to narrow them down by.
+func (c *exportRepo) ExportJobs(ctx context.Context, query domain.ExportQuery, afterId int, limit int) ([]domain.JobListing, error) {
+	var jobs []domain.JobListing
+
+	sqlQuery := `SELECT ` + jobListingColumns + ` FROM jobs j
+					JOIN categories c ON c.id_category=j.category_id
+					JOIN users u ON u.id_user=j.id_worker
+					LEFT JOIN profiles pr ON pr.user_id=j.id_worker
+					LEFT JOIN subscriptions s ON s.worker_id=j.id_worker AND s.status IN ($1,$2)
+					LEFT JOIN subscription_plans p ON p.id_plan=s.plan_id
+					WHERE ($3='%%' OR u.phone ILIKE $3 OR u.email ILIKE $3 OR c.category ILIKE $3
+						OR CONCAT(pr.first_name, ' ', pr.last_name) ILIKE $3)
+					AND ($4='' OR j.openwork=($4='` + domain.JobOpen + `'))
+					AND ($5=0 OR j.category_id=$5)
+					AND j.id_job>$6 ORDER BY j.id_job LIMIT $7;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, domain.SubscriptionActive, domain.SubscriptionGrace, likePattern(query.Search), query.Status, query.CategoryId, afterId, limit)
+	if err != nil {
+		return jobs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJobListing(rows)
+		if err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// ExportRequests implements interfaces.ExportRepository. The search matches
+// the user, the worker or the category.
+func (c *exportRepo) ExportRequests(ctx context.Context, query domain.ExportQuery, afterId int, limit int) ([]domain.BookingResponse, error) {
+	var bookings []domain.BookingResponse
+
+	sqlQuery := `SELECT ` + bookingColumns + ` FROM ` + bookingTables + `
+					JOIN users cu ON cu.id_user=r.user_id
+					JOIN users wu ON wu.id_user=j.id_worker
+					WHERE ($1='%%' OR cu.phone ILIKE $1 OR cu.email ILIKE $1 OR wu.phone ILIKE $1 OR wu.email ILIKE $1 OR c.category ILIKE $1)
+					AND ($2='' OR r.status=$2)
+					AND ($3=0 OR j.category_id=$3)
+					AND ($4::timestamptz IS NULL OR r.created_at>=$4) AND ($5::timestamptz IS NULL OR r.created_at<$5)
+					AND r.id_requset>$6 ORDER BY r.id_requset LIMIT $7;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, likePattern(query.Search), query.Status, query.CategoryId, query.From, query.To, afterId, limit)
+	if err != nil {
+		return bookings, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return bookings, err
+		}
+		bookings = append(bookings, booking)
+	}
+	return bookings, rows.Err()
+}
+
+// ExportPayments implements interfaces.ExportRepository. The search matches
+// the gateway's order or payment id.
+func (c *exportRepo) ExportPayments(ctx context.Context, query domain.ExportQuery, afterId int, limit int) ([]domain.Payment, error) {
+	var payments []domain.Payment
+
+	sqlQuery := `SELECT ` + paymentColumns + ` FROM payments
+					WHERE ($1='%%' OR gateway_order_id ILIKE $1 OR gateway_payment_id ILIKE $1)
+					AND ($2='' OR status=$2)
+					AND ($3=0 OR EXISTS (SELECT 1 FROM requests r JOIN jobs j ON j.id_job=r.job_id
+						WHERE r.id_requset=payments.request_id AND j.category_id=$3))
+					AND ($4::timestamptz IS NULL OR created_at>=$4) AND ($5::timestamptz IS NULL OR created_at<$5)
+					AND id_payment>$6 ORDER BY id_payment LIMIT $7;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, sqlQuery, likePattern(query.Search), query.Status, query.CategoryId, query.From, query.To, afterId, limit)
+	if err != nil {
+		return payments, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return payments, err
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
+
+func NewExportRepo(db *sql.DB) interfaces.ExportRepository {
+	return &exportRepo{
+		db: db,
+	}
+}
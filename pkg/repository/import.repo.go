@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/lib/pq"
+)
+
+type importRepo struct {
+	db *sql.DB
+}
+
+// ListCategories implements interfaces.ImportRepository
+func (c *importRepo) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	var categories []domain.Category
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, `SELECT id_category, category, category_icon FROM categories ORDER BY id_category;`)
+	if err != nil {
+		return categories, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category domain.Category
+		if err = rows.Scan(&category.IdCategory, &category.Category, &category.CategoryIcon); err != nil {
+			return categories, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// TakenContacts implements interfaces.ImportRepository. It returns the
+// phone numbers and emails, emails in lower case, that accounts already use.
+func (c *importRepo) TakenContacts(ctx context.Context, phones []string, emails []string) (map[string]bool, error) {
+	taken := make(map[string]bool)
+
+	query := `SELECT phone, LOWER(email) FROM users WHERE phone=ANY($1) OR LOWER(email)=ANY($2);`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, pq.Array(phones), pq.Array(emails))
+	if err != nil {
+		return taken, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var phone, email string
+		if err = rows.Scan(&phone, &email); err != nil {
+			return taken, err
+		}
+		taken[phone] = true
+		taken[email] = true
+	}
+	return taken, rows.Err()
+}
+
+// ImportCategories implements interfaces.ImportRepository. The categories
+// are added together or not at all.
+func (c *importRepo) ImportCategories(ctx context.Context, categories []domain.Category) (int, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, category := range categories {
+		_, err = tx.ExecContext(ctx, `INSERT INTO categories (category, category_icon) VALUES ($1,$2);`, category.Category, category.CategoryIcon)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(categories), tx.Commit()
+}
+
+// ImportWorkers implements interfaces.ImportRepository. Each worker comes in
+// verified and active with a profile, and a job when a category is given. The
+// workers are added together or not at all.
+func (c *importRepo) ImportWorkers(ctx context.Context, workers []domain.WorkerImport) (int, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, worker := range workers {
+		var id int
+		query := `INSERT INTO users (phone, phone_country, email, password, user_type, verification, status, signup_channel, created_at)
+					VALUES ($1,$2,$3,'',$4,true,$5,$6,NOW()) RETURNING id_user;`
+		err = tx.QueryRowContext(ctx, query,
+			worker.Phone,
+			worker.PhoneCountry,
+			strings.ToLower(worker.Email),
+			domain.RoleWorker,
+			domain.UserActive,
+			domain.SignupImport,
+		).Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+
+		query = `INSERT INTO profiles (user_id, first_name, last_name, gender, dob, profile_photo) VALUES ($1,$2,$3,'','','');`
+		if _, err = tx.ExecContext(ctx, query, id, worker.FirstName, worker.LastName); err != nil {
+			return 0, err
+		}
+
+		if worker.CategoryId == 0 {
+			continue
+		}
+		query = `INSERT INTO jobs (id_worker, category_id, expirience, description, full_day_wage, half_day_wage, openwork, priority)
+					VALUES ($1,$2,$3,$4,$5,$6,true,false);`
+		_, err = tx.ExecContext(ctx, query, id, worker.CategoryId, worker.Expirience, worker.Description, worker.FullDayWage, worker.HalfDayWage)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(workers), tx.Commit()
+}
+
+func NewImportRepo(db *sql.DB) interfaces.ImportRepository {
+	return &importRepo{
+		db: db,
+	}
+}
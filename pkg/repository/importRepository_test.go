@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportRepo_ImportWorkers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	importRepo := NewImportRepo(db)
+
+	withJob := domain.WorkerImport{Row: 2, Phone: "+919876543210", PhoneCountry: "IN", Email: "Anu@Example.com", FirstName: "Anu", LastName: "K",
+		CategoryId: 3, Expirience: "4 years", Description: "Wiring and repairs", FullDayWage: 900, HalfDayWage: 500}
+	withoutJob := domain.WorkerImport{Row: 3, Phone: "+919876543211", PhoneCountry: "IN", Email: "ravi@example.com", FirstName: "Ravi"}
+
+	expectWorker := func(worker domain.WorkerImport, id int) {
+		mock.ExpectQuery("INSERT INTO users").
+			WithArgs(worker.Phone, worker.PhoneCountry, strings.ToLower(worker.Email), domain.RoleWorker, domain.UserActive, domain.SignupImport).
+			WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(id))
+		mock.ExpectExec("INSERT INTO profiles").WithArgs(id, worker.FirstName, worker.LastName).WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	tests := []struct {
+		name             string
+		workers          []domain.WorkerImport
+		beforeTest       func()
+		expectedImported int
+		expectedErr      error
+	}{
+		{
+			name:    "test a worker with a category is given a job",
+			workers: []domain.WorkerImport{withJob},
+			beforeTest: func() {
+				mock.ExpectBegin()
+				expectWorker(withJob, 21)
+				mock.ExpectExec("INSERT INTO jobs").
+					WithArgs(21, 3, "4 years", "Wiring and repairs", 900, 500).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedImported: 1,
+		},
+		{
+			name:    "test a failed row rolls back the whole import",
+			workers: []domain.WorkerImport{withJob, withoutJob},
+			beforeTest: func() {
+				mock.ExpectBegin()
+				expectWorker(withJob, 21)
+				mock.ExpectExec("INSERT INTO jobs").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO users").
+					WillReturnError(errors.New(`duplicate key value violates unique constraint "users_phone_key"`))
+				mock.ExpectRollback()
+			},
+			expectedErr: errors.New(`duplicate key value violates unique constraint "users_phone_key"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+			ctx := context.Background()
+
+			actualImported, actualErr := importRepo.ImportWorkers(ctx, tt.workers)
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+			assert.Equal(t, tt.expectedImported, actualImported)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
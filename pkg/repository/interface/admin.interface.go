@@ -1,4 +1,19 @@
 package interfaces
 
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
 type AdminRepository interface {
+	SearchAccounts(ctx context.Context, userType string, search string, status string, filter utils.Filter) ([]domain.AccountSummary, utils.Metadata, error)
+	FindAccount(ctx context.Context, userId int) (domain.AccountSummary, error)
+	ListAddresses(ctx context.Context, userId int) ([]domain.Address, error)
+	ListRecentRequests(ctx context.Context, userId int, limit int) ([]domain.BookingResponse, error)
+	RatingSummary(ctx context.Context, workerId int) (float64, int, error)
+	ListAccountActions(ctx context.Context, userId int) ([]domain.AccountAction, error)
+	ApplyAccountAction(ctx context.Context, action domain.AccountAction, audit domain.AuditLog) (domain.AccountAction, error)
+	ListLogins(ctx context.Context, userId int, filter utils.Filter) ([]domain.LoginEvent, utils.Metadata, error)
 }
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type AnalyticsRepository interface {
+	SignupsPerDay(ctx context.Context, query domain.AnalyticsQuery) ([]domain.SignupStat, error)
+	ActiveWorkers(ctx context.Context, query domain.AnalyticsQuery) ([]domain.WorkerActivity, error)
+	RequestFunnel(ctx context.Context, query domain.AnalyticsQuery) (domain.RequestFunnel, error)
+	Cancellations(ctx context.Context, query domain.AnalyticsQuery) ([]domain.CancellationStat, error)
+	Revenue(ctx context.Context, query domain.AnalyticsQuery) ([]domain.RevenueStat, error)
+	RatingsByCategory(ctx context.Context, query domain.AnalyticsQuery) ([]domain.CategoryRating, error)
+	TopWorkers(ctx context.Context, query domain.AnalyticsQuery) ([]domain.TopWorker, error)
+	RefreshSummaries(ctx context.Context) error
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type AuditRepository interface {
+	AppendAudit(ctx context.Context, entry domain.AuditLog) (domain.AuditLog, error)
+	ListAuditLogs(ctx context.Context, query domain.AuditQuery, filter utils.Filter) ([]domain.AuditLog, utils.Metadata, error)
+	ListAuditRange(ctx context.Context, query domain.AuditQuery, afterId int, limit int) ([]domain.AuditLog, error)
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type BookingRepository interface {
+	FindJob(ctx context.Context, jobId int) (domain.Job, error)
+	CreateBooking(ctx context.Context, request domain.Request, workerId int) (int, error)
+	FindBooking(ctx context.Context, requestId int) (domain.BookingResponse, error)
+	UpdateStatus(ctx context.Context, requestId int, from string, to string) error
+	ListUserBookings(ctx context.Context, userId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error)
+	ListWorkerBookings(ctx context.Context, workerId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error)
+}
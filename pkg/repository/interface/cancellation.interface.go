@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type CancellationRepository interface {
+	FindPolicy(ctx context.Context, categoryId int) (domain.CancellationPolicy, error)
+	SetPolicy(ctx context.Context, policy domain.CancellationPolicy) (domain.CancellationPolicy, error)
+	Settle(ctx context.Context, settlement domain.Settlement) (domain.PolicyDecision, *domain.Refund, error)
+	ListDecisions(ctx context.Context, requestId int) ([]domain.PolicyDecision, error)
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type ChatRepository interface {
+	CreateMessage(ctx context.Context, message domain.Message) (domain.Message, error)
+	ListMessages(ctx context.Context, requestId int, filter utils.Filter) ([]domain.Message, utils.Metadata, error)
+	MarkRead(ctx context.Context, requestId int, readerId int, readTo int) (int64, error)
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type DisputeRepository interface {
+	OpenDispute(ctx context.Context, dispute domain.Dispute) (domain.Dispute, error)
+	FindDispute(ctx context.Context, disputeId int) (domain.Dispute, error)
+	ListPartyDisputes(ctx context.Context, userId int, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error)
+	ListDisputesByStatus(ctx context.Context, status string, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error)
+	AssignDispute(ctx context.Context, disputeId int, adminId int) (domain.Dispute, error)
+	AddEvidence(ctx context.Context, evidence domain.DisputeEvidence) (domain.DisputeEvidence, error)
+	ListEvidence(ctx context.Context, disputeId int) ([]domain.DisputeEvidence, error)
+	FindEvidence(ctx context.Context, disputeId int, evidenceId int) (domain.DisputeEvidence, error)
+	AddMessage(ctx context.Context, message domain.DisputeMessage) (domain.DisputeMessage, error)
+	ListMessages(ctx context.Context, disputeId int, withInternal bool) ([]domain.DisputeMessage, error)
+	ResolveDispute(ctx context.Context, resolution domain.DisputeResolution) (domain.Dispute, error)
+}
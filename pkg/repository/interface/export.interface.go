@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type ExportRepository interface {
+	ExportAccounts(ctx context.Context, userType string, query domain.ExportQuery, afterId int, limit int) ([]domain.AccountSummary, error)
+	ExportJobs(ctx context.Context, query domain.ExportQuery, afterId int, limit int) ([]domain.JobListing, error)
+	ExportRequests(ctx context.Context, query domain.ExportQuery, afterId int, limit int) ([]domain.BookingResponse, error)
+	ExportPayments(ctx context.Context, query domain.ExportQuery, afterId int, limit int) ([]domain.Payment, error)
+}
+
+type ImportRepository interface {
+	ListCategories(ctx context.Context) ([]domain.Category, error)
+	TakenContacts(ctx context.Context, phones []string, emails []string) (map[string]bool, error)
+	ImportCategories(ctx context.Context, categories []domain.Category) (int, error)
+	ImportWorkers(ctx context.Context, workers []domain.WorkerImport) (int, error)
+}
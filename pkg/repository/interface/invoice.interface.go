@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type InvoiceRepository interface {
+	SetMaterials(ctx context.Context, requestId int, materials []domain.RequestMaterial) error
+	ListMaterials(ctx context.Context, requestId int) ([]domain.RequestMaterial, error)
+	IssueInvoice(ctx context.Context, invoice domain.Invoice, series string) (domain.Invoice, error)
+	ListRequestInvoices(ctx context.Context, requestId int) ([]domain.Invoice, error)
+	FindInvoice(ctx context.Context, invoiceId int) (domain.Invoice, error)
+}
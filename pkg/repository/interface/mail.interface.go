@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type MailRepository interface {
+	EnqueueMail(ctx context.Context, mail domain.OutboxMail) (int, error)
+	ClaimDueMails(ctx context.Context, limit int, leaseUntil time.Time) ([]domain.OutboxMail, error)
+	MarkMailSent(ctx context.Context, mailId int) error
+	MarkMailFailed(ctx context.Context, mailId int, status string, lastError string, nextAttemptAt time.Time) error
+	ListDeadMails(ctx context.Context, filter utils.Filter) ([]domain.OutboxMail, utils.Metadata, error)
+	RequeueMail(ctx context.Context, mailId int) error
+}
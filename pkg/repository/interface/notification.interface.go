@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification domain.Notification) (domain.Notification, error)
+	ListNotifications(ctx context.Context, userId int, filter utils.Filter) ([]domain.Notification, utils.Metadata, error)
+	CountUnread(ctx context.Context, userId int) (int, error)
+	MarkRead(ctx context.Context, userId int, notificationId int) error
+	MarkAllRead(ctx context.Context, userId int) (int64, error)
+	ListPreferences(ctx context.Context, userId int) ([]domain.NotificationPreference, error)
+	SetPreferences(ctx context.Context, userId int, preferences []domain.NotificationPreference) error
+	AddDevice(ctx context.Context, device domain.DeviceToken) error
+	ListDevices(ctx context.Context, userId int) ([]domain.DeviceToken, error)
+	DeleteDevice(ctx context.Context, userId int, token string) error
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type OfferRepository interface {
+	CreateOffer(ctx context.Context, offer domain.Offer) (int, error)
+	FindOffer(ctx context.Context, offerId int) (domain.Offer, error)
+	ListOffers(ctx context.Context, requestId int) ([]domain.Offer, error)
+	CloseOffer(ctx context.Context, offerId int, status string, counter *domain.Offer) (int, error)
+	ExpireOffers(ctx context.Context) error
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type PaymentRepository interface {
+	CreatePayment(ctx context.Context, payment domain.Payment) (domain.Payment, error)
+	SetGatewayOrder(ctx context.Context, paymentId int, orderId string) error
+	FindRequestPayment(ctx context.Context, requestId int) (domain.Payment, error)
+	ApplyGatewayEvent(ctx context.Context, gateway string, eventId string, orderId string, gatewayPaymentId string, status string) (bool, error)
+	ReleasePayment(ctx context.Context, requestId int, commissionBps int) (domain.Payment, error)
+	RefundReleased(ctx context.Context, requestId int, amount int64, actorId int) (domain.Refund, error)
+	MarkRefund(ctx context.Context, refundId int, status string, gatewayRefundId string, lastError string) error
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type PromoRepository interface {
+	CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error)
+	FindPromoCode(ctx context.Context, code string) (domain.PromoCode, error)
+	ListPromoCodes(ctx context.Context, filter utils.Filter) ([]domain.PromoCode, utils.Metadata, error)
+	CountRedemptions(ctx context.Context, promoCodeId int, userId int) (int, error)
+	CountUserBookings(ctx context.Context, userId int) (int, error)
+	ReleaseRedemption(ctx context.Context, requestId int) error
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type ReferralRepository interface {
+	FindCode(ctx context.Context, userId int) (domain.ReferralCode, error)
+	CreateCode(ctx context.Context, userId int, code string) (domain.ReferralCode, error)
+	FindReferrer(ctx context.Context, code string) (domain.User, error)
+	AddSignupDevice(ctx context.Context, userId int, deviceId string) error
+	DeviceSeen(ctx context.Context, deviceId string, referrerId int) (bool, error)
+	CreateReferral(ctx context.Context, referral domain.Referral) (domain.Referral, error)
+	RewardReferral(ctx context.Context, refereeId int, requestId int, reward int64) (*domain.Referral, error)
+	Summary(ctx context.Context, userId int) (domain.ReferralSummary, error)
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type SubscriptionRepository interface {
+	CreatePlan(ctx context.Context, plan domain.SubscriptionPlan) (domain.SubscriptionPlan, error)
+	UpdatePlan(ctx context.Context, plan domain.SubscriptionPlan) (domain.SubscriptionPlan, error)
+	FindPlan(ctx context.Context, planId int) (domain.SubscriptionPlan, error)
+	ListPlans(ctx context.Context, activeOnly bool) ([]domain.SubscriptionPlan, error)
+	Subscribe(ctx context.Context, workerId int, plan domain.SubscriptionPlan) (domain.Subscription, error)
+	CurrentSubscription(ctx context.Context, workerId int) (domain.Subscription, error)
+	SetAutoRenew(ctx context.Context, workerId int, autoRenew bool) (domain.Subscription, error)
+	DueSubscriptions(ctx context.Context, now time.Time, limit int) ([]int, error)
+	Renew(ctx context.Context, subscriptionId int, now time.Time, graceDays int) (domain.Subscription, error)
+}
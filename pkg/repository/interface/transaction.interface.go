@@ -0,0 +1,9 @@
+package interfaces
+
+import "context"
+
+// Transactor runs a unit of work in one transaction. The repositories pick
+// the transaction up from the context handed to the work.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
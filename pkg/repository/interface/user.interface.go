@@ -9,8 +9,10 @@ import (
 type UserRepository interface {
 	CreateUser(ctx context.Context, user domain.User) (int, error)
 	FindUserWithNumber(ctx context.Context, phoneNumber string) (domain.User, error)
+	FindUserWithId(ctx context.Context, userId int) (domain.User, error)
 	FindUserWithEmail(ctx context.Context, email string) (domain.User, error)
 	AddProfile(ctx context.Context, profile domain.UserData) error
 	UpdateMail(ctx context.Context, mail string, userId int) error
 	GetProfile(ctx context.Context, userId int) (domain.Profile, error)
+	RecordLogin(ctx context.Context, login domain.LoginEvent) error
 }
@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type WalletRepository interface {
+	Balance(ctx context.Context, workerId int) (domain.Wallet, error)
+	ListEntries(ctx context.Context, workerId int, filter utils.Filter) ([]domain.WalletEntry, utils.Metadata, error)
+	Statement(ctx context.Context, workerId int, from time.Time, to time.Time) (domain.Statement, error)
+	PostAdjustment(ctx context.Context, workerId int, actorId int, amount int64, memo string) (domain.LedgerTransaction, error)
+	RequestPayout(ctx context.Context, payout domain.Payout) (domain.Payout, error)
+	ListWorkerPayouts(ctx context.Context, workerId int, filter utils.Filter) ([]domain.Payout, utils.Metadata, error)
+	ListPayoutsByStatus(ctx context.Context, status string, filter utils.Filter) ([]domain.Payout, utils.Metadata, error)
+	ApprovePayout(ctx context.Context, payoutId int, adminId int, note string) (domain.Payout, error)
+	RejectPayout(ctx context.Context, payoutId int, adminId int, note string) (domain.Payout, error)
+	CreatePayoutBatch(ctx context.Context, adminId int) (domain.PayoutBatch, []domain.Payout, error)
+	FindPayoutBatch(ctx context.Context, batchId int) (domain.PayoutBatch, []domain.Payout, error)
+}
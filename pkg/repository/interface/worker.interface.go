@@ -1,4 +1,21 @@
 package interfaces
 
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
 type WorkerRepository interface {
+	SetAvailability(ctx context.Context, workerId int, availability []domain.Availability) error
+	GetAvailability(ctx context.Context, workerId int) ([]domain.Availability, error)
+	AddBlackout(ctx context.Context, blackout domain.Blackout) (int, error)
+	ListBlackouts(ctx context.Context, workerId int, from time.Time) ([]domain.Blackout, error)
+	DeleteBlackout(ctx context.Context, workerId int, blackoutId int) error
+	AddJob(ctx context.Context, job domain.Job, jobLimit int) (int, error)
+	ListWorkerJobs(ctx context.Context, workerId int) ([]domain.JobListing, error)
+	SetJobOpen(ctx context.Context, workerId int, jobId int, open bool, jobLimit int) error
+	SearchJobs(ctx context.Context, categoryId int, filter utils.Filter) ([]domain.JobListing, utils.Metadata, error)
 }
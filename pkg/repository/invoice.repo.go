@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+const invoiceColumns = `id_invoice, number, kind, request_id, credited_id, refund_id, issuer_id, issuer_name, recipient_id, recipient_name, currency, tax_bps, subtotal, tax, total, issued_at`
+
+type invoiceRepo struct {
+	db *sql.DB
+}
+
+// SetMaterials implements interfaces.InvoiceRepository. The materials replace
+// any the request had, so completing again after a failure does not add them twice.
+func (c *invoiceRepo) SetMaterials(ctx context.Context, requestId int, materials []domain.RequestMaterial) error {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM request_materials WHERE request_id=$1;`, requestId); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO request_materials (request_id, description, amount) VALUES ($1,$2,$3);`
+	for _, material := range materials {
+		if _, err = tx.ExecContext(ctx, query, requestId, material.Description, material.Amount); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListMaterials implements interfaces.InvoiceRepository
+func (c *invoiceRepo) ListMaterials(ctx context.Context, requestId int) ([]domain.RequestMaterial, error) {
+	var materials []domain.RequestMaterial
+
+	query := `SELECT id_material, request_id, description, amount FROM request_materials WHERE request_id=$1 ORDER BY id_material;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, requestId)
+	if err != nil {
+		return materials, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var material domain.RequestMaterial
+		err = rows.Scan(
+			&material.IdMaterial,
+			&material.RequestId,
+			&material.Description,
+			&material.Amount,
+		)
+		if err != nil {
+			return materials, err
+		}
+		materials = append(materials, material)
+	}
+	return materials, rows.Err()
+}
+
+// IssueInvoice implements interfaces.InvoiceRepository. A request has at most
+// one invoice of a kind other than credit notes, asking again returns it. The
+// number is the next of series, e.g. INV-2026-000042.
+func (c *invoiceRepo) IssueInvoice(ctx context.Context, invoice domain.Invoice, series string) (domain.Invoice, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return invoice, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := `SELECT id_requset FROM requests WHERE id_requset=$1 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, invoice.RequestId).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return invoice, domain.ErrNoRequest
+	}
+	if err != nil {
+		return invoice, err
+	}
+
+	if invoice.Kind != domain.InvoiceCreditNote {
+		query = `SELECT ` + invoiceColumns + ` FROM invoices WHERE request_id=$1 AND kind=$2;`
+		existing, err := scanInvoice(tx.QueryRowContext(ctx, query, invoice.RequestId, invoice.Kind))
+		if err == nil {
+			existing.Lines, err = invoiceLines(ctx, querier{tx}, existing.IdInvoice)
+			if err != nil {
+				return existing, err
+			}
+			return existing, tx.Commit()
+		}
+		if err != sql.ErrNoRows {
+			return invoice, err
+		}
+	}
+
+	var last int
+	query = `INSERT INTO invoice_sequences (series, last) VALUES ($1, 1)
+				ON CONFLICT (series) DO UPDATE SET last=invoice_sequences.last+1 RETURNING last;`
+	if err = tx.QueryRowContext(ctx, query, series).Scan(&last); err != nil {
+		return invoice, err
+	}
+	invoice.Number = fmt.Sprintf("%s-%06d", series, last)
+
+	lines := invoice.Lines
+	query = `INSERT INTO invoices (number, kind, request_id, credited_id, refund_id, issuer_id, issuer_name, recipient_id, recipient_name, currency, tax_bps, subtotal, tax, total, issued_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,NOW()) RETURNING ` + invoiceColumns + `;`
+	invoice, err = scanInvoice(tx.QueryRowContext(ctx, query,
+		invoice.Number,
+		invoice.Kind,
+		invoice.RequestId,
+		invoice.CreditedId,
+		invoice.RefundId,
+		invoice.IssuerId,
+		invoice.IssuerName,
+		invoice.RecipientId,
+		invoice.RecipientName,
+		invoice.Currency,
+		invoice.TaxBps,
+		invoice.Subtotal,
+		invoice.Tax,
+		invoice.Total,
+	))
+	if err != nil {
+		return invoice, err
+	}
+
+	query = `INSERT INTO invoice_lines (invoice_id, kind, description, amount) VALUES ($1,$2,$3,$4);`
+	for _, line := range lines {
+		if _, err = tx.ExecContext(ctx, query, invoice.IdInvoice, line.Kind, line.Description, line.Amount); err != nil {
+			return invoice, err
+		}
+	}
+	invoice.Lines = lines
+
+	return invoice, tx.Commit()
+}
+
+// ListRequestInvoices implements interfaces.InvoiceRepository
+func (c *invoiceRepo) ListRequestInvoices(ctx context.Context, requestId int) ([]domain.Invoice, error) {
+	var invoices []domain.Invoice
+
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE request_id=$1 ORDER BY id_invoice;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, requestId)
+	if err != nil {
+		return invoices, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return invoices, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err = rows.Err(); err != nil {
+		return invoices, err
+	}
+	rows.Close()
+
+	for i := range invoices {
+		invoices[i].Lines, err = invoiceLines(ctx, conn(ctx, c.db), invoices[i].IdInvoice)
+		if err != nil {
+			return invoices, err
+		}
+	}
+	return invoices, nil
+}
+
+// FindInvoice implements interfaces.InvoiceRepository
+func (c *invoiceRepo) FindInvoice(ctx context.Context, invoiceId int) (domain.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id_invoice=$1;`
+	invoice, err := scanInvoice(conn(ctx, c.db).QueryRowContext(ctx, query, invoiceId))
+	if err != nil && err == sql.ErrNoRows {
+		return invoice, domain.ErrNoInvoice
+	}
+	if err != nil {
+		return invoice, err
+	}
+
+	invoice.Lines, err = invoiceLines(ctx, conn(ctx, c.db), invoiceId)
+	return invoice, err
+}
+
+func invoiceLines(ctx context.Context, db querier, invoiceId int) ([]domain.InvoiceLine, error) {
+	var lines []domain.InvoiceLine
+
+	query := `SELECT id_line, invoice_id, kind, description, amount FROM invoice_lines WHERE invoice_id=$1 ORDER BY id_line;`
+	rows, err := db.QueryContext(ctx, query, invoiceId)
+	if err != nil {
+		return lines, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domain.InvoiceLine
+		err = rows.Scan(
+			&line.IdLine,
+			&line.InvoiceId,
+			&line.Kind,
+			&line.Description,
+			&line.Amount,
+		)
+		if err != nil {
+			return lines, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func scanInvoice(row rowScanner) (domain.Invoice, error) {
+	var invoice domain.Invoice
+	var creditedId, refundId sql.NullInt64
+	err := row.Scan(
+		&invoice.IdInvoice,
+		&invoice.Number,
+		&invoice.Kind,
+		&invoice.RequestId,
+		&creditedId,
+		&refundId,
+		&invoice.IssuerId,
+		&invoice.IssuerName,
+		&invoice.RecipientId,
+		&invoice.RecipientName,
+		&invoice.Currency,
+		&invoice.TaxBps,
+		&invoice.Subtotal,
+		&invoice.Tax,
+		&invoice.Total,
+		&invoice.IssuedAt,
+	)
+	if creditedId.Valid {
+		id := int(creditedId.Int64)
+		invoice.CreditedId = &id
+	}
+	if refundId.Valid {
+		id := int(refundId.Int64)
+		invoice.RefundId = &id
+	}
+	return invoice, err
+}
+
+func NewInvoiceRepo(db *sql.DB) interfaces.InvoiceRepository {
+	return &invoiceRepo{
+		db: db,
+	}
+}
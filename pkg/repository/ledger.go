@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+// postLedger writes a ledger transaction and its entries inside tx. Entries
+// that do not sum to zero are refused, as is a transaction without any.
+func postLedger(ctx context.Context, tx *sql.Tx, transaction domain.LedgerTransaction, entries ...domain.LedgerEntry) (domain.LedgerTransaction, error) {
+	var sum int64
+	for _, entry := range entries {
+		sum += entry.Amount
+	}
+	if len(entries) == 0 || sum != 0 {
+		return transaction, errors.New("ledger entries must balance")
+	}
+
+	query := `INSERT INTO ledger_transactions (kind, request_id, payout_id, memo, actor_id, created_at)
+				VALUES ($1,$2,$3,$4,$5,NOW()) RETURNING id_transaction, created_at;`
+	err := tx.QueryRowContext(ctx, query,
+		transaction.Kind,
+		transaction.RequestId,
+		transaction.PayoutId,
+		transaction.Memo,
+		transaction.ActorId,
+	).Scan(
+		&transaction.IdTransaction,
+		&transaction.CreatedAt,
+	)
+	if err != nil {
+		return transaction, err
+	}
+
+	query = `INSERT INTO ledger_entries (transaction_id, account, owner_id, amount, currency, created_at) VALUES ($1,$2,$3,$4,$5,$6);`
+	for _, entry := range entries {
+		_, err = tx.ExecContext(ctx, query,
+			transaction.IdTransaction,
+			entry.Account,
+			entry.OwnerId,
+			entry.Amount,
+			entry.Currency,
+			transaction.CreatedAt,
+		)
+		if err != nil {
+			return transaction, err
+		}
+	}
+	return transaction, nil
+}
+
+// transfer is the two entries moving amount from one account to another
+func transfer(from domain.LedgerEntry, to domain.LedgerEntry, amount int64, currency string) []domain.LedgerEntry {
+	from.Amount, from.Currency = -amount, currency
+	to.Amount, to.Currency = amount, currency
+	return []domain.LedgerEntry{from, to}
+}
+
+// postRelease credits a worker with what they kept of a payment and takes
+// the platform commission off it
+func postRelease(ctx context.Context, tx *sql.Tx, requestId int, workerId int, kept int64, commission int64, currency string) error {
+	worker := domain.LedgerEntry{Account: domain.AccountWorker, OwnerId: workerId}
+
+	_, err := postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerEarning, RequestId: &requestId},
+		transfer(domain.LedgerEntry{Account: domain.AccountEscrow}, worker, kept, currency)...)
+	if err != nil || commission == 0 {
+		return err
+	}
+	_, err = postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerCommission, RequestId: &requestId},
+		transfer(worker, domain.LedgerEntry{Account: domain.AccountCommission}, commission, currency)...)
+	return err
+}
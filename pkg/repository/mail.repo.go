@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+const mailColumns = `id_mail, to_address, template, locale, subject, text_body, html_body, status, attempts, next_attempt_at, last_error, sent_at, created_at`
+
+type mailRepo struct {
+	db *sql.DB
+}
+
+// EnqueueMail implements interfaces.MailRepository
+func (c *mailRepo) EnqueueMail(ctx context.Context, mail domain.OutboxMail) (int, error) {
+	var id int
+	query := `INSERT INTO outbox_mails (to_address, template, locale, subject, text_body, html_body, status, attempts, next_attempt_at, last_error, created_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,0,NOW(),'',NOW()) RETURNING id_mail;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
+		mail.ToAddress,
+		mail.Template,
+		mail.Locale,
+		mail.Subject,
+		mail.TextBody,
+		mail.HTMLBody,
+		domain.MailQueued,
+	).Scan(&id)
+	return id, err
+}
+
+// ClaimDueMails implements interfaces.MailRepository
+func (c *mailRepo) ClaimDueMails(ctx context.Context, limit int, leaseUntil time.Time) ([]domain.OutboxMail, error) {
+	var mails []domain.OutboxMail
+
+	// Claimed mails are pushed to leaseUntil so no other worker picks them up
+	// meanwhile, and so they come round again if this one dies mid send
+	query := `UPDATE outbox_mails SET attempts=attempts+1, next_attempt_at=$3
+				WHERE id_mail IN (
+					SELECT id_mail FROM outbox_mails WHERE status=$1 AND next_attempt_at<=NOW()
+					ORDER BY next_attempt_at LIMIT $2 FOR UPDATE SKIP LOCKED
+				) RETURNING ` + mailColumns + `;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, domain.MailQueued, limit, leaseUntil)
+	if err != nil {
+		return mails, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		mail, err := scanMail(rows)
+		if err != nil {
+			return mails, err
+		}
+		mails = append(mails, mail)
+	}
+	return mails, rows.Err()
+}
+
+// MarkMailSent implements interfaces.MailRepository
+func (c *mailRepo) MarkMailSent(ctx context.Context, mailId int) error {
+	query := `UPDATE outbox_mails SET status=$2, sent_at=NOW(), last_error='' WHERE id_mail=$1;`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, mailId, domain.MailSent)
+	return err
+}
+
+// MarkMailFailed implements interfaces.MailRepository
+func (c *mailRepo) MarkMailFailed(ctx context.Context, mailId int, status string, lastError string, nextAttemptAt time.Time) error {
+	query := `UPDATE outbox_mails SET status=$2, last_error=$3, next_attempt_at=$4 WHERE id_mail=$1;`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, mailId, status, lastError, nextAttemptAt)
+	return err
+}
+
+// ListDeadMails implements interfaces.MailRepository
+func (c *mailRepo) ListDeadMails(ctx context.Context, filter utils.Filter) ([]domain.OutboxMail, utils.Metadata, error) {
+	var mails []domain.OutboxMail
+	var total int
+
+	args := []interface{}{domain.MailDead}
+	keyset, keysetArgs := filter.KeysetCondition("created_at", "id_mail", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + mailColumns + `, COUNT(*) OVER()
+				FROM outbox_mails WHERE status=$1` + keyset + ` ORDER BY created_at DESC, id_mail DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return mails, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		mail, err := scanMail(rows, &total)
+		if err != nil {
+			return mails, utils.Metadata{}, err
+		}
+		mails = append(mails, mail)
+	}
+	if err = rows.Err(); err != nil {
+		return mails, utils.Metadata{}, err
+	}
+
+	fetched := len(mails)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		mails = mails[:filter.PageSize]
+	}
+	if len(mails) > 0 {
+		last = utils.Cursor{Id: mails[len(mails)-1].IdMail, CreatedAt: mails[len(mails)-1].CreatedAt}
+	}
+
+	return mails, pageMetadata(filter, fetched, total, last), nil
+}
+
+// RequeueMail implements interfaces.MailRepository
+func (c *mailRepo) RequeueMail(ctx context.Context, mailId int) error {
+	var id int
+	query := `UPDATE outbox_mails SET status=$2, attempts=0, next_attempt_at=NOW() WHERE id_mail=$1 AND status=$3 RETURNING id_mail;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, mailId, domain.MailQueued, domain.MailDead).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrNoDeadMail
+	}
+	return err
+}
+
+// scanMail reads the mailColumns of a row followed by any extra columns
+func scanMail(row rowScanner, extra ...interface{}) (domain.OutboxMail, error) {
+	var mail domain.OutboxMail
+	var sentAt sql.NullTime
+	dest := []interface{}{
+		&mail.IdMail,
+		&mail.ToAddress,
+		&mail.Template,
+		&mail.Locale,
+		&mail.Subject,
+		&mail.TextBody,
+		&mail.HTMLBody,
+		&mail.Status,
+		&mail.Attempts,
+		&mail.NextAttemptAt,
+		&mail.LastError,
+		&sentAt,
+		&mail.CreatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if sentAt.Valid {
+		mail.SentAt = &sentAt.Time
+	}
+	return mail, err
+}
+
+func NewMailRepo(db *sql.DB) interfaces.MailRepository {
+	return &mailRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type notificationRepo struct {
+	db *sql.DB
+}
+
+// CreateNotification implements interfaces.NotificationRepository
+func (c *notificationRepo) CreateNotification(ctx context.Context, notification domain.Notification) (domain.Notification, error) {
+	query := `INSERT INTO notifications (user_id, event, title, body, request_id, created_at)
+				VALUES ($1,$2,$3,$4,$5,NOW()) RETURNING id_notification, created_at;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
+		notification.UserId,
+		notification.Event,
+		notification.Title,
+		notification.Body,
+		notification.RequestId,
+	).Scan(
+		&notification.IdNotification,
+		&notification.CreatedAt,
+	)
+	return notification, err
+}
+
+// ListNotifications implements interfaces.NotificationRepository
+func (c *notificationRepo) ListNotifications(ctx context.Context, userId int, filter utils.Filter) ([]domain.Notification, utils.Metadata, error) {
+	var notifications []domain.Notification
+	var total int
+
+	args := []interface{}{userId}
+	keyset, keysetArgs := filter.KeysetCondition("created_at", "id_notification", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT id_notification, user_id, event, title, body, request_id, read_at, created_at, COUNT(*) OVER()
+				FROM notifications WHERE user_id=$1` + keyset + ` ORDER BY created_at DESC, id_notification DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return notifications, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var notification domain.Notification
+		var requestId sql.NullInt64
+		var readAt sql.NullTime
+		err = rows.Scan(
+			&notification.IdNotification,
+			&notification.UserId,
+			&notification.Event,
+			&notification.Title,
+			&notification.Body,
+			&requestId,
+			&readAt,
+			&notification.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return notifications, utils.Metadata{}, err
+		}
+		if requestId.Valid {
+			id := int(requestId.Int64)
+			notification.RequestId = &id
+		}
+		if readAt.Valid {
+			notification.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, notification)
+	}
+	if err = rows.Err(); err != nil {
+		return notifications, utils.Metadata{}, err
+	}
+
+	fetched := len(notifications)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		notifications = notifications[:filter.PageSize]
+	}
+	if len(notifications) > 0 {
+		last = utils.Cursor{Id: notifications[len(notifications)-1].IdNotification, CreatedAt: notifications[len(notifications)-1].CreatedAt}
+	}
+
+	return notifications, pageMetadata(filter, fetched, total, last), nil
+}
+
+// CountUnread implements interfaces.NotificationRepository
+func (c *notificationRepo) CountUnread(ctx context.Context, userId int) (int, error) {
+	var unread int
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id=$1 AND read_at IS NULL;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, userId).Scan(&unread)
+	return unread, err
+}
+
+// MarkRead implements interfaces.NotificationRepository
+func (c *notificationRepo) MarkRead(ctx context.Context, userId int, notificationId int) error {
+	var id int
+	query := `UPDATE notifications SET read_at=COALESCE(read_at, NOW()) WHERE id_notification=$1 AND user_id=$2 RETURNING id_notification;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, notificationId, userId).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrNoNotification
+	}
+	return err
+}
+
+// MarkAllRead implements interfaces.NotificationRepository
+func (c *notificationRepo) MarkAllRead(ctx context.Context, userId int) (int64, error) {
+	query := `UPDATE notifications SET read_at=NOW() WHERE user_id=$1 AND read_at IS NULL;`
+	result, err := conn(ctx, c.db).ExecContext(ctx, query, userId)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListPreferences implements interfaces.NotificationRepository
+func (c *notificationRepo) ListPreferences(ctx context.Context, userId int) ([]domain.NotificationPreference, error) {
+	var preferences []domain.NotificationPreference
+
+	query := `SELECT id_preference, user_id, event, channel, enabled FROM notification_preferences WHERE user_id=$1 ORDER BY event, channel;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return preferences, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var preference domain.NotificationPreference
+		err = rows.Scan(
+			&preference.IdPreference,
+			&preference.UserId,
+			&preference.Event,
+			&preference.Channel,
+			&preference.Enabled,
+		)
+		if err != nil {
+			return preferences, err
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences, rows.Err()
+}
+
+// SetPreferences implements interfaces.NotificationRepository
+func (c *notificationRepo) SetPreferences(ctx context.Context, userId int, preferences []domain.NotificationPreference) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO notification_preferences (user_id, event, channel, enabled) VALUES ($1,$2,$3,$4)
+				ON CONFLICT (user_id, event, channel) DO UPDATE SET enabled=EXCLUDED.enabled;`
+	for _, preference := range preferences {
+		_, err = tx.ExecContext(ctx, query,
+			userId,
+			preference.Event,
+			preference.Channel,
+			preference.Enabled,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AddDevice implements interfaces.NotificationRepository
+func (c *notificationRepo) AddDevice(ctx context.Context, device domain.DeviceToken) error {
+	// A token moves to whoever registered it last, e.g. after a logout and a
+	// login with another account on the same phone
+	query := `INSERT INTO device_tokens (user_id, token, platform, created_at) VALUES ($1,$2,$3,NOW())
+				ON CONFLICT (token) DO UPDATE SET user_id=EXCLUDED.user_id, platform=EXCLUDED.platform;`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, device.UserId, device.Token, device.Platform)
+	return err
+}
+
+// ListDevices implements interfaces.NotificationRepository
+func (c *notificationRepo) ListDevices(ctx context.Context, userId int) ([]domain.DeviceToken, error) {
+	var devices []domain.DeviceToken
+
+	query := `SELECT id_device, user_id, token, platform, created_at FROM device_tokens WHERE user_id=$1;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return devices, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var device domain.DeviceToken
+		err = rows.Scan(
+			&device.IdDevice,
+			&device.UserId,
+			&device.Token,
+			&device.Platform,
+			&device.CreatedAt,
+		)
+		if err != nil {
+			return devices, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+// DeleteDevice implements interfaces.NotificationRepository
+func (c *notificationRepo) DeleteDevice(ctx context.Context, userId int, token string) error {
+	var id int
+	query := `DELETE FROM device_tokens WHERE token=$1 AND user_id=$2 RETURNING id_device;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, token, userId).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrNoDevice
+	}
+	return err
+}
+
+func NewNotificationRepo(db *sql.DB) interfaces.NotificationRepository {
+	return &notificationRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+type offerRepo struct {
+	db *sql.DB
+}
+
+const offerColumns = `id_offer, request_id, parent_id, proposed_by, proposer_id, amount, currency, status, expires_at, responded_at, created_at`
+
+// CreateOffer implements interfaces.OfferRepository
+func (c *offerRepo) CreateOffer(ctx context.Context, offer domain.Offer) (int, error) {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err = lockNegotiableRequest(ctx, tx.Tx, offer.RequestId); err != nil {
+		return 0, err
+	}
+
+	var open int
+	query := `SELECT COUNT(*) FROM offers WHERE request_id=$1 AND status=$2 AND expires_at>NOW();`
+	if err = tx.QueryRowContext(ctx, query, offer.RequestId, domain.OfferOpen).Scan(&open); err != nil {
+		return 0, err
+	}
+	if open > 0 {
+		return 0, domain.ErrOfferOpen
+	}
+
+	id, err := insertOffer(ctx, tx.Tx, offer)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// FindOffer implements interfaces.OfferRepository
+func (c *offerRepo) FindOffer(ctx context.Context, offerId int) (domain.Offer, error) {
+	query := `SELECT ` + offerColumns + ` FROM offers WHERE id_offer=$1;`
+	offer, err := scanOffer(conn(ctx, c.db).QueryRowContext(ctx, query, offerId))
+	if err != nil && err == sql.ErrNoRows {
+		return offer, domain.ErrNoOffer
+	}
+	return offer, err
+}
+
+// ListOffers implements interfaces.OfferRepository
+func (c *offerRepo) ListOffers(ctx context.Context, requestId int) ([]domain.Offer, error) {
+	var offers []domain.Offer
+
+	query := `SELECT ` + offerColumns + ` FROM offers WHERE request_id=$1 ORDER BY created_at, id_offer;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, requestId)
+	if err != nil {
+		return offers, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return offers, err
+		}
+		offers = append(offers, offer)
+	}
+	return offers, rows.Err()
+}
+
+// CloseOffer implements interfaces.OfferRepository
+//
+// Closing with OfferAccepted locks the offer amount onto the request, closing
+// with OfferCountered inserts counter in the same transaction. A promo code
+// discount worked out at booking stays, but never more than the agreed price.
+func (c *offerRepo) CloseOffer(ctx context.Context, offerId int, status string, counter *domain.Offer) (int, error) {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var requestId int
+	var amount int64
+	err = tx.QueryRowContext(ctx, `SELECT request_id FROM offers WHERE id_offer=$1;`, offerId).Scan(&requestId)
+	if err != nil && err == sql.ErrNoRows {
+		return 0, domain.ErrNoOffer
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err = lockNegotiableRequest(ctx, tx.Tx, requestId); err != nil {
+		return 0, err
+	}
+
+	query := `UPDATE offers SET status=$1, responded_at=NOW() WHERE id_offer=$2 AND status=$3 AND expires_at>NOW() RETURNING amount;`
+	err = tx.QueryRowContext(ctx, query, status, offerId, domain.OfferOpen).Scan(&amount)
+	if err != nil && err == sql.ErrNoRows {
+		return 0, domain.ErrOfferClosed
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if status == domain.OfferAccepted {
+		query = `UPDATE requests SET amount=$1, discount=LEAST(discount,$1), price_locked=true, updated_at=NOW() WHERE id_requset=$2;`
+		if _, err = tx.ExecContext(ctx, query, amount, requestId); err != nil {
+			return 0, err
+		}
+	}
+
+	var id int
+	if counter != nil {
+		counter.ParentId = &offerId
+		if id, err = insertOffer(ctx, tx.Tx, *counter); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+// ExpireOffers implements interfaces.OfferRepository
+func (c *offerRepo) ExpireOffers(ctx context.Context) error {
+	query := `UPDATE offers SET status=$1 WHERE status=$2 AND expires_at<=NOW();`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, domain.OfferExpired, domain.OfferOpen)
+	return err
+}
+
+// lockNegotiableRequest locks the request row and checks its price can still be negotiated
+func lockNegotiableRequest(ctx context.Context, tx *sql.Tx, requestId int) error {
+	var status string
+	var locked bool
+	query := `SELECT status, price_locked FROM requests WHERE id_requset=$1 FOR UPDATE;`
+	err := tx.QueryRowContext(ctx, query, requestId).Scan(&status, &locked)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrNoRequest
+	}
+	if err != nil {
+		return err
+	}
+	if status != domain.RequestPending || locked {
+		return domain.ErrNotNegotiable
+	}
+	return nil
+}
+
+func insertOffer(ctx context.Context, tx *sql.Tx, offer domain.Offer) (int, error) {
+	var id int
+	query := `INSERT INTO offers (request_id, parent_id, proposed_by, proposer_id, amount, currency, status, expires_at, created_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NOW()) RETURNING id_offer;`
+	err := tx.QueryRowContext(ctx, query,
+		offer.RequestId,
+		offer.ParentId,
+		offer.ProposedBy,
+		offer.ProposerId,
+		offer.Amount,
+		offer.Currency,
+		domain.OfferOpen,
+		offer.ExpiresAt,
+	).Scan(&id)
+	return id, err
+}
+
+func scanOffer(row rowScanner) (domain.Offer, error) {
+	var offer domain.Offer
+	var parentId sql.NullInt64
+	var respondedAt sql.NullTime
+
+	err := row.Scan(
+		&offer.IdOffer,
+		&offer.RequestId,
+		&parentId,
+		&offer.ProposedBy,
+		&offer.ProposerId,
+		&offer.Amount,
+		&offer.Currency,
+		&offer.Status,
+		&offer.ExpiresAt,
+		&respondedAt,
+		&offer.CreatedAt,
+	)
+	if parentId.Valid {
+		id := int(parentId.Int64)
+		offer.ParentId = &id
+	}
+	if respondedAt.Valid {
+		offer.RespondedAt = &respondedAt.Time
+	}
+	return offer, err
+}
+
+func NewOfferRepo(db *sql.DB) interfaces.OfferRepository {
+	return &offerRepo{
+		db: db,
+	}
+}
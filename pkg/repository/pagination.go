@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+// pageClause returns the LIMIT/OFFSET suffix of a list query. Cursor pages
+// fetch one row more than asked so the caller can tell if another page follows.
+func pageClause(filter utils.Filter, argIndex int) (string, []interface{}) {
+	if filter.IsCursor() {
+		return fmt.Sprintf(" LIMIT $%d;", argIndex), []interface{}{filter.Limit() + 1}
+	}
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d;", argIndex, argIndex+1), []interface{}{filter.Limit(), filter.Offset()}
+}
+
+// pageMetadata computes the metadata of a fetched page. fetched is the number
+// of rows the query returned, total the COUNT(*) OVER() of an offset query and
+// last the keyset position of the last row kept on a cursor page.
+func pageMetadata(filter utils.Filter, fetched int, total int, last utils.Cursor) utils.Metadata {
+	if filter.IsCursor() {
+		return utils.ComputeCursorMetaData(fetched, filter.PageSize, last)
+	}
+	return utils.ComputeMetaData(total, filter.Page, filter.PageSize)
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+const paymentColumns = `id_payment, request_id, user_id, worker_id, amount, discount, commission, currency, gateway, gateway_order_id, gateway_payment_id, status, held_at, released_at, created_at, updated_at`
+
+type paymentRepo struct {
+	db *sql.DB
+}
+
+// CreatePayment implements interfaces.PaymentRepository. A payment already
+// made with the same idempotency key, or still live on the request, is
+// returned instead of a new one.
+func (c *paymentRepo) CreatePayment(ctx context.Context, payment domain.Payment) (domain.Payment, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return payment, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	query := `SELECT status FROM requests WHERE id_requset=$1 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, payment.RequestId).Scan(&status)
+	if err != nil && err == sql.ErrNoRows {
+		return payment, domain.ErrNoRequest
+	}
+	if err != nil {
+		return payment, err
+	}
+
+	query = `SELECT ` + paymentColumns + ` FROM payments WHERE idempotency_key=$1;`
+	existing, err := scanPayment(tx.QueryRowContext(ctx, query, payment.IdempotencyKey))
+	if err == nil {
+		if existing.RequestId != payment.RequestId {
+			return payment, domain.ErrIdempotencyReused
+		}
+		return existing, tx.Commit()
+	}
+	if err != sql.ErrNoRows {
+		return payment, err
+	}
+
+	query = `SELECT ` + paymentColumns + ` FROM payments WHERE request_id=$1 AND status<>$2 ORDER BY id_payment DESC LIMIT 1;`
+	existing, err = scanPayment(tx.QueryRowContext(ctx, query, payment.RequestId, domain.PaymentFailed))
+	if err == nil {
+		return existing, tx.Commit()
+	}
+	if err != sql.ErrNoRows {
+		return payment, err
+	}
+
+	if status != domain.RequestAccepted {
+		return payment, domain.ErrNotPayable
+	}
+
+	query = `INSERT INTO payments (request_id, user_id, worker_id, amount, discount, commission, currency, gateway, gateway_order_id, gateway_payment_id, idempotency_key, status, created_at, updated_at)
+				VALUES ($1,$2,$3,$4,$5,0,$6,$7,'','',$8,$9,NOW(),NOW()) RETURNING ` + paymentColumns + `;`
+	payment, err = scanPayment(tx.QueryRowContext(ctx, query,
+		payment.RequestId,
+		payment.UserId,
+		payment.WorkerId,
+		payment.Amount,
+		payment.Discount,
+		payment.Currency,
+		payment.Gateway,
+		payment.IdempotencyKey,
+		domain.PaymentCreated,
+	))
+	if err != nil {
+		return payment, err
+	}
+	return payment, tx.Commit()
+}
+
+// SetGatewayOrder implements interfaces.PaymentRepository
+func (c *paymentRepo) SetGatewayOrder(ctx context.Context, paymentId int, orderId string) error {
+	query := `UPDATE payments SET gateway_order_id=$1, updated_at=NOW() WHERE id_payment=$2;`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, orderId, paymentId)
+	return err
+}
+
+// FindRequestPayment implements interfaces.PaymentRepository
+func (c *paymentRepo) FindRequestPayment(ctx context.Context, requestId int) (domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE request_id=$1 ORDER BY (status=$2), id_payment DESC LIMIT 1;`
+	payment, err := scanPayment(conn(ctx, c.db).QueryRowContext(ctx, query, requestId, domain.PaymentFailed))
+	if err != nil && err == sql.ErrNoRows {
+		return payment, domain.ErrNoPayment
+	}
+	return payment, err
+}
+
+// ApplyGatewayEvent implements interfaces.PaymentRepository. It reports false
+// when the event was seen before and nothing changed.
+func (c *paymentRepo) ApplyGatewayEvent(ctx context.Context, gateway string, eventId string, orderId string, gatewayPaymentId string, status string) (bool, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO payment_events (gateway, event_id, received_at) VALUES ($1,$2,NOW()) ON CONFLICT DO NOTHING;`, gateway, eventId)
+	if err != nil {
+		return false, err
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return false, err
+	}
+
+	query := `UPDATE payments SET status=$1, gateway_payment_id=$2, updated_at=NOW()
+				WHERE gateway=$3 AND gateway_order_id=$4 AND status=$5 RETURNING request_id, amount, currency;`
+	if status == domain.PaymentHeld {
+		query = `UPDATE payments SET status=$1, gateway_payment_id=$2, held_at=NOW(), updated_at=NOW()
+				WHERE gateway=$3 AND gateway_order_id=$4 AND status=$5 RETURNING request_id, amount, currency;`
+	}
+	var requestId int
+	var amount int64
+	var currency string
+	err = tx.QueryRowContext(ctx, query, status, gatewayPaymentId, gateway, orderId, domain.PaymentCreated).Scan(&requestId, &amount, &currency)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	if err == nil && status == domain.PaymentHeld {
+		_, err = postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerCapture, RequestId: &requestId},
+			transfer(domain.LedgerEntry{Account: domain.AccountGateway}, domain.LedgerEntry{Account: domain.AccountEscrow}, amount, currency)...)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// ReleasePayment implements interfaces.PaymentRepository. The request is
+// completed in the same transaction so funds never move without it. The
+// commission is on the full price, discount included, and the platform pays
+// the worker the discount out of promotions.
+func (c *paymentRepo) ReleasePayment(ctx context.Context, requestId int, commissionBps int) (domain.Payment, error) {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	defer tx.Rollback()
+
+	var paymentId int
+	query := `SELECT id_payment FROM payments WHERE request_id=$1 AND status=$2 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, requestId, domain.PaymentHeld).Scan(&paymentId)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.Payment{}, domain.ErrPaymentNotHeld
+	}
+	if err != nil {
+		return domain.Payment{}, err
+	}
+
+	var id int
+	query = `UPDATE requests SET status=$1, updated_at=NOW() WHERE id_requset=$2 AND status=$3 RETURNING id_requset;`
+	err = tx.QueryRowContext(ctx, query, domain.RequestCompleted, requestId, domain.RequestAccepted).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.Payment{}, domain.ErrStatusChanged
+	}
+	if err != nil {
+		return domain.Payment{}, err
+	}
+
+	query = `UPDATE payments SET status=$1, commission=(amount+discount)*$2/10000, released_at=NOW(), updated_at=NOW()
+				WHERE id_payment=$3 RETURNING ` + paymentColumns + `;`
+	payment, err := scanPayment(tx.QueryRowContext(ctx, query, domain.PaymentReleased, commissionBps, paymentId))
+	if err != nil {
+		return payment, err
+	}
+
+	err = postRelease(ctx, tx.Tx, requestId, payment.WorkerId, payment.Amount, payment.Commission, payment.Currency)
+	if err != nil {
+		return payment, err
+	}
+
+	if payment.Discount > 0 {
+		_, err = postLedger(ctx, tx.Tx, domain.LedgerTransaction{Kind: domain.LedgerPromotion, RequestId: &requestId},
+			transfer(domain.LedgerEntry{Account: domain.AccountPromotions}, domain.LedgerEntry{Account: domain.AccountWorker, OwnerId: payment.WorkerId}, payment.Discount, payment.Currency)...)
+		if err != nil {
+			return payment, err
+		}
+	}
+
+	return payment, tx.Commit()
+}
+
+// RefundReleased implements interfaces.PaymentRepository. The refund is taken
+// back from the worker and the platform in proportion to what each got of the
+// payment, even if that leaves the worker's wallet owing. The same share of a
+// promo code discount the worker was paid goes back to promotions.
+func (c *paymentRepo) RefundReleased(ctx context.Context, requestId int, amount int64, actorId int) (domain.Refund, error) {
+	refund := domain.Refund{RequestId: requestId, Amount: amount, Status: domain.RefundPending}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return refund, err
+	}
+	defer tx.Rollback()
+
+	var workerId int
+	var paid, discount, commission, refunded int64
+	query := `SELECT id_payment, worker_id, amount, discount, commission, refunded, currency FROM payments WHERE request_id=$1 AND status=$2 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, requestId, domain.PaymentReleased).Scan(
+		&refund.PaymentId,
+		&workerId,
+		&paid,
+		&discount,
+		&commission,
+		&refunded,
+		&refund.Currency,
+	)
+	if err != nil && err == sql.ErrNoRows {
+		return refund, domain.ErrPaymentNotReleased
+	}
+	if err != nil {
+		return refund, err
+	}
+	if amount > paid-refunded {
+		return refund, domain.ErrRefundTooLarge
+	}
+
+	commissionShare := commission * amount / (paid - refunded)
+	discountShare := discount * amount / (paid - refunded)
+	status := domain.PaymentReleased
+	if refunded+amount == paid {
+		status = domain.PaymentRefunded
+	}
+	query = `UPDATE payments SET status=$1, refunded=refunded+$2, commission=commission-$3, discount=discount-$4, updated_at=NOW() WHERE id_payment=$5;`
+	if _, err = tx.ExecContext(ctx, query, status, amount, commissionShare, discountShare, refund.PaymentId); err != nil {
+		return refund, err
+	}
+
+	if err = insertRefund(ctx, tx, &refund); err != nil {
+		return refund, err
+	}
+
+	entries := []domain.LedgerEntry{
+		{Account: domain.AccountWorker, OwnerId: workerId, Amount: commissionShare - amount - discountShare, Currency: refund.Currency},
+		{Account: domain.AccountCommission, Amount: -commissionShare, Currency: refund.Currency},
+		{Account: domain.AccountRefunds, Amount: amount, Currency: refund.Currency},
+	}
+	if discountShare > 0 {
+		entries = append(entries, domain.LedgerEntry{Account: domain.AccountPromotions, Amount: discountShare, Currency: refund.Currency})
+	}
+	_, err = postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerRefund, RequestId: &requestId, ActorId: actorId}, entries...)
+	if err != nil {
+		return refund, err
+	}
+	return refund, tx.Commit()
+}
+
+// MarkRefund implements interfaces.PaymentRepository
+func (c *paymentRepo) MarkRefund(ctx context.Context, refundId int, status string, gatewayRefundId string, lastError string) error {
+	query := `UPDATE refunds SET status=$1, gateway_refund_id=$2, last_error=$3, updated_at=NOW() WHERE id_refund=$4;`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, status, gatewayRefundId, lastError, refundId)
+	return err
+}
+
+// insertRefund records a refund to send inside the transaction that takes the money back
+func insertRefund(ctx context.Context, tx *sql.Tx, refund *domain.Refund) error {
+	query := `INSERT INTO refunds (payment_id, request_id, amount, currency, status, gateway_refund_id, last_error, created_at, updated_at)
+				VALUES ($1,$2,$3,$4,$5,'','',NOW(),NOW()) RETURNING id_refund, created_at;`
+	return tx.QueryRowContext(ctx, query,
+		refund.PaymentId,
+		refund.RequestId,
+		refund.Amount,
+		refund.Currency,
+		refund.Status,
+	).Scan(
+		&refund.IdRefund,
+		&refund.CreatedAt,
+	)
+}
+
+func scanPayment(row rowScanner, extra ...interface{}) (domain.Payment, error) {
+	var payment domain.Payment
+	var heldAt, releasedAt sql.NullTime
+	dest := []interface{}{
+		&payment.IdPayment,
+		&payment.RequestId,
+		&payment.UserId,
+		&payment.WorkerId,
+		&payment.Amount,
+		&payment.Discount,
+		&payment.Commission,
+		&payment.Currency,
+		&payment.Gateway,
+		&payment.GatewayOrderId,
+		&payment.GatewayPaymentId,
+		&payment.Status,
+		&heldAt,
+		&releasedAt,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if heldAt.Valid {
+		payment.HeldAt = &heldAt.Time
+	}
+	if releasedAt.Valid {
+		payment.ReleasedAt = &releasedAt.Time
+	}
+	return payment, err
+}
+
+func NewPaymentRepo(db *sql.DB) interfaces.PaymentRepository {
+	return &paymentRepo{
+		db: db,
+	}
+}
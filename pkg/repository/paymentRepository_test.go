@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentRepo_ApplyGatewayEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	paymentRepo := NewPaymentRepo(db)
+
+	eventQuery := "INSERT INTO payment_events"
+	updateQuery := "UPDATE payments SET status=\\$1, gateway_payment_id=\\$2, held_at=NOW\\(\\)"
+
+	tests := []struct {
+		name            string
+		mockQueryFunc   func()
+		expectedApplied bool
+		expectedErr     error
+	}{
+		{
+			name: "test first delivery holds the payment",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(eventQuery).WithArgs("fake", "evt_1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(updateQuery).WithArgs(domain.PaymentHeld, "pay_1", "fake", "order_1", domain.PaymentCreated).
+					WillReturnRows(sqlmock.NewRows([]string{"request_id", "amount", "currency"}).AddRow(1, int64(10000), "INR"))
+				mock.ExpectQuery("INSERT INTO ledger_transactions").WithArgs(domain.LedgerCapture, 1, nil, "", 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id_transaction", "created_at"}).AddRow(1, time.Now()))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountGateway, 0, int64(-10000), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountEscrow, 0, int64(10000), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			expectedApplied: true,
+			expectedErr:     nil,
+		},
+		{
+			name: "test redelivered event is ignored",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(eventQuery).WithArgs("fake", "evt_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedApplied: false,
+			expectedErr:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			applied, actualErr := paymentRepo.ApplyGatewayEvent(ctx, "fake", "evt_1", "order_1", "pay_1", domain.PaymentHeld)
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+			assert.Equal(t, tt.expectedApplied, applied)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPaymentRepo_ReleasePayment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	paymentRepo := NewPaymentRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id_payment FROM payments WHERE request_id=\\$1 AND status=\\$2 FOR UPDATE;").
+		WithArgs(7, domain.PaymentHeld).
+		WillReturnRows(sqlmock.NewRows([]string{"id_payment"}))
+	mock.ExpectRollback()
+
+	_, actualErr := paymentRepo.ReleasePayment(context.Background(), 7, 1000)
+
+	assert.Equal(t, domain.ErrPaymentNotHeld, actualErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepo_RefundReleased(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	paymentRepo := NewPaymentRepo(db)
+
+	selectQuery := "SELECT id_payment, worker_id, amount, discount, commission, refunded, currency FROM payments"
+	updateQuery := "UPDATE payments SET status=\\$1, refunded=refunded\\+\\$2, commission=commission-\\$3, discount=discount-\\$4"
+
+	tests := []struct {
+		name          string
+		amount        int64
+		mockQueryFunc func()
+		expectedErr   bool
+	}{
+		{
+			name:   "test part refund is shared by worker and commission",
+			amount: 4000,
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs(7, domain.PaymentReleased).
+					WillReturnRows(sqlmock.NewRows([]string{"id_payment", "worker_id", "amount", "discount", "commission", "refunded", "currency"}).
+						AddRow(3, 5, int64(10000), int64(0), int64(1000), int64(0), "INR"))
+				mock.ExpectExec(updateQuery).WithArgs(domain.PaymentReleased, int64(4000), int64(400), int64(0), 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO refunds").WithArgs(3, 7, int64(4000), "INR", domain.RefundPending).
+					WillReturnRows(sqlmock.NewRows([]string{"id_refund", "created_at"}).AddRow(1, time.Now()))
+				mock.ExpectQuery("INSERT INTO ledger_transactions").WithArgs(domain.LedgerRefund, 7, nil, "", 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id_transaction", "created_at"}).AddRow(1, time.Now()))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountWorker, 5, int64(-3600), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountCommission, 0, int64(-400), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountRefunds, 0, int64(4000), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "test refund above what is left fails",
+			amount: 7000,
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs(7, domain.PaymentReleased).
+					WillReturnRows(sqlmock.NewRows([]string{"id_payment", "worker_id", "amount", "discount", "commission", "refunded", "currency"}).
+						AddRow(3, 5, int64(10000), int64(0), int64(600), int64(4000), "INR"))
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+
+			_, actualErr := paymentRepo.RefundReleased(context.Background(), 7, tt.amount, 2)
+
+			assert.Equal(t, tt.expectedErr, actualErr != nil)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+const promoCodeColumns = `id_promo_code, code, description, kind, value, max_discount, min_amount, category_id, first_booking_only, usage_limit, per_user_limit, used, starts_at, ends_at, active, created_by, created_at, updated_at`
+
+type promoRepo struct {
+	db *sql.DB
+}
+
+// CreatePromoCode implements interfaces.PromoRepository
+func (c *promoRepo) CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	query := `INSERT INTO promo_codes (code, description, kind, value, max_discount, min_amount, category_id, first_booking_only, usage_limit, per_user_limit, used, starts_at, ends_at, active, created_by, created_at, updated_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,0,$11,$12,$13,$14,NOW(),NOW())
+				ON CONFLICT (code) DO NOTHING RETURNING ` + promoCodeColumns + `;`
+	created, err := scanPromoCode(conn(ctx, c.db).QueryRowContext(ctx, query,
+		promo.Code,
+		promo.Description,
+		promo.Kind,
+		promo.Value,
+		promo.MaxDiscount,
+		promo.MinAmount,
+		promo.CategoryId,
+		promo.FirstBookingOnly,
+		promo.UsageLimit,
+		promo.PerUserLimit,
+		promo.StartsAt,
+		promo.EndsAt,
+		promo.Active,
+		promo.CreatedBy,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return created, domain.ErrPromoCodeTaken
+	}
+	return created, err
+}
+
+// UpdatePromoCode implements interfaces.PromoRepository. The code itself and
+// how often it was used are kept.
+func (c *promoRepo) UpdatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	query := `UPDATE promo_codes SET description=$1, kind=$2, value=$3, max_discount=$4, min_amount=$5, category_id=$6, first_booking_only=$7,
+				usage_limit=$8, per_user_limit=$9, starts_at=$10, ends_at=$11, active=$12, updated_at=NOW()
+				WHERE id_promo_code=$13 RETURNING ` + promoCodeColumns + `;`
+	updated, err := scanPromoCode(conn(ctx, c.db).QueryRowContext(ctx, query,
+		promo.Description,
+		promo.Kind,
+		promo.Value,
+		promo.MaxDiscount,
+		promo.MinAmount,
+		promo.CategoryId,
+		promo.FirstBookingOnly,
+		promo.UsageLimit,
+		promo.PerUserLimit,
+		promo.StartsAt,
+		promo.EndsAt,
+		promo.Active,
+		promo.IdPromoCode,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return updated, domain.ErrNoPromoCode
+	}
+	return updated, err
+}
+
+// FindPromoCode implements interfaces.PromoRepository
+func (c *promoRepo) FindPromoCode(ctx context.Context, code string) (domain.PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE code=$1;`
+	promo, err := scanPromoCode(conn(ctx, c.db).QueryRowContext(ctx, query, code))
+	if err != nil && err == sql.ErrNoRows {
+		return promo, domain.ErrNoPromoCode
+	}
+	return promo, err
+}
+
+// ListPromoCodes implements interfaces.PromoRepository
+func (c *promoRepo) ListPromoCodes(ctx context.Context, filter utils.Filter) ([]domain.PromoCode, utils.Metadata, error) {
+	var promos []domain.PromoCode
+	var total int
+
+	keyset, args := filter.KeysetCondition("created_at", "id_promo_code", 1)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + promoCodeColumns + `, COUNT(*) OVER() FROM promo_codes
+				WHERE TRUE` + keyset + ` ORDER BY created_at DESC, id_promo_code DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return promos, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		promo, err := scanPromoCode(rows, &total)
+		if err != nil {
+			return promos, utils.Metadata{}, err
+		}
+		promos = append(promos, promo)
+	}
+	if err = rows.Err(); err != nil {
+		return promos, utils.Metadata{}, err
+	}
+
+	fetched := len(promos)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		promos = promos[:filter.PageSize]
+	}
+	if len(promos) > 0 {
+		last = utils.Cursor{Id: promos[len(promos)-1].IdPromoCode, CreatedAt: promos[len(promos)-1].CreatedAt}
+	}
+	return promos, pageMetadata(filter, fetched, total, last), nil
+}
+
+// CountRedemptions implements interfaces.PromoRepository
+func (c *promoRepo) CountRedemptions(ctx context.Context, promoCodeId int, userId int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM promo_redemptions WHERE promo_code_id=$1 AND user_id=$2;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, promoCodeId, userId).Scan(&count)
+	return count, err
+}
+
+// CountUserBookings implements interfaces.PromoRepository. Bookings that were
+// rejected or cancelled do not count.
+func (c *promoRepo) CountUserBookings(ctx context.Context, userId int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM requests WHERE user_id=$1 AND status NOT IN ($2,$3);`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, userId, domain.RequestRejected, domain.RequestCancelled).Scan(&count)
+	return count, err
+}
+
+// ReleaseRedemption implements interfaces.PromoRepository
+func (c *promoRepo) ReleaseRedemption(ctx context.Context, requestId int) error {
+	query := `WITH released AS (DELETE FROM promo_redemptions WHERE request_id=$1 RETURNING promo_code_id)
+				UPDATE promo_codes SET used=used-1, updated_at=NOW() WHERE id_promo_code IN (SELECT promo_code_id FROM released);`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, requestId)
+	return err
+}
+
+// redeemPromo uses up a promo code for a request inside the transaction that
+// creates it. Locking the code's row while it is counted keeps concurrent
+// bookings from going over either usage limit.
+func redeemPromo(ctx context.Context, tx *sql.Tx, promoCodeId int, userId int, requestId int, discount int64) error {
+	var perUserLimit int
+	query := `UPDATE promo_codes SET used=used+1, updated_at=NOW()
+				WHERE id_promo_code=$1 AND active AND (usage_limit=0 OR used<usage_limit) RETURNING per_user_limit;`
+	err := tx.QueryRowContext(ctx, query, promoCodeId).Scan(&perUserLimit)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrPromoUsedUp
+	}
+	if err != nil {
+		return err
+	}
+
+	if perUserLimit > 0 {
+		var used int
+		query = `SELECT COUNT(*) FROM promo_redemptions WHERE promo_code_id=$1 AND user_id=$2;`
+		if err = tx.QueryRowContext(ctx, query, promoCodeId, userId).Scan(&used); err != nil {
+			return err
+		}
+		if used >= perUserLimit {
+			return domain.ErrPromoUsed
+		}
+	}
+
+	query = `INSERT INTO promo_redemptions (promo_code_id, user_id, request_id, discount, created_at) VALUES ($1,$2,$3,$4,NOW());`
+	_, err = tx.ExecContext(ctx, query, promoCodeId, userId, requestId, discount)
+	return err
+}
+
+func scanPromoCode(row rowScanner, extra ...interface{}) (domain.PromoCode, error) {
+	var promo domain.PromoCode
+	var categoryId sql.NullInt64
+	dest := []interface{}{
+		&promo.IdPromoCode,
+		&promo.Code,
+		&promo.Description,
+		&promo.Kind,
+		&promo.Value,
+		&promo.MaxDiscount,
+		&promo.MinAmount,
+		&categoryId,
+		&promo.FirstBookingOnly,
+		&promo.UsageLimit,
+		&promo.PerUserLimit,
+		&promo.Used,
+		&promo.StartsAt,
+		&promo.EndsAt,
+		&promo.Active,
+		&promo.CreatedBy,
+		&promo.CreatedAt,
+		&promo.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if categoryId.Valid {
+		id := int(categoryId.Int64)
+		promo.CategoryId = &id
+	}
+	return promo, err
+}
+
+func NewPromoRepo(db *sql.DB) interfaces.PromoRepository {
+	return &promoRepo{
+		db: db,
+	}
+}
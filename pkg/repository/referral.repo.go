@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+const referralColumns = `id_referral, referrer_id, referee_id, code, device_id, status, reject_reason, reward, request_id, rewarded_at, created_at`
+
+type referralRepo struct {
+	db *sql.DB
+}
+
+// FindCode implements interfaces.ReferralRepository
+func (c *referralRepo) FindCode(ctx context.Context, userId int) (domain.ReferralCode, error) {
+	code := domain.ReferralCode{UserId: userId}
+	query := `SELECT code, created_at FROM referral_codes WHERE user_id=$1;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, userId).Scan(&code.Code, &code.CreatedAt)
+	if err != nil && err == sql.ErrNoRows {
+		return code, domain.ErrNoReferralCode
+	}
+	return code, err
+}
+
+// CreateCode implements interfaces.ReferralRepository. An account keeps the
+// code it already has, a code another account holds is refused.
+func (c *referralRepo) CreateCode(ctx context.Context, userId int, code string) (domain.ReferralCode, error) {
+	query := `INSERT INTO referral_codes (user_id, code, created_at) VALUES ($1,$2,NOW()) ON CONFLICT DO NOTHING;`
+	if _, err := conn(ctx, c.db).ExecContext(ctx, query, userId, code); err != nil {
+		return domain.ReferralCode{}, err
+	}
+	created, err := c.FindCode(ctx, userId)
+	if err != nil && errors.Is(err, domain.ErrNoReferralCode) {
+		return created, domain.ErrReferralTaken
+	}
+	return created, err
+}
+
+// FindReferrer implements interfaces.ReferralRepository
+func (c *referralRepo) FindReferrer(ctx context.Context, code string) (domain.User, error) {
+	var user domain.User
+	query := `SELECT u.id_user, u.phone, u.user_type FROM referral_codes r JOIN users u ON u.id_user=r.user_id WHERE r.code=$1;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, code).Scan(
+		&user.IdUser,
+		&user.Phone,
+		&user.UserType,
+	)
+	if err != nil && err == sql.ErrNoRows {
+		return user, domain.ErrNoReferralCode
+	}
+	return user, err
+}
+
+// AddSignupDevice implements interfaces.ReferralRepository
+func (c *referralRepo) AddSignupDevice(ctx context.Context, userId int, deviceId string) error {
+	query := `INSERT INTO signup_devices (user_id, device_id, created_at) VALUES ($1,$2,NOW());`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query, userId, deviceId)
+	return err
+}
+
+// DeviceSeen implements interfaces.ReferralRepository. A device is seen if the
+// referrer signed up from it or it already brought in a referral.
+func (c *referralRepo) DeviceSeen(ctx context.Context, deviceId string, referrerId int) (bool, error) {
+	var seen bool
+	query := `SELECT EXISTS (SELECT 1 FROM signup_devices WHERE device_id=$1 AND user_id=$2)
+				OR EXISTS (SELECT 1 FROM referrals WHERE device_id=$1);`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, deviceId, referrerId).Scan(&seen)
+	return seen, err
+}
+
+// CreateReferral implements interfaces.ReferralRepository
+func (c *referralRepo) CreateReferral(ctx context.Context, referral domain.Referral) (domain.Referral, error) {
+	query := `INSERT INTO referrals (referrer_id, referee_id, code, device_id, status, reject_reason, reward, created_at)
+				VALUES ($1,$2,$3,$4,$5,$6,0,NOW()) ON CONFLICT (referee_id) DO NOTHING RETURNING ` + referralColumns + `;`
+	created, err := scanReferral(conn(ctx, c.db).QueryRowContext(ctx, query,
+		referral.ReferrerId,
+		referral.RefereeId,
+		referral.Code,
+		referral.DeviceId,
+		referral.Status,
+		referral.RejectReason,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return created, domain.ErrAlreadyReferred
+	}
+	return created, err
+}
+
+// RewardReferral implements interfaces.ReferralRepository. It returns nil if
+// the account has no referral waiting for a reward. Workers are paid into
+// their wallet and users get credit.
+func (c *referralRepo) RewardReferral(ctx context.Context, refereeId int, requestId int, reward int64) (*domain.Referral, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + referralColumns + ` FROM referrals WHERE referee_id=$1 AND status=$2 FOR UPDATE;`
+	referral, err := scanReferral(tx.QueryRowContext(ctx, query, refereeId, domain.ReferralPending))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.LedgerEntry{{Account: domain.AccountReferrals, Amount: -2 * reward, Currency: domain.DefaultCurrency}}
+	for _, userId := range []int{referral.ReferrerId, referral.RefereeId} {
+		var userType string
+		query = `SELECT user_type FROM users WHERE id_user=$1;`
+		if err = tx.QueryRowContext(ctx, query, userId).Scan(&userType); err != nil {
+			return nil, err
+		}
+		account := domain.AccountCredit
+		if userType == domain.RoleWorker {
+			account = domain.AccountWorker
+		}
+		entries = append(entries, domain.LedgerEntry{Account: account, OwnerId: userId, Amount: reward, Currency: domain.DefaultCurrency})
+	}
+
+	_, err = postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerReferral, RequestId: &requestId, Memo: "Referral " + referral.Code}, entries...)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE referrals SET status=$1, reward=$2, request_id=$3, rewarded_at=NOW() WHERE id_referral=$4 RETURNING ` + referralColumns + `;`
+	referral, err = scanReferral(tx.QueryRowContext(ctx, query, domain.ReferralRewarded, reward, requestId, referral.IdReferral))
+	if err != nil {
+		return nil, err
+	}
+	return &referral, tx.Commit()
+}
+
+// Summary implements interfaces.ReferralRepository
+func (c *referralRepo) Summary(ctx context.Context, userId int) (domain.ReferralSummary, error) {
+	summary := domain.ReferralSummary{Currency: domain.DefaultCurrency}
+	query := `SELECT COUNT(*) FILTER (WHERE status=$2), COUNT(*) FILTER (WHERE status=$3), COALESCE(SUM(reward) FILTER (WHERE status=$3), 0),
+				COALESCE((SELECT SUM(amount) FROM ledger_entries WHERE account=$4 AND owner_id=$1), 0)
+				FROM referrals WHERE referrer_id=$1;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, userId, domain.ReferralPending, domain.ReferralRewarded, domain.AccountCredit).Scan(
+		&summary.Pending,
+		&summary.Rewarded,
+		&summary.Earned,
+		&summary.Credit,
+	)
+	return summary, err
+}
+
+func scanReferral(row rowScanner) (domain.Referral, error) {
+	var referral domain.Referral
+	var requestId sql.NullInt64
+	var rewardedAt sql.NullTime
+	err := row.Scan(
+		&referral.IdReferral,
+		&referral.ReferrerId,
+		&referral.RefereeId,
+		&referral.Code,
+		&referral.DeviceId,
+		&referral.Status,
+		&referral.RejectReason,
+		&referral.Reward,
+		&requestId,
+		&rewardedAt,
+		&referral.CreatedAt,
+	)
+	if requestId.Valid {
+		id := int(requestId.Int64)
+		referral.RequestId = &id
+	}
+	if rewardedAt.Valid {
+		referral.RewardedAt = &rewardedAt.Time
+	}
+	return referral, err
+}
+
+func NewReferralRepo(db *sql.DB) interfaces.ReferralRepository {
+	return &referralRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferralRepo_RewardReferral(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	referralRepo := NewReferralRepo(db)
+
+	columns := []string{"id_referral", "referrer_id", "referee_id", "code", "device_id", "status", "reject_reason", "reward", "request_id", "rewarded_at", "created_at"}
+	pendingQuery := "SELECT (.+) FROM referrals WHERE referee_id=\\$1 AND status=\\$2 FOR UPDATE;"
+	roleQuery := "SELECT user_type FROM users WHERE id_user=\\$1;"
+
+	tests := []struct {
+		name             string
+		mockQueryFunc    func()
+		expectedRewarded bool
+	}{
+		{
+			name: "test worker referrer and user referee are both rewarded",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(pendingQuery).WithArgs(4, domain.ReferralPending).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 3, 4, "ABCD2345", "device-1", domain.ReferralPending, "", int64(0), nil, nil, time.Now()))
+				mock.ExpectQuery(roleQuery).WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"user_type"}).AddRow(domain.RoleWorker))
+				mock.ExpectQuery(roleQuery).WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"user_type"}).AddRow(""))
+				mock.ExpectQuery("INSERT INTO ledger_transactions").WithArgs(domain.LedgerReferral, 9, nil, "Referral ABCD2345", 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id_transaction", "created_at"}).AddRow(1, time.Now()))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountReferrals, 0, int64(-20000), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountWorker, 3, int64(10000), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("INSERT INTO ledger_entries").WithArgs(1, domain.AccountCredit, 4, int64(10000), "INR", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectQuery("UPDATE referrals SET status=\\$1").WithArgs(domain.ReferralRewarded, int64(10000), 9, 1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 3, 4, "ABCD2345", "device-1", domain.ReferralRewarded, "", int64(10000), 9, time.Now(), time.Now()))
+				mock.ExpectCommit()
+			},
+			expectedRewarded: true,
+		},
+		{
+			name: "test account without a pending referral",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(pendingQuery).WithArgs(4, domain.ReferralPending).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectRollback()
+			},
+			expectedRewarded: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+
+			referral, actualErr := referralRepo.RewardReferral(context.Background(), 4, 9, 10000)
+
+			assert.NoError(t, actualErr)
+			assert.Equal(t, tt.expectedRewarded, referral != nil)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+)
+
+const subscriptionPlanColumns = `p.id_plan, p.name, p.billing_interval, p.price, p.currency, p.job_limit, p.priority, p.badge, p.active, p.created_at, p.updated_at`
+
+const subscriptionColumns = `s.id_subscription, s.worker_id, s.plan_id, s.status, s.auto_renew, s.period_start, s.period_end, s.grace_ends_at, s.created_at, s.updated_at`
+
+type subscriptionRepo struct {
+	db *sql.DB
+}
+
+// CreatePlan implements interfaces.SubscriptionRepository
+func (c *subscriptionRepo) CreatePlan(ctx context.Context, plan domain.SubscriptionPlan) (domain.SubscriptionPlan, error) {
+	query := `INSERT INTO subscription_plans AS p (name, billing_interval, price, currency, job_limit, priority, badge, active, created_at, updated_at)
+				VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NOW(),NOW())
+				ON CONFLICT (name) DO NOTHING RETURNING ` + subscriptionPlanColumns + `;`
+	created, err := scanSubscriptionPlan(conn(ctx, c.db).QueryRowContext(ctx, query,
+		plan.Name,
+		plan.Interval,
+		plan.Price,
+		plan.Currency,
+		plan.JobLimit,
+		plan.Priority,
+		plan.Badge,
+		plan.Active,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		return created, domain.ErrPlanNameTaken
+	}
+	return created, err
+}
+
+// UpdatePlan implements interfaces.SubscriptionRepository. The jobs of
+// current subscribers follow a change of the plan's priority right away,
+// other changes apply from their next renewal.
+func (c *subscriptionRepo) UpdatePlan(ctx context.Context, plan domain.SubscriptionPlan) (domain.SubscriptionPlan, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return plan, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE subscription_plans AS p SET name=$1, billing_interval=$2, price=$3, job_limit=$4, priority=$5, badge=$6, active=$7, updated_at=NOW()
+				WHERE id_plan=$8 AND NOT EXISTS (SELECT 1 FROM subscription_plans WHERE name=$1 AND id_plan<>$8)
+				RETURNING ` + subscriptionPlanColumns + `;`
+	updated, err := scanSubscriptionPlan(tx.QueryRowContext(ctx, query,
+		plan.Name,
+		plan.Interval,
+		plan.Price,
+		plan.JobLimit,
+		plan.Priority,
+		plan.Badge,
+		plan.Active,
+		plan.IdPlan,
+	))
+	if err != nil && err == sql.ErrNoRows {
+		var exists bool
+		query = `SELECT EXISTS (SELECT 1 FROM subscription_plans WHERE id_plan=$1);`
+		if err = tx.QueryRowContext(ctx, query, plan.IdPlan).Scan(&exists); err != nil {
+			return updated, err
+		}
+		if exists {
+			return updated, domain.ErrPlanNameTaken
+		}
+		return updated, domain.ErrNoPlan
+	}
+	if err != nil {
+		return updated, err
+	}
+
+	query = `UPDATE jobs SET priority=$1 WHERE id_worker IN (SELECT worker_id FROM subscriptions WHERE plan_id=$2 AND status IN ($3,$4));`
+	_, err = tx.ExecContext(ctx, query, updated.Priority, updated.IdPlan, domain.SubscriptionActive, domain.SubscriptionGrace)
+	if err != nil {
+		return updated, err
+	}
+	return updated, tx.Commit()
+}
+
+// FindPlan implements interfaces.SubscriptionRepository
+func (c *subscriptionRepo) FindPlan(ctx context.Context, planId int) (domain.SubscriptionPlan, error) {
+	query := `SELECT ` + subscriptionPlanColumns + ` FROM subscription_plans p WHERE p.id_plan=$1;`
+	plan, err := scanSubscriptionPlan(conn(ctx, c.db).QueryRowContext(ctx, query, planId))
+	if err != nil && err == sql.ErrNoRows {
+		return plan, domain.ErrNoPlan
+	}
+	return plan, err
+}
+
+// ListPlans implements interfaces.SubscriptionRepository
+func (c *subscriptionRepo) ListPlans(ctx context.Context, activeOnly bool) ([]domain.SubscriptionPlan, error) {
+	var plans []domain.SubscriptionPlan
+
+	query := `SELECT ` + subscriptionPlanColumns + ` FROM subscription_plans p WHERE p.active OR NOT $1 ORDER BY p.price, p.id_plan;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, activeOnly)
+	if err != nil {
+		return plans, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		plan, err := scanSubscriptionPlan(rows)
+		if err != nil {
+			return plans, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+// Subscribe implements interfaces.SubscriptionRepository. The first period
+// is paid out of the worker's wallet and the plan's priority is given to
+// their jobs. A worker holds one plan at a time.
+func (c *subscriptionRepo) Subscribe(ctx context.Context, workerId int, plan domain.SubscriptionPlan) (domain.Subscription, error) {
+	var subscription domain.Subscription
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return subscription, err
+	}
+	defer tx.Rollback()
+
+	if err = lockWorker(ctx, tx, workerId); err != nil {
+		return subscription, err
+	}
+
+	var current int
+	query := `SELECT COUNT(*) FROM subscriptions WHERE worker_id=$1 AND status IN ($2,$3);`
+	err = tx.QueryRowContext(ctx, query, workerId, domain.SubscriptionActive, domain.SubscriptionGrace).Scan(&current)
+	if err != nil {
+		return subscription, err
+	}
+	if current > 0 {
+		return subscription, domain.ErrHasPlan
+	}
+
+	if err = chargeSubscription(ctx, tx, workerId, plan); err != nil {
+		return subscription, err
+	}
+
+	start := time.Now().UTC()
+	query = `INSERT INTO subscriptions AS s (worker_id, plan_id, status, auto_renew, period_start, period_end, created_at, updated_at)
+				VALUES ($1,$2,$3,true,$4,$5,NOW(),NOW()) RETURNING ` + subscriptionColumns + `;`
+	subscription, err = scanSubscription(tx.QueryRowContext(ctx, query,
+		workerId,
+		plan.IdPlan,
+		domain.SubscriptionActive,
+		start,
+		plan.PeriodEnd(start),
+	))
+	if err != nil {
+		return subscription, err
+	}
+	subscription.Plan = &plan
+
+	_, err = tx.ExecContext(ctx, `UPDATE jobs SET priority=$1 WHERE id_worker=$2;`, plan.Priority, workerId)
+	if err != nil {
+		return subscription, err
+	}
+	return subscription, tx.Commit()
+}
+
+// CurrentSubscription implements interfaces.SubscriptionRepository
+func (c *subscriptionRepo) CurrentSubscription(ctx context.Context, workerId int) (domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `, ` + subscriptionPlanColumns + ` FROM subscriptions s
+				JOIN subscription_plans p ON p.id_plan=s.plan_id
+				WHERE s.worker_id=$1 AND s.status IN ($2,$3);`
+	subscription, err := scanSubscriptionWithPlan(conn(ctx, c.db).QueryRowContext(ctx, query, workerId, domain.SubscriptionActive, domain.SubscriptionGrace))
+	if err != nil && err == sql.ErrNoRows {
+		return subscription, domain.ErrNoSubscription
+	}
+	return subscription, err
+}
+
+// SetAutoRenew implements interfaces.SubscriptionRepository
+func (c *subscriptionRepo) SetAutoRenew(ctx context.Context, workerId int, autoRenew bool) (domain.Subscription, error) {
+	query := `UPDATE subscriptions s SET auto_renew=$1, updated_at=NOW()
+				FROM subscription_plans p
+				WHERE p.id_plan=s.plan_id AND s.worker_id=$2 AND s.status IN ($3,$4)
+				RETURNING ` + subscriptionColumns + `, ` + subscriptionPlanColumns + `;`
+	subscription, err := scanSubscriptionWithPlan(conn(ctx, c.db).QueryRowContext(ctx, query, autoRenew, workerId, domain.SubscriptionActive, domain.SubscriptionGrace))
+	if err != nil && err == sql.ErrNoRows {
+		return subscription, domain.ErrNoSubscription
+	}
+	return subscription, err
+}
+
+// DueSubscriptions implements interfaces.SubscriptionRepository. Those in
+// grace stay due so the renewal is retried until the grace period runs out.
+func (c *subscriptionRepo) DueSubscriptions(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	var ids []int
+
+	query := `SELECT id_subscription FROM subscriptions
+				WHERE (status=$1 AND period_end<=$3) OR status=$2
+				ORDER BY period_end LIMIT $4;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, domain.SubscriptionActive, domain.SubscriptionGrace, now, limit)
+	if err != nil {
+		return ids, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Renew implements interfaces.SubscriptionRepository. A subscription that
+// is due is charged for another period. When the wallet cannot cover it the
+// subscription goes into grace for graceDays, and is expired once that runs
+// out, as it is when it was not to be renewed or its plan was withdrawn.
+func (c *subscriptionRepo) Renew(ctx context.Context, subscriptionId int, now time.Time, graceDays int) (domain.Subscription, error) {
+	var subscription domain.Subscription
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return subscription, err
+	}
+	defer tx.Rollback()
+
+	// The worker is locked before the subscription, the same order Subscribe takes
+	var workerId int
+	query := `SELECT worker_id FROM subscriptions WHERE id_subscription=$1;`
+	err = tx.QueryRowContext(ctx, query, subscriptionId).Scan(&workerId)
+	if err != nil && err == sql.ErrNoRows {
+		return subscription, domain.ErrNoSubscription
+	}
+	if err != nil {
+		return subscription, err
+	}
+	if err = lockWorker(ctx, tx, workerId); err != nil {
+		return subscription, err
+	}
+
+	query = `SELECT ` + subscriptionColumns + `, ` + subscriptionPlanColumns + ` FROM subscriptions s
+				JOIN subscription_plans p ON p.id_plan=s.plan_id
+				WHERE s.id_subscription=$1 FOR UPDATE OF s;`
+	subscription, err = scanSubscriptionWithPlan(tx.QueryRowContext(ctx, query, subscriptionId))
+	if err != nil {
+		return subscription, err
+	}
+	plan := *subscription.Plan
+
+	switch {
+	case subscription.Status == domain.SubscriptionExpired:
+		return subscription, nil
+	case subscription.Status == domain.SubscriptionActive && subscription.PeriodEnd.After(now):
+		return subscription, nil
+	case !subscription.AutoRenew || !plan.Active:
+		return c.expire(ctx, tx, subscription)
+	}
+
+	err = chargeSubscription(ctx, tx, workerId, plan)
+	if errors.Is(err, domain.ErrLowBalance) {
+		if subscription.Status == domain.SubscriptionActive {
+			graceEndsAt := subscription.PeriodEnd.AddDate(0, 0, graceDays)
+			return c.updateSubscription(ctx, tx, subscription, domain.SubscriptionGrace, subscription.PeriodStart, subscription.PeriodEnd, &graceEndsAt)
+		}
+		if subscription.GraceEndsAt != nil && subscription.GraceEndsAt.After(now) {
+			return subscription, nil
+		}
+		return c.expire(ctx, tx, subscription)
+	}
+	if err != nil {
+		return subscription, err
+	}
+
+	// A period that would already be over starts afresh from now
+	start := subscription.PeriodEnd
+	if !plan.PeriodEnd(start).After(now) {
+		start = now
+	}
+	return c.updateSubscription(ctx, tx, subscription, domain.SubscriptionActive, start, plan.PeriodEnd(start), nil)
+}
+
+func (c *subscriptionRepo) updateSubscription(ctx context.Context, tx *sql.Tx, subscription domain.Subscription, status string, start time.Time, end time.Time, graceEndsAt *time.Time) (domain.Subscription, error) {
+	query := `UPDATE subscriptions s SET status=$1, period_start=$2, period_end=$3, grace_ends_at=$4, updated_at=NOW()
+				WHERE id_subscription=$5 RETURNING ` + subscriptionColumns + `;`
+	updated, err := scanSubscription(tx.QueryRowContext(ctx, query,
+		status,
+		start,
+		end,
+		graceEndsAt,
+		subscription.IdSubscription,
+	))
+	if err != nil {
+		return subscription, err
+	}
+	updated.Plan = subscription.Plan
+	return updated, tx.Commit()
+}
+
+// expire ends a subscription and downgrades the worker to the free tier.
+// Their jobs lose priority and the newest open ones above the free listing
+// limit are closed.
+func (c *subscriptionRepo) expire(ctx context.Context, tx *sql.Tx, subscription domain.Subscription) (domain.Subscription, error) {
+	_, err := tx.ExecContext(ctx, `UPDATE jobs SET priority=false WHERE id_worker=$1;`, subscription.WorkerId)
+	if err != nil {
+		return subscription, err
+	}
+
+	query := `UPDATE jobs SET openwork=false WHERE id_worker=$1 AND openwork AND id_job NOT IN (
+				SELECT id_job FROM jobs WHERE id_worker=$1 AND openwork ORDER BY id_job LIMIT $2
+			);`
+	_, err = tx.ExecContext(ctx, query, subscription.WorkerId, domain.FreeJobLimit)
+	if err != nil {
+		return subscription, err
+	}
+	return c.updateSubscription(ctx, tx, subscription, domain.SubscriptionExpired, subscription.PeriodStart, subscription.PeriodEnd, subscription.GraceEndsAt)
+}
+
+// chargeSubscription moves the price of a period of plan from the worker's
+// wallet, failing with domain.ErrLowBalance when it cannot cover it. The
+// worker must be locked by the caller.
+func chargeSubscription(ctx context.Context, tx *sql.Tx, workerId int, plan domain.SubscriptionPlan) error {
+	wallet, err := walletBalance(ctx, querier{tx}, workerId)
+	if err != nil {
+		return err
+	}
+	if plan.Price > wallet.Available {
+		return domain.ErrLowBalance
+	}
+
+	_, err = postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerSubscription, Memo: fmt.Sprintf("%s plan", plan.Name)},
+		transfer(domain.LedgerEntry{Account: domain.AccountWorker, OwnerId: workerId}, domain.LedgerEntry{Account: domain.AccountSubscriptions}, plan.Price, plan.Currency)...)
+	return err
+}
+
+// lockWorker locks the worker's row for the rest of tx
+func lockWorker(ctx context.Context, tx *sql.Tx, workerId int) error {
+	var id int
+	query := `SELECT id_user FROM users WHERE id_user=$1 FOR UPDATE;`
+	err := tx.QueryRowContext(ctx, query, workerId).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrNoWorker
+	}
+	return err
+}
+
+func scanSubscriptionPlan(row rowScanner, extra ...interface{}) (domain.SubscriptionPlan, error) {
+	var plan domain.SubscriptionPlan
+	dest := []interface{}{
+		&plan.IdPlan,
+		&plan.Name,
+		&plan.Interval,
+		&plan.Price,
+		&plan.Currency,
+		&plan.JobLimit,
+		&plan.Priority,
+		&plan.Badge,
+		&plan.Active,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return plan, err
+}
+
+func scanSubscription(row rowScanner, extra ...interface{}) (domain.Subscription, error) {
+	var subscription domain.Subscription
+	var graceEndsAt sql.NullTime
+	dest := []interface{}{
+		&subscription.IdSubscription,
+		&subscription.WorkerId,
+		&subscription.PlanId,
+		&subscription.Status,
+		&subscription.AutoRenew,
+		&subscription.PeriodStart,
+		&subscription.PeriodEnd,
+		&graceEndsAt,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if graceEndsAt.Valid {
+		subscription.GraceEndsAt = &graceEndsAt.Time
+	}
+	return subscription, err
+}
+
+func scanSubscriptionWithPlan(row rowScanner) (domain.Subscription, error) {
+	var plan domain.SubscriptionPlan
+	subscription, err := scanSubscription(row,
+		&plan.IdPlan,
+		&plan.Name,
+		&plan.Interval,
+		&plan.Price,
+		&plan.Currency,
+		&plan.JobLimit,
+		&plan.Priority,
+		&plan.Badge,
+		&plan.Active,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	)
+	subscription.Plan = &plan
+	return subscription, err
+}
+
+func NewSubscriptionRepo(db *sql.DB) interfaces.SubscriptionRepository {
+	return &subscriptionRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionRepo_Renew(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	subscriptionRepo := NewSubscriptionRepo(db)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	periodEnd := now.Add(-time.Hour)
+	graceOver := now.Add(-time.Minute)
+
+	workerQuery := "SELECT worker_id FROM subscriptions WHERE id_subscription=\\$1;"
+	lockQuery := "SELECT id_user FROM users WHERE id_user=\\$1 FOR UPDATE;"
+	selectQuery := "SELECT s.id_subscription, .* FOR UPDATE OF s;"
+	balanceQuery := "SELECT COALESCE\\(\\(SELECT SUM\\(amount\\) FROM ledger_entries"
+	updateQuery := "UPDATE subscriptions s SET status=\\$1"
+
+	subscriptionColumns := []string{"id_subscription", "worker_id", "plan_id", "status", "auto_renew", "period_start", "period_end", "grace_ends_at", "created_at", "updated_at",
+		"id_plan", "name", "billing_interval", "price", "currency", "job_limit", "priority", "badge", "active", "created_at", "updated_at"}
+	subscriptionRow := func(status string, graceEndsAt interface{}) *sqlmock.Rows {
+		return sqlmock.NewRows(subscriptionColumns).
+			AddRow(7, 5, 2, status, true, periodEnd.AddDate(0, -1, 0), periodEnd, graceEndsAt, now, now,
+				2, "Pro", domain.IntervalMonthly, int64(49900), "INR", 10, true, "Pro", true, now, now)
+	}
+	updatedRow := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows(subscriptionColumns[:10]).AddRow(7, 5, 2, status, true, periodEnd, periodEnd.AddDate(0, 1, 0), nil, now, now)
+	}
+	expectLocks := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery(workerQuery).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"worker_id"}).AddRow(5))
+		mock.ExpectQuery(lockQuery).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(5))
+	}
+
+	tests := []struct {
+		name           string
+		mockQueryFunc  func()
+		expectedStatus string
+	}{
+		{
+			name: "test a covered renewal starts the next period",
+			mockQueryFunc: func() {
+				expectLocks()
+				mock.ExpectQuery(selectQuery).WithArgs(7).WillReturnRows(subscriptionRow(domain.SubscriptionActive, nil))
+				mock.ExpectQuery(balanceQuery).WithArgs(domain.AccountWorker, 5, domain.PayoutRequested).
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "pending"}).AddRow(int64(100000), int64(0)))
+				expectLedger(mock, domain.LedgerSubscription, domain.AccountWorker, 5, domain.AccountSubscriptions, 0, 49900)
+				mock.ExpectQuery(updateQuery).WithArgs(domain.SubscriptionActive, periodEnd, periodEnd.AddDate(0, 1, 0), nil, 7).
+					WillReturnRows(updatedRow(domain.SubscriptionActive))
+				mock.ExpectCommit()
+			},
+			expectedStatus: domain.SubscriptionActive,
+		},
+		{
+			name: "test an uncovered renewal goes into grace",
+			mockQueryFunc: func() {
+				expectLocks()
+				mock.ExpectQuery(selectQuery).WithArgs(7).WillReturnRows(subscriptionRow(domain.SubscriptionActive, nil))
+				mock.ExpectQuery(balanceQuery).WithArgs(domain.AccountWorker, 5, domain.PayoutRequested).
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "pending"}).AddRow(int64(1000), int64(0)))
+				mock.ExpectQuery(updateQuery).WithArgs(domain.SubscriptionGrace, sqlmock.AnyArg(), periodEnd, sqlmock.AnyArg(), 7).
+					WillReturnRows(updatedRow(domain.SubscriptionGrace))
+				mock.ExpectCommit()
+			},
+			expectedStatus: domain.SubscriptionGrace,
+		},
+		{
+			name: "test the worker is downgraded once grace runs out",
+			mockQueryFunc: func() {
+				expectLocks()
+				mock.ExpectQuery(selectQuery).WithArgs(7).WillReturnRows(subscriptionRow(domain.SubscriptionGrace, graceOver))
+				mock.ExpectQuery(balanceQuery).WithArgs(domain.AccountWorker, 5, domain.PayoutRequested).
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "pending"}).AddRow(int64(1000), int64(0)))
+				mock.ExpectExec("UPDATE jobs SET priority=false").WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("UPDATE jobs SET openwork=false").WithArgs(5, domain.FreeJobLimit).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(updateQuery).WithArgs(domain.SubscriptionExpired, sqlmock.AnyArg(), periodEnd, sqlmock.AnyArg(), 7).
+					WillReturnRows(updatedRow(domain.SubscriptionExpired))
+				mock.ExpectCommit()
+			},
+			expectedStatus: domain.SubscriptionExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			subscription, actualErr := subscriptionRepo.Renew(ctx, 7, now, domain.DefaultGraceDays)
+
+			assert.NoError(t, actualErr)
+			assert.Equal(t, tt.expectedStatus, subscription.Status)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
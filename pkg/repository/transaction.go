@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/lib/pq"
+)
+
+// maxTxAttempts is how many times a unit of work is tried when the database
+// gives up on it over a conflict with another transaction, and txRetryDelay
+// how long the first retry waits, later ones waiting longer
+const (
+	maxTxAttempts = 3
+	txRetryDelay  = 50 * time.Millisecond
+)
+
+// txKey is the context key of the transaction a unit of work runs in
+type txKey struct{}
+
+// sqlQuerier is what a query runs on, the pool or a transaction
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type transactor struct {
+	db *sql.DB
+}
+
+// WithinTx implements interfaces.Transactor. The transaction is REPEATABLE
+// READ, so the work sees the database as it was at its first query. It is
+// rolled back when it fails or panics, and run again from the start when the
+// transaction fails to serialize with a concurrent one or deadlocks, so it
+// should have no effect beyond its queries. Work started within a unit of
+// work joins it.
+//
+// Repository methods that begin a transaction of their own with beginTx join
+// it as well. Those that call BeginTx keep to theirs, so they don't belong in
+// a unit of work.
+func (c *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = c.attempt(ctx, fn)
+		if attempt == maxTxAttempts || !retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * txRetryDelay):
+		}
+	}
+}
+
+// attempt runs the work once in a new transaction
+func (c *transactor) attempt(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// retryable reports whether a transaction failed only for clashing with
+// another one, in which case running it again can succeed
+func retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+}
+
+// repoTx is a transaction a repository method runs its queries in. Joined to
+// a unit of work, committing and rolling back are left to the unit of work.
+type repoTx struct {
+	*sql.Tx
+	joined bool
+}
+
+func (t repoTx) Commit() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t repoTx) Rollback() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+// beginTx joins the unit of work ctx belongs to, or begins a transaction of
+// the method's own outside of one
+func beginTx(ctx context.Context, db *sql.DB) (repoTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return repoTx{Tx: tx, joined: true}, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	return repoTx{Tx: tx}, err
+}
+
+// conn is the transaction of the unit of work ctx belongs to, or the pool
+// outside of one. The errors of its queries come translated.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return querier{tx}
+	}
+	return querier{db}
+}
+
+func NewTransactor(db *sql.DB) interfaces.Transactor {
+	return &transactor{
+		db: db,
+	}
+}
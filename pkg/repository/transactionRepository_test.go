@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactor_WithinTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	transactor := NewTransactor(db)
+	userRepo := NewUserRepo(db)
+	profile := domain.UserData{UserId: 1, Email: "test@gmail.com", FirstName: "test", LastName: "user"}
+
+	updateMail := "update users set email = \\$1 where id_user=\\$2 RETURNING id_user;"
+	addProfile := "INSERT INTO profiles"
+
+	work := func(ctx context.Context) error {
+		if err := userRepo.UpdateMail(ctx, profile.Email, profile.UserId); err != nil {
+			return err
+		}
+		return userRepo.AddProfile(ctx, profile)
+	}
+
+	tests := []struct {
+		name        string
+		beforeTest  func()
+		work        func(ctx context.Context) error
+		expectedErr error
+	}{
+		{
+			name: "test both writes are committed together",
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(1))
+				mock.ExpectQuery(addProfile).WillReturnRows(sqlmock.NewRows([]string{"id_profie"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			work: work,
+		},
+		{
+			name: "test a failed write rolls the other back",
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(1))
+				mock.ExpectQuery(addProfile).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			work:        work,
+			expectedErr: errors.New("db error"),
+		},
+		{
+			name: "test a deadlock is tried again",
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnError(&pq.Error{Code: "40P01"})
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(1))
+				mock.ExpectQuery(addProfile).WillReturnRows(sqlmock.NewRows([]string{"id_profie"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			work: work,
+		},
+		{
+			name: "test a serialization failure is tried again",
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnError(&pq.Error{Code: "40001"})
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(1))
+				mock.ExpectQuery(addProfile).WillReturnRows(sqlmock.NewRows([]string{"id_profie"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			work: work,
+		},
+		{
+			name: "test a deadlock is given up on after the last attempt",
+			beforeTest: func() {
+				for i := 0; i < maxTxAttempts; i++ {
+					mock.ExpectBegin()
+					mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnError(&pq.Error{Code: "40P01", Message: "deadlock detected"})
+					mock.ExpectRollback()
+				}
+			},
+			work:        work,
+			expectedErr: &pq.Error{Code: "40P01", Message: "deadlock detected"},
+		},
+		{
+			name: "test work started within a unit of work joins it",
+			beforeTest: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(updateMail).WithArgs(profile.Email, 1).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(1))
+				mock.ExpectQuery(addProfile).WillReturnRows(sqlmock.NewRows([]string{"id_profie"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			work: func(ctx context.Context) error {
+				return transactor.WithinTx(ctx, work)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.beforeTest()
+			ctx := context.Background()
+
+			actualErr := transactor.WithinTx(ctx, tt.work)
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestTransactor_WithinTxJoinedByRepositoryTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	invoiceRepo := NewInvoiceRepo(db)
+	paymentRepo := NewPaymentRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM request_materials WHERE request_id=\\$1;").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO request_materials").WithArgs(7, "pipe", int64(300)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id_payment FROM payments WHERE request_id=\\$1 AND status=\\$2 FOR UPDATE;").
+		WithArgs(7, domain.PaymentHeld).
+		WillReturnRows(sqlmock.NewRows([]string{"id_payment"}))
+	mock.ExpectRollback()
+
+	actualErr := NewTransactor(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := invoiceRepo.SetMaterials(ctx, 7, []domain.RequestMaterial{{RequestId: 7, Description: "pipe", Amount: 300}}); err != nil {
+			return err
+		}
+		_, err := paymentRepo.ReleasePayment(ctx, 7, 1000)
+		return err
+	})
+
+	assert.Equal(t, domain.ErrPaymentNotHeld, actualErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactor_WithinTxPanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		NewTransactor(db).WithinTx(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+
+	tests := []struct {
+		name        string
+		err         error
+		expectedErr error
+	}{
+		{name: "test a unique violation", err: &pq.Error{Code: "23505"}, expectedErr: domain.ErrAlreadyExists},
+		{name: "test a reference to a missing record", err: &pq.Error{Code: "23503", Detail: `Key (job_id)=(9) is not present in table "jobs".`}, expectedErr: domain.ErrReferenceNotFound},
+		{name: "test deleting a record still referred to", err: &pq.Error{Code: "23503", Detail: `Key (id_job)=(9) is still referenced from table "requsets".`}, expectedErr: domain.ErrStillReferenced},
+		{name: "test other errors are left alone", err: serialization, expectedErr: serialization},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualErr := translate(tt.err)
+
+			assert.ErrorIs(t, actualErr, tt.expectedErr)
+			assert.ErrorIs(t, actualErr, tt.err)
+		})
+	}
+}
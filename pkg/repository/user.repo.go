@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 
 	"github.com/fazilnbr/project-workey/pkg/domain"
 	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
@@ -17,7 +16,7 @@ type userRepo struct {
 func (c *userRepo) GetProfile(ctx context.Context, userId int) (domain.Profile, error) {
 	var userProfile domain.Profile
 	query := `SELECT id_profie,user_id,first_name,last_name,gender,dob,profile_photo FROM profiles WHERE user_id=$1;`
-	err := c.db.QueryRow(query,
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
 		userId).Scan(
 		&userProfile.IdProfie,
 		&userProfile.UserId,
@@ -28,7 +27,7 @@ func (c *userRepo) GetProfile(ctx context.Context, userId int) (domain.Profile,
 		&userProfile.ProfilePhoto,
 	)
 	if err != nil && err == sql.ErrNoRows {
-		return userProfile, errors.New("there is no user")
+		return userProfile, domain.ErrNoUser
 	}
 
 	return userProfile, err
@@ -39,12 +38,12 @@ func (c *userRepo) UpdateMail(ctx context.Context, mail string, userId int) erro
 	var id int
 	query := `update users set email = $1 where id_user=$2 RETURNING id_user;`
 
-	err := c.db.QueryRow(query,
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
 		mail,
 		userId,
 	).Scan(&id)
-	if id == 0 {
-		err = errors.New("Invalid User")
+	if err == sql.ErrNoRows || (err == nil && id == 0) {
+		err = domain.ErrNoUser
 	}
 
 	return err
@@ -55,7 +54,7 @@ func (c *userRepo) UpdateMail(ctx context.Context, mail string, userId int) erro
 func (c *userRepo) AddProfile(ctx context.Context, profile domain.UserData) error {
 	var id int
 	query := `INSERT INTO profiles (user_id, first_name, last_name, gender, dob, profile_photo) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id_profie;`
-	err := c.db.QueryRow(query,
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
 		profile.UserId,
 		profile.FirstName,
 		profile.LastName,
@@ -63,8 +62,8 @@ func (c *userRepo) AddProfile(ctx context.Context, profile domain.UserData) erro
 		profile.Dob,
 		profile.ProfilePhoto,
 	).Scan(&id)
-	if id == 0 {
-		err = errors.New("Invalid User")
+	if err == sql.ErrNoRows || (err == nil && id == 0) {
+		err = domain.ErrNoUser
 	}
 	return err
 }
@@ -73,16 +72,18 @@ func (c *userRepo) AddProfile(ctx context.Context, profile domain.UserData) erro
 func (c *userRepo) CreateUser(ctx context.Context, user domain.User) (int, error) {
 	var id int
 
-	query := `INSERT INTO users (phone,email,password,user_type,verification,status) 
-				VALUES($1,$2,$3,$4,$5,$6) RETURNING id_user;`
+	query := `INSERT INTO users (phone,email,password,user_type,verification,status,signup_channel,phone_country,created_at) 
+				VALUES($1,$2,$3,$4,$5,$6,$7,NULLIF($8,''),NOW()) RETURNING id_user;`
 
-	err := c.db.QueryRow(query,
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
 		user.Phone,
 		user.Email,
 		user.Password,
 		user.UserType,
 		user.Verification,
 		user.Status,
+		user.SignupChannel,
+		user.PhoneCountry,
 	).Scan(
 		&id,
 	)
@@ -95,7 +96,7 @@ func (c *userRepo) FindUserWithEmail(ctx context.Context, email string) (domain.
 	var user domain.User
 	query := `SELECT id_user, phone, email, password, user_type, verification, status from users WHERE email=$1;`
 
-	err := c.db.QueryRow(query,
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
 		email).Scan(
 		&user.IdUser,
 		&user.Phone,
@@ -106,7 +107,7 @@ func (c *userRepo) FindUserWithEmail(ctx context.Context, email string) (domain.
 		&user.Status,
 	)
 	if err != nil && err == sql.ErrNoRows {
-		return user, errors.New("there is no user")
+		return user, domain.ErrNoUser
 	}
 
 	return user, err
@@ -117,7 +118,7 @@ func (c *userRepo) FindUserWithNumber(ctx context.Context, phoneNumber string) (
 	var user domain.User
 	query := `SELECT id_user, phone, email, password, user_type, verification, status from users WHERE phone=$1;`
 
-	err := c.db.QueryRow(query,
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
 		phoneNumber).Scan(
 		&user.IdUser,
 		&user.Phone,
@@ -128,12 +129,55 @@ func (c *userRepo) FindUserWithNumber(ctx context.Context, phoneNumber string) (
 		&user.Status,
 	)
 	if err != nil && err == sql.ErrNoRows {
-		return user, errors.New("there is no user")
+		return user, domain.ErrNoUser
 	}
 
 	return user, err
 }
 
+// FindUserWithId implements interfaces.UserRepository
+func (c *userRepo) FindUserWithId(ctx context.Context, userId int) (domain.User, error) {
+	var user domain.User
+	var suspendedUntil, loggedOutAt sql.NullTime
+	query := `SELECT id_user, phone, email, password, user_type, verification, status, suspended_until, logged_out_at from users WHERE id_user=$1;`
+
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
+		userId).Scan(
+		&user.IdUser,
+		&user.Phone,
+		&user.Email,
+		&user.Password,
+		&user.UserType,
+		&user.Verification,
+		&user.Status,
+		&suspendedUntil,
+		&loggedOutAt,
+	)
+	if err != nil && err == sql.ErrNoRows {
+		return user, domain.ErrNoUser
+	}
+	if suspendedUntil.Valid {
+		user.SuspendedUntil = &suspendedUntil.Time
+	}
+	if loggedOutAt.Valid {
+		user.LoggedOutAt = &loggedOutAt.Time
+	}
+
+	return user, err
+}
+
+// RecordLogin implements interfaces.UserRepository
+func (c *userRepo) RecordLogin(ctx context.Context, login domain.LoginEvent) error {
+	query := `INSERT INTO login_events (user_id, method, ip, user_agent, created_at) VALUES ($1,$2,$3,$4,NOW());`
+	_, err := conn(ctx, c.db).ExecContext(ctx, query,
+		login.UserId,
+		login.Method,
+		login.Ip,
+		login.UserAgent,
+	)
+	return err
+}
+
 func NewUserRepo(db *sql.DB) interfaces.UserRepository {
 	return &userRepo{
 		db: db,
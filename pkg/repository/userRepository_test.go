@@ -65,7 +65,7 @@ func TestUserRepo_GetProfile(t *testing.T) {
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectuserprofile: domain.Profile{},
-			expectedErr:       errors.New("there is no user"),
+			expectedErr:       domain.ErrNoUser,
 		},
 		{
 			name:   "test there is any db errors",
@@ -135,7 +135,7 @@ func TestUserRepo_UpdateMail(t *testing.T) {
 					WithArgs("test@mail.com", 1).
 					WillReturnRows(sqlmock.NewRows([]string{"id_profie"}).AddRow(0))
 			},
-			expectedErr: errors.New("Invalid User"),
+			expectedErr: domain.ErrNoUser,
 		},
 	}
 
@@ -198,7 +198,7 @@ func TestUserRepo_AddProfile(t *testing.T) {
 					WithArgs(mockUserData.UserId, mockUserData.FirstName, mockUserData.LastName, mockUserData.Gender, mockUserData.Dob, mockUserData.ProfilePhoto).
 					WillReturnRows(sqlmock.NewRows([]string{"id_profie"}).AddRow(0))
 			},
-			expectedErr: errors.New("Invalid User"),
+			expectedErr: domain.ErrNoUser,
 		},
 	}
 
@@ -226,15 +226,17 @@ func TestUserRepo_CreateUser(t *testing.T) {
 
 	userRepo := NewUserRepo(db)
 
-	mockQuery := "INSERT INTO users \\(phone,email,password,user_type,verification,status\\) VALUES\\(\\$1,\\$2,\\$3,\\$4,\\$5,\\$6\\) RETURNING id_user;"
+	mockQuery := "INSERT INTO users \\(phone,email,password,user_type,verification,status,signup_channel,phone_country,created_at\\) VALUES\\(\\$1,\\$2,\\$3,\\$4,\\$5,\\$6,\\$7,NULLIF\\(\\$8,''\\),NOW\\(\\)\\) RETURNING id_user;"
 	mockUser := domain.User{
-		IdUser:       1,
-		Phone:        "",
-		Email:        "",
-		Password:     "",
-		UserType:     "",
-		Verification: false,
-		Status:       "",
+		IdUser:        1,
+		Phone:         "+919876543210",
+		PhoneCountry:  "IN",
+		Email:         "",
+		Password:      "",
+		UserType:      "",
+		Verification:  false,
+		Status:        "",
+		SignupChannel: domain.LoginPhone,
 	}
 
 	tests := []struct {
@@ -249,7 +251,7 @@ func TestUserRepo_CreateUser(t *testing.T) {
 			user: mockUser,
 			mockQueryFunc: func() {
 				mock.ExpectQuery(mockQuery).
-					WithArgs(mockUser.Phone, mockUser.Email, mockUser.Password, mockUser.UserType, mockUser.Verification, mockUser.Status).
+					WithArgs(mockUser.Phone, mockUser.Email, mockUser.Password, mockUser.UserType, mockUser.Verification, mockUser.Status, mockUser.SignupChannel, mockUser.PhoneCountry).
 					WillReturnError(errors.New("db error"))
 			},
 			expectedId:  0,
@@ -260,7 +262,7 @@ func TestUserRepo_CreateUser(t *testing.T) {
 			user: mockUser,
 			mockQueryFunc: func() {
 				mock.ExpectQuery(mockQuery).
-					WithArgs(mockUser.Phone, mockUser.Email, mockUser.Password, mockUser.UserType, mockUser.Verification, mockUser.Status).
+					WithArgs(mockUser.Phone, mockUser.Email, mockUser.Password, mockUser.UserType, mockUser.Verification, mockUser.Status, mockUser.SignupChannel, mockUser.PhoneCountry).
 					WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(1))
 			},
 			expectedId:  1,
@@ -313,7 +315,7 @@ func TestUserRepo_FindUserWithNumber(t *testing.T) {
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectedUser: domain.User{},
-			expectedErr:  errors.New("there is no user"),
+			expectedErr:  domain.ErrNoUser,
 		},
 		{
 			name:        "found user from database",
@@ -383,7 +385,7 @@ func TestUserRepo_FindUserWithemail(t *testing.T) {
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectedUser: domain.User{},
-			expectedErr:  errors.New("there is no user"),
+			expectedErr:  domain.ErrNoUser,
 		},
 		{
 			name:  "found user from database",
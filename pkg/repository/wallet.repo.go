@@ -0,0 +1,455 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+const payoutColumns = `id_payout, worker_id, amount, currency, destination, status, note, batch_id, reviewed_by, reviewed_at, paid_at, created_at, updated_at`
+
+const walletEntryColumns = `e.id_entry, e.transaction_id, t.kind, t.request_id, t.payout_id, t.memo, e.amount, e.currency, e.created_at`
+
+type walletRepo struct {
+	db *sql.DB
+}
+
+// Balance implements interfaces.WalletRepository
+func (c *walletRepo) Balance(ctx context.Context, workerId int) (domain.Wallet, error) {
+	return walletBalance(ctx, conn(ctx, c.db), workerId)
+}
+
+func walletBalance(ctx context.Context, db querier, workerId int) (domain.Wallet, error) {
+	wallet := domain.Wallet{WorkerId: workerId, Currency: domain.DefaultCurrency}
+	query := `SELECT COALESCE((SELECT SUM(amount) FROM ledger_entries WHERE account=$1 AND owner_id=$2), 0),
+				COALESCE((SELECT SUM(amount) FROM payouts WHERE worker_id=$2 AND status=$3), 0);`
+	err := db.QueryRowContext(ctx, query, domain.AccountWorker, workerId, domain.PayoutRequested).Scan(
+		&wallet.Balance,
+		&wallet.Pending,
+	)
+	wallet.Available = wallet.Balance - wallet.Pending
+	return wallet, err
+}
+
+// ListEntries implements interfaces.WalletRepository
+func (c *walletRepo) ListEntries(ctx context.Context, workerId int, filter utils.Filter) ([]domain.WalletEntry, utils.Metadata, error) {
+	var entries []domain.WalletEntry
+	var total int
+
+	args := []interface{}{domain.AccountWorker, workerId}
+	keyset, keysetArgs := filter.KeysetCondition("e.created_at", "e.id_entry", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + walletEntryColumns + `, COUNT(*) OVER()
+				FROM ledger_entries AS e INNER JOIN ledger_transactions AS t ON t.id_transaction=e.transaction_id
+				WHERE e.account=$1 AND e.owner_id=$2` + keyset + ` ORDER BY e.created_at DESC, e.id_entry DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return entries, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanWalletEntry(rows, &total)
+		if err != nil {
+			return entries, utils.Metadata{}, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return entries, utils.Metadata{}, err
+	}
+
+	fetched := len(entries)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		entries = entries[:filter.PageSize]
+	}
+	if len(entries) > 0 {
+		last = utils.Cursor{Id: entries[len(entries)-1].IdEntry, CreatedAt: entries[len(entries)-1].CreatedAt}
+	}
+
+	return entries, pageMetadata(filter, fetched, total, last), nil
+}
+
+// Statement implements interfaces.WalletRepository. The entries of the period
+// come oldest first so they read like a bank statement.
+func (c *walletRepo) Statement(ctx context.Context, workerId int, from time.Time, to time.Time) (domain.Statement, error) {
+	statement := domain.Statement{WorkerId: workerId, Currency: domain.DefaultCurrency}
+
+	query := `SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account=$1 AND owner_id=$2 AND created_at<$3;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, domain.AccountWorker, workerId, from).Scan(&statement.Opening)
+	if err != nil {
+		return statement, err
+	}
+
+	query = `SELECT ` + walletEntryColumns + `
+				FROM ledger_entries AS e INNER JOIN ledger_transactions AS t ON t.id_transaction=e.transaction_id
+				WHERE e.account=$1 AND e.owner_id=$2 AND e.created_at>=$3 AND e.created_at<$4 ORDER BY e.created_at, e.id_entry;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, domain.AccountWorker, workerId, from, to)
+	if err != nil {
+		return statement, err
+	}
+	defer rows.Close()
+
+	statement.Closing = statement.Opening
+	for rows.Next() {
+		entry, err := scanWalletEntry(rows)
+		if err != nil {
+			return statement, err
+		}
+		switch entry.Kind {
+		case domain.LedgerEarning:
+			statement.Earnings += entry.Amount
+		case domain.LedgerCommission:
+			statement.Commissions += entry.Amount
+		case domain.LedgerRefund:
+			statement.Refunds += entry.Amount
+		case domain.LedgerAdjustment:
+			statement.Adjustments += entry.Amount
+		case domain.LedgerPayout:
+			statement.Payouts += entry.Amount
+		case domain.LedgerPromotion:
+			statement.Promotions += entry.Amount
+		case domain.LedgerReferral:
+			statement.Referrals += entry.Amount
+		case domain.LedgerSubscription:
+			statement.Subscriptions += entry.Amount
+		default:
+			statement.Other += entry.Amount
+		}
+		statement.Closing += entry.Amount
+		statement.Entries = append(statement.Entries, entry)
+	}
+	return statement, rows.Err()
+}
+
+// PostAdjustment implements interfaces.WalletRepository
+func (c *walletRepo) PostAdjustment(ctx context.Context, workerId int, actorId int, amount int64, memo string) (domain.LedgerTransaction, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.LedgerTransaction{}, err
+	}
+	defer tx.Rollback()
+
+	transaction, err := postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerAdjustment, Memo: memo, ActorId: actorId},
+		transfer(domain.LedgerEntry{Account: domain.AccountAdjustments}, domain.LedgerEntry{Account: domain.AccountWorker, OwnerId: workerId}, amount, domain.DefaultCurrency)...)
+	if err != nil {
+		return transaction, err
+	}
+	return transaction, tx.Commit()
+}
+
+// RequestPayout implements interfaces.WalletRepository. The worker's row is
+// locked so two requests cannot both spend the same balance.
+func (c *walletRepo) RequestPayout(ctx context.Context, payout domain.Payout) (domain.Payout, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return payout, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := `SELECT id_user FROM users WHERE id_user=$1 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, payout.WorkerId).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return payout, domain.ErrNoWorker
+	}
+	if err != nil {
+		return payout, err
+	}
+
+	wallet, err := walletBalance(ctx, querier{tx}, payout.WorkerId)
+	if err != nil {
+		return payout, err
+	}
+	if payout.Amount > wallet.Available {
+		return payout, domain.ErrLowBalance
+	}
+
+	query = `INSERT INTO payouts (worker_id, amount, currency, destination, status, note, created_at, updated_at)
+				VALUES ($1,$2,$3,$4,$5,'',NOW(),NOW()) RETURNING ` + payoutColumns + `;`
+	payout, err = scanPayout(tx.QueryRowContext(ctx, query,
+		payout.WorkerId,
+		payout.Amount,
+		payout.Currency,
+		payout.Destination,
+		domain.PayoutRequested,
+	))
+	if err != nil {
+		return payout, err
+	}
+	return payout, tx.Commit()
+}
+
+// ListWorkerPayouts implements interfaces.WalletRepository
+func (c *walletRepo) ListWorkerPayouts(ctx context.Context, workerId int, filter utils.Filter) ([]domain.Payout, utils.Metadata, error) {
+	return c.listPayouts(ctx, `worker_id=$1`, workerId, filter)
+}
+
+// ListPayoutsByStatus implements interfaces.WalletRepository
+func (c *walletRepo) ListPayoutsByStatus(ctx context.Context, status string, filter utils.Filter) ([]domain.Payout, utils.Metadata, error) {
+	return c.listPayouts(ctx, `status=$1`, status, filter)
+}
+
+func (c *walletRepo) listPayouts(ctx context.Context, condition string, arg interface{}, filter utils.Filter) ([]domain.Payout, utils.Metadata, error) {
+	var payouts []domain.Payout
+	var total int
+
+	args := []interface{}{arg}
+	keyset, keysetArgs := filter.KeysetCondition("created_at", "id_payout", len(args)+1)
+	args = append(args, keysetArgs...)
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + payoutColumns + `, COUNT(*) OVER() FROM payouts
+				WHERE ` + condition + keyset + ` ORDER BY created_at DESC, id_payout DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return payouts, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payout, err := scanPayout(rows, &total)
+		if err != nil {
+			return payouts, utils.Metadata{}, err
+		}
+		payouts = append(payouts, payout)
+	}
+	if err = rows.Err(); err != nil {
+		return payouts, utils.Metadata{}, err
+	}
+
+	fetched := len(payouts)
+	var last utils.Cursor
+	if filter.IsCursor() && fetched > filter.PageSize {
+		payouts = payouts[:filter.PageSize]
+	}
+	if len(payouts) > 0 {
+		last = utils.Cursor{Id: payouts[len(payouts)-1].IdPayout, CreatedAt: payouts[len(payouts)-1].CreatedAt}
+	}
+
+	return payouts, pageMetadata(filter, fetched, total, last), nil
+}
+
+// ApprovePayout implements interfaces.WalletRepository. The worker's account
+// is debited with the approval, so the balance can no longer be spent twice.
+func (c *walletRepo) ApprovePayout(ctx context.Context, payoutId int, adminId int, note string) (domain.Payout, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Payout{}, err
+	}
+	defer tx.Rollback()
+
+	var workerId int
+	query := `SELECT worker_id FROM payouts WHERE id_payout=$1 AND status=$2 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, query, payoutId, domain.PayoutRequested).Scan(&workerId)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.Payout{}, domain.ErrNoPayout
+	}
+	if err != nil {
+		return domain.Payout{}, err
+	}
+
+	query = `UPDATE payouts SET status=$1, note=$2, reviewed_by=$3, reviewed_at=NOW(), updated_at=NOW()
+				WHERE id_payout=$4 RETURNING ` + payoutColumns + `;`
+	payout, err := scanPayout(tx.QueryRowContext(ctx, query, domain.PayoutApproved, note, adminId, payoutId))
+	if err != nil {
+		return payout, err
+	}
+
+	// The worker may have lost part of the balance since asking, to an
+	// adjustment say, in which case the payout has to be rejected instead
+	wallet, err := walletBalance(ctx, querier{tx}, workerId)
+	if err != nil {
+		return payout, err
+	}
+	if payout.Amount > wallet.Balance {
+		return payout, domain.ErrLowBalance
+	}
+
+	_, err = postLedger(ctx, tx, domain.LedgerTransaction{Kind: domain.LedgerPayout, PayoutId: &payout.IdPayout, ActorId: adminId},
+		transfer(domain.LedgerEntry{Account: domain.AccountWorker, OwnerId: workerId}, domain.LedgerEntry{Account: domain.AccountPayouts}, payout.Amount, payout.Currency)...)
+	if err != nil {
+		return payout, err
+	}
+	return payout, tx.Commit()
+}
+
+// RejectPayout implements interfaces.WalletRepository
+func (c *walletRepo) RejectPayout(ctx context.Context, payoutId int, adminId int, note string) (domain.Payout, error) {
+	query := `UPDATE payouts SET status=$1, note=$2, reviewed_by=$3, reviewed_at=NOW(), updated_at=NOW()
+				WHERE id_payout=$4 AND status=$5 RETURNING ` + payoutColumns + `;`
+	payout, err := scanPayout(conn(ctx, c.db).QueryRowContext(ctx, query, domain.PayoutRejected, note, adminId, payoutId, domain.PayoutRequested))
+	if err != nil && err == sql.ErrNoRows {
+		return payout, domain.ErrNoPayout
+	}
+	return payout, err
+}
+
+// CreatePayoutBatch implements interfaces.WalletRepository. Every approved
+// payout goes into the batch and is taken as paid from then on.
+func (c *walletRepo) CreatePayoutBatch(ctx context.Context, adminId int) (domain.PayoutBatch, []domain.Payout, error) {
+	var payouts []domain.Payout
+	batch := domain.PayoutBatch{CreatedBy: adminId, Currency: domain.DefaultCurrency}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return batch, payouts, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO payout_batches (created_by, count, total, currency, created_at) VALUES ($1,0,0,$2,NOW()) RETURNING id_batch, created_at;`
+	err = tx.QueryRowContext(ctx, query, batch.CreatedBy, batch.Currency).Scan(&batch.IdBatch, &batch.CreatedAt)
+	if err != nil {
+		return batch, payouts, err
+	}
+
+	query = `UPDATE payouts SET status=$1, batch_id=$2, paid_at=NOW(), updated_at=NOW()
+				WHERE id_payout IN (SELECT id_payout FROM payouts WHERE status=$3 ORDER BY id_payout FOR UPDATE)
+				RETURNING ` + payoutColumns + `;`
+	rows, err := tx.QueryContext(ctx, query, domain.PayoutPaid, batch.IdBatch, domain.PayoutApproved)
+	if err != nil {
+		return batch, payouts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return batch, payouts, err
+		}
+		batch.Count++
+		batch.Total += payout.Amount
+		payouts = append(payouts, payout)
+	}
+	if err = rows.Err(); err != nil {
+		return batch, payouts, err
+	}
+	rows.Close()
+	if batch.Count == 0 {
+		return batch, payouts, domain.ErrNoApprovedPayouts
+	}
+
+	query = `UPDATE payout_batches SET count=$1, total=$2 WHERE id_batch=$3;`
+	if _, err = tx.ExecContext(ctx, query, batch.Count, batch.Total, batch.IdBatch); err != nil {
+		return batch, payouts, err
+	}
+	return batch, payouts, tx.Commit()
+}
+
+// FindPayoutBatch implements interfaces.WalletRepository
+func (c *walletRepo) FindPayoutBatch(ctx context.Context, batchId int) (domain.PayoutBatch, []domain.Payout, error) {
+	var batch domain.PayoutBatch
+	var payouts []domain.Payout
+
+	query := `SELECT id_batch, created_by, count, total, currency, created_at FROM payout_batches WHERE id_batch=$1;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, batchId).Scan(
+		&batch.IdBatch,
+		&batch.CreatedBy,
+		&batch.Count,
+		&batch.Total,
+		&batch.Currency,
+		&batch.CreatedAt,
+	)
+	if err != nil && err == sql.ErrNoRows {
+		return batch, payouts, domain.ErrNoPayoutBatch
+	}
+	if err != nil {
+		return batch, payouts, err
+	}
+
+	query = `SELECT ` + payoutColumns + ` FROM payouts WHERE batch_id=$1 ORDER BY id_payout;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, batchId)
+	if err != nil {
+		return batch, payouts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return batch, payouts, err
+		}
+		payouts = append(payouts, payout)
+	}
+	return batch, payouts, rows.Err()
+}
+
+func scanPayout(row rowScanner, extra ...interface{}) (domain.Payout, error) {
+	var payout domain.Payout
+	var batchId, reviewedBy sql.NullInt64
+	var reviewedAt, paidAt sql.NullTime
+	dest := []interface{}{
+		&payout.IdPayout,
+		&payout.WorkerId,
+		&payout.Amount,
+		&payout.Currency,
+		&payout.Destination,
+		&payout.Status,
+		&payout.Note,
+		&batchId,
+		&reviewedBy,
+		&reviewedAt,
+		&paidAt,
+		&payout.CreatedAt,
+		&payout.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if batchId.Valid {
+		id := int(batchId.Int64)
+		payout.BatchId = &id
+	}
+	if reviewedBy.Valid {
+		id := int(reviewedBy.Int64)
+		payout.ReviewedBy = &id
+	}
+	if reviewedAt.Valid {
+		payout.ReviewedAt = &reviewedAt.Time
+	}
+	if paidAt.Valid {
+		payout.PaidAt = &paidAt.Time
+	}
+	return payout, err
+}
+
+func scanWalletEntry(row rowScanner, extra ...interface{}) (domain.WalletEntry, error) {
+	var entry domain.WalletEntry
+	var requestId, payoutId sql.NullInt64
+	dest := []interface{}{
+		&entry.IdEntry,
+		&entry.TransactionId,
+		&entry.Kind,
+		&requestId,
+		&payoutId,
+		&entry.Memo,
+		&entry.Amount,
+		&entry.Currency,
+		&entry.CreatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if requestId.Valid {
+		id := int(requestId.Int64)
+		entry.RequestId = &id
+	}
+	if payoutId.Valid {
+		id := int(payoutId.Int64)
+		entry.PayoutId = &id
+	}
+	return entry, err
+}
+
+func NewWalletRepo(db *sql.DB) interfaces.WalletRepository {
+	return &walletRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletRepo_RequestPayout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	walletRepo := NewWalletRepo(db)
+
+	lockQuery := "SELECT id_user FROM users WHERE id_user=\\$1 FOR UPDATE;"
+	balanceQuery := "SELECT COALESCE\\(\\(SELECT SUM\\(amount\\) FROM ledger_entries"
+	insertQuery := "INSERT INTO payouts"
+
+	payoutRow := sqlmock.NewRows([]string{"id_payout", "worker_id", "amount", "currency", "destination", "status", "note", "batch_id", "reviewed_by", "reviewed_at", "paid_at", "created_at", "updated_at"}).
+		AddRow(1, 5, int64(60000), "INR", "worker@upi", domain.PayoutRequested, "", nil, nil, nil, nil, time.Now(), time.Now())
+
+	tests := []struct {
+		name          string
+		mockQueryFunc func()
+		expectedErr   error
+	}{
+		{
+			name: "test payout within the available balance",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(5))
+				mock.ExpectQuery(balanceQuery).WithArgs(domain.AccountWorker, 5, domain.PayoutRequested).
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "pending"}).AddRow(int64(100000), int64(0)))
+				mock.ExpectQuery(insertQuery).WithArgs(5, int64(60000), "INR", "worker@upi", domain.PayoutRequested).WillReturnRows(payoutRow)
+				mock.ExpectCommit()
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "test pending payouts are not spent twice",
+			mockQueryFunc: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id_user"}).AddRow(5))
+				mock.ExpectQuery(balanceQuery).WithArgs(domain.AccountWorker, 5, domain.PayoutRequested).
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "pending"}).AddRow(int64(100000), int64(60000)))
+				mock.ExpectRollback()
+			},
+			expectedErr: domain.ErrLowBalance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockQueryFunc()
+			ctx := context.Background()
+
+			_, actualErr := walletRepo.RequestPayout(ctx, domain.Payout{
+				WorkerId:    5,
+				Amount:      60000,
+				Currency:    "INR",
+				Destination: "worker@upi",
+			})
+
+			assert.Equal(t, tt.expectedErr, actualErr)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWalletRepo_PostAdjustment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	walletRepo := NewWalletRepo(db)
+
+	mock.ExpectBegin()
+	expectLedger(mock, domain.LedgerAdjustment, domain.AccountAdjustments, 0, domain.AccountWorker, 5, -2500)
+	mock.ExpectCommit()
+
+	_, actualErr := walletRepo.PostAdjustment(context.Background(), 5, 1, -2500, "damaged tools")
+
+	assert.NoError(t, actualErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWalletRepo_Statement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	walletRepo := NewWalletRepo(db)
+	from := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\), 0\\) FROM ledger_entries").
+		WithArgs(domain.AccountWorker, 5, from).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(int64(10000)))
+	entries := sqlmock.NewRows([]string{"id_entry", "transaction_id", "kind", "request_id", "payout_id", "memo", "amount", "currency", "created_at"}).
+		AddRow(1, 1, domain.LedgerEarning, 3, nil, "", int64(50000), "INR", from).
+		AddRow(2, 2, domain.LedgerCommission, 3, nil, "", int64(-5000), "INR", from).
+		AddRow(3, 3, domain.LedgerSubscription, nil, nil, "Pro plan", int64(-20000), "INR", from).
+		AddRow(4, 4, domain.LedgerReferral, 3, nil, "Referral ABC", int64(1000), "INR", from).
+		AddRow(5, 5, domain.LedgerPromotion, 3, nil, "", int64(2000), "INR", from).
+		AddRow(6, 6, "bonus", nil, nil, "", int64(300), "INR", from)
+	mock.ExpectQuery("SELECT e.id_entry").WithArgs(domain.AccountWorker, 5, from, to).WillReturnRows(entries)
+
+	statement, err := walletRepo.Statement(context.Background(), 5, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50000), statement.Earnings)
+	assert.Equal(t, int64(-5000), statement.Commissions)
+	assert.Equal(t, int64(-20000), statement.Subscriptions)
+	assert.Equal(t, int64(1000), statement.Referrals)
+	assert.Equal(t, int64(2000), statement.Promotions)
+	assert.Equal(t, int64(300), statement.Other)
+	assert.Equal(t, int64(38300), statement.Closing)
+	sums := statement.Opening + statement.Earnings + statement.Commissions + statement.Refunds + statement.Adjustments +
+		statement.Payouts + statement.Promotions + statement.Referrals + statement.Subscriptions + statement.Other
+	assert.Equal(t, statement.Closing, sums)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
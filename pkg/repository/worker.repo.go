@@ -1,15 +1,299 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 )
 
 type workerRepository struct {
 	db *sql.DB
 }
 
+// SetAvailability implements interfaces.WorkerRepository
+func (c *workerRepository) SetAvailability(ctx context.Context, workerId int, availability []domain.Availability) error {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM availabilities WHERE worker_id=$1;`, workerId)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO availabilities (worker_id, weekday, first_half, second_half, timezone) VALUES ($1,$2,$3,$4,$5);`
+	for _, day := range availability {
+		_, err = tx.ExecContext(ctx, query,
+			workerId,
+			day.Weekday,
+			day.FirstHalf,
+			day.SecondHalf,
+			day.Timezone,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAvailability implements interfaces.WorkerRepository
+func (c *workerRepository) GetAvailability(ctx context.Context, workerId int) ([]domain.Availability, error) {
+	var availability []domain.Availability
+
+	query := `SELECT id_availability, worker_id, weekday, first_half, second_half, timezone FROM availabilities WHERE worker_id=$1 ORDER BY weekday;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, workerId)
+	if err != nil {
+		return availability, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day domain.Availability
+		err = rows.Scan(
+			&day.IdAvailability,
+			&day.WorkerId,
+			&day.Weekday,
+			&day.FirstHalf,
+			&day.SecondHalf,
+			&day.Timezone,
+		)
+		if err != nil {
+			return availability, err
+		}
+		availability = append(availability, day)
+	}
+	return availability, rows.Err()
+}
+
+// AddBlackout implements interfaces.WorkerRepository
+func (c *workerRepository) AddBlackout(ctx context.Context, blackout domain.Blackout) (int, error) {
+	var id int
+	query := `INSERT INTO blackouts (worker_id, day, reason) VALUES ($1,$2,$3) RETURNING id_blackout;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query,
+		blackout.WorkerId,
+		blackout.Day,
+		blackout.Reason,
+	).Scan(&id)
+	return id, err
+}
+
+// ListBlackouts implements interfaces.WorkerRepository
+func (c *workerRepository) ListBlackouts(ctx context.Context, workerId int, from time.Time) ([]domain.Blackout, error) {
+	var blackouts []domain.Blackout
+
+	query := `SELECT id_blackout, worker_id, day, reason FROM blackouts WHERE worker_id=$1 AND day>=$2 ORDER BY day;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, workerId, from)
+	if err != nil {
+		return blackouts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blackout domain.Blackout
+		err = rows.Scan(
+			&blackout.IdBlackout,
+			&blackout.WorkerId,
+			&blackout.Day,
+			&blackout.Reason,
+		)
+		if err != nil {
+			return blackouts, err
+		}
+		blackouts = append(blackouts, blackout)
+	}
+	return blackouts, rows.Err()
+}
+
+// DeleteBlackout implements interfaces.WorkerRepository
+func (c *workerRepository) DeleteBlackout(ctx context.Context, workerId int, blackoutId int) error {
+	var id int
+	query := `DELETE FROM blackouts WHERE id_blackout=$1 AND worker_id=$2 RETURNING id_blackout;`
+	err := conn(ctx, c.db).QueryRowContext(ctx, query, blackoutId, workerId).Scan(&id)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrNoBlackout
+	}
+	return err
+}
+
+// AddJob implements interfaces.WorkerRepository. The worker row is locked so
+// concurrent listings cannot both slip under jobLimit.
+func (c *workerRepository) AddJob(ctx context.Context, job domain.Job, jobLimit int) (int, error) {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err = lockWorker(ctx, tx.Tx, job.IdWorker); err != nil {
+		return 0, err
+	}
+	if err = checkJobLimit(ctx, querier{tx}, job.IdWorker, jobLimit); err != nil {
+		return 0, err
+	}
+
+	var id int
+	query := `INSERT INTO jobs (id_worker, category_id, expirience, description, full_day_wage, half_day_wage, openwork, priority)
+				VALUES ($1,$2,$3,$4,$5,$6,true,EXISTS (
+					SELECT 1 FROM subscriptions s JOIN subscription_plans p ON p.id_plan=s.plan_id
+					WHERE s.worker_id=$1 AND s.status IN ($7,$8) AND p.priority
+				)) RETURNING id_job;`
+	err = tx.QueryRowContext(ctx, query,
+		job.IdWorker,
+		job.CategoryId,
+		job.Expirience,
+		job.Description,
+		job.FullDayWage,
+		job.HalfDayWage,
+		domain.SubscriptionActive,
+		domain.SubscriptionGrace,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// ListWorkerJobs implements interfaces.WorkerRepository
+func (c *workerRepository) ListWorkerJobs(ctx context.Context, workerId int) ([]domain.JobListing, error) {
+	var jobs []domain.JobListing
+
+	query := `SELECT ` + jobListingColumns + ` FROM jobs j
+				JOIN categories c ON c.id_category=j.category_id
+				LEFT JOIN subscriptions s ON s.worker_id=j.id_worker AND s.status IN ($2,$3)
+				LEFT JOIN subscription_plans p ON p.id_plan=s.plan_id
+				WHERE j.id_worker=$1 ORDER BY j.id_job DESC;`
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, workerId, domain.SubscriptionActive, domain.SubscriptionGrace)
+	if err != nil {
+		return jobs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJobListing(rows)
+		if err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// SetJobOpen implements interfaces.WorkerRepository. Opening a job counts
+// against jobLimit, closing one never fails on it.
+func (c *workerRepository) SetJobOpen(ctx context.Context, workerId int, jobId int, open bool, jobLimit int) error {
+	tx, err := beginTx(ctx, c.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var openwork bool
+	query := `SELECT j.openwork FROM users u JOIN jobs j ON j.id_worker=u.id_user
+				WHERE u.id_user=$1 AND j.id_job=$2 FOR UPDATE OF u, j;`
+	err = tx.QueryRowContext(ctx, query, workerId, jobId).Scan(&openwork)
+	if err != nil && err == sql.ErrNoRows {
+		return domain.ErrNoJob
+	}
+	if err != nil {
+		return err
+	}
+	if openwork == open {
+		return nil
+	}
+
+	if open {
+		if err = checkJobLimit(ctx, querier{tx}, workerId, jobLimit); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE jobs SET openwork=$1 WHERE id_job=$2;`, open, jobId)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SearchJobs implements interfaces.WorkerRepository. Priority jobs come
+// first, newest first within each group. A categoryId of 0 searches all.
+func (c *workerRepository) SearchJobs(ctx context.Context, categoryId int, filter utils.Filter) ([]domain.JobListing, utils.Metadata, error) {
+	var jobs []domain.JobListing
+	var total int
+
+	args := []interface{}{domain.SubscriptionActive, domain.SubscriptionGrace, categoryId}
+	page, pageArgs := pageClause(filter, len(args)+1)
+	args = append(args, pageArgs...)
+
+	query := `SELECT ` + jobListingColumns + `, COUNT(*) OVER() FROM jobs j
+				JOIN categories c ON c.id_category=j.category_id
+				LEFT JOIN subscriptions s ON s.worker_id=j.id_worker AND s.status IN ($1,$2)
+				LEFT JOIN subscription_plans p ON p.id_plan=s.plan_id
+				WHERE j.openwork AND ($3=0 OR j.category_id=$3)
+				ORDER BY j.priority DESC, j.id_job DESC` + page
+
+	rows, err := conn(ctx, c.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return jobs, utils.Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJobListing(rows, &total)
+		if err != nil {
+			return jobs, utils.Metadata{}, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err = rows.Err(); err != nil {
+		return jobs, utils.Metadata{}, err
+	}
+	return jobs, pageMetadata(filter, len(jobs), total, utils.Cursor{}), nil
+}
+
+const jobListingColumns = `j.id_job, j.id_worker, j.category_id, c.category, j.expirience, j.description, j.full_day_wage, j.half_day_wage, j.openwork, j.priority, COALESCE(p.badge, '')`
+
+func scanJobListing(row rowScanner, extra ...interface{}) (domain.JobListing, error) {
+	var job domain.JobListing
+	dest := []interface{}{
+		&job.IdJob,
+		&job.WorkerId,
+		&job.CategoryId,
+		&job.Category,
+		&job.Expirience,
+		&job.Description,
+		&job.FullDayWage,
+		&job.HalfDayWage,
+		&job.Openwork,
+		&job.Priority,
+		&job.Badge,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return job, err
+}
+
+// checkJobLimit fails with domain.ErrJobLimit when the worker already has
+// jobLimit open jobs
+func checkJobLimit(ctx context.Context, q querier, workerId int, jobLimit int) error {
+	var open int
+	query := `SELECT COUNT(*) FROM jobs WHERE id_worker=$1 AND openwork;`
+	err := q.QueryRowContext(ctx, query, workerId).Scan(&open)
+	if err != nil {
+		return err
+	}
+	if open >= jobLimit {
+		return domain.ErrJobLimit
+	}
+	return nil
+}
+
 func NewWorkerRepo(db *sql.DB) interfaces.WorkerRepository {
 	return &workerRepository{
 		db: db,
@@ -1,21 +1,165 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 )
 
+// recentRequests is how many requests the account detail shows
+const recentRequests = 20
+
 type adminUseCase struct {
-	adminRepo  interfaces.AdminRepository
-	mailConfig config.MailConfig
+	adminRepo   interfaces.AdminRepository
+	workerRepo  interfaces.WorkerRepository
+	userRepo    interfaces.UserRepository
+	mailConfig  config.MailConfig
+	chatUseCase services.ChatUseCase
+}
+
+// SearchAccounts implements interfaces.AdminUseCase. Accounts have no
+// creation time to keep a cursor on, so they are paged by offset only.
+func (c *adminUseCase) SearchAccounts(ctx context.Context, userType string, search string, status string, filter utils.Filter) ([]domain.AccountSummary, utils.Metadata, error) {
+	if filter.IsCursor() {
+		return nil, utils.Metadata{}, domain.ErrOffsetOnly.WithMessage("account search is paged by offset only")
+	}
+	switch status {
+	case "", domain.UserNew, domain.UserActive, domain.UserBlocked, domain.UserSuspended:
+	default:
+		return nil, utils.Metadata{}, domain.ErrUnknownStatus.WithMessage("unknown account status")
+	}
+	return c.adminRepo.SearchAccounts(ctx, userType, search, status, filter)
+}
+
+// GetAccount implements interfaces.AdminUseCase
+func (c *adminUseCase) GetAccount(ctx context.Context, userType string, userId int) (domain.AccountDetail, error) {
+	var detail domain.AccountDetail
+
+	account, err := c.adminRepo.FindAccount(ctx, userId)
+	if err != nil {
+		return detail, err
+	}
+	if account.UserType != userType && userType == domain.RoleWorker {
+		return detail, domain.ErrNoWorker
+	}
+	if account.UserType != userType {
+		return detail, domain.ErrNoUser
+	}
+	detail.AccountSummary = account
+
+	profile, err := c.userRepo.GetProfile(ctx, userId)
+	if err != nil && !errors.Is(err, domain.ErrNoUser) {
+		return detail, err
+	}
+	if err == nil {
+		detail.Profile = &profile
+	}
+
+	if detail.Addresses, err = c.adminRepo.ListAddresses(ctx, userId); err != nil {
+		return detail, err
+	}
+	if detail.Requests, err = c.adminRepo.ListRecentRequests(ctx, userId, recentRequests); err != nil {
+		return detail, err
+	}
+	if detail.Actions, err = c.adminRepo.ListAccountActions(ctx, userId); err != nil {
+		return detail, err
+	}
+	if userType == domain.RoleWorker {
+		if detail.Jobs, err = c.workerRepo.ListWorkerJobs(ctx, userId); err != nil {
+			return detail, err
+		}
+		if detail.Rating, detail.RatingCount, err = c.adminRepo.RatingSummary(ctx, userId); err != nil {
+			return detail, err
+		}
+	}
+	return detail, nil
+}
+
+// ActOnAccount implements interfaces.AdminUseCase. Admin accounts are out of
+// reach, as is lifting a sanction that is not there.
+func (c *adminUseCase) ActOnAccount(ctx context.Context, adminId int, userId int, kind string, input domain.AccountActionInput) (domain.AccountAction, error) {
+	account, err := c.adminRepo.FindAccount(ctx, userId)
+	if err != nil {
+		return domain.AccountAction{}, err
+	}
+	if account.UserType == domain.RoleAdmin {
+		return domain.AccountAction{}, domain.ErrAdminAccount
+	}
+
+	action := domain.AccountAction{
+		UserId:  userId,
+		Kind:    kind,
+		Reason:  input.Reason,
+		ActorId: adminId,
+	}
+	switch kind {
+	case domain.ActionSuspension:
+		if input.Days < 1 {
+			return action, domain.ErrSuspensionDays
+		}
+		until := time.Now().AddDate(0, 0, input.Days)
+		action.Until = &until
+	case domain.ActionUnblock:
+		if account.Status != domain.UserBlocked && account.Status != domain.UserSuspended {
+			return action, domain.ErrNotSanctioned
+		}
+	}
+
+	type state struct {
+		Status         string     `json:"status"`
+		SuspendedUntil *time.Time `json:"suspendeduntil"`
+		Reason         string     `json:"reason,omitempty"`
+	}
+	before := state{Status: account.Status, SuspendedUntil: account.SuspendedUntil}
+	after := before
+	after.Reason = action.Reason
+	switch kind {
+	case domain.ActionSuspension:
+		after.Status, after.SuspendedUntil = domain.UserSuspended, action.Until
+	case domain.ActionBlock:
+		after.Status, after.SuspendedUntil = domain.UserBlocked, nil
+	case domain.ActionUnblock:
+		after.Status, after.SuspendedUntil = domain.UserActive, nil
+	}
+	entry, err := auditEntry(ctx, kind, domain.AuditTargetUser, userId, before, after)
+	if err != nil {
+		return action, err
+	}
+	if action, err = c.adminRepo.ApplyAccountAction(ctx, action, entry); err != nil {
+		return action, err
+	}
+
+	// Tokens already handed out fail their next session check, sockets
+	// opened with them are ended here
+	switch kind {
+	case domain.ActionSuspension, domain.ActionBlock, domain.ActionLogout:
+		c.chatUseCase.Disconnect(userId)
+	}
+	return action, nil
+}
+
+// ListLogins implements interfaces.AdminUseCase
+func (c *adminUseCase) ListLogins(ctx context.Context, userId int, filter utils.Filter) ([]domain.LoginEvent, utils.Metadata, error) {
+	return c.adminRepo.ListLogins(ctx, userId, filter)
 }
 
 func NewAdminService(
 	adminRepo interfaces.AdminRepository,
-	mailConfig config.MailConfig) services.AdminUseCase {
+	workerRepo interfaces.WorkerRepository,
+	userRepo interfaces.UserRepository,
+	mailConfig config.MailConfig,
+	chatUseCase services.ChatUseCase) services.AdminUseCase {
 	return &adminUseCase{
-		adminRepo:  adminRepo,
-		mailConfig: mailConfig,
+		adminRepo:   adminRepo,
+		workerRepo:  workerRepo,
+		userRepo:    userRepo,
+		mailConfig:  mailConfig,
+		chatUseCase: chatUseCase,
 	}
 }
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// analyticsDefaultDays is the range taken when the query gives no start
+	analyticsDefaultDays = 30
+	// analyticsMaxDays keeps a query to about a year of summaries
+	analyticsMaxDays  = 366
+	topWorkersDefault = 10
+	topWorkersMax     = 100
+)
+
+type analyticsUseCase struct {
+	analyticsRepo interfaces.AnalyticsRepository
+	config        config.Config
+	logger        *logrus.Logger
+}
+
+// SignupsPerDay implements interfaces.AnalyticsUseCase
+func (c *analyticsUseCase) SignupsPerDay(ctx context.Context, query domain.AnalyticsQuery) ([]domain.SignupStat, error) {
+	query, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.analyticsRepo.SignupsPerDay(ctx, query)
+}
+
+// ActiveWorkers implements interfaces.AnalyticsUseCase
+func (c *analyticsUseCase) ActiveWorkers(ctx context.Context, query domain.AnalyticsQuery) ([]domain.WorkerActivity, error) {
+	query, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.analyticsRepo.ActiveWorkers(ctx, query)
+}
+
+// RequestFunnel implements interfaces.AnalyticsUseCase
+func (c *analyticsUseCase) RequestFunnel(ctx context.Context, query domain.AnalyticsQuery) (domain.RequestFunnel, error) {
+	query, err := analyticsRange(query)
+	if err != nil {
+		return domain.RequestFunnel{}, err
+	}
+
+	funnel, err := c.analyticsRepo.RequestFunnel(ctx, query)
+	if err != nil {
+		return funnel, err
+	}
+	funnel.Conversion = ratio(funnel.Completed, funnel.Requests)
+	funnel.CancellationRate = ratio(funnel.Cancelled, funnel.Requests)
+	return funnel, nil
+}
+
+// Cancellations implements interfaces.AnalyticsUseCase. No-shows count as
+// falling through along with cancellations.
+func (c *analyticsUseCase) Cancellations(ctx context.Context, query domain.AnalyticsQuery) ([]domain.CancellationStat, error) {
+	query, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := c.analyticsRepo.Cancellations(ctx, query)
+	for i := range stats {
+		stats[i].Rate = ratio(stats[i].Cancelled+stats[i].NoShow, stats[i].Requests)
+	}
+	return stats, err
+}
+
+// Revenue implements interfaces.AnalyticsUseCase
+func (c *analyticsUseCase) Revenue(ctx context.Context, query domain.AnalyticsQuery) ([]domain.RevenueStat, error) {
+	query, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.analyticsRepo.Revenue(ctx, query)
+}
+
+// RatingsByCategory implements interfaces.AnalyticsUseCase
+func (c *analyticsUseCase) RatingsByCategory(ctx context.Context, query domain.AnalyticsQuery) ([]domain.CategoryRating, error) {
+	query, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.analyticsRepo.RatingsByCategory(ctx, query)
+}
+
+// TopWorkers implements interfaces.AnalyticsUseCase
+func (c *analyticsUseCase) TopWorkers(ctx context.Context, query domain.AnalyticsQuery) ([]domain.TopWorker, error) {
+	query, err := analyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = topWorkersDefault
+	}
+	if query.Limit > topWorkersMax {
+		query.Limit = topWorkersMax
+	}
+	return c.analyticsRepo.TopWorkers(ctx, query)
+}
+
+// RefreshSummaries implements interfaces.AnalyticsUseCase
+func (c *analyticsUseCase) RefreshSummaries(ctx context.Context) error {
+	return c.analyticsRepo.RefreshSummaries(ctx)
+}
+
+// Run implements interfaces.AnalyticsUseCase. It refreshes the summaries
+// analytics read from until ctx is done.
+func (c *analyticsUseCase) Run(ctx context.Context) {
+	minutes := c.config.AnalyticsRefreshMinutes
+	if minutes <= 0 {
+		minutes = domain.DefaultAnalyticsRefreshMinutes
+	}
+	ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		if err := c.analyticsRepo.RefreshSummaries(ctx); err != nil {
+			c.logger.WithContext(ctx).WithError(err).Error("failed to refresh analytics")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// analyticsRange fills in the range of a query, the last 30 days up to today
+// when none is given
+func analyticsRange(query domain.AnalyticsQuery) (domain.AnalyticsQuery, error) {
+	if query.To == nil {
+		to := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+		query.To = &to
+	}
+	if query.From == nil {
+		from := query.To.AddDate(0, 0, -analyticsDefaultDays)
+		query.From = &from
+	}
+
+	if !query.From.Before(*query.To) {
+		return query, domain.ErrInvalidRange
+	}
+	if query.To.Sub(*query.From) > analyticsMaxDays*24*time.Hour {
+		return query, domain.ErrRangeTooLong
+	}
+	return query, nil
+}
+
+// ratio is part of whole to four decimal places, 0 when there is no whole
+func ratio(part int, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
+
+func NewAnalyticsService(
+	analyticsRepo interfaces.AnalyticsRepository,
+	cfg config.Config,
+	logger *logrus.Logger) services.AnalyticsUseCase {
+	return &analyticsUseCase{
+		analyticsRepo: analyticsRepo,
+		config:        cfg,
+		logger:        logger,
+	}
+}
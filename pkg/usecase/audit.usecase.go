@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// auditBatch is how many records export and verification read at a time
+	auditBatch = 500
+	// auditExportLimit keeps an export to what fits in a response
+	auditExportLimit = 100000
+)
+
+type auditUseCase struct {
+	auditRepo interfaces.AuditRepository
+	logger    *logrus.Logger
+}
+
+// Record implements interfaces.AuditUseCase. The actor is taken from the
+// request when the entry has none. The action went through already, so a
+// failure is only logged.
+func (c *auditUseCase) Record(ctx context.Context, entry domain.AuditLog) {
+	if entry.ActorId == 0 {
+		entry = withActor(ctx, entry)
+	}
+	if _, err := c.auditRepo.AppendAudit(ctx, entry); err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"action":      entry.Action,
+			"target_type": entry.TargetType,
+			"target":      entry.TargetId,
+		}).Error("failed to record audit entry")
+	}
+}
+
+// ListAuditLogs implements interfaces.AuditUseCase
+func (c *auditUseCase) ListAuditLogs(ctx context.Context, query domain.AuditQuery, filter utils.Filter) ([]domain.AuditLog, utils.Metadata, error) {
+	return c.auditRepo.ListAuditLogs(ctx, query, filter)
+}
+
+// ExportAuditLogs implements interfaces.AuditUseCase. The CSV carries the
+// hashes, so the chain can be checked on the export too when it is unfiltered.
+func (c *auditUseCase) ExportAuditLogs(ctx context.Context, query domain.AuditQuery) ([]byte, error) {
+	var file bytes.Buffer
+	writer := csv.NewWriter(&file)
+	writer.Write([]string{"id", "created_at", "actor_id", "actor_role", "action", "target_type", "target_id", "before", "after", "ip", "user_agent", "prev_hash", "hash"})
+
+	afterId, count := 0, 0
+	for {
+		entries, err := c.auditRepo.ListAuditRange(ctx, query, afterId, auditBatch)
+		if err != nil {
+			return nil, err
+		}
+		count += len(entries)
+		if count > auditExportLimit {
+			return nil, domain.ErrExportTooLarge
+		}
+		for _, entry := range entries {
+			writer.Write([]string{
+				strconv.Itoa(entry.IdAudit),
+				entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+				strconv.Itoa(entry.ActorId),
+				entry.ActorRole,
+				entry.Action,
+				entry.TargetType,
+				strconv.Itoa(entry.TargetId),
+				entry.Before,
+				entry.After,
+				entry.Ip,
+				entry.UserAgent,
+				entry.PrevHash,
+				entry.Hash,
+			})
+		}
+		if len(entries) < auditBatch {
+			break
+		}
+		afterId = entries[len(entries)-1].IdAudit
+	}
+
+	writer.Flush()
+	return file.Bytes(), writer.Error()
+}
+
+// VerifyChain implements interfaces.AuditUseCase. It walks the whole chain
+// and stops at the first record that does not hold.
+func (c *auditUseCase) VerifyChain(ctx context.Context) (domain.AuditVerification, error) {
+	var verification domain.AuditVerification
+	prevHash, afterId := "", 0
+	for {
+		entries, err := c.auditRepo.ListAuditRange(ctx, domain.AuditQuery{}, afterId, auditBatch)
+		if err != nil {
+			return verification, err
+		}
+		for _, entry := range entries {
+			if entry.PrevHash != prevHash || entry.Hash != entry.Digest() {
+				id := entry.IdAudit
+				verification.BrokenAt = &id
+				return verification, nil
+			}
+			verification.Checked++
+			prevHash = entry.Hash
+		}
+		if len(entries) < auditBatch {
+			break
+		}
+		afterId = entries[len(entries)-1].IdAudit
+	}
+	verification.Valid = true
+	return verification, nil
+}
+
+// auditEntry builds the record of an action taken in the request behind ctx.
+// before and after are the states around it, of which only what changed is
+// kept.
+func auditEntry(ctx context.Context, action string, targetType string, targetId int, before interface{}, after interface{}) (domain.AuditLog, error) {
+	entry := withActor(ctx, domain.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+	})
+	var err error
+	entry.Before, entry.After, err = auditDiff(before, after)
+	return entry, err
+}
+
+// withActor fills in who is behind the request
+func withActor(ctx context.Context, entry domain.AuditLog) domain.AuditLog {
+	if actor, ok := ctx.Value(domain.AuditActorKey).(domain.AuditActor); ok {
+		entry.ActorId = actor.Id
+		entry.ActorRole = actor.Role
+		entry.Ip = actor.Ip
+		entry.UserAgent = actor.UserAgent
+	}
+	return entry
+}
+
+// auditDiff renders the fields that differ between before and after, both
+// of which marshal to JSON objects
+func auditDiff(before interface{}, after interface{}) (string, string, error) {
+	var prev, next map[string]interface{}
+	for _, state := range []struct {
+		value interface{}
+		into  *map[string]interface{}
+	}{{before, &prev}, {after, &next}} {
+		raw, err := json.Marshal(state.value)
+		if err != nil {
+			return "", "", err
+		}
+		if err = json.Unmarshal(raw, state.into); err != nil {
+			return "", "", err
+		}
+	}
+
+	changedPrev, changedNext := map[string]interface{}{}, map[string]interface{}{}
+	for key, value := range prev {
+		if !reflect.DeepEqual(value, next[key]) {
+			changedPrev[key] = value
+		}
+	}
+	for key, value := range next {
+		if !reflect.DeepEqual(value, prev[key]) {
+			changedNext[key] = value
+		}
+	}
+	rawPrev, err := json.Marshal(changedPrev)
+	if err != nil {
+		return "", "", err
+	}
+	rawNext, err := json.Marshal(changedNext)
+	return string(rawPrev), string(rawNext), err
+}
+
+func NewAuditService(
+	auditRepo interfaces.AuditRepository,
+	logger *logrus.Logger) services.AuditUseCase {
+	return &auditUseCase{
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
@@ -2,10 +2,13 @@ package usecase
 
 import (
 	"context"
+	"strings"
 
 	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 )
 
 type authUseCase struct {
@@ -17,14 +20,37 @@ type authUseCase struct {
 	config       config.Config
 }
 
-// SendOTP implements interfaces.AuthUseCase
-func (c *authUseCase) SendOTP(ctx context.Context, phoneNumber string) error {
-	return c.twilioConfig.SendOTP(c.config, phoneNumber)
+// ParsePhone implements interfaces.AuthUseCase. A number that carries its
+// own calling code goes by it over countryCode, and one with neither is read
+// as a number of the configured default region.
+func (c *authUseCase) ParsePhone(countryCode string, number string) (domain.PhoneNumber, error) {
+	number = strings.TrimSpace(number)
+	if countryCode != "" && !strings.HasPrefix(number, "+") && !strings.HasPrefix(number, "00") {
+		number = countryCode + number
+	}
+	return utils.ParsePhone(number, phoneRegion(c.config))
+}
+
+// SendOTP implements interfaces.AuthUseCase. Only a number that may be a
+// mobile one is sent a code.
+func (c *authUseCase) SendOTP(ctx context.Context, phone domain.PhoneNumber) error {
+	if !utils.CanReceiveSMS(phone) {
+		return domain.ErrNotMobile
+	}
+	return c.twilioConfig.SendOTP(c.config, phone.E164)
 }
 
 // VarifyOTP implements interfaces.AuthUseCase
-func (c *authUseCase) VarifyOTP(ctx context.Context, phoneNumber string, otp string) error {
-	return c.twilioConfig.VerifyOTP(c.config, phoneNumber, otp)
+func (c *authUseCase) VarifyOTP(ctx context.Context, phone domain.PhoneNumber, otp string) error {
+	return c.twilioConfig.VerifyOTP(c.config, phone.E164, otp)
+}
+
+// phoneRegion is the region of numbers given without a calling code
+func phoneRegion(cfg config.Config) string {
+	if cfg.DefaultPhoneRegion == "" {
+		return domain.DefaultPhoneRegion
+	}
+	return cfg.DefaultPhoneRegion
 }
 
 func NewAuthService(
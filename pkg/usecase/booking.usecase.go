@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const dateLayout = "2006-01-02"
+
+// Working hours of each slot in the worker's local time
+var slotHours = map[string][2]int{
+	domain.SlotFullDay:    {9, 18},
+	domain.SlotFirstHalf:  {9, 13},
+	domain.SlotSecondHalf: {13, 18},
+}
+
+type bookingUseCase struct {
+	bookingRepo         interfaces.BookingRepository
+	workerRepo          interfaces.WorkerRepository
+	notificationUseCase services.NotificationUseCase
+	paymentUseCase      services.PaymentUseCase
+	cancellationUseCase services.CancellationUseCase
+	invoiceUseCase      services.InvoiceUseCase
+	promoUseCase        services.PromoUseCase
+	referralUseCase     services.ReferralUseCase
+	transactor          interfaces.Transactor
+	logger              *logrus.Logger
+}
+
+// Book implements interfaces.BookingUseCase
+func (c *bookingUseCase) Book(ctx context.Context, userId int, booking domain.BookingInput) (domain.BookingResponse, error) {
+	job, err := c.bookingRepo.FindJob(ctx, booking.JobId)
+	if err != nil {
+		return domain.BookingResponse{}, err
+	}
+	if !job.Openwork {
+		return domain.BookingResponse{}, domain.ErrJobNotOpen
+	}
+	if job.IdWorker == userId {
+		return domain.BookingResponse{}, domain.ErrOwnJob
+	}
+
+	availability, err := c.workerRepo.GetAvailability(ctx, job.IdWorker)
+	if err != nil {
+		return domain.BookingResponse{}, err
+	}
+	if len(availability) == 0 {
+		return domain.BookingResponse{}, domain.ErrSlotUnavailable
+	}
+	location, err := time.LoadLocation(availability[0].Timezone)
+	if err != nil {
+		return domain.BookingResponse{}, err
+	}
+
+	day, err := time.ParseInLocation(dateLayout, booking.Date, location)
+	if err != nil {
+		return domain.BookingResponse{}, domain.ErrInvalidDate
+	}
+	hours := slotHours[booking.Slot]
+	startAt := time.Date(day.Year(), day.Month(), day.Day(), hours[0], 0, 0, 0, location)
+	endAt := time.Date(day.Year(), day.Month(), day.Day(), hours[1], 0, 0, 0, location)
+	if !startAt.After(time.Now()) {
+		return domain.BookingResponse{}, domain.ErrSlotStarted
+	}
+
+	request := domain.Request{
+		UserId:    userId,
+		JobId:     job.IdJob,
+		AddressId: booking.AddressId,
+		Status:    domain.RequestPending,
+		Date:      time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC),
+		Slot:      booking.Slot,
+		Timezone:  location.String(),
+		StartAt:   startAt,
+		EndAt:     endAt,
+		Amount:    slotPrice(job, booking.Slot),
+		Currency:  domain.DefaultCurrency,
+	}
+	if booking.PromoCode != "" {
+		promo, discount, err := c.promoUseCase.ApplyPromoCode(ctx, userId, booking.PromoCode, job, request.Amount)
+		if err != nil {
+			return domain.BookingResponse{}, err
+		}
+		request.Discount, request.PromoCodeId = discount, &promo.IdPromoCode
+	}
+
+	id, err := c.bookingRepo.CreateBooking(ctx, request, job.IdWorker)
+	if err != nil {
+		return domain.BookingResponse{}, err
+	}
+
+	created, err := c.bookingRepo.FindBooking(ctx, id)
+	if err != nil {
+		return created, err
+	}
+	c.notify(ctx, created.WorkerId, domain.EventBookingCreated, created)
+	return created, nil
+}
+
+// ListUserBookings implements interfaces.BookingUseCase
+func (c *bookingUseCase) ListUserBookings(ctx context.Context, userId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error) {
+	return c.bookingRepo.ListUserBookings(ctx, userId, filter)
+}
+
+// ListWorkerBookings implements interfaces.BookingUseCase
+func (c *bookingUseCase) ListWorkerBookings(ctx context.Context, workerId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error) {
+	return c.bookingRepo.ListWorkerBookings(ctx, workerId, filter)
+}
+
+// AcceptBooking implements interfaces.BookingUseCase
+func (c *bookingUseCase) AcceptBooking(ctx context.Context, workerId int, requestId int) error {
+	booking, err := c.workerBooking(ctx, workerId, requestId)
+	if err != nil {
+		return err
+	}
+	err = c.transition(ctx, booking, domain.RequestAccepted, domain.RequestPending)
+	if err != nil {
+		return err
+	}
+	c.notify(ctx, booking.UserId, domain.EventBookingAccepted, booking)
+	return nil
+}
+
+// RejectBooking implements interfaces.BookingUseCase
+func (c *bookingUseCase) RejectBooking(ctx context.Context, workerId int, requestId int) error {
+	booking, err := c.workerBooking(ctx, workerId, requestId)
+	if err != nil {
+		return err
+	}
+	err = c.transition(ctx, booking, domain.RequestRejected, domain.RequestPending)
+	if err != nil {
+		return err
+	}
+	c.releasePromo(ctx, booking)
+	c.notify(ctx, booking.UserId, domain.EventBookingRejected, booking)
+	return nil
+}
+
+// CompleteBooking implements interfaces.BookingUseCase
+func (c *bookingUseCase) CompleteBooking(ctx context.Context, workerId int, requestId int, completion domain.CompletionInput) error {
+	booking, err := c.workerBooking(ctx, workerId, requestId)
+	if err != nil {
+		return err
+	}
+	if booking.Status != domain.RequestAccepted {
+		return domain.ErrInvalidTransition.WithMessage("cannot move a " + booking.Status + " request to " + domain.RequestCompleted)
+	}
+	// Completing the request releases the escrowed payment to the worker, and
+	// the materials are only kept for a request that got completed
+	err = c.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := c.invoiceUseCase.SetMaterials(ctx, booking, completion.Materials); err != nil {
+			return err
+		}
+		_, err := c.paymentUseCase.Release(ctx, booking)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// Missing invoices are issued when they are next asked for
+	if _, err = c.invoiceUseCase.IssueInvoices(ctx, requestId); err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("request", requestId).Error("failed to issue invoices")
+	}
+	if err = c.referralUseCase.Reward(ctx, booking); err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("request", requestId).Error("failed to reward referral")
+	}
+	return nil
+}
+
+// CancelBooking implements interfaces.BookingUseCase
+func (c *bookingUseCase) CancelBooking(ctx context.Context, userId int, requestId int) (domain.PolicyDecision, error) {
+	booking, err := c.userBooking(ctx, userId, requestId)
+	if err != nil {
+		return domain.PolicyDecision{}, err
+	}
+	decision, err := c.cancellationUseCase.Cancel(ctx, booking, userId, domain.CancelByUser)
+	if err != nil {
+		return decision, err
+	}
+	c.releasePromo(ctx, booking)
+	c.notify(ctx, booking.WorkerId, domain.EventBookingCancelled, booking)
+	return decision, nil
+}
+
+// WorkerCancelBooking implements interfaces.BookingUseCase
+func (c *bookingUseCase) WorkerCancelBooking(ctx context.Context, workerId int, requestId int) (domain.PolicyDecision, error) {
+	booking, err := c.workerBooking(ctx, workerId, requestId)
+	if err != nil {
+		return domain.PolicyDecision{}, err
+	}
+	decision, err := c.cancellationUseCase.Cancel(ctx, booking, workerId, domain.CancelByWorker)
+	if err != nil {
+		return decision, err
+	}
+	c.releasePromo(ctx, booking)
+	c.notify(ctx, booking.UserId, domain.EventBookingCancelled, booking)
+	return decision, nil
+}
+
+// ReportNoShow implements interfaces.BookingUseCase
+func (c *bookingUseCase) ReportNoShow(ctx context.Context, userId int, requestId int) (domain.PolicyDecision, error) {
+	booking, err := c.userBooking(ctx, userId, requestId)
+	if err != nil {
+		return domain.PolicyDecision{}, err
+	}
+	decision, err := c.cancellationUseCase.Cancel(ctx, booking, userId, domain.WorkerNoShow)
+	if err != nil {
+		return decision, err
+	}
+	c.releasePromo(ctx, booking)
+	c.notify(ctx, booking.WorkerId, domain.EventBookingCancelled, booking)
+	return decision, nil
+}
+
+func (c *bookingUseCase) userBooking(ctx context.Context, userId int, requestId int) (domain.BookingResponse, error) {
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return booking, err
+	}
+	if booking.UserId != userId {
+		return booking, domain.ErrNoRequest
+	}
+	return booking, nil
+}
+
+func (c *bookingUseCase) workerBooking(ctx context.Context, workerId int, requestId int) (domain.BookingResponse, error) {
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return booking, err
+	}
+	if booking.WorkerId != workerId {
+		return booking, domain.ErrNoRequest
+	}
+	return booking, nil
+}
+
+// transition moves a booking to a new status if its current status is one of from
+func (c *bookingUseCase) transition(ctx context.Context, booking domain.BookingResponse, to string, from ...string) error {
+	for _, status := range from {
+		if booking.Status == status {
+			return c.bookingRepo.UpdateStatus(ctx, booking.IdRequest, booking.Status, to)
+		}
+	}
+	return domain.ErrInvalidTransition.WithMessage("cannot move a " + booking.Status + " request to " + to)
+}
+
+// releasePromo gives back the promo code a called off booking used. Like a
+// notification it follows a committed change, so a failure is only logged.
+func (c *bookingUseCase) releasePromo(ctx context.Context, booking domain.BookingResponse) {
+	if booking.PromoCodeId == nil {
+		return
+	}
+	if err := c.promoUseCase.ReleasePromoCode(ctx, booking.IdRequest); err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("request", booking.IdRequest).Error("failed to release promo code")
+	}
+}
+
+// slotPrice is the list price of booking a slot of a job, in minor units
+func slotPrice(job domain.Job, slot string) int64 {
+	if slot == domain.SlotFullDay {
+		return int64(job.FullDayWage) * 100
+	}
+	return int64(job.HalfDayWage) * 100
+}
+
+// notify tells the other side of a booking about a change. The change is
+// already committed, so a failing notification is logged rather than returned.
+func (c *bookingUseCase) notify(ctx context.Context, userId int, event string, booking domain.BookingResponse) {
+	err := c.notificationUseCase.Notify(ctx, userId, event, domain.NotificationData{
+		RequestId: booking.IdRequest,
+		Date:      booking.Date,
+		Slot:      booking.Slot,
+	})
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{"request": booking.IdRequest, "event": event}).Error("failed to notify")
+	}
+}
+
+func NewBookingService(
+	bookingRepo interfaces.BookingRepository,
+	workerRepo interfaces.WorkerRepository,
+	notificationUseCase services.NotificationUseCase,
+	paymentUseCase services.PaymentUseCase,
+	cancellationUseCase services.CancellationUseCase,
+	invoiceUseCase services.InvoiceUseCase,
+	promoUseCase services.PromoUseCase,
+	referralUseCase services.ReferralUseCase,
+	transactor interfaces.Transactor,
+	logger *logrus.Logger) services.BookingUseCase {
+	return &bookingUseCase{
+		bookingRepo:         bookingRepo,
+		workerRepo:          workerRepo,
+		notificationUseCase: notificationUseCase,
+		paymentUseCase:      paymentUseCase,
+		cancellationUseCase: cancellationUseCase,
+		invoiceUseCase:      invoiceUseCase,
+		promoUseCase:        promoUseCase,
+		referralUseCase:     referralUseCase,
+		transactor:          transactor,
+		logger:              logger,
+	}
+}
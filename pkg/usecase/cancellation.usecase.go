@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/sirupsen/logrus"
+)
+
+// noShowGrace is how long after the start a worker may still turn up before
+// the user can report a no-show
+const noShowGrace = time.Hour
+
+type cancellationUseCase struct {
+	cancellationRepo interfaces.CancellationRepository
+	paymentRepo      interfaces.PaymentRepository
+	gateway          config.PaymentGateway
+	config           config.Config
+	logger           *logrus.Logger
+}
+
+// GetPolicy implements interfaces.CancellationUseCase
+func (c *cancellationUseCase) GetPolicy(ctx context.Context, categoryId int) (domain.CancellationPolicy, error) {
+	policy, err := c.cancellationRepo.FindPolicy(ctx, categoryId)
+	if err != nil && errors.Is(err, domain.ErrNoPolicy) {
+		policy = domain.DefaultCancellationPolicy
+		policy.CategoryId = categoryId
+		return policy, nil
+	}
+	return policy, err
+}
+
+// SetPolicy implements interfaces.CancellationUseCase
+func (c *cancellationUseCase) SetPolicy(ctx context.Context, categoryId int, input domain.CancellationPolicyInput) (domain.CancellationPolicy, error) {
+	return c.cancellationRepo.SetPolicy(ctx, domain.CancellationPolicy{
+		CategoryId: categoryId,
+		FreeHours:  input.FreeHours,
+		LateFeeBps: input.LateFeeBps,
+	})
+}
+
+// Cancel implements interfaces.CancellationUseCase
+func (c *cancellationUseCase) Cancel(ctx context.Context, booking domain.BookingResponse, actorId int, reason string) (domain.PolicyDecision, error) {
+	now := time.Now()
+	settlement := domain.Settlement{
+		RequestId:     booking.IdRequest,
+		To:            domain.RequestCancelled,
+		CommissionBps: commissionBps(c.config),
+	}
+	switch reason {
+	case domain.CancelByUser:
+		settlement.From = []string{domain.RequestPending, domain.RequestAccepted}
+	case domain.CancelByWorker:
+		settlement.From = []string{domain.RequestAccepted}
+	case domain.WorkerNoShow:
+		if now.Before(booking.StartAt.Add(noShowGrace)) {
+			return domain.PolicyDecision{}, domain.ErrNoShowTooEarly
+		}
+		settlement.From = []string{domain.RequestAccepted}
+		settlement.To = domain.RequestNoShow
+	default:
+		return domain.PolicyDecision{}, domain.ErrUnknownReason.WithMessage("unknown cancellation reason " + reason)
+	}
+
+	policy, err := c.GetPolicy(ctx, booking.CategoryId)
+	if err != nil {
+		return domain.PolicyDecision{}, err
+	}
+	settlement.Decision = decide(policy, booking, now, reason)
+	settlement.Decision.ActorId = actorId
+
+	decision, refund, err := c.cancellationRepo.Settle(ctx, settlement)
+	if err != nil {
+		return decision, err
+	}
+	if refund != nil {
+		sendRefund(ctx, c.gateway, c.config, c.paymentRepo, c.logger, *refund)
+	}
+	return decision, nil
+}
+
+// decide applies a cancellation policy. The worker calling off or not turning
+// up always refunds the user in full, a user gets back what the policy allows
+// for how late they cancel.
+func decide(policy domain.CancellationPolicy, booking domain.BookingResponse, now time.Time, reason string) domain.PolicyDecision {
+	decision := domain.PolicyDecision{
+		FreeHours:     policy.FreeHours,
+		LateFeeBps:    policy.LateFeeBps,
+		Reason:        reason,
+		MinutesBefore: int(booking.StartAt.Sub(now).Minutes()),
+	}
+	if policy.IdPolicy != 0 {
+		decision.PolicyId = &policy.IdPolicy
+	}
+
+	switch {
+	case reason != domain.CancelByUser:
+		decision.RefundBps = 10000
+	case !now.Before(booking.StartAt):
+		decision.RefundBps = 0
+	case booking.StartAt.Sub(now) >= time.Duration(policy.FreeHours)*time.Hour:
+		decision.RefundBps = 10000
+	default:
+		decision.RefundBps = 10000 - policy.LateFeeBps
+	}
+
+	switch decision.RefundBps {
+	case 10000:
+		decision.Outcome = domain.OutcomeFullRefund
+	case 0:
+		decision.Outcome = domain.OutcomeNoRefund
+	default:
+		decision.Outcome = domain.OutcomePartialRefund
+	}
+	return decision
+}
+
+// ListDecisions implements interfaces.CancellationUseCase
+func (c *cancellationUseCase) ListDecisions(ctx context.Context, requestId int) ([]domain.PolicyDecision, error) {
+	return c.cancellationRepo.ListDecisions(ctx, requestId)
+}
+
+func NewCancellationService(
+	cancellationRepo interfaces.CancellationRepository,
+	paymentRepo interfaces.PaymentRepository,
+	gateway config.PaymentGateway,
+	cfg config.Config,
+	logger *logrus.Logger) services.CancellationUseCase {
+	return &cancellationUseCase{
+		cancellationRepo: cancellationRepo,
+		paymentRepo:      paymentRepo,
+		gateway:          gateway,
+		config:           cfg,
+		logger:           logger,
+	}
+}
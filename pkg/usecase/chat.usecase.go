@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+const (
+	maxMessageLength = 2000
+	// chatBuffer is how many events a slow subscriber may fall behind before
+	// further events to it are dropped
+	chatBuffer = 32
+)
+
+type chatUseCase struct {
+	chatRepo    interfaces.ChatRepository
+	bookingRepo interfaces.BookingRepository
+	hub         *chatHub
+}
+
+// JoinChat implements interfaces.ChatUseCase
+func (c *chatUseCase) JoinChat(ctx context.Context, actorId int, requestId int) (string, error) {
+	_, party, err := c.participant(ctx, actorId, requestId)
+	return party, err
+}
+
+// SendMessage implements interfaces.ChatUseCase
+func (c *chatUseCase) SendMessage(ctx context.Context, actorId int, requestId int, body string) (domain.Message, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return domain.Message{}, domain.ErrEmptyMessage
+	}
+	if len(body) > maxMessageLength {
+		return domain.Message{}, domain.ErrMessageTooLong
+	}
+
+	booking, party, err := c.participant(ctx, actorId, requestId)
+	if err != nil {
+		return domain.Message{}, err
+	}
+	if booking.Status != domain.RequestAccepted && booking.Status != domain.RequestCompleted {
+		body = utils.MaskContactDetails(body)
+	}
+
+	message, err := c.chatRepo.CreateMessage(ctx, domain.Message{
+		RequestId:  requestId,
+		SenderId:   actorId,
+		SenderRole: party,
+		Body:       body,
+	})
+	if err != nil {
+		return message, err
+	}
+
+	c.hub.publish(domain.ChatEvent{
+		Type:      domain.ChatEventMessage,
+		RequestId: requestId,
+		SenderId:  actorId,
+		Message:   &message,
+		At:        message.CreatedAt,
+	})
+	return message, nil
+}
+
+// MarkRead implements interfaces.ChatUseCase
+func (c *chatUseCase) MarkRead(ctx context.Context, actorId int, requestId int, readTo int) error {
+	if _, _, err := c.participant(ctx, actorId, requestId); err != nil {
+		return err
+	}
+
+	marked, err := c.chatRepo.MarkRead(ctx, requestId, actorId, readTo)
+	if err != nil || marked == 0 {
+		return err
+	}
+
+	c.hub.publish(domain.ChatEvent{
+		Type:      domain.ChatEventRead,
+		RequestId: requestId,
+		SenderId:  actorId,
+		ReadTo:    readTo,
+		At:        time.Now(),
+	})
+	return nil
+}
+
+// Typing implements interfaces.ChatUseCase
+func (c *chatUseCase) Typing(ctx context.Context, actorId int, requestId int) error {
+	if _, _, err := c.participant(ctx, actorId, requestId); err != nil {
+		return err
+	}
+	c.hub.publish(domain.ChatEvent{
+		Type:      domain.ChatEventTyping,
+		RequestId: requestId,
+		SenderId:  actorId,
+		At:        time.Now(),
+	})
+	return nil
+}
+
+// ListMessages implements interfaces.ChatUseCase
+func (c *chatUseCase) ListMessages(ctx context.Context, actorId int, requestId int, filter utils.Filter) ([]domain.Message, utils.Metadata, error) {
+	if _, _, err := c.participant(ctx, actorId, requestId); err != nil {
+		return nil, utils.Metadata{}, err
+	}
+	return c.chatRepo.ListMessages(ctx, requestId, filter)
+}
+
+// Subscribe implements interfaces.ChatUseCase
+func (c *chatUseCase) Subscribe(userId int, requestId int) (<-chan domain.ChatEvent, func()) {
+	return c.hub.subscribe(userId, requestId)
+}
+
+// Disconnect implements interfaces.ChatUseCase
+func (c *chatUseCase) Disconnect(userId int) {
+	c.hub.disconnect(userId)
+}
+
+// participant loads the request and tells which side of it the actor is on
+func (c *chatUseCase) participant(ctx context.Context, actorId int, requestId int) (domain.BookingResponse, string, error) {
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return booking, "", err
+	}
+	switch actorId {
+	case booking.UserId:
+		return booking, domain.PartyUser, nil
+	case booking.WorkerId:
+		return booking, domain.PartyWorker, nil
+	}
+	return booking, "", domain.ErrNoRequest
+}
+
+// chatHub fans chat events out to the sockets connected to each request. It
+// lives in process memory, so every participant of a request has to be
+// connected to the same API instance.
+type chatHub struct {
+	mu    sync.RWMutex
+	rooms map[int]map[*chatSubscription]struct{}
+}
+
+// chatSubscription is a socket of userId listening to a request. Its events
+// are closed once, by whichever of the socket and the hub lets go first.
+type chatSubscription struct {
+	userId    int
+	requestId int
+	events    chan domain.ChatEvent
+	once      sync.Once
+}
+
+func (h *chatHub) subscribe(userId int, requestId int) (<-chan domain.ChatEvent, func()) {
+	sub := &chatSubscription{
+		userId:    userId,
+		requestId: requestId,
+		events:    make(chan domain.ChatEvent, chatBuffer),
+	}
+
+	h.mu.Lock()
+	if h.rooms[requestId] == nil {
+		h.rooms[requestId] = map[*chatSubscription]struct{}{}
+	}
+	h.rooms[requestId][sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub.events, func() {
+		h.mu.Lock()
+		h.remove(sub)
+		h.mu.Unlock()
+	}
+}
+
+// disconnect lets go of every socket of userId, which ends them
+func (h *chatHub) disconnect(userId int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, room := range h.rooms {
+		for sub := range room {
+			if sub.userId == userId {
+				h.remove(sub)
+			}
+		}
+	}
+}
+
+// remove takes sub out of its room and closes its events. The hub has to be
+// locked.
+func (h *chatHub) remove(sub *chatSubscription) {
+	delete(h.rooms[sub.requestId], sub)
+	if len(h.rooms[sub.requestId]) == 0 {
+		delete(h.rooms, sub.requestId)
+	}
+	sub.once.Do(func() { close(sub.events) })
+}
+
+func (h *chatHub) publish(event domain.ChatEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.rooms[event.RequestId] {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+func NewChatService(
+	chatRepo interfaces.ChatRepository,
+	bookingRepo interfaces.BookingRepository) services.ChatUseCase {
+	return &chatUseCase{
+		chatRepo:    chatRepo,
+		bookingRepo: bookingRepo,
+		hub: &chatHub{
+			rooms: map[int]map[*chatSubscription]struct{}{},
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatHubDisconnect(t *testing.T) {
+	hub := &chatHub{rooms: map[int]map[*chatSubscription]struct{}{}}
+
+	blocked, unsubscribeBlocked := hub.subscribe(1, 10)
+	other, unsubscribeOther := hub.subscribe(2, 10)
+	elsewhere, _ := hub.subscribe(1, 11)
+	defer unsubscribeOther()
+
+	hub.disconnect(1)
+
+	_, open := <-blocked
+	assert.False(t, open)
+	_, open = <-elsewhere
+	assert.False(t, open)
+	assert.NotContains(t, hub.rooms, 11)
+
+	hub.publish(domain.ChatEvent{Type: domain.ChatEventTyping, RequestId: 10})
+	event := <-other
+	assert.Equal(t, domain.ChatEventTyping, event.Type)
+
+	// The socket letting go afterwards is harmless
+	unsubscribeBlocked()
+	assert.Len(t, hub.rooms[10], 1)
+}
@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// evidenceTypes are the sniffed content types accepted as evidence
+var evidenceTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"video/mp4":       true,
+}
+
+type disputeUseCase struct {
+	disputeRepo         interfaces.DisputeRepository
+	bookingRepo         interfaces.BookingRepository
+	paymentRepo         interfaces.PaymentRepository
+	userRepo            interfaces.UserRepository
+	paymentUseCase      services.PaymentUseCase
+	notificationUseCase services.NotificationUseCase
+	fileStore           config.FileStore
+	logger              *logrus.Logger
+}
+
+// OpenDispute implements interfaces.DisputeUseCase. Requests that never went
+// ahead, pending or rejected ones, cannot be disputed.
+func (c *disputeUseCase) OpenDispute(ctx context.Context, actorId int, requestId int, input domain.DisputeInput) (domain.Dispute, error) {
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	var respondent int
+	switch actorId {
+	case booking.UserId:
+		respondent = booking.WorkerId
+	case booking.WorkerId:
+		respondent = booking.UserId
+	default:
+		return domain.Dispute{}, domain.ErrNoRequest
+	}
+	if booking.Status == domain.RequestPending || booking.Status == domain.RequestRejected {
+		return domain.Dispute{}, domain.ErrNotDisputable.WithMessage(fmt.Sprintf("a %s request cannot be disputed", booking.Status))
+	}
+
+	dispute, err := c.disputeRepo.OpenDispute(ctx, domain.Dispute{
+		RequestId:   requestId,
+		OpenedBy:    actorId,
+		Respondent:  respondent,
+		Reason:      input.Reason,
+		Description: input.Description,
+	})
+	if err != nil {
+		return dispute, err
+	}
+	c.notify(ctx, dispute, domain.EventDisputeOpened, respondent)
+	return dispute, nil
+}
+
+// ListDisputes implements interfaces.DisputeUseCase
+func (c *disputeUseCase) ListDisputes(ctx context.Context, actorId int, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error) {
+	return c.disputeRepo.ListPartyDisputes(ctx, actorId, filter)
+}
+
+// GetDispute implements interfaces.DisputeUseCase
+func (c *disputeUseCase) GetDispute(ctx context.Context, actorId int, disputeId int) (domain.DisputeDetail, error) {
+	dispute, err := c.partyDispute(ctx, actorId, disputeId)
+	if err != nil {
+		return domain.DisputeDetail{}, err
+	}
+	return c.detail(ctx, dispute, false)
+}
+
+// AddEvidence implements interfaces.DisputeUseCase. The file type is sniffed
+// from its content, whatever the name or the client says it is.
+func (c *disputeUseCase) AddEvidence(ctx context.Context, actorId int, disputeId int, fileName string, data []byte) (domain.DisputeEvidence, error) {
+	dispute, err := c.partyDispute(ctx, actorId, disputeId)
+	if err != nil {
+		return domain.DisputeEvidence{}, err
+	}
+	if dispute.Status == domain.DisputeResolved {
+		return domain.DisputeEvidence{}, domain.ErrDisputeResolved
+	}
+	if len(data) == 0 || len(data) > domain.MaxEvidenceSize {
+		return domain.DisputeEvidence{}, domain.ErrEvidenceSize.WithMessage(fmt.Sprintf("evidence must be between 1 byte and %d MB", domain.MaxEvidenceSize>>20))
+	}
+	contentType := http.DetectContentType(data)
+	if !evidenceTypes[contentType] {
+		return domain.DisputeEvidence{}, domain.ErrEvidenceType.WithMessage(fmt.Sprintf("%s files are not accepted as evidence", contentType))
+	}
+
+	key := fmt.Sprintf("disputes/%d/%s%s", disputeId, utils.RandomCode(16), strings.ToLower(filepath.Ext(fileName)))
+	if err = c.fileStore.Put(key, data); err != nil {
+		return domain.DisputeEvidence{}, err
+	}
+	return c.disputeRepo.AddEvidence(ctx, domain.DisputeEvidence{
+		DisputeId:   disputeId,
+		UploadedBy:  actorId,
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  key,
+	})
+}
+
+// EvidenceFile implements interfaces.DisputeUseCase
+func (c *disputeUseCase) EvidenceFile(ctx context.Context, actorId int, disputeId int, evidenceId int) (domain.DisputeEvidence, []byte, error) {
+	if _, err := c.partyDispute(ctx, actorId, disputeId); err != nil {
+		return domain.DisputeEvidence{}, nil, err
+	}
+	return c.AdminEvidenceFile(ctx, disputeId, evidenceId)
+}
+
+// PostMessage implements interfaces.DisputeUseCase
+func (c *disputeUseCase) PostMessage(ctx context.Context, actorId int, disputeId int, input domain.DisputeMessageInput) (domain.DisputeMessage, error) {
+	dispute, err := c.partyDispute(ctx, actorId, disputeId)
+	if err != nil {
+		return domain.DisputeMessage{}, err
+	}
+	if dispute.Status == domain.DisputeResolved {
+		return domain.DisputeMessage{}, domain.ErrDisputeResolved
+	}
+
+	message, err := c.disputeRepo.AddMessage(ctx, domain.DisputeMessage{
+		DisputeId: disputeId,
+		AuthorId:  actorId,
+		Body:      input.Body,
+	})
+	if err != nil {
+		return message, err
+	}
+	if dispute.AssignedTo != nil {
+		c.notify(ctx, dispute, domain.EventDisputeMessage, *dispute.AssignedTo)
+	}
+	return message, nil
+}
+
+// ListQueue implements interfaces.DisputeUseCase
+func (c *disputeUseCase) ListQueue(ctx context.Context, status string, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error) {
+	if status == "" {
+		status = domain.DisputeOpen
+	}
+	return c.disputeRepo.ListDisputesByStatus(ctx, status, filter)
+}
+
+// AdminGetDispute implements interfaces.DisputeUseCase
+func (c *disputeUseCase) AdminGetDispute(ctx context.Context, disputeId int) (domain.DisputeDetail, error) {
+	dispute, err := c.disputeRepo.FindDispute(ctx, disputeId)
+	if err != nil {
+		return domain.DisputeDetail{}, err
+	}
+	return c.detail(ctx, dispute, true)
+}
+
+// AdminEvidenceFile implements interfaces.DisputeUseCase
+func (c *disputeUseCase) AdminEvidenceFile(ctx context.Context, disputeId int, evidenceId int) (domain.DisputeEvidence, []byte, error) {
+	evidence, err := c.disputeRepo.FindEvidence(ctx, disputeId, evidenceId)
+	if err != nil {
+		return evidence, nil, err
+	}
+	data, err := c.fileStore.Get(evidence.StorageKey)
+	return evidence, data, err
+}
+
+// AssignDispute implements interfaces.DisputeUseCase. Without an assignee
+// the admin takes the case themselves.
+func (c *disputeUseCase) AssignDispute(ctx context.Context, adminId int, disputeId int, input domain.DisputeAssignInput) (domain.Dispute, error) {
+	assignee := input.AdminId
+	if assignee == 0 {
+		assignee = adminId
+	}
+	user, err := c.userRepo.FindUserWithId(ctx, assignee)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+	if user.UserType != domain.RoleAdmin {
+		return domain.Dispute{}, domain.ErrNotAdmin
+	}
+	return c.disputeRepo.AssignDispute(ctx, disputeId, assignee)
+}
+
+// AddNote implements interfaces.DisputeUseCase. A note that is not internal
+// is a message to both parties.
+func (c *disputeUseCase) AddNote(ctx context.Context, adminId int, disputeId int, input domain.DisputeNoteInput) (domain.DisputeMessage, error) {
+	dispute, err := c.disputeRepo.FindDispute(ctx, disputeId)
+	if err != nil {
+		return domain.DisputeMessage{}, err
+	}
+
+	message, err := c.disputeRepo.AddMessage(ctx, domain.DisputeMessage{
+		DisputeId: disputeId,
+		AuthorId:  adminId,
+		Body:      input.Body,
+		Internal:  input.Internal,
+	})
+	if err != nil {
+		return message, err
+	}
+	if !input.Internal {
+		c.notify(ctx, dispute, domain.EventDisputeMessage, dispute.OpenedBy, dispute.Respondent)
+	}
+	return message, nil
+}
+
+// ResolveDispute implements interfaces.DisputeUseCase. A refund outcome pays
+// back out of the request's released payment before the dispute is closed,
+// so a refund that cannot be made leaves the dispute open.
+func (c *disputeUseCase) ResolveDispute(ctx context.Context, adminId int, disputeId int, input domain.DisputeResolutionInput) (domain.Dispute, error) {
+	dispute, err := c.disputeRepo.FindDispute(ctx, disputeId)
+	if err != nil {
+		return dispute, err
+	}
+	if dispute.Status == domain.DisputeResolved {
+		return dispute, domain.ErrNoOpenDispute
+	}
+	booking, err := c.bookingRepo.FindBooking(ctx, dispute.RequestId)
+	if err != nil {
+		return dispute, err
+	}
+
+	resolution := domain.DisputeResolution{
+		DisputeId:  disputeId,
+		Outcome:    input.Outcome,
+		Resolution: input.Resolution,
+		ResolvedBy: adminId,
+	}
+
+	switch input.Outcome {
+	case domain.OutcomeFullRefund, domain.OutcomePartialRefund:
+		amount := input.Amount
+		if input.Outcome == domain.OutcomeFullRefund {
+			payment, err := c.paymentRepo.FindRequestPayment(ctx, dispute.RequestId)
+			if err != nil {
+				return dispute, err
+			}
+			amount = payment.Amount - payment.Refunded
+		}
+		if amount <= 0 {
+			return dispute, domain.ErrNothingToRefund
+		}
+		refund, err := c.paymentUseCase.RefundReleased(ctx, adminId, dispute.RequestId, domain.RefundInput{
+			Amount: amount,
+			Reason: input.Resolution,
+		})
+		if err != nil {
+			return dispute, err
+		}
+		resolution.RefundId = &refund.IdRefund
+	case domain.OutcomeWarning, domain.OutcomeSuspension:
+		switch input.Party {
+		case domain.PartyUser:
+			resolution.SanctionedId = booking.UserId
+		case domain.PartyWorker:
+			resolution.SanctionedId = booking.WorkerId
+		default:
+			return dispute, domain.ErrOutcomeParty.WithMessage(fmt.Sprintf("a %s needs the party it is for", input.Outcome))
+		}
+	}
+
+	dispute, err = c.disputeRepo.ResolveDispute(ctx, resolution)
+	if err != nil {
+		if resolution.RefundId != nil {
+			c.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{"dispute": disputeId, "refund": *resolution.RefundId}).Error("refund made but the dispute is not resolved")
+		}
+		return dispute, err
+	}
+	c.notify(ctx, dispute, domain.EventDisputeResolved, dispute.OpenedBy, dispute.Respondent)
+	return dispute, nil
+}
+
+// partyDispute loads a dispute the actor is a party of
+func (c *disputeUseCase) partyDispute(ctx context.Context, actorId int, disputeId int) (domain.Dispute, error) {
+	dispute, err := c.disputeRepo.FindDispute(ctx, disputeId)
+	if err != nil {
+		return dispute, err
+	}
+	if dispute.OpenedBy != actorId && dispute.Respondent != actorId {
+		return dispute, domain.ErrNoDispute
+	}
+	return dispute, nil
+}
+
+func (c *disputeUseCase) detail(ctx context.Context, dispute domain.Dispute, withInternal bool) (domain.DisputeDetail, error) {
+	detail := domain.DisputeDetail{Dispute: dispute}
+
+	evidence, err := c.disputeRepo.ListEvidence(ctx, dispute.IdDispute)
+	if err != nil {
+		return detail, err
+	}
+	messages, err := c.disputeRepo.ListMessages(ctx, dispute.IdDispute, withInternal)
+	if err != nil {
+		return detail, err
+	}
+	detail.Evidence, detail.Messages = evidence, messages
+	return detail, nil
+}
+
+// notify tells users about a change to a dispute. The change is already
+// committed, so a failing notification is logged rather than returned.
+func (c *disputeUseCase) notify(ctx context.Context, dispute domain.Dispute, event string, userIds ...int) {
+	for _, userId := range userIds {
+		err := c.notificationUseCase.Notify(ctx, userId, event, domain.NotificationData{RequestId: dispute.RequestId})
+		if err != nil {
+			c.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{"dispute": dispute.IdDispute, "event": event}).Error("failed to notify")
+		}
+	}
+}
+
+func NewDisputeService(
+	disputeRepo interfaces.DisputeRepository,
+	bookingRepo interfaces.BookingRepository,
+	paymentRepo interfaces.PaymentRepository,
+	userRepo interfaces.UserRepository,
+	paymentUseCase services.PaymentUseCase,
+	notificationUseCase services.NotificationUseCase,
+	fileStore config.FileStore,
+	logger *logrus.Logger) services.DisputeUseCase {
+	return &disputeUseCase{
+		disputeRepo:         disputeRepo,
+		bookingRepo:         bookingRepo,
+		paymentRepo:         paymentRepo,
+		userRepo:            userRepo,
+		paymentUseCase:      paymentUseCase,
+		notificationUseCase: notificationUseCase,
+		fileStore:           fileStore,
+		logger:              logger,
+	}
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+// exportBatch is how many records an export reads at a time
+const exportBatch = 500
+
+type exportUseCase struct {
+	exportRepo interfaces.ExportRepository
+}
+
+// exportReader reads the rows of an export that follow afterId, along with
+// the id of the last of them
+type exportReader func(ctx context.Context, query domain.ExportQuery, afterId int) ([][]interface{}, int, error)
+
+// Export implements interfaces.ExportUseCase. The table is written to w as
+// it is read. Nothing is written until the first batch is read, so an error
+// before then leaves w untouched.
+func (c *exportUseCase) Export(ctx context.Context, table string, query domain.ExportQuery, w io.Writer) error {
+	if query.Format == "" {
+		query.Format = utils.SheetCSV
+	}
+	if query.Format != utils.SheetCSV && query.Format != utils.SheetXLSX {
+		return domain.ErrUnknownFormat.WithMessage(fmt.Sprintf("unknown format %q, use csv or xlsx", query.Format))
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return domain.ErrInvalidRange
+	}
+
+	header, read, statuses, err := c.exportTable(table)
+	if err != nil {
+		return err
+	}
+	if query.Status != "" && !contains(statuses, query.Status) {
+		return domain.ErrUnknownStatus.WithMessage(fmt.Sprintf("unknown status %q", query.Status))
+	}
+
+	var sheet utils.SheetWriter
+	afterId := 0
+	for {
+		rows, lastId, err := read(ctx, query, afterId)
+		if err != nil {
+			return err
+		}
+		if sheet == nil {
+			if sheet, err = utils.NewSheetWriter(w, query.Format, table); err != nil {
+				return err
+			}
+			if err = sheet.WriteRow(header...); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			if err = sheet.WriteRow(row...); err != nil {
+				return err
+			}
+		}
+		if len(rows) < exportBatch {
+			break
+		}
+		afterId = lastId
+	}
+	return sheet.Close()
+}
+
+// exportTable is the header, reader and statuses to filter by of a table
+func (c *exportUseCase) exportTable(table string) ([]interface{}, exportReader, []string, error) {
+	accountStatuses := []string{domain.UserNew, domain.UserActive, domain.UserBlocked, domain.UserSuspended}
+
+	switch table {
+	case domain.ExportUsers, domain.ExportWorkers:
+		userType := domain.RoleUser
+		if table == domain.ExportWorkers {
+			userType = domain.RoleWorker
+		}
+		header := []interface{}{"id", "phone", "email", "type", "verified", "status", "suspended_until", "first_name", "last_name"}
+		return header, func(ctx context.Context, query domain.ExportQuery, afterId int) ([][]interface{}, int, error) {
+			accounts, err := c.exportRepo.ExportAccounts(ctx, userType, query, afterId, exportBatch)
+			var rows [][]interface{}
+			for _, account := range accounts {
+				rows = append(rows, []interface{}{account.Id, account.Phone, account.Email, account.UserType, account.Verification,
+					account.Status, account.SuspendedUntil, account.FirstName, account.LastName})
+				afterId = account.Id
+			}
+			return rows, afterId, err
+		}, accountStatuses, nil
+
+	case domain.ExportJobs:
+		header := []interface{}{"id", "worker_id", "category_id", "category", "experience", "description", "full_day_wage", "half_day_wage", "open", "priority", "badge"}
+		return header, func(ctx context.Context, query domain.ExportQuery, afterId int) ([][]interface{}, int, error) {
+			jobs, err := c.exportRepo.ExportJobs(ctx, query, afterId, exportBatch)
+			var rows [][]interface{}
+			for _, job := range jobs {
+				rows = append(rows, []interface{}{job.IdJob, job.WorkerId, job.CategoryId, job.Category, job.Expirience, job.Description,
+					job.FullDayWage, job.HalfDayWage, job.Openwork, job.Priority, job.Badge})
+				afterId = job.IdJob
+			}
+			return rows, afterId, err
+		}, []string{domain.JobOpen, domain.JobClosed}, nil
+
+	case domain.ExportRequests:
+		header := []interface{}{"id", "user_id", "worker_id", "job_id", "category", "date", "slot", "timezone", "start_at", "end_at",
+			"status", "amount", "discount", "currency", "price_locked", "promo_code_id", "created_at"}
+		return header, func(ctx context.Context, query domain.ExportQuery, afterId int) ([][]interface{}, int, error) {
+			bookings, err := c.exportRepo.ExportRequests(ctx, query, afterId, exportBatch)
+			var rows [][]interface{}
+			for _, booking := range bookings {
+				var promoCodeId interface{}
+				if booking.PromoCodeId != nil {
+					promoCodeId = *booking.PromoCodeId
+				}
+				rows = append(rows, []interface{}{booking.IdRequest, booking.UserId, booking.WorkerId, booking.JobId, booking.JobCategory,
+					booking.Date, booking.Slot, booking.Timezone, booking.StartAt, booking.EndAt, booking.Status, major(booking.Amount),
+					major(booking.Discount), booking.Currency, booking.PriceLocked, promoCodeId, booking.CreatedAt})
+				afterId = booking.IdRequest
+			}
+			return rows, afterId, err
+		}, []string{domain.RequestPending, domain.RequestAccepted, domain.RequestRejected, domain.RequestCancelled, domain.RequestCompleted, domain.RequestNoShow}, nil
+
+	case domain.ExportPayments:
+		header := []interface{}{"id", "request_id", "user_id", "worker_id", "amount", "discount", "commission", "currency", "gateway",
+			"gateway_order_id", "gateway_payment_id", "status", "held_at", "released_at", "created_at"}
+		return header, func(ctx context.Context, query domain.ExportQuery, afterId int) ([][]interface{}, int, error) {
+			payments, err := c.exportRepo.ExportPayments(ctx, query, afterId, exportBatch)
+			var rows [][]interface{}
+			for _, payment := range payments {
+				rows = append(rows, []interface{}{payment.IdPayment, payment.RequestId, payment.UserId, payment.WorkerId, major(payment.Amount),
+					major(payment.Discount), major(payment.Commission), payment.Currency, payment.Gateway, payment.GatewayOrderId,
+					payment.GatewayPaymentId, payment.Status, payment.HeldAt, payment.ReleasedAt, payment.CreatedAt})
+				afterId = payment.IdPayment
+			}
+			return rows, afterId, err
+		}, []string{domain.PaymentCreated, domain.PaymentHeld, domain.PaymentReleased, domain.PaymentFailed, domain.PaymentRefunded}, nil
+	}
+	return nil, nil, nil, domain.ErrNoExport.WithMessage("there is no export of " + table)
+}
+
+// major is an amount in minor units as a number of major units, which is how
+// a spreadsheet should show it
+func major(amount int64) float64 {
+	return float64(amount) / 100
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func NewExportService(exportRepo interfaces.ExportRepository) services.ExportUseCase {
+	return &exportUseCase{
+		exportRepo: exportRepo,
+	}
+}
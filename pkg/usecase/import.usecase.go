@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type importUseCase struct {
+	importRepo interfaces.ImportRepository
+	config     config.Config
+}
+
+// importSheet is the rows of an import under its header, which names the
+// columns in any order
+type importSheet struct {
+	columns map[string]int
+	rows    [][]string
+	report  domain.ImportReport
+}
+
+// ImportCategories implements interfaces.ImportUseCase. Names and icons have
+// to be new, names regardless of case.
+func (c *importUseCase) ImportCategories(ctx context.Context, data []byte, format string, dryRun bool) (domain.ImportReport, error) {
+	sheet, err := readImport(data, format, dryRun, []string{"category", "category_icon"})
+	if err != nil || len(sheet.report.Errors) > 0 {
+		return sheet.report, err
+	}
+
+	existing, err := c.importRepo.ListCategories(ctx)
+	if err != nil {
+		return sheet.report, err
+	}
+	names, icons := make(map[string]int), make(map[string]int)
+	for _, category := range existing {
+		names[strings.ToLower(category.Category)] = 0
+		icons[category.CategoryIcon] = 0
+	}
+
+	var categories []domain.Category
+	for i, row := range sheet.rows {
+		if blank(row) {
+			continue
+		}
+		line := i + 2
+		category := domain.Category{
+			Category:     sheet.cell(row, "category"),
+			CategoryIcon: sheet.cell(row, "category_icon"),
+		}
+
+		failed := len(sheet.report.Errors)
+		sheet.unique(line, "category", strings.ToLower(category.Category), names)
+		sheet.unique(line, "category_icon", category.CategoryIcon, icons)
+		if len(sheet.report.Errors) == failed {
+			sheet.report.Valid++
+			categories = append(categories, category)
+		}
+	}
+
+	if dryRun || len(sheet.report.Errors) > 0 {
+		return sheet.report, nil
+	}
+	sheet.report.Imported, err = c.importRepo.ImportCategories(ctx, categories)
+	return sheet.report, err
+}
+
+// ImportWorkers implements interfaces.ImportUseCase. The workers come in
+// verified, so phone numbers and emails have to be new. Phone numbers are kept
+// in E.164 format, those without a calling code being of the default region.
+// A category, when given, has to exist and needs the rest of the job with it.
+func (c *importUseCase) ImportWorkers(ctx context.Context, data []byte, format string, dryRun bool) (domain.ImportReport, error) {
+	sheet, err := readImport(data, format, dryRun, []string{"phone", "email", "first_name", "last_name"})
+	if err != nil || len(sheet.report.Errors) > 0 {
+		return sheet.report, err
+	}
+
+	existing, err := c.importRepo.ListCategories(ctx)
+	if err != nil {
+		return sheet.report, err
+	}
+	categoryIds := make(map[string]int)
+	for _, category := range existing {
+		categoryIds[strings.ToLower(category.Category)] = category.IdCategory
+	}
+
+	var phones []domain.PhoneNumber
+	var phoneErrs []error
+	var e164s, emails []string
+	for _, row := range sheet.rows {
+		phone, err := utils.ParsePhone(sheet.cell(row, "phone"), phoneRegion(c.config))
+		phones, phoneErrs = append(phones, phone), append(phoneErrs, err)
+		e164s = append(e164s, phone.E164)
+		emails = append(emails, strings.ToLower(sheet.cell(row, "email")))
+	}
+	taken, err := c.importRepo.TakenContacts(ctx, e164s, emails)
+	if err != nil {
+		return sheet.report, err
+	}
+	contacts := make(map[string]int)
+	for contact := range taken {
+		contacts[contact] = 0
+	}
+
+	var workers []domain.WorkerImport
+	for i, row := range sheet.rows {
+		if blank(row) {
+			continue
+		}
+		line := i + 2
+		failed := len(sheet.report.Errors)
+		worker := domain.WorkerImport{
+			Row:          line,
+			Phone:        phones[i].E164,
+			PhoneCountry: phones[i].Region,
+			Email:        emails[i],
+			FirstName:    sheet.cell(row, "first_name"),
+			LastName:     sheet.cell(row, "last_name"),
+		}
+
+		switch {
+		case sheet.cell(row, "phone") == "":
+			sheet.fail(line, "phone", "is required")
+		case errors.Is(phoneErrs[i], domain.ErrPhoneRegion):
+			sheet.fail(line, "phone", "is of a region that is not supported")
+		case phoneErrs[i] != nil:
+			sheet.fail(line, "phone", "is not a phone number")
+		default:
+			sheet.unique(line, "phone", worker.Phone, contacts)
+		}
+		if address, err := mail.ParseAddress(worker.Email); worker.Email != "" && (err != nil || address.Address != worker.Email) {
+			sheet.fail(line, "email", "is not an email address")
+		} else {
+			sheet.unique(line, "email", worker.Email, contacts)
+		}
+		if worker.FirstName == "" {
+			sheet.fail(line, "first_name", "is required")
+		}
+
+		if category := sheet.cell(row, "category"); category != "" {
+			worker.CategoryId = categoryIds[strings.ToLower(category)]
+			if worker.CategoryId == 0 {
+				sheet.fail(line, "category", "there is no such category")
+			}
+			worker.Expirience = sheet.cell(row, "experience")
+			if worker.Expirience == "" {
+				sheet.fail(line, "experience", "is required with a category")
+			}
+			worker.Description = sheet.cell(row, "description")
+			if worker.Description == "" {
+				sheet.fail(line, "description", "is required with a category")
+			}
+			worker.FullDayWage = sheet.wage(line, row, "full_day_wage")
+			worker.HalfDayWage = sheet.wage(line, row, "half_day_wage")
+		}
+
+		if len(sheet.report.Errors) == failed {
+			sheet.report.Valid++
+			workers = append(workers, worker)
+		}
+	}
+
+	if dryRun || len(sheet.report.Errors) > 0 {
+		return sheet.report, nil
+	}
+	sheet.report.Imported, err = c.importRepo.ImportWorkers(ctx, workers)
+	return sheet.report, err
+}
+
+// readImport reads an import and checks its header has the required columns.
+// A missing column is reported against the header row.
+func readImport(data []byte, format string, dryRun bool, required []string) (importSheet, error) {
+	sheet := importSheet{
+		columns: make(map[string]int),
+		report:  domain.ImportReport{DryRun: dryRun, Errors: []domain.ImportError{}},
+	}
+
+	rows, err := utils.ReadSheet(data, format)
+	if err != nil {
+		return sheet, err
+	}
+	if len(rows) == 0 {
+		return sheet, domain.ErrEmptyImport
+	}
+	if len(rows)-1 > domain.MaxImportRows {
+		return sheet, domain.ErrImportRows.WithMessage(fmt.Sprintf("an import can have %d rows at most", domain.MaxImportRows))
+	}
+
+	for i, column := range rows[0] {
+		sheet.columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range required {
+		if _, ok := sheet.columns[column]; !ok {
+			sheet.fail(1, column, "the column is missing")
+		}
+	}
+
+	sheet.rows = rows[1:]
+	for _, row := range sheet.rows {
+		if !blank(row) {
+			sheet.report.Rows++
+		}
+	}
+	return sheet, nil
+}
+
+// cell is the trimmed text of a row under column, empty when the row is short
+// or the header has no such column
+func (s *importSheet) cell(row []string, column string) string {
+	i, ok := s.columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func (s *importSheet) fail(line int, column string, message string) {
+	s.report.Errors = append(s.report.Errors, domain.ImportError{Row: line, Column: column, Message: message})
+}
+
+// unique fails a value that is empty, already taken or given on an earlier
+// row. seen maps values to the row they were given on, 0 for values taken
+// before the import.
+func (s *importSheet) unique(line int, column string, value string, seen map[string]int) {
+	first, ok := seen[value]
+	switch {
+	case value == "":
+		s.fail(line, column, "is required")
+	case ok && first == 0:
+		s.fail(line, column, "is already taken")
+	case ok:
+		s.fail(line, column, fmt.Sprintf("is already given on row %d", first))
+	default:
+		seen[value] = line
+	}
+}
+
+// wage reads a wage in whole currency units, which has to be above zero
+func (s *importSheet) wage(line int, row []string, column string) int {
+	wage, err := strconv.Atoi(s.cell(row, column))
+	if err != nil || wage <= 0 {
+		s.fail(line, column, "has to be a whole amount above zero")
+		return 0
+	}
+	return wage
+}
+
+func blank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func NewImportService(
+	importRepo interfaces.ImportRepository,
+	cfg config.Config) services.ImportUseCase {
+	return &importUseCase{
+		importRepo: importRepo,
+		config:     cfg,
+	}
+}
@@ -1,4 +1,15 @@
 package interfaces
 
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
 type AdminUseCase interface {
+	SearchAccounts(ctx context.Context, userType string, search string, status string, filter utils.Filter) ([]domain.AccountSummary, utils.Metadata, error)
+	GetAccount(ctx context.Context, userType string, userId int) (domain.AccountDetail, error)
+	ActOnAccount(ctx context.Context, adminId int, userId int, kind string, input domain.AccountActionInput) (domain.AccountAction, error)
+	ListLogins(ctx context.Context, userId int, filter utils.Filter) ([]domain.LoginEvent, utils.Metadata, error)
 }
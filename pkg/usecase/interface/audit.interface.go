@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type AuditUseCase interface {
+	Record(ctx context.Context, entry domain.AuditLog)
+	ListAuditLogs(ctx context.Context, query domain.AuditQuery, filter utils.Filter) ([]domain.AuditLog, utils.Metadata, error)
+	ExportAuditLogs(ctx context.Context, query domain.AuditQuery) ([]byte, error)
+	VerifyChain(ctx context.Context) (domain.AuditVerification, error)
+}
@@ -1,8 +1,15 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
 
 type AuthUseCase interface {
-	SendOTP(ctx context.Context, phoneNumber string) error
-	VarifyOTP(ctx context.Context, phoneNumber string, otp string) error
+	// ParsePhone reads a phone number along with the country calling code it
+	// was given apart, which may be left out
+	ParsePhone(countryCode string, number string) (domain.PhoneNumber, error)
+	SendOTP(ctx context.Context, phone domain.PhoneNumber) error
+	VarifyOTP(ctx context.Context, phone domain.PhoneNumber, otp string) error
 }
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type BookingUseCase interface {
+	Book(ctx context.Context, userId int, booking domain.BookingInput) (domain.BookingResponse, error)
+	ListUserBookings(ctx context.Context, userId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error)
+	ListWorkerBookings(ctx context.Context, workerId int, filter utils.Filter) ([]domain.BookingResponse, utils.Metadata, error)
+	AcceptBooking(ctx context.Context, workerId int, requestId int) error
+	RejectBooking(ctx context.Context, workerId int, requestId int) error
+	CompleteBooking(ctx context.Context, workerId int, requestId int, completion domain.CompletionInput) error
+	CancelBooking(ctx context.Context, userId int, requestId int) (domain.PolicyDecision, error)
+	WorkerCancelBooking(ctx context.Context, workerId int, requestId int) (domain.PolicyDecision, error)
+	ReportNoShow(ctx context.Context, userId int, requestId int) (domain.PolicyDecision, error)
+}
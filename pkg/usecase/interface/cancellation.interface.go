@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type CancellationUseCase interface {
+	GetPolicy(ctx context.Context, categoryId int) (domain.CancellationPolicy, error)
+	SetPolicy(ctx context.Context, categoryId int, policy domain.CancellationPolicyInput) (domain.CancellationPolicy, error)
+	Cancel(ctx context.Context, booking domain.BookingResponse, actorId int, reason string) (domain.PolicyDecision, error)
+	ListDecisions(ctx context.Context, requestId int) ([]domain.PolicyDecision, error)
+}
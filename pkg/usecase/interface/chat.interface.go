@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type ChatUseCase interface {
+	JoinChat(ctx context.Context, actorId int, requestId int) (string, error)
+	SendMessage(ctx context.Context, actorId int, requestId int, body string) (domain.Message, error)
+	MarkRead(ctx context.Context, actorId int, requestId int, readTo int) error
+	Typing(ctx context.Context, actorId int, requestId int) error
+	ListMessages(ctx context.Context, actorId int, requestId int, filter utils.Filter) ([]domain.Message, utils.Metadata, error)
+	Subscribe(userId int, requestId int) (<-chan domain.ChatEvent, func())
+	// Disconnect ends the chat sockets of an account
+	Disconnect(userId int)
+}
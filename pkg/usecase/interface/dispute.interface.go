@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type DisputeUseCase interface {
+	OpenDispute(ctx context.Context, actorId int, requestId int, input domain.DisputeInput) (domain.Dispute, error)
+	ListDisputes(ctx context.Context, actorId int, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error)
+	GetDispute(ctx context.Context, actorId int, disputeId int) (domain.DisputeDetail, error)
+	AddEvidence(ctx context.Context, actorId int, disputeId int, fileName string, data []byte) (domain.DisputeEvidence, error)
+	EvidenceFile(ctx context.Context, actorId int, disputeId int, evidenceId int) (domain.DisputeEvidence, []byte, error)
+	PostMessage(ctx context.Context, actorId int, disputeId int, input domain.DisputeMessageInput) (domain.DisputeMessage, error)
+	ListQueue(ctx context.Context, status string, filter utils.Filter) ([]domain.Dispute, utils.Metadata, error)
+	AdminGetDispute(ctx context.Context, disputeId int) (domain.DisputeDetail, error)
+	AdminEvidenceFile(ctx context.Context, disputeId int, evidenceId int) (domain.DisputeEvidence, []byte, error)
+	AssignDispute(ctx context.Context, adminId int, disputeId int, input domain.DisputeAssignInput) (domain.Dispute, error)
+	AddNote(ctx context.Context, adminId int, disputeId int, input domain.DisputeNoteInput) (domain.DisputeMessage, error)
+	ResolveDispute(ctx context.Context, adminId int, disputeId int, input domain.DisputeResolutionInput) (domain.Dispute, error)
+}
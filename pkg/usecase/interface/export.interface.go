@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type ExportUseCase interface {
+	Export(ctx context.Context, table string, query domain.ExportQuery, w io.Writer) error
+}
+
+type ImportUseCase interface {
+	ImportCategories(ctx context.Context, data []byte, format string, dryRun bool) (domain.ImportReport, error)
+	ImportWorkers(ctx context.Context, data []byte, format string, dryRun bool) (domain.ImportReport, error)
+}
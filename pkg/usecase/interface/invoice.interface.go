@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type InvoiceUseCase interface {
+	SetMaterials(ctx context.Context, booking domain.BookingResponse, materials []domain.MaterialInput) error
+	// IssueInvoices issues the invoices of a completed request, the ones already issued are returned as they are
+	IssueInvoices(ctx context.Context, requestId int) ([]domain.Invoice, error)
+	IssueCreditNote(ctx context.Context, refund domain.Refund, reason string) (domain.Invoice, error)
+	ListInvoices(ctx context.Context, actorId int, requestId int) ([]domain.Invoice, error)
+	InvoicePDF(ctx context.Context, actorId int, invoiceId int) (domain.Invoice, []byte, error)
+}
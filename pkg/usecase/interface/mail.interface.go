@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type MailUseCase interface {
+	Enqueue(ctx context.Context, to string, template string, locale string, data interface{}) (int, error)
+	Dispatch(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+	ListDeadLetters(ctx context.Context, filter utils.Filter) ([]domain.OutboxMail, utils.Metadata, error)
+	RetryDeadLetter(ctx context.Context, mailId int) error
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type NotificationUseCase interface {
+	Notify(ctx context.Context, userId int, event string, data domain.NotificationData) error
+	ListNotifications(ctx context.Context, userId int, filter utils.Filter) ([]domain.Notification, utils.Metadata, error)
+	UnreadCount(ctx context.Context, userId int) (domain.UnreadCount, error)
+	MarkRead(ctx context.Context, userId int, notificationId int) error
+	MarkAllRead(ctx context.Context, userId int) error
+	GetPreferences(ctx context.Context, userId int) ([]domain.NotificationPreference, error)
+	SetPreferences(ctx context.Context, userId int, preferences domain.PreferencesInput) error
+	RegisterDevice(ctx context.Context, userId int, device domain.DeviceInput) error
+	RemoveDevice(ctx context.Context, userId int, token string) error
+}
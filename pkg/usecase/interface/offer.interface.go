@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type OfferUseCase interface {
+	ProposeOffer(ctx context.Context, userId int, requestId int, offer domain.OfferInput) (domain.Offer, error)
+	AcceptOffer(ctx context.Context, actorId int, party string, offerId int) error
+	RejectOffer(ctx context.Context, actorId int, party string, offerId int) error
+	CounterOffer(ctx context.Context, actorId int, party string, offerId int, offer domain.OfferInput) (domain.Offer, error)
+	ListOffers(ctx context.Context, actorId int, party string, requestId int) ([]domain.Offer, error)
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type PaymentUseCase interface {
+	Pay(ctx context.Context, userId int, requestId int, idempotencyKey string) (domain.PaymentResponse, error)
+	GetPayment(ctx context.Context, actorId int, requestId int) (domain.Payment, error)
+	HandleWebhook(ctx context.Context, gateway string, header http.Header, payload []byte) error
+	Release(ctx context.Context, booking domain.BookingResponse) (domain.Payment, error)
+	// RefundReleased sends part or all of a completed request's payment back to the user
+	RefundReleased(ctx context.Context, adminId int, requestId int, refund domain.RefundInput) (domain.Refund, error)
+}
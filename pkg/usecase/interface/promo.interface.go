@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type PromoUseCase interface {
+	CreatePromoCode(ctx context.Context, adminId int, input domain.PromoCodeInput) (domain.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, promoCodeId int, input domain.PromoCodeInput) (domain.PromoCode, error)
+	ListPromoCodes(ctx context.Context, filter utils.Filter) ([]domain.PromoCode, utils.Metadata, error)
+	// CheckPromoCode works out what a code would take off a booking without using it up
+	CheckPromoCode(ctx context.Context, userId int, input domain.PromoCheckInput) (domain.PromoQuote, error)
+	// ApplyPromoCode checks a code against a booking of job for amount and
+	// returns the code with its discount. The code is used up when the booking
+	// is created.
+	ApplyPromoCode(ctx context.Context, userId int, code string, job domain.Job, amount int64) (domain.PromoCode, int64, error)
+	// ReleasePromoCode gives back the use of a code on a request that was called off
+	ReleasePromoCode(ctx context.Context, requestId int) error
+}
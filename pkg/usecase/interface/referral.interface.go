@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type ReferralUseCase interface {
+	// GetReferral gives an account its referral code, making one if it has none yet
+	GetReferral(ctx context.Context, userId int) (domain.ReferralSummary, error)
+	CheckCode(ctx context.Context, code string) error
+	// SignedUp records where a new account came from and who referred it
+	SignedUp(ctx context.Context, userId int, phoneNumber string, signup domain.Signup) error
+	// Reward pays out a pending referral of either side of a completed booking
+	Reward(ctx context.Context, booking domain.BookingResponse) error
+}
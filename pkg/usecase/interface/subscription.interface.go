@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+type SubscriptionUseCase interface {
+	CreatePlan(ctx context.Context, input domain.SubscriptionPlanInput) (domain.SubscriptionPlan, error)
+	UpdatePlan(ctx context.Context, planId int, input domain.SubscriptionPlanInput) (domain.SubscriptionPlan, error)
+	ListPlans(ctx context.Context, activeOnly bool) ([]domain.SubscriptionPlan, error)
+	Subscribe(ctx context.Context, workerId int, input domain.SubscribeInput) (domain.Subscription, error)
+	GetSubscription(ctx context.Context, workerId int) (domain.Subscription, error)
+	SetAutoRenew(ctx context.Context, workerId int, autoRenew bool) (domain.Subscription, error)
+	RenewDue(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
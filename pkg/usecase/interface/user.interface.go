@@ -2,14 +2,20 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/fazilnbr/project-workey/pkg/domain"
 )
 
 type UserUseCase interface {
-	RegisterAndVarifyWithNumber(ctx context.Context, phoneNumber string) (int, error)
+	// RegisterAndVarifyWithNumber also reports whether the account is new
+	RegisterAndVarifyWithNumber(ctx context.Context, phone domain.PhoneNumber) (int, bool, error)
 	RegisterAndVarifyWithEmail(ctx context.Context, email string) (int, error)
 	AddProfile(ctx context.Context, userData domain.UserData) error
+	AddProfileAndUpdateMail(ctx context.Context, userData domain.UserData) error
 	UpdateMail(ctx context.Context, email string, userId int) error
 	GetProfile(ctx context.Context, userId int) (domain.Profile, error)
+	UserRole(ctx context.Context, userId int) (string, error)
+	CheckSession(ctx context.Context, userId int, issuedAt time.Time) error
+	RecordLogin(ctx context.Context, userId int, method string, ip string, userAgent string)
 }
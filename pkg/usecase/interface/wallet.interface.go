@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type WalletUseCase interface {
+	GetWallet(ctx context.Context, workerId int) (domain.Wallet, error)
+	ListEntries(ctx context.Context, workerId int, filter utils.Filter) ([]domain.WalletEntry, utils.Metadata, error)
+	// Statement sums up a calendar month, given as 2006-01, in UTC
+	Statement(ctx context.Context, workerId int, month string) (domain.Statement, error)
+	Adjust(ctx context.Context, adminId int, workerId int, adjustment domain.AdjustmentInput) (domain.LedgerTransaction, error)
+	RequestPayout(ctx context.Context, workerId int, payout domain.PayoutInput) (domain.Payout, error)
+	ListPayouts(ctx context.Context, workerId int, filter utils.Filter) ([]domain.Payout, utils.Metadata, error)
+	ListPayoutsByStatus(ctx context.Context, status string, filter utils.Filter) ([]domain.Payout, utils.Metadata, error)
+	ApprovePayout(ctx context.Context, adminId int, payoutId int, review domain.PayoutReviewInput) (domain.Payout, error)
+	RejectPayout(ctx context.Context, adminId int, payoutId int, review domain.PayoutReviewInput) (domain.Payout, error)
+	// ExportPayoutBatch batches every approved payout and returns the batch as CSV for the bank
+	ExportPayoutBatch(ctx context.Context, adminId int) (domain.PayoutBatch, []byte, error)
+	GetPayoutBatch(ctx context.Context, batchId int) (domain.PayoutBatch, []byte, error)
+}
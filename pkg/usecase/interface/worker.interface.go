@@ -1,4 +1,20 @@
 package interfaces
 
+import (
+	"context"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
 type WorkerUseCase interface {
+	SetAvailability(ctx context.Context, workerId int, availability domain.WeeklyAvailability) error
+	GetAvailability(ctx context.Context, workerId int) (domain.WeeklyAvailability, error)
+	AddBlackout(ctx context.Context, workerId int, blackout domain.BlackoutInput) (int, error)
+	ListBlackouts(ctx context.Context, workerId int) ([]domain.Blackout, error)
+	DeleteBlackout(ctx context.Context, workerId int, blackoutId int) error
+	AddJob(ctx context.Context, workerId int, input domain.JobInput) (int, error)
+	ListJobs(ctx context.Context, workerId int) ([]domain.JobListing, error)
+	SetJobOpen(ctx context.Context, workerId int, jobId int, input domain.JobOpenInput) error
+	SearchJobs(ctx context.Context, categoryId int, filter utils.Filter) ([]domain.JobListing, utils.Metadata, error)
 }
@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+// Invoice number series, a year is appended so numbering restarts every year
+const (
+	invoiceSeries    = "INV"
+	creditNoteSeries = "CN"
+)
+
+var wageDescriptions = map[string]string{
+	domain.SlotFullDay:    "Full day wage",
+	domain.SlotFirstHalf:  "Half day wage, first half",
+	domain.SlotSecondHalf: "Half day wage, second half",
+}
+
+var invoiceTitles = map[string]string{
+	domain.InvoiceJob:        "Tax Invoice",
+	domain.InvoiceCommission: "Commission Invoice",
+	domain.InvoiceCreditNote: "Credit Note",
+}
+
+type invoiceUseCase struct {
+	invoiceRepo interfaces.InvoiceRepository
+	bookingRepo interfaces.BookingRepository
+	paymentRepo interfaces.PaymentRepository
+	userRepo    interfaces.UserRepository
+	config      config.Config
+}
+
+// SetMaterials implements interfaces.InvoiceUseCase
+func (c *invoiceUseCase) SetMaterials(ctx context.Context, booking domain.BookingResponse, materials []domain.MaterialInput) error {
+	var total int64
+	var rows []domain.RequestMaterial
+	for _, material := range materials {
+		total += material.Amount
+		rows = append(rows, domain.RequestMaterial{
+			RequestId:   booking.IdRequest,
+			Description: material.Description,
+			Amount:      material.Amount,
+		})
+	}
+	if total >= booking.Amount {
+		return domain.ErrMaterialsCost
+	}
+	return c.invoiceRepo.SetMaterials(ctx, booking.IdRequest, rows)
+}
+
+// IssueInvoices implements interfaces.InvoiceUseCase
+func (c *invoiceUseCase) IssueInvoices(ctx context.Context, requestId int) ([]domain.Invoice, error) {
+	var invoices []domain.Invoice
+
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return invoices, err
+	}
+	if booking.Status != domain.RequestCompleted {
+		return invoices, domain.ErrNotCompleted
+	}
+	payment, err := c.paymentRepo.FindRequestPayment(ctx, requestId)
+	if err != nil {
+		return invoices, err
+	}
+	if payment.Status != domain.PaymentReleased && payment.Status != domain.PaymentRefunded {
+		return invoices, domain.ErrPaymentNotHeld
+	}
+	materials, err := c.invoiceRepo.ListMaterials(ctx, requestId)
+	if err != nil {
+		return invoices, err
+	}
+
+	workerName := c.partyName(ctx, booking.WorkerId)
+	series := invoiceSeries + "-" + strconv.Itoa(time.Now().Year())
+
+	// The user is invoiced by the worker for the job, the platform only
+	// collects the money on the worker's behalf. A promo code discount is
+	// taken off the full price.
+	wage := booking.Amount
+	var lines []domain.InvoiceLine
+	for _, material := range materials {
+		wage -= material.Amount
+		lines = append(lines, domain.InvoiceLine{Kind: domain.LineMaterials, Description: material.Description, Amount: material.Amount})
+	}
+	description := wageDescriptions[booking.Slot]
+	if booking.PriceLocked {
+		description = "Agreed price"
+	}
+	lines = append([]domain.InvoiceLine{{
+		Kind:        domain.LineWage,
+		Description: description + " for " + booking.JobCategory + " on " + booking.Date,
+		Amount:      wage,
+	}}, lines...)
+	if booking.Discount > 0 {
+		lines = append(lines, domain.InvoiceLine{Kind: domain.LineDiscount, Description: "Promo code discount", Amount: -booking.Discount})
+	}
+
+	job, err := c.invoiceRepo.IssueInvoice(ctx, c.withTax(domain.Invoice{
+		Kind:          domain.InvoiceJob,
+		RequestId:     requestId,
+		IssuerId:      booking.WorkerId,
+		IssuerName:    workerName,
+		RecipientId:   booking.UserId,
+		RecipientName: c.partyName(ctx, booking.UserId),
+		Currency:      payment.Currency,
+	}, lines), series)
+	if err != nil {
+		return invoices, err
+	}
+	invoices = append(invoices, job)
+
+	if payment.Commission == 0 {
+		return invoices, nil
+	}
+	commission, err := c.invoiceRepo.IssueInvoice(ctx, c.withTax(domain.Invoice{
+		Kind:          domain.InvoiceCommission,
+		RequestId:     requestId,
+		IssuerName:    domain.PlatformName,
+		RecipientId:   booking.WorkerId,
+		RecipientName: workerName,
+		Currency:      payment.Currency,
+	}, []domain.InvoiceLine{{
+		Kind:        domain.LineCommission,
+		Description: "Platform commission on request #" + strconv.Itoa(requestId),
+		Amount:      payment.Commission,
+	}}), series)
+	if err != nil {
+		return invoices, err
+	}
+	return append(invoices, commission), nil
+}
+
+// IssueCreditNote implements interfaces.InvoiceUseCase
+func (c *invoiceUseCase) IssueCreditNote(ctx context.Context, refund domain.Refund, reason string) (domain.Invoice, error) {
+	invoices, err := c.IssueInvoices(ctx, refund.RequestId)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	job := invoices[0]
+
+	return c.invoiceRepo.IssueInvoice(ctx, c.withTax(domain.Invoice{
+		Kind:          domain.InvoiceCreditNote,
+		RequestId:     refund.RequestId,
+		CreditedId:    &job.IdInvoice,
+		RefundId:      &refund.IdRefund,
+		IssuerId:      job.IssuerId,
+		IssuerName:    job.IssuerName,
+		RecipientId:   job.RecipientId,
+		RecipientName: job.RecipientName,
+		Currency:      refund.Currency,
+	}, []domain.InvoiceLine{{
+		Kind:        domain.LineRefund,
+		Description: "Refund: " + reason,
+		Amount:      refund.Amount,
+	}}), creditNoteSeries+"-"+strconv.Itoa(time.Now().Year()))
+}
+
+// ListInvoices implements interfaces.InvoiceUseCase. Invoices a completed
+// request is missing, say because issuing failed on completion, are issued now.
+func (c *invoiceUseCase) ListInvoices(ctx context.Context, actorId int, requestId int) ([]domain.Invoice, error) {
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserId != actorId && booking.WorkerId != actorId {
+		return nil, domain.ErrNoRequest
+	}
+
+	invoices, err := c.invoiceRepo.ListRequestInvoices(ctx, requestId)
+	if err != nil {
+		return nil, err
+	}
+	if len(invoices) == 0 && booking.Status == domain.RequestCompleted {
+		if _, err = c.IssueInvoices(ctx, requestId); err != nil {
+			return nil, err
+		}
+		if invoices, err = c.invoiceRepo.ListRequestInvoices(ctx, requestId); err != nil {
+			return nil, err
+		}
+	}
+
+	var visible []domain.Invoice
+	for _, invoice := range invoices {
+		if invoice.IssuerId == actorId || invoice.RecipientId == actorId {
+			visible = append(visible, invoice)
+		}
+	}
+	return visible, nil
+}
+
+// InvoicePDF implements interfaces.InvoiceUseCase
+func (c *invoiceUseCase) InvoicePDF(ctx context.Context, actorId int, invoiceId int) (domain.Invoice, []byte, error) {
+	invoice, err := c.invoiceRepo.FindInvoice(ctx, invoiceId)
+	if err != nil {
+		return invoice, nil, err
+	}
+	if invoice.IssuerId != actorId && invoice.RecipientId != actorId {
+		return domain.Invoice{}, nil, domain.ErrNoInvoice
+	}
+
+	var credited *domain.Invoice
+	if invoice.CreditedId != nil {
+		original, err := c.invoiceRepo.FindInvoice(ctx, *invoice.CreditedId)
+		if err != nil {
+			return invoice, nil, err
+		}
+		credited = &original
+	}
+	return invoice, renderInvoice(invoice, credited), nil
+}
+
+// withTax takes the tax out of tax inclusive lines and adds it as a line of its own
+func (c *invoiceUseCase) withTax(invoice domain.Invoice, lines []domain.InvoiceLine) domain.Invoice {
+	invoice.TaxBps = c.config.TaxBps
+	if invoice.TaxBps <= 0 {
+		invoice.TaxBps = domain.DefaultTaxBps
+	}
+
+	for _, line := range lines {
+		tax := line.Amount * int64(invoice.TaxBps) / int64(10000+invoice.TaxBps)
+		line.Amount -= tax
+		invoice.Tax += tax
+		invoice.Subtotal += line.Amount
+		invoice.Lines = append(invoice.Lines, line)
+	}
+	invoice.Total = invoice.Subtotal + invoice.Tax
+	invoice.Lines = append(invoice.Lines, domain.InvoiceLine{
+		Kind:        domain.LineTax,
+		Description: "Tax at " + utils.FormatMinor(int64(invoice.TaxBps)) + "%",
+		Amount:      invoice.Tax,
+	})
+	return invoice
+}
+
+// partyName is the name an invoice is made out to, the email of an account
+// without a profile
+func (c *invoiceUseCase) partyName(ctx context.Context, userId int) string {
+	profile, err := c.userRepo.GetProfile(ctx, userId)
+	if name := strings.TrimSpace(profile.FirstName + " " + profile.LastName); err == nil && name != "" {
+		return name
+	}
+	user, err := c.userRepo.FindUserWithId(ctx, userId)
+	if err == nil && user.Email != "" {
+		return user.Email
+	}
+	return "#" + strconv.Itoa(userId)
+}
+
+// renderInvoice lays an invoice out on an A4 page
+func renderInvoice(invoice domain.Invoice, credited *domain.Invoice) []byte {
+	const left, right = 50.0, utils.PDFPageWidth - 50
+	pdf := utils.NewPDF()
+
+	y := utils.PDFPageHeight - 70
+	pdf.Text(left, y, utils.FontBold, 20, invoiceTitles[invoice.Kind])
+	pdf.Text(right-150, y, utils.FontBold, 12, domain.PlatformName)
+
+	y -= 30
+	details := []string{
+		"Number: " + invoice.Number,
+		"Date: " + invoice.IssuedAt.Format("02 Jan 2006"),
+		"Request: #" + strconv.Itoa(invoice.RequestId),
+	}
+	if credited != nil {
+		details = append(details, "Credits invoice: "+credited.Number)
+	}
+	for _, detail := range details {
+		pdf.Text(left, y, utils.FontRegular, 10, detail)
+		y -= 14
+	}
+
+	y -= 16
+	pdf.Text(left, y, utils.FontBold, 10, "From")
+	pdf.Text(left+250, y, utils.FontBold, 10, "To")
+	y -= 14
+	pdf.Text(left, y, utils.FontRegular, 10, invoice.IssuerName)
+	pdf.Text(left+250, y, utils.FontRegular, 10, invoice.RecipientName)
+
+	y -= 40
+	pdf.Text(left, y, utils.FontBold, 10, "Description")
+	pdf.TextRight(right, y, 10, "Amount ("+invoice.Currency+")")
+	y -= 8
+	pdf.Rule(left, y, right, y)
+	y -= 16
+	for _, line := range invoice.Lines {
+		if line.Kind == domain.LineTax {
+			continue
+		}
+		pdf.Text(left, y, utils.FontRegular, 10, line.Description)
+		pdf.TextRight(right, y, 10, utils.FormatMinor(line.Amount))
+		y -= 16
+	}
+	pdf.Rule(left, y+8, right, y+8)
+
+	y -= 8
+	totals := [][2]string{
+		{"Subtotal", utils.FormatMinor(invoice.Subtotal)},
+		{"Tax at " + utils.FormatMinor(int64(invoice.TaxBps)) + "%", utils.FormatMinor(invoice.Tax)},
+	}
+	for _, total := range totals {
+		pdf.Text(right-250, y, utils.FontRegular, 10, total[0])
+		pdf.TextRight(right, y, 10, total[1])
+		y -= 16
+	}
+	pdf.Text(right-250, y, utils.FontBold, 11, "Total")
+	pdf.TextRight(right, y, 11, utils.FormatMinor(invoice.Total))
+
+	footer := "Prices include tax."
+	if invoice.IssuerId != 0 {
+		footer += " Issued by " + domain.PlatformName + " on behalf of " + invoice.IssuerName + "."
+	}
+	pdf.Text(left, 50, utils.FontRegular, 8, footer)
+	return pdf.Bytes()
+}
+
+func NewInvoiceService(
+	invoiceRepo interfaces.InvoiceRepository,
+	bookingRepo interfaces.BookingRepository,
+	paymentRepo interfaces.PaymentRepository,
+	userRepo interfaces.UserRepository,
+	cfg config.Config) services.InvoiceUseCase {
+	return &invoiceUseCase{
+		invoiceRepo: invoiceRepo,
+		bookingRepo: bookingRepo,
+		paymentRepo: paymentRepo,
+		userRepo:    userRepo,
+		config:      cfg,
+	}
+}
@@ -2,17 +2,18 @@ package usecase
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"time"
 
 	model "github.com/fazilnbr/project-workey/pkg/domain"
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
 )
 
 type JWTUseCase struct {
 	SecretKey string
+	logger    *logrus.Logger
 }
 
 // GenerateRefreshToken implements interfaces.JWTUsecase
@@ -24,6 +25,7 @@ func (j *JWTUseCase) GenerateRefreshToken(userid int, username string, role stri
 		Role:     role,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Local().Add(time.Hour * 12 * 7).Unix(),
+			IssuedAt:  time.Now().Unix(),
 		},
 	}
 	// fmt.Printf("\n\nrefresh time : %v\n\n", time.Hour*12*7)
@@ -33,7 +35,7 @@ func (j *JWTUseCase) GenerateRefreshToken(userid int, username string, role stri
 	signedToken, err := token.SignedString([]byte(j.SecretKey))
 
 	if err != nil {
-		log.Println(err)
+		j.logger.WithError(err).Error("failed to sign token")
 	}
 
 	return signedToken, err
@@ -49,6 +51,7 @@ func (j *JWTUseCase) GenerateAccessToken(userid int, username string, role strin
 		Role:     role,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Local().Add(time.Minute * time.Duration(5)).Unix(),
+			IssuedAt:  time.Now().Unix(),
 		},
 	}
 
@@ -57,7 +60,7 @@ func (j *JWTUseCase) GenerateAccessToken(userid int, username string, role strin
 	signedToken, err := token.SignedString([]byte(j.SecretKey))
 
 	if err != nil {
-		log.Println(err)
+		j.logger.WithError(err).Error("failed to sign token")
 	}
 
 	return signedToken, err
@@ -92,8 +95,9 @@ func (j *JWTUseCase) VerifyToken(signedToken string) (bool, *model.SignedDetails
 	return false, claims
 }
 
-func NewJWTUserService() services.JWTUseCase {
+func NewJWTUserService(logger *logrus.Logger) services.JWTUseCase {
 	return &JWTUseCase{
 		SecretKey: os.Getenv("USER_KEY"),
+		logger:    logger,
 	}
 }
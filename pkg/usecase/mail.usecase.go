@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxMailAttempts is how many times a mail is tried before it goes to the dead letters
+	maxMailAttempts = 6
+	mailBaseBackoff = 30 * time.Second
+	mailMaxBackoff  = time.Hour
+	// mailLease must outlast one send, or a slow mail would be claimed twice
+	mailLease        = 5 * time.Minute
+	mailBatchSize    = 20
+	mailPollInterval = 10 * time.Second
+)
+
+type mailUseCase struct {
+	mailRepo   interfaces.MailRepository
+	mailConfig config.MailConfig
+	config     config.Config
+	logger     *logrus.Logger
+}
+
+// Enqueue implements interfaces.MailUseCase
+func (c *mailUseCase) Enqueue(ctx context.Context, to string, template string, locale string, data interface{}) (int, error) {
+	rendered, err := utils.RenderMail(template, locale, data)
+	if err != nil {
+		return 0, err
+	}
+	return c.mailRepo.EnqueueMail(ctx, domain.OutboxMail{
+		ToAddress: to,
+		Template:  template,
+		Locale:    rendered.Locale,
+		Subject:   rendered.Subject,
+		TextBody:  rendered.Text,
+		HTMLBody:  rendered.HTML,
+	})
+}
+
+// Dispatch implements interfaces.MailUseCase. It returns how many mails were
+// attempted, sent or not.
+func (c *mailUseCase) Dispatch(ctx context.Context) (int, error) {
+	mails, err := c.mailRepo.ClaimDueMails(ctx, mailBatchSize, time.Now().Add(mailLease))
+	if err != nil {
+		return 0, err
+	}
+
+	for i, mail := range mails {
+		err := c.send(mail)
+		if err == nil {
+			err = c.mailRepo.MarkMailSent(ctx, mail.IdMail)
+			if err != nil {
+				c.logger.WithContext(ctx).WithError(err).WithField("mail", mail.IdMail).Error("mail sent but not marked")
+			}
+			continue
+		}
+
+		status := domain.MailQueued
+		if mail.Attempts >= maxMailAttempts {
+			status = domain.MailDead
+		}
+		c.logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{"mail": mail.IdMail, "attempt": mail.Attempts}).Warn("failed to send mail")
+		err = c.mailRepo.MarkMailFailed(ctx, mail.IdMail, status, err.Error(), time.Now().Add(mailBackoff(mail.Attempts)))
+		if err != nil {
+			return i + 1, err
+		}
+	}
+	return len(mails), nil
+}
+
+func (c *mailUseCase) send(mail domain.OutboxMail) error {
+	message, err := utils.BuildMail(c.config.SMTPUSERNAME, mail.ToAddress, mail.Subject, mail.TextBody, mail.HTMLBody)
+	if err != nil {
+		return err
+	}
+	return c.mailConfig.SendMail(c.config, mail.ToAddress, message)
+}
+
+// mailBackoff doubles the wait after every failed attempt, up to mailMaxBackoff
+func mailBackoff(attempts int) time.Duration {
+	backoff := mailBaseBackoff
+	for i := 1; i < attempts && backoff < mailMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > mailMaxBackoff {
+		backoff = mailMaxBackoff
+	}
+	return backoff
+}
+
+// Run implements interfaces.MailUseCase
+func (c *mailUseCase) Run(ctx context.Context) {
+	ticker := time.NewTicker(mailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while whole batches come back, then wait for the next tick
+		for {
+			attempted, err := c.Dispatch(ctx)
+			if err != nil {
+				c.logger.WithContext(ctx).WithError(err).Error("failed to dispatch mail outbox")
+				break
+			}
+			if attempted < mailBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListDeadLetters implements interfaces.MailUseCase
+func (c *mailUseCase) ListDeadLetters(ctx context.Context, filter utils.Filter) ([]domain.OutboxMail, utils.Metadata, error) {
+	return c.mailRepo.ListDeadMails(ctx, filter)
+}
+
+// RetryDeadLetter implements interfaces.MailUseCase
+func (c *mailUseCase) RetryDeadLetter(ctx context.Context, mailId int) error {
+	return c.mailRepo.RequeueMail(ctx, mailId)
+}
+
+func NewMailService(
+	mailRepo interfaces.MailRepository,
+	mailConfig config.MailConfig,
+	cfg config.Config,
+	logger *logrus.Logger) services.MailUseCase {
+	return &mailUseCase{
+		mailRepo:   mailRepo,
+		mailConfig: mailConfig,
+		config:     cfg,
+		logger:     logger,
+	}
+}
@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// deliveryTimeout bounds how long the channels of one notification may take
+// once the request that raised it has returned
+const deliveryTimeout = 30 * time.Second
+
+type notificationTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+func newNotificationTemplate(event string, title string, body string) notificationTemplate {
+	return notificationTemplate{
+		title: template.Must(template.New(event + ".title").Parse(title)),
+		body:  template.Must(template.New(event + ".body").Parse(body)),
+	}
+}
+
+var notificationTemplates = map[string]notificationTemplate{
+	domain.EventBookingCreated: newNotificationTemplate(domain.EventBookingCreated,
+		"New booking request",
+		"You have a new request #{{.RequestId}} for {{.Date}} ({{.Slot}})."),
+	domain.EventBookingAccepted: newNotificationTemplate(domain.EventBookingAccepted,
+		"Booking accepted",
+		"Your request #{{.RequestId}} for {{.Date}} ({{.Slot}}) was accepted."),
+	domain.EventBookingRejected: newNotificationTemplate(domain.EventBookingRejected,
+		"Booking rejected",
+		"Your request #{{.RequestId}} for {{.Date}} ({{.Slot}}) was rejected."),
+	domain.EventBookingCancelled: newNotificationTemplate(domain.EventBookingCancelled,
+		"Booking cancelled",
+		"Request #{{.RequestId}} for {{.Date}} ({{.Slot}}) was cancelled."),
+	domain.EventDisputeOpened: newNotificationTemplate(domain.EventDisputeOpened,
+		"Dispute opened",
+		"A dispute was opened on request #{{.RequestId}}."),
+	domain.EventDisputeMessage: newNotificationTemplate(domain.EventDisputeMessage,
+		"New dispute message",
+		"There is a new message on the dispute of request #{{.RequestId}}."),
+	domain.EventDisputeResolved: newNotificationTemplate(domain.EventDisputeResolved,
+		"Dispute resolved",
+		"The dispute on request #{{.RequestId}} was resolved."),
+}
+
+// Whether a channel is on for a user who never set a preference for it
+var channelDefaults = map[string]bool{
+	domain.ChannelEmail: true,
+	domain.ChannelSMS:   false,
+	domain.ChannelPush:  true,
+}
+
+// deliverer sends a rendered notification to a user over one channel
+type deliverer func(ctx context.Context, user domain.User, notification domain.Notification) error
+
+type notificationUseCase struct {
+	notificationRepo interfaces.NotificationRepository
+	userRepo         interfaces.UserRepository
+	channels         map[string]deliverer
+	logger           *logrus.Logger
+}
+
+// Notify implements interfaces.NotificationUseCase
+func (c *notificationUseCase) Notify(ctx context.Context, userId int, event string, data domain.NotificationData) error {
+	tmpl, ok := notificationTemplates[event]
+	if !ok {
+		return domain.ErrUnknownEvent.WithMessage("unknown notification event " + event)
+	}
+
+	var title, body bytes.Buffer
+	if err := tmpl.title.Execute(&title, data); err != nil {
+		return err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return err
+	}
+
+	notification := domain.Notification{
+		UserId: userId,
+		Event:  event,
+		Title:  title.String(),
+		Body:   body.String(),
+	}
+	if data.RequestId != 0 {
+		notification.RequestId = &data.RequestId
+	}
+
+	notification, err := c.notificationRepo.CreateNotification(ctx, notification)
+	if err != nil {
+		return err
+	}
+
+	// The in-app copy is stored, the other channels must not hold up the caller
+	go c.deliver(notification)
+	return nil
+}
+
+// deliver sends a stored notification over every channel the user has enabled
+func (c *notificationUseCase) deliver(notification domain.Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	user, err := c.userRepo.FindUserWithId(ctx, notification.UserId)
+	if err != nil {
+		c.logger.WithError(err).WithField("notification", notification.IdNotification).Error("failed to deliver notification")
+		return
+	}
+
+	enabled, err := c.enabledChannels(ctx, notification.UserId, notification.Event)
+	if err != nil {
+		c.logger.WithError(err).WithField("notification", notification.IdNotification).Error("failed to deliver notification")
+		return
+	}
+
+	for _, channel := range enabled {
+		send, ok := c.channels[channel]
+		if !ok {
+			continue
+		}
+		if err := send(ctx, user, notification); err != nil {
+			c.logger.WithError(err).WithFields(logrus.Fields{"notification": notification.IdNotification, "channel": channel}).Error("failed to deliver notification")
+		}
+	}
+}
+
+// enabledChannels resolves the user's preferences for an event against the channel defaults
+func (c *notificationUseCase) enabledChannels(ctx context.Context, userId int, event string) ([]string, error) {
+	preferences, err := c.notificationRepo.ListPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	state := map[string]bool{}
+	for channel, enabled := range channelDefaults {
+		state[channel] = enabled
+	}
+	for _, preference := range preferences {
+		if preference.Event == event {
+			state[preference.Channel] = preference.Enabled
+		}
+	}
+
+	var enabled []string
+	for _, channel := range []string{domain.ChannelEmail, domain.ChannelSMS, domain.ChannelPush} {
+		if state[channel] {
+			enabled = append(enabled, channel)
+		}
+	}
+	return enabled, nil
+}
+
+// ListNotifications implements interfaces.NotificationUseCase
+func (c *notificationUseCase) ListNotifications(ctx context.Context, userId int, filter utils.Filter) ([]domain.Notification, utils.Metadata, error) {
+	return c.notificationRepo.ListNotifications(ctx, userId, filter)
+}
+
+// UnreadCount implements interfaces.NotificationUseCase
+func (c *notificationUseCase) UnreadCount(ctx context.Context, userId int) (domain.UnreadCount, error) {
+	unread, err := c.notificationRepo.CountUnread(ctx, userId)
+	return domain.UnreadCount{Unread: unread}, err
+}
+
+// MarkRead implements interfaces.NotificationUseCase
+func (c *notificationUseCase) MarkRead(ctx context.Context, userId int, notificationId int) error {
+	return c.notificationRepo.MarkRead(ctx, userId, notificationId)
+}
+
+// MarkAllRead implements interfaces.NotificationUseCase
+func (c *notificationUseCase) MarkAllRead(ctx context.Context, userId int) error {
+	_, err := c.notificationRepo.MarkAllRead(ctx, userId)
+	return err
+}
+
+// GetPreferences implements interfaces.NotificationUseCase
+func (c *notificationUseCase) GetPreferences(ctx context.Context, userId int) ([]domain.NotificationPreference, error) {
+	stored, err := c.notificationRepo.ListPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := map[string]bool{}
+	for _, preference := range stored {
+		saved[preference.Event+"/"+preference.Channel] = preference.Enabled
+	}
+
+	// Every event and channel is listed so clients can render the whole matrix
+	var preferences []domain.NotificationPreference
+	for _, event := range []string{
+		domain.EventBookingCreated,
+		domain.EventBookingAccepted,
+		domain.EventBookingRejected,
+		domain.EventBookingCancelled,
+		domain.EventDisputeOpened,
+		domain.EventDisputeMessage,
+		domain.EventDisputeResolved,
+	} {
+		for _, channel := range []string{domain.ChannelEmail, domain.ChannelSMS, domain.ChannelPush} {
+			enabled, ok := saved[event+"/"+channel]
+			if !ok {
+				enabled = channelDefaults[channel]
+			}
+			preferences = append(preferences, domain.NotificationPreference{
+				UserId:  userId,
+				Event:   event,
+				Channel: channel,
+				Enabled: enabled,
+			})
+		}
+	}
+	return preferences, nil
+}
+
+// SetPreferences implements interfaces.NotificationUseCase
+func (c *notificationUseCase) SetPreferences(ctx context.Context, userId int, input domain.PreferencesInput) error {
+	var preferences []domain.NotificationPreference
+	for _, preference := range input.Preferences {
+		preferences = append(preferences, domain.NotificationPreference{
+			UserId:  userId,
+			Event:   preference.Event,
+			Channel: preference.Channel,
+			Enabled: preference.Enabled,
+		})
+	}
+	return c.notificationRepo.SetPreferences(ctx, userId, preferences)
+}
+
+// RegisterDevice implements interfaces.NotificationUseCase
+func (c *notificationUseCase) RegisterDevice(ctx context.Context, userId int, device domain.DeviceInput) error {
+	return c.notificationRepo.AddDevice(ctx, domain.DeviceToken{
+		UserId:   userId,
+		Token:    device.Token,
+		Platform: device.Platform,
+	})
+}
+
+// RemoveDevice implements interfaces.NotificationUseCase
+func (c *notificationUseCase) RemoveDevice(ctx context.Context, userId int, token string) error {
+	return c.notificationRepo.DeleteDevice(ctx, userId, token)
+}
+
+func NewNotificationService(
+	notificationRepo interfaces.NotificationRepository,
+	userRepo interfaces.UserRepository,
+	mailUseCase services.MailUseCase,
+	smsConfig config.SMSConfig,
+	pushConfig config.PushConfig,
+	cfg config.Config,
+	logger *logrus.Logger) services.NotificationUseCase {
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		channels: map[string]deliverer{
+			domain.ChannelEmail: func(ctx context.Context, user domain.User, notification domain.Notification) error {
+				// Accounts made with a phone number hold a placeholder address
+				// until the user gives one
+				if utils.IsRandommail(user.Email) {
+					return nil
+				}
+				_, err := mailUseCase.Enqueue(ctx, user.Email, "notification", domain.DefaultLocale, notification)
+				return err
+			},
+			domain.ChannelSMS: func(ctx context.Context, user domain.User, notification domain.Notification) error {
+				return smsConfig.SendSMS(cfg, user.Phone, notification.Title+": "+notification.Body)
+			},
+			domain.ChannelPush: func(ctx context.Context, user domain.User, notification domain.Notification) error {
+				devices, err := notificationRepo.ListDevices(ctx, user.IdUser)
+				if err != nil {
+					return err
+				}
+				data := map[string]string{
+					"event":          notification.Event,
+					"notificationid": strconv.Itoa(notification.IdNotification),
+				}
+				for _, device := range devices {
+					if err := pushConfig.SendPush(cfg, device.Token, notification.Title, notification.Body, data); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		logger: logger,
+	}
+}
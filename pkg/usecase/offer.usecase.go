@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+)
+
+// offerTTL is how long an offer stays open before it expires unanswered
+const offerTTL = 24 * time.Hour
+
+type offerUseCase struct {
+	offerRepo   interfaces.OfferRepository
+	bookingRepo interfaces.BookingRepository
+}
+
+// ProposeOffer implements interfaces.OfferUseCase
+func (c *offerUseCase) ProposeOffer(ctx context.Context, userId int, requestId int, offer domain.OfferInput) (domain.Offer, error) {
+	if err := c.offerRepo.ExpireOffers(ctx); err != nil {
+		return domain.Offer{}, err
+	}
+
+	booking, err := c.partyBooking(ctx, userId, domain.PartyUser, requestId)
+	if err != nil {
+		return domain.Offer{}, err
+	}
+	if offer.Currency != "" && offer.Currency != booking.Currency {
+		return domain.Offer{}, domain.ErrOfferCurrency.WithMessage("offer currency must be " + booking.Currency)
+	}
+
+	id, err := c.offerRepo.CreateOffer(ctx, domain.Offer{
+		RequestId:  requestId,
+		ProposedBy: domain.PartyUser,
+		ProposerId: userId,
+		Amount:     offer.Amount,
+		Currency:   booking.Currency,
+		ExpiresAt:  time.Now().Add(offerTTL),
+	})
+	if err != nil {
+		return domain.Offer{}, err
+	}
+	return c.offerRepo.FindOffer(ctx, id)
+}
+
+// AcceptOffer implements interfaces.OfferUseCase
+func (c *offerUseCase) AcceptOffer(ctx context.Context, actorId int, party string, offerId int) error {
+	if _, err := c.answerableOffer(ctx, actorId, party, offerId); err != nil {
+		return err
+	}
+	_, err := c.offerRepo.CloseOffer(ctx, offerId, domain.OfferAccepted, nil)
+	return err
+}
+
+// RejectOffer implements interfaces.OfferUseCase
+func (c *offerUseCase) RejectOffer(ctx context.Context, actorId int, party string, offerId int) error {
+	if _, err := c.answerableOffer(ctx, actorId, party, offerId); err != nil {
+		return err
+	}
+	_, err := c.offerRepo.CloseOffer(ctx, offerId, domain.OfferRejected, nil)
+	return err
+}
+
+// CounterOffer implements interfaces.OfferUseCase
+func (c *offerUseCase) CounterOffer(ctx context.Context, actorId int, party string, offerId int, offer domain.OfferInput) (domain.Offer, error) {
+	previous, err := c.answerableOffer(ctx, actorId, party, offerId)
+	if err != nil {
+		return domain.Offer{}, err
+	}
+	if offer.Currency != "" && offer.Currency != previous.Currency {
+		return domain.Offer{}, domain.ErrOfferCurrency.WithMessage("offer currency must be " + previous.Currency)
+	}
+	if offer.Amount == previous.Amount {
+		return domain.Offer{}, domain.ErrSameCounter
+	}
+
+	id, err := c.offerRepo.CloseOffer(ctx, offerId, domain.OfferCountered, &domain.Offer{
+		RequestId:  previous.RequestId,
+		ProposedBy: party,
+		ProposerId: actorId,
+		Amount:     offer.Amount,
+		Currency:   previous.Currency,
+		ExpiresAt:  time.Now().Add(offerTTL),
+	})
+	if err != nil {
+		return domain.Offer{}, err
+	}
+	return c.offerRepo.FindOffer(ctx, id)
+}
+
+// ListOffers implements interfaces.OfferUseCase
+func (c *offerUseCase) ListOffers(ctx context.Context, actorId int, party string, requestId int) ([]domain.Offer, error) {
+	if err := c.offerRepo.ExpireOffers(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := c.partyBooking(ctx, actorId, party, requestId); err != nil {
+		return nil, err
+	}
+	return c.offerRepo.ListOffers(ctx, requestId)
+}
+
+// answerableOffer loads an open offer the actor may answer, which is any
+// offer on their request proposed by the other party
+func (c *offerUseCase) answerableOffer(ctx context.Context, actorId int, party string, offerId int) (domain.Offer, error) {
+	if err := c.offerRepo.ExpireOffers(ctx); err != nil {
+		return domain.Offer{}, err
+	}
+
+	offer, err := c.offerRepo.FindOffer(ctx, offerId)
+	if err != nil {
+		return offer, err
+	}
+	if _, err = c.partyBooking(ctx, actorId, party, offer.RequestId); err != nil {
+		return offer, domain.ErrNoOffer
+	}
+	if offer.ProposedBy == party {
+		return offer, domain.ErrOwnOffer
+	}
+	if offer.Status != domain.OfferOpen {
+		return offer, domain.ErrOfferClosed
+	}
+	return offer, nil
+}
+
+// partyBooking loads a request the actor takes part in as the given party
+func (c *offerUseCase) partyBooking(ctx context.Context, actorId int, party string, requestId int) (domain.BookingResponse, error) {
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return booking, err
+	}
+	if (party == domain.PartyUser && booking.UserId != actorId) ||
+		(party == domain.PartyWorker && booking.WorkerId != actorId) {
+		return booking, domain.ErrNoRequest
+	}
+	return booking, nil
+}
+
+func NewOfferService(
+	offerRepo interfaces.OfferRepository,
+	bookingRepo interfaces.BookingRepository) services.OfferUseCase {
+	return &offerUseCase{
+		offerRepo:   offerRepo,
+		bookingRepo: bookingRepo,
+	}
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/sirupsen/logrus"
+)
+
+const maxIdempotencyKeyLength = 64
+
+type paymentUseCase struct {
+	paymentRepo    interfaces.PaymentRepository
+	bookingRepo    interfaces.BookingRepository
+	invoiceUseCase services.InvoiceUseCase
+	gateway        config.PaymentGateway
+	config         config.Config
+	logger         *logrus.Logger
+}
+
+// Pay implements interfaces.PaymentUseCase
+func (c *paymentUseCase) Pay(ctx context.Context, userId int, requestId int, idempotencyKey string) (domain.PaymentResponse, error) {
+	if idempotencyKey == "" || len(idempotencyKey) > maxIdempotencyKeyLength {
+		return domain.PaymentResponse{}, domain.ErrIdempotencyKey
+	}
+
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return domain.PaymentResponse{}, err
+	}
+	if booking.UserId != userId {
+		return domain.PaymentResponse{}, domain.ErrNoRequest
+	}
+
+	payment, err := c.paymentRepo.CreatePayment(ctx, domain.Payment{
+		RequestId:      booking.IdRequest,
+		UserId:         booking.UserId,
+		WorkerId:       booking.WorkerId,
+		Amount:         booking.Payable(),
+		Discount:       booking.Discount,
+		Currency:       booking.Currency,
+		Gateway:        c.gateway.Name(),
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return domain.PaymentResponse{}, err
+	}
+	if payment.Status != domain.PaymentCreated {
+		return domain.PaymentResponse{Payment: payment}, nil
+	}
+
+	// The order is asked for again on a retry, the gateway answers with the
+	// same one as the payment's key is passed along as its reference
+	order, err := c.gateway.CreateOrder(c.config, config.PaymentOrder{
+		Reference: payment.IdempotencyKey,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+	})
+	if err != nil {
+		return domain.PaymentResponse{}, err
+	}
+	if payment.GatewayOrderId != order.OrderId {
+		if err = c.paymentRepo.SetGatewayOrder(ctx, payment.IdPayment, order.OrderId); err != nil {
+			return domain.PaymentResponse{}, err
+		}
+		payment.GatewayOrderId = order.OrderId
+	}
+
+	return domain.PaymentResponse{
+		Payment: payment,
+		Checkout: &domain.Checkout{
+			Gateway:      c.gateway.Name(),
+			OrderId:      order.OrderId,
+			ClientSecret: order.ClientSecret,
+			KeyId:        order.KeyId,
+		},
+	}, nil
+}
+
+// GetPayment implements interfaces.PaymentUseCase
+func (c *paymentUseCase) GetPayment(ctx context.Context, actorId int, requestId int) (domain.Payment, error) {
+	booking, err := c.bookingRepo.FindBooking(ctx, requestId)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	if booking.UserId != actorId && booking.WorkerId != actorId {
+		return domain.Payment{}, domain.ErrNoRequest
+	}
+	return c.paymentRepo.FindRequestPayment(ctx, requestId)
+}
+
+// HandleWebhook implements interfaces.PaymentUseCase
+func (c *paymentUseCase) HandleWebhook(ctx context.Context, gateway string, header http.Header, payload []byte) error {
+	if gateway != c.gateway.Name() {
+		return domain.ErrUnknownGateway.WithMessage("unknown payment gateway " + gateway)
+	}
+
+	event, err := c.gateway.ParseWebhook(c.config, header, payload)
+	if errors.Is(err, config.ErrIgnoredEvent) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	status := domain.PaymentFailed
+	if event.Status == config.GatewayPaymentCaptured {
+		status = domain.PaymentHeld
+	}
+	_, err = c.paymentRepo.ApplyGatewayEvent(ctx, gateway, event.EventId, event.OrderId, event.PaymentId, status)
+	return err
+}
+
+// Release implements interfaces.PaymentUseCase
+func (c *paymentUseCase) Release(ctx context.Context, booking domain.BookingResponse) (domain.Payment, error) {
+	return c.paymentRepo.ReleasePayment(ctx, booking.IdRequest, commissionBps(c.config))
+}
+
+// RefundReleased implements interfaces.PaymentUseCase
+func (c *paymentUseCase) RefundReleased(ctx context.Context, adminId int, requestId int, input domain.RefundInput) (domain.Refund, error) {
+	refund, err := c.paymentRepo.RefundReleased(ctx, requestId, input.Amount, adminId)
+	if err != nil {
+		return refund, err
+	}
+	refund = sendRefund(ctx, c.gateway, c.config, c.paymentRepo, c.logger, refund)
+
+	// The refund stands whether or not its paperwork does
+	if _, err = c.invoiceUseCase.IssueCreditNote(ctx, refund, input.Reason); err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("refund", refund.IdRefund).Error("failed to issue credit note")
+	}
+	return refund, nil
+}
+
+// sendRefund asks the gateway for a refund recorded as pending and returns it
+// as it was marked. A failure is kept on the refund for support to act on,
+// the money has already been taken back in the ledger.
+func sendRefund(ctx context.Context, gateway config.PaymentGateway, cfg config.Config, paymentRepo interfaces.PaymentRepository, logger *logrus.Logger, refund domain.Refund) domain.Refund {
+	payment, err := paymentRepo.FindRequestPayment(ctx, refund.RequestId)
+	if err == nil {
+		refund.GatewayRefundId, err = gateway.Refund(cfg, config.RefundOrder{
+			Reference: "refund-" + strconv.Itoa(refund.IdRefund),
+			OrderId:   payment.GatewayOrderId,
+			PaymentId: payment.GatewayPaymentId,
+			Amount:    refund.Amount,
+			Currency:  refund.Currency,
+		})
+	}
+	if err == nil {
+		refund.Status = domain.RefundProcessed
+	} else {
+		logger.WithContext(ctx).WithError(err).WithField("refund", refund.IdRefund).Error("gateway failed to refund")
+		refund.Status, refund.LastError = domain.RefundFailed, err.Error()
+	}
+
+	if err = paymentRepo.MarkRefund(ctx, refund.IdRefund, refund.Status, refund.GatewayRefundId, refund.LastError); err != nil {
+		logger.WithContext(ctx).WithError(err).WithFields(logrus.Fields{"refund": refund.IdRefund, "status": refund.Status}).Error("refund not marked")
+	}
+	return refund
+}
+
+// commissionBps is the platform's cut of a released payment in basis points
+func commissionBps(cfg config.Config) int {
+	if cfg.CommissionBps <= 0 {
+		return domain.DefaultCommissionBps
+	}
+	return cfg.CommissionBps
+}
+
+func NewPaymentService(
+	paymentRepo interfaces.PaymentRepository,
+	bookingRepo interfaces.BookingRepository,
+	invoiceUseCase services.InvoiceUseCase,
+	gateway config.PaymentGateway,
+	cfg config.Config,
+	logger *logrus.Logger) services.PaymentUseCase {
+	return &paymentUseCase{
+		paymentRepo:    paymentRepo,
+		bookingRepo:    bookingRepo,
+		invoiceUseCase: invoiceUseCase,
+		gateway:        gateway,
+		config:         cfg,
+		logger:         logger,
+	}
+}
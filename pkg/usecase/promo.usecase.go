@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type promoUseCase struct {
+	promoRepo   interfaces.PromoRepository
+	bookingRepo interfaces.BookingRepository
+}
+
+// CreatePromoCode implements interfaces.PromoUseCase
+func (c *promoUseCase) CreatePromoCode(ctx context.Context, adminId int, input domain.PromoCodeInput) (domain.PromoCode, error) {
+	promo, err := promoFromInput(input)
+	if err != nil {
+		return promo, err
+	}
+	promo.CreatedBy = adminId
+	return c.promoRepo.CreatePromoCode(ctx, promo)
+}
+
+// UpdatePromoCode implements interfaces.PromoUseCase
+func (c *promoUseCase) UpdatePromoCode(ctx context.Context, promoCodeId int, input domain.PromoCodeInput) (domain.PromoCode, error) {
+	promo, err := promoFromInput(input)
+	if err != nil {
+		return promo, err
+	}
+	promo.IdPromoCode = promoCodeId
+	return c.promoRepo.UpdatePromoCode(ctx, promo)
+}
+
+// ListPromoCodes implements interfaces.PromoUseCase
+func (c *promoUseCase) ListPromoCodes(ctx context.Context, filter utils.Filter) ([]domain.PromoCode, utils.Metadata, error) {
+	return c.promoRepo.ListPromoCodes(ctx, filter)
+}
+
+// CheckPromoCode implements interfaces.PromoUseCase
+func (c *promoUseCase) CheckPromoCode(ctx context.Context, userId int, input domain.PromoCheckInput) (domain.PromoQuote, error) {
+	job, err := c.bookingRepo.FindJob(ctx, input.JobId)
+	if err != nil {
+		return domain.PromoQuote{}, err
+	}
+	amount := slotPrice(job, input.Slot)
+
+	promo, discount, err := c.ApplyPromoCode(ctx, userId, input.Code, job, amount)
+	if err != nil {
+		return domain.PromoQuote{}, err
+	}
+	return domain.PromoQuote{
+		Code:     promo.Code,
+		Amount:   amount,
+		Discount: discount,
+		Payable:  amount - discount,
+		Currency: domain.DefaultCurrency,
+	}, nil
+}
+
+// ApplyPromoCode implements interfaces.PromoUseCase. The usage limits are
+// checked again when the code is used up, this only saves a booking that
+// would fail anyway.
+func (c *promoUseCase) ApplyPromoCode(ctx context.Context, userId int, code string, job domain.Job, amount int64) (domain.PromoCode, int64, error) {
+	promo, err := c.promoRepo.FindPromoCode(ctx, normalisePromoCode(code))
+	if err != nil {
+		return promo, 0, err
+	}
+
+	now := time.Now()
+	if !promo.Active || now.Before(promo.StartsAt) || !now.Before(promo.EndsAt) {
+		return promo, 0, domain.ErrPromoNotActive
+	}
+	if promo.CategoryId != nil && *promo.CategoryId != job.CategoryId {
+		return promo, 0, domain.ErrPromoCategory
+	}
+	if amount < promo.MinAmount {
+		return promo, 0, domain.ErrPromoMinimum.WithMessage("the promo code needs a booking of at least " + utils.FormatMinor(promo.MinAmount) + " " + domain.DefaultCurrency)
+	}
+	if promo.UsageLimit > 0 && promo.Used >= promo.UsageLimit {
+		return promo, 0, domain.ErrPromoUsedUp
+	}
+
+	if promo.PerUserLimit > 0 {
+		used, err := c.promoRepo.CountRedemptions(ctx, promo.IdPromoCode, userId)
+		if err != nil {
+			return promo, 0, err
+		}
+		if used >= promo.PerUserLimit {
+			return promo, 0, domain.ErrPromoUsed
+		}
+	}
+	if promo.FirstBookingOnly {
+		bookings, err := c.promoRepo.CountUserBookings(ctx, userId)
+		if err != nil {
+			return promo, 0, err
+		}
+		if bookings > 0 {
+			return promo, 0, domain.ErrPromoNotFirst
+		}
+	}
+
+	return promo, promoDiscount(promo, amount), nil
+}
+
+// ReleasePromoCode implements interfaces.PromoUseCase
+func (c *promoUseCase) ReleasePromoCode(ctx context.Context, requestId int) error {
+	return c.promoRepo.ReleaseRedemption(ctx, requestId)
+}
+
+// promoDiscount is what a code takes off amount. Something is always left to
+// pay as a gateway cannot take a payment of nothing.
+func promoDiscount(promo domain.PromoCode, amount int64) int64 {
+	discount := promo.Value
+	if promo.Kind == domain.PromoPercent {
+		discount = amount * promo.Value / 10000
+		if promo.MaxDiscount > 0 && discount > promo.MaxDiscount {
+			discount = promo.MaxDiscount
+		}
+	}
+	if discount >= amount {
+		discount = amount - 1
+	}
+	return discount
+}
+
+func promoFromInput(input domain.PromoCodeInput) (domain.PromoCode, error) {
+	if input.Kind == domain.PromoPercent && input.Value > 10000 {
+		return domain.PromoCode{}, domain.ErrPromoPercent
+	}
+	return domain.PromoCode{
+		Code:             normalisePromoCode(input.Code),
+		Description:      input.Description,
+		Kind:             input.Kind,
+		Value:            input.Value,
+		MaxDiscount:      input.MaxDiscount,
+		MinAmount:        input.MinAmount,
+		CategoryId:       input.CategoryId,
+		FirstBookingOnly: input.FirstBookingOnly,
+		UsageLimit:       input.UsageLimit,
+		PerUserLimit:     input.PerUserLimit,
+		StartsAt:         input.StartsAt,
+		EndsAt:           input.EndsAt,
+		Active:           input.Active,
+	}, nil
+}
+
+// normalisePromoCode makes codes case insensitive, they are stored upper case
+func normalisePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func NewPromoService(
+	promoRepo interfaces.PromoRepository,
+	bookingRepo interfaces.BookingRepository) services.PromoUseCase {
+	return &promoUseCase{
+		promoRepo:   promoRepo,
+		bookingRepo: bookingRepo,
+	}
+}
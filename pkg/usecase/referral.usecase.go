@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	referralCodeLength = 8
+	// referralCodeAttempts bounds the retries when a new code is already taken
+	referralCodeAttempts = 5
+)
+
+type referralUseCase struct {
+	referralRepo interfaces.ReferralRepository
+	config       config.Config
+	logger       *logrus.Logger
+}
+
+// GetReferral implements interfaces.ReferralUseCase
+func (c *referralUseCase) GetReferral(ctx context.Context, userId int) (domain.ReferralSummary, error) {
+	code, err := c.ensureCode(ctx, userId)
+	if err != nil {
+		return domain.ReferralSummary{}, err
+	}
+	summary, err := c.referralRepo.Summary(ctx, userId)
+	summary.Code = code.Code
+	return summary, err
+}
+
+// CheckCode implements interfaces.ReferralUseCase
+func (c *referralUseCase) CheckCode(ctx context.Context, code string) error {
+	_, err := c.referralRepo.FindReferrer(ctx, normaliseReferralCode(code))
+	return err
+}
+
+// SignedUp implements interfaces.ReferralUseCase. A referral from the same
+// phone number, in E.164 format, or device as the referrer is recorded as
+// rejected so it is never rewarded.
+func (c *referralUseCase) SignedUp(ctx context.Context, userId int, phoneNumber string, signup domain.Signup) error {
+	deviceId := strings.TrimSpace(signup.DeviceId)
+	if deviceId != "" {
+		if err := c.referralRepo.AddSignupDevice(ctx, userId, deviceId); err != nil {
+			return err
+		}
+	}
+	if _, err := c.ensureCode(ctx, userId); err != nil {
+		return err
+	}
+	if strings.TrimSpace(signup.ReferralCode) == "" {
+		return nil
+	}
+
+	code := normaliseReferralCode(signup.ReferralCode)
+	referrer, err := c.referralRepo.FindReferrer(ctx, code)
+	if err != nil {
+		return err
+	}
+	referral := domain.Referral{
+		ReferrerId: referrer.IdUser,
+		RefereeId:  userId,
+		Code:       code,
+		DeviceId:   deviceId,
+		Status:     domain.ReferralPending,
+	}
+
+	switch {
+	case referrer.IdUser == userId:
+		referral.Status, referral.RejectReason = domain.ReferralRejected, "own code"
+	case referrer.Phone == phoneNumber:
+		referral.Status, referral.RejectReason = domain.ReferralRejected, "same phone number as the referrer"
+	case deviceId == "":
+		referral.Status, referral.RejectReason = domain.ReferralRejected, "no device id"
+	default:
+		seen, err := c.referralRepo.DeviceSeen(ctx, deviceId, referrer.IdUser)
+		if err != nil {
+			return err
+		}
+		if seen {
+			referral.Status, referral.RejectReason = domain.ReferralRejected, "device already used by the referrer or another referral"
+		}
+	}
+
+	_, err = c.referralRepo.CreateReferral(ctx, referral)
+	return err
+}
+
+// Reward implements interfaces.ReferralUseCase. Only the first completed
+// booking finds the referral pending, later ones leave it alone.
+func (c *referralUseCase) Reward(ctx context.Context, booking domain.BookingResponse) error {
+	reward := c.config.ReferralReward
+	if reward <= 0 {
+		reward = domain.DefaultReferralReward
+	}
+	for _, refereeId := range []int{booking.UserId, booking.WorkerId} {
+		referral, err := c.referralRepo.RewardReferral(ctx, refereeId, booking.IdRequest, reward)
+		if err != nil {
+			return err
+		}
+		if referral != nil {
+			c.logger.WithContext(ctx).WithFields(logrus.Fields{
+				"referral": referral.IdReferral,
+				"reward":   utils.FormatMinor(reward),
+				"referrer": referral.ReferrerId,
+				"referee":  referral.RefereeId,
+			}).Info("referral rewarded")
+		}
+	}
+	return nil
+}
+
+// ensureCode returns an account's referral code, making one if it has none
+func (c *referralUseCase) ensureCode(ctx context.Context, userId int) (domain.ReferralCode, error) {
+	code, err := c.referralRepo.FindCode(ctx, userId)
+	if err == nil || !errors.Is(err, domain.ErrNoReferralCode) {
+		return code, err
+	}
+	for attempt := 0; attempt < referralCodeAttempts; attempt++ {
+		code, err = c.referralRepo.CreateCode(ctx, userId, utils.RandomCode(referralCodeLength))
+		if err == nil || !errors.Is(err, domain.ErrReferralTaken) {
+			return code, err
+		}
+	}
+	return code, err
+}
+
+func normaliseReferralCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func NewReferralService(
+	referralRepo interfaces.ReferralRepository,
+	cfg config.Config,
+	logger *logrus.Logger) services.ReferralUseCase {
+	return &referralUseCase{
+		referralRepo: referralRepo,
+		config:       cfg,
+		logger:       logger,
+	}
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	renewalBatchSize    = 100
+	renewalPollInterval = 10 * time.Minute
+)
+
+type subscriptionUseCase struct {
+	subscriptionRepo interfaces.SubscriptionRepository
+	config           config.Config
+	logger           *logrus.Logger
+}
+
+// CreatePlan implements interfaces.SubscriptionUseCase
+func (c *subscriptionUseCase) CreatePlan(ctx context.Context, input domain.SubscriptionPlanInput) (domain.SubscriptionPlan, error) {
+	return c.subscriptionRepo.CreatePlan(ctx, planFromInput(input))
+}
+
+// UpdatePlan implements interfaces.SubscriptionUseCase
+func (c *subscriptionUseCase) UpdatePlan(ctx context.Context, planId int, input domain.SubscriptionPlanInput) (domain.SubscriptionPlan, error) {
+	plan := planFromInput(input)
+	plan.IdPlan = planId
+	return c.subscriptionRepo.UpdatePlan(ctx, plan)
+}
+
+// ListPlans implements interfaces.SubscriptionUseCase
+func (c *subscriptionUseCase) ListPlans(ctx context.Context, activeOnly bool) ([]domain.SubscriptionPlan, error) {
+	return c.subscriptionRepo.ListPlans(ctx, activeOnly)
+}
+
+// Subscribe implements interfaces.SubscriptionUseCase
+func (c *subscriptionUseCase) Subscribe(ctx context.Context, workerId int, input domain.SubscribeInput) (domain.Subscription, error) {
+	plan, err := c.subscriptionRepo.FindPlan(ctx, input.PlanId)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if !plan.Active {
+		return domain.Subscription{}, domain.ErrPlanRetired
+	}
+	return c.subscriptionRepo.Subscribe(ctx, workerId, plan)
+}
+
+// GetSubscription implements interfaces.SubscriptionUseCase
+func (c *subscriptionUseCase) GetSubscription(ctx context.Context, workerId int) (domain.Subscription, error) {
+	return c.subscriptionRepo.CurrentSubscription(ctx, workerId)
+}
+
+// SetAutoRenew implements interfaces.SubscriptionUseCase
+func (c *subscriptionUseCase) SetAutoRenew(ctx context.Context, workerId int, autoRenew bool) (domain.Subscription, error) {
+	return c.subscriptionRepo.SetAutoRenew(ctx, workerId, autoRenew)
+}
+
+// RenewDue implements interfaces.SubscriptionUseCase. It returns how many
+// subscriptions were looked at, one failing does not stop the others.
+func (c *subscriptionUseCase) RenewDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	ids, err := c.subscriptionRepo.DueSubscriptions(ctx, now, renewalBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	graceDays := c.config.GraceDays
+	if graceDays <= 0 {
+		graceDays = domain.DefaultGraceDays
+	}
+	for _, id := range ids {
+		if _, err := c.subscriptionRepo.Renew(ctx, id, now, graceDays); err != nil {
+			c.logger.WithContext(ctx).WithError(err).WithField("subscription", id).Error("failed to renew subscription")
+		}
+	}
+	return len(ids), nil
+}
+
+// Run implements interfaces.SubscriptionUseCase. It renews due subscriptions
+// until ctx is done. Subscriptions in grace are due on every pass, so a single
+// batch is taken per tick.
+func (c *subscriptionUseCase) Run(ctx context.Context) {
+	ticker := time.NewTicker(renewalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.RenewDue(ctx); err != nil {
+			c.logger.WithContext(ctx).WithError(err).Error("failed to renew due subscriptions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func planFromInput(input domain.SubscriptionPlanInput) domain.SubscriptionPlan {
+	return domain.SubscriptionPlan{
+		Name:     input.Name,
+		Interval: input.Interval,
+		Price:    input.Price,
+		Currency: domain.DefaultCurrency,
+		JobLimit: input.JobLimit,
+		Priority: input.Priority,
+		Badge:    input.Badge,
+		Active:   input.Active,
+	}
+}
+
+func NewSubscriptionService(
+	subscriptionRepo interfaces.SubscriptionRepository,
+	cfg config.Config,
+	logger *logrus.Logger) services.SubscriptionUseCase {
+	return &subscriptionUseCase{
+		subscriptionRepo: subscriptionRepo,
+		config:           cfg,
+		logger:           logger,
+	}
+}
@@ -2,15 +2,21 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/fazilnbr/project-workey/pkg/domain"
 	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
 	"github.com/fazilnbr/project-workey/pkg/utils"
+	"github.com/sirupsen/logrus"
 )
 
 type userUseCase struct {
-	userRepo interfaces.UserRepository
+	userRepo     interfaces.UserRepository
+	transactor   interfaces.Transactor
+	auditUseCase services.AuditUseCase
+	logger       *logrus.Logger
 }
 
 // GetProfile implements interfaces.UserUseCase
@@ -19,10 +25,19 @@ func (c *userUseCase) GetProfile(ctx context.Context, userId int) (domain.Profil
 	return profile, err
 }
 
-// UpdateMail implements interfaces.UserUseCase
+// UpdateMail implements interfaces.UserUseCase. The change is audited as the
+// email is what a password reset or a Google sign in goes by.
 func (c *userUseCase) UpdateMail(ctx context.Context, email string, userId int) error {
-	err := c.userRepo.UpdateMail(ctx, email, userId)
-	return err
+	user, err := c.userRepo.FindUserWithId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	err = c.userRepo.UpdateMail(ctx, email, userId)
+	if err != nil {
+		return err
+	}
+	c.auditMailChange(ctx, userId, user.Email, email)
+	return nil
 }
 
 // AddProfile implements interfaces.UserUseCase
@@ -31,15 +46,50 @@ func (c *userUseCase) AddProfile(ctx context.Context, userData domain.UserData)
 	return err
 }
 
+// AddProfileAndUpdateMail implements interfaces.UserUseCase. The email and
+// the profile are written in one transaction, so either both change or
+// neither does.
+func (c *userUseCase) AddProfileAndUpdateMail(ctx context.Context, userData domain.UserData) error {
+	user, err := c.userRepo.FindUserWithId(ctx, userData.UserId)
+	if err != nil {
+		return err
+	}
+	err = c.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := c.userRepo.UpdateMail(ctx, userData.Email, userData.UserId); err != nil {
+			return err
+		}
+		return c.userRepo.AddProfile(ctx, userData)
+	})
+	if err != nil {
+		return err
+	}
+	c.auditMailChange(ctx, userData.UserId, user.Email, userData.Email)
+	return nil
+}
+
+// auditMailChange records an email change
+func (c *userUseCase) auditMailChange(ctx context.Context, userId int, before string, after string) {
+	type state struct {
+		Email string `json:"email"`
+	}
+	entry, err := auditEntry(ctx, domain.AuditEmailChange, domain.AuditTargetUser, userId, state{before}, state{after})
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("user", userId).Error("failed to audit email change")
+		return
+	}
+	c.auditUseCase.Record(ctx, entry)
+}
+
 // RegisterAndVarifyWithEmail implements interfaces.UserUseCase
 func (c *userUseCase) RegisterAndVarifyWithEmail(ctx context.Context, email string) (int, error) {
 	user, err := c.userRepo.FindUserWithEmail(ctx, email)
-	if err == nil || err.Error() != "there is no user" {
+	if err == nil || !errors.Is(err, domain.ErrNoUser) {
 		return user.IdUser, err
 	}
 	id, err := c.userRepo.CreateUser(ctx, domain.User{
-		Email: email,
-		Phone: utils.Randomphone(5),
+		Email:         email,
+		Phone:         utils.Randomphone(5),
+		SignupChannel: domain.LoginGoogle,
 	})
 	if err != nil {
 		return 0, err
@@ -48,24 +98,90 @@ func (c *userUseCase) RegisterAndVarifyWithEmail(ctx context.Context, email stri
 }
 
 // RegisterAndVarify implements interfaces.UserUseCase
-func (c *userUseCase) RegisterAndVarifyWithNumber(ctx context.Context, phoneNumber string) (int, error) {
-	user, err := c.userRepo.FindUserWithNumber(ctx, phoneNumber)
-	if err == nil || err.Error() != "there is no user" {
-		return user.IdUser, err
+func (c *userUseCase) RegisterAndVarifyWithNumber(ctx context.Context, phone domain.PhoneNumber) (int, bool, error) {
+	user, err := c.userRepo.FindUserWithNumber(ctx, phone.E164)
+	if err == nil || !errors.Is(err, domain.ErrNoUser) {
+		return user.IdUser, false, err
 	}
 	id, err := c.userRepo.CreateUser(ctx, domain.User{
-		Phone: phoneNumber,
-		Email: utils.Randommail(5),
+		Phone:         phone.E164,
+		PhoneCountry:  phone.Region,
+		Email:         utils.Randommail(5),
+		SignupChannel: domain.LoginPhone,
 	})
 	if err != nil {
-		return 0, err
+		return 0, false, err
 	}
-	return id, err
+	return id, true, err
+}
+
+// UserRole implements interfaces.UserUseCase. A blocked or suspended account
+// has no role and so cannot be given a token.
+func (c *userUseCase) UserRole(ctx context.Context, userId int) (string, error) {
+	user, err := c.userRepo.FindUserWithId(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	if err = lockedError(user); err != nil {
+		return "", err
+	}
+	if user.UserType == "" {
+		return domain.RoleUser, nil
+	}
+	return user.UserType, nil
+}
+
+// CheckSession implements interfaces.UserUseCase. A token issued at issuedAt
+// is still good unless the account got locked or logged out after it.
+func (c *userUseCase) CheckSession(ctx context.Context, userId int, issuedAt time.Time) error {
+	user, err := c.userRepo.FindUserWithId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if err = lockedError(user); err != nil {
+		return err
+	}
+	// Tokens carry whole seconds, so one issued in the second of the logout
+	// may be older than it and goes as well
+	if user.LoggedOutAt != nil && !issuedAt.After(*user.LoggedOutAt) {
+		return domain.ErrLoggedOut
+	}
+	return nil
+}
+
+// RecordLogin implements interfaces.UserUseCase. The sign in went through
+// already, so a failure is only logged.
+func (c *userUseCase) RecordLogin(ctx context.Context, userId int, method string, ip string, userAgent string) {
+	err := c.userRepo.RecordLogin(ctx, domain.LoginEvent{
+		UserId:    userId,
+		Method:    method,
+		Ip:        ip,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithField("user", userId).Error("failed to record login")
+	}
+}
+
+func lockedError(user domain.User) error {
+	if !user.Locked(time.Now()) {
+		return nil
+	}
+	if user.Status == domain.UserBlocked {
+		return domain.ErrBlocked
+	}
+	return domain.ErrSuspended
 }
 
 func NewUserService(
-	userRepo interfaces.UserRepository) services.UserUseCase {
+	userRepo interfaces.UserRepository,
+	transactor interfaces.Transactor,
+	auditUseCase services.AuditUseCase,
+	logger *logrus.Logger) services.UserUseCase {
 	return &userUseCase{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		transactor:   transactor,
+		auditUseCase: auditUseCase,
+		logger:       logger,
 	}
 }
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
+	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
+)
+
+type walletUseCase struct {
+	walletRepo interfaces.WalletRepository
+	config     config.Config
+}
+
+// GetWallet implements interfaces.WalletUseCase
+func (c *walletUseCase) GetWallet(ctx context.Context, workerId int) (domain.Wallet, error) {
+	return c.walletRepo.Balance(ctx, workerId)
+}
+
+// ListEntries implements interfaces.WalletUseCase
+func (c *walletUseCase) ListEntries(ctx context.Context, workerId int, filter utils.Filter) ([]domain.WalletEntry, utils.Metadata, error) {
+	return c.walletRepo.ListEntries(ctx, workerId, filter)
+}
+
+// Statement implements interfaces.WalletUseCase
+func (c *walletUseCase) Statement(ctx context.Context, workerId int, month string) (domain.Statement, error) {
+	from, err := time.Parse("2006-01", month)
+	if err != nil {
+		return domain.Statement{}, domain.ErrInvalidMonth
+	}
+
+	statement, err := c.walletRepo.Statement(ctx, workerId, from, from.AddDate(0, 1, 0))
+	statement.Month = month
+	return statement, err
+}
+
+// Adjust implements interfaces.WalletUseCase
+func (c *walletUseCase) Adjust(ctx context.Context, adminId int, workerId int, adjustment domain.AdjustmentInput) (domain.LedgerTransaction, error) {
+	return c.walletRepo.PostAdjustment(ctx, workerId, adminId, adjustment.Amount, adjustment.Memo)
+}
+
+// RequestPayout implements interfaces.WalletUseCase
+func (c *walletUseCase) RequestPayout(ctx context.Context, workerId int, payout domain.PayoutInput) (domain.Payout, error) {
+	if minimum := payoutMinimum(c.config); payout.Amount < minimum {
+		return domain.Payout{}, domain.ErrPayoutMinimum.WithMessage(fmt.Sprintf("a payout must be at least %s %s", utils.FormatMinor(minimum), domain.DefaultCurrency))
+	}
+
+	return c.walletRepo.RequestPayout(ctx, domain.Payout{
+		WorkerId:    workerId,
+		Amount:      payout.Amount,
+		Currency:    domain.DefaultCurrency,
+		Destination: payout.Destination,
+	})
+}
+
+// ListPayouts implements interfaces.WalletUseCase
+func (c *walletUseCase) ListPayouts(ctx context.Context, workerId int, filter utils.Filter) ([]domain.Payout, utils.Metadata, error) {
+	return c.walletRepo.ListWorkerPayouts(ctx, workerId, filter)
+}
+
+// ListPayoutsByStatus implements interfaces.WalletUseCase
+func (c *walletUseCase) ListPayoutsByStatus(ctx context.Context, status string, filter utils.Filter) ([]domain.Payout, utils.Metadata, error) {
+	switch status {
+	case "":
+		status = domain.PayoutRequested
+	case domain.PayoutRequested, domain.PayoutApproved, domain.PayoutRejected, domain.PayoutPaid:
+	default:
+		return nil, utils.Metadata{}, domain.ErrUnknownStatus.WithMessage("unknown payout status " + status)
+	}
+	return c.walletRepo.ListPayoutsByStatus(ctx, status, filter)
+}
+
+// ApprovePayout implements interfaces.WalletUseCase
+func (c *walletUseCase) ApprovePayout(ctx context.Context, adminId int, payoutId int, review domain.PayoutReviewInput) (domain.Payout, error) {
+	return c.walletRepo.ApprovePayout(ctx, payoutId, adminId, review.Note)
+}
+
+// RejectPayout implements interfaces.WalletUseCase
+func (c *walletUseCase) RejectPayout(ctx context.Context, adminId int, payoutId int, review domain.PayoutReviewInput) (domain.Payout, error) {
+	return c.walletRepo.RejectPayout(ctx, payoutId, adminId, review.Note)
+}
+
+// ExportPayoutBatch implements interfaces.WalletUseCase
+func (c *walletUseCase) ExportPayoutBatch(ctx context.Context, adminId int) (domain.PayoutBatch, []byte, error) {
+	batch, payouts, err := c.walletRepo.CreatePayoutBatch(ctx, adminId)
+	if err != nil {
+		return batch, nil, err
+	}
+	file, err := payoutBatchCSV(payouts)
+	return batch, file, err
+}
+
+// GetPayoutBatch implements interfaces.WalletUseCase
+func (c *walletUseCase) GetPayoutBatch(ctx context.Context, batchId int) (domain.PayoutBatch, []byte, error) {
+	batch, payouts, err := c.walletRepo.FindPayoutBatch(ctx, batchId)
+	if err != nil {
+		return batch, nil, err
+	}
+	file, err := payoutBatchCSV(payouts)
+	return batch, file, err
+}
+
+// payoutBatchCSV lays a batch out the way bank bulk transfer uploads expect,
+// one transfer a line with the payout as its reference
+func payoutBatchCSV(payouts []domain.Payout) ([]byte, error) {
+	var file bytes.Buffer
+	writer := csv.NewWriter(&file)
+
+	writer.Write([]string{"reference", "worker_id", "destination", "amount", "currency"})
+	for _, payout := range payouts {
+		writer.Write([]string{
+			"payout-" + strconv.Itoa(payout.IdPayout),
+			strconv.Itoa(payout.WorkerId),
+			payout.Destination,
+			utils.FormatMinor(payout.Amount),
+			payout.Currency,
+		})
+	}
+	writer.Flush()
+	return file.Bytes(), writer.Error()
+}
+
+// payoutMinimum is the smallest payout a worker can ask for in minor units
+func payoutMinimum(cfg config.Config) int64 {
+	if cfg.PayoutMinimum <= 0 {
+		return domain.DefaultPayoutMinimum
+	}
+	return cfg.PayoutMinimum
+}
+
+func NewWalletService(
+	walletRepo interfaces.WalletRepository,
+	cfg config.Config) services.WalletUseCase {
+	return &walletUseCase{
+		walletRepo: walletRepo,
+		config:     cfg,
+	}
+}
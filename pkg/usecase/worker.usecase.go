@@ -1,17 +1,149 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	interfaces "github.com/fazilnbr/project-workey/pkg/repository/interface"
 	services "github.com/fazilnbr/project-workey/pkg/usecase/interface"
+	"github.com/fazilnbr/project-workey/pkg/utils"
 )
 
 type workerService struct {
-	workerRepo interfaces.WorkerRepository
+	workerRepo       interfaces.WorkerRepository
+	subscriptionRepo interfaces.SubscriptionRepository
+}
+
+// SetAvailability implements interfaces.WorkerUseCase
+func (c *workerService) SetAvailability(ctx context.Context, workerId int, availability domain.WeeklyAvailability) error {
+	if _, err := time.LoadLocation(availability.Timezone); err != nil {
+		return domain.ErrUnknownTimezone.WithMessage(fmt.Sprintf("unknown timezone %q", availability.Timezone))
+	}
+
+	seen := map[int]bool{}
+	days := make([]domain.Availability, 0, len(availability.Days))
+	for _, day := range availability.Days {
+		if seen[day.Weekday] {
+			return domain.ErrRepeatedWeekday.WithMessage(fmt.Sprintf("weekday %d is listed more than once", day.Weekday))
+		}
+		seen[day.Weekday] = true
+		days = append(days, domain.Availability{
+			WorkerId:   workerId,
+			Weekday:    day.Weekday,
+			FirstHalf:  day.FirstHalf,
+			SecondHalf: day.SecondHalf,
+			Timezone:   availability.Timezone,
+		})
+	}
+
+	return c.workerRepo.SetAvailability(ctx, workerId, days)
+}
+
+// GetAvailability implements interfaces.WorkerUseCase
+func (c *workerService) GetAvailability(ctx context.Context, workerId int) (domain.WeeklyAvailability, error) {
+	var availability domain.WeeklyAvailability
+
+	days, err := c.workerRepo.GetAvailability(ctx, workerId)
+	if err != nil {
+		return availability, err
+	}
+	availability.Days = []domain.DayAvailability{}
+	for _, day := range days {
+		availability.Timezone = day.Timezone
+		availability.Days = append(availability.Days, domain.DayAvailability{
+			Weekday:    day.Weekday,
+			FirstHalf:  day.FirstHalf,
+			SecondHalf: day.SecondHalf,
+		})
+	}
+	return availability, nil
+}
+
+// AddBlackout implements interfaces.WorkerUseCase
+func (c *workerService) AddBlackout(ctx context.Context, workerId int, blackout domain.BlackoutInput) (int, error) {
+	day, err := time.Parse(dateLayout, blackout.Day)
+	if err != nil {
+		return 0, domain.ErrInvalidDate.WithMessage("day must be in YYYY-MM-DD format")
+	}
+	return c.workerRepo.AddBlackout(ctx, domain.Blackout{
+		WorkerId: workerId,
+		Day:      day,
+		Reason:   blackout.Reason,
+	})
+}
+
+// ListBlackouts implements interfaces.WorkerUseCase
+func (c *workerService) ListBlackouts(ctx context.Context, workerId int) ([]domain.Blackout, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return c.workerRepo.ListBlackouts(ctx, workerId, today.AddDate(0, 0, -1))
+}
+
+// DeleteBlackout implements interfaces.WorkerUseCase
+func (c *workerService) DeleteBlackout(ctx context.Context, workerId int, blackoutId int) error {
+	return c.workerRepo.DeleteBlackout(ctx, workerId, blackoutId)
+}
+
+// AddJob implements interfaces.WorkerUseCase
+func (c *workerService) AddJob(ctx context.Context, workerId int, input domain.JobInput) (int, error) {
+	jobLimit, err := c.jobLimit(ctx, workerId)
+	if err != nil {
+		return 0, err
+	}
+	return c.workerRepo.AddJob(ctx, domain.Job{
+		IdWorker:    workerId,
+		CategoryId:  input.CategoryId,
+		Expirience:  input.Expirience,
+		Description: input.Description,
+		FullDayWage: input.FullDayWage,
+		HalfDayWage: input.HalfDayWage,
+	}, jobLimit)
+}
+
+// ListJobs implements interfaces.WorkerUseCase
+func (c *workerService) ListJobs(ctx context.Context, workerId int) ([]domain.JobListing, error) {
+	return c.workerRepo.ListWorkerJobs(ctx, workerId)
+}
+
+// SetJobOpen implements interfaces.WorkerUseCase
+func (c *workerService) SetJobOpen(ctx context.Context, workerId int, jobId int, input domain.JobOpenInput) error {
+	jobLimit, err := c.jobLimit(ctx, workerId)
+	if err != nil {
+		return err
+	}
+	return c.workerRepo.SetJobOpen(ctx, workerId, jobId, input.Openwork, jobLimit)
+}
+
+// SearchJobs implements interfaces.WorkerUseCase. Results are ranked rather
+// than ordered by time, so they are paged by offset only.
+func (c *workerService) SearchJobs(ctx context.Context, categoryId int, filter utils.Filter) ([]domain.JobListing, utils.Metadata, error) {
+	if filter.IsCursor() {
+		return nil, utils.Metadata{}, domain.ErrOffsetOnly.WithMessage("job search is paged by offset only")
+	}
+	return c.workerRepo.SearchJobs(ctx, categoryId, filter)
+}
+
+// jobLimit is how many open jobs the worker's plan allows
+func (c *workerService) jobLimit(ctx context.Context, workerId int) (int, error) {
+	subscription, err := c.subscriptionRepo.CurrentSubscription(ctx, workerId)
+	if err != nil && errors.Is(err, domain.ErrNoSubscription) {
+		return domain.FreeJobLimit, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if subscription.Plan.JobLimit < domain.FreeJobLimit {
+		return domain.FreeJobLimit, nil
+	}
+	return subscription.Plan.JobLimit, nil
 }
 
-func NewWorkerService(workerRepo interfaces.WorkerRepository) services.WorkerUseCase {
+func NewWorkerService(workerRepo interfaces.WorkerRepository, subscriptionRepo interfaces.SubscriptionRepository) services.WorkerUseCase {
 	return &workerService{
-		workerRepo: workerRepo,
+		workerRepo:       workerRepo,
+		subscriptionRepo: subscriptionRepo,
 	}
 
 }
@@ -1,20 +1,43 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"strings"
 
+	"github.com/fazilnbr/project-workey/pkg/domain"
 	"github.com/gin-gonic/gin"
 )
 
-// Response is used for static shape json return
+// Response is used for static shape json return. Code is the machine
+// readable code of a failure.
 type Response struct {
 	Status  bool        `json:"status"`
 	Message string      `json:"message"`
+	Code    string      `json:"code,omitempty"`
 	Errors  interface{} `json:"errors,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// Codes of the failures that are not domain errors
+const (
+	ErrCodeTimeout  = "timeout"
+	ErrCodeInternal = "internal"
+)
+
+// kindStatus is the status code each kind of domain error is answered with
+var kindStatus = map[string]int{
+	domain.ErrKindNotFound:      http.StatusNotFound,
+	domain.ErrKindConflict:      http.StatusConflict,
+	domain.ErrKindValidation:    http.StatusBadRequest,
+	domain.ErrKindUnprocessable: http.StatusUnprocessableEntity,
+	domain.ErrKindUnauthorized:  http.StatusUnauthorized,
+	domain.ErrKindForbidden:     http.StatusForbidden,
+	domain.ErrKindRateLimited:   http.StatusTooManyRequests,
+}
+
 // ErrorResponse method is used to inject data value to dynamic failed response
 func ErrorResponse(message string, err string, data interface{}) Response {
 	splittedError := strings.Split(err, "\n")
@@ -27,6 +50,45 @@ func ErrorResponse(message string, err string, data interface{}) Response {
 	return res
 }
 
+// ErrorStatus is the status code and error code a request failing with err
+// is answered with. Errors that are not domain errors are failures of the
+// server, the client can do nothing about them.
+func ErrorStatus(err error) (int, string) {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		if status, ok := kindStatus[domainErr.Kind]; ok {
+			return status, domainErr.Code
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, ErrCodeTimeout
+	}
+	return http.StatusInternalServerError, ErrCodeInternal
+}
+
+// ErrorMessage is what a client is told about err. Only domain errors are
+// meant for clients, the text of others can tell about the database.
+func ErrorMessage(err error) string {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return err.Error()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "the request took too long"
+	}
+	return "something went wrong, try again later"
+}
+
+// ErrorFields is what is wrong with each field of the request err failed, nil
+// when err is not about the fields of a request
+func ErrorFields(err error) map[string]domain.FieldError {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) && len(domainErr.Fields) > 0 {
+		return domainErr.Fields
+	}
+	return nil
+}
+
 // SuccessResponse method is used to inject data value to dynamic success response
 func SuccessResponse(status bool, message string, data interface{}) Response {
 	res := Response{
@@ -38,7 +100,29 @@ func SuccessResponse(status bool, message string, data interface{}) Response {
 	return res
 }
 
-func ResponseJSON(c gin.Context, data interface{}) {
+// Page is the data envelope shared by every list endpoint
+type Page struct {
+	Items      interface{} `json:"items"`
+	Pagination Metadata    `json:"pagination"`
+}
+
+// PageResponse wraps a page of items in a success response, turning the
+// keyset position of the next page into an opaque cursor token
+func PageResponse(codec CursorCodec, message string, items interface{}, meta Metadata) (Response, error) {
+	if meta.NextKey != nil {
+		token, err := codec.Encode(*meta.NextKey)
+		if err != nil {
+			return Response{}, err
+		}
+		meta.NextCursor = token
+	}
+	return SuccessResponse(true, message, Page{
+		Items:      items,
+		Pagination: meta,
+	}), nil
+}
+
+func ResponseJSON(c *gin.Context, data interface{}) {
 
 	c.Writer.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(c.Writer).Encode(data)
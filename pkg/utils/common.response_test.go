@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "test a missing record", err: domain.ErrNoRequest, expectedStatus: http.StatusNotFound, expectedCode: "request_not_found"},
+		{name: "test a conflict", err: domain.ErrSlotBooked, expectedStatus: http.StatusConflict, expectedCode: "slot_booked"},
+		{name: "test a wrapped domain error", err: fmt.Errorf("booking: %w", domain.ErrBlocked), expectedStatus: http.StatusForbidden, expectedCode: "account_blocked"},
+		{name: "test a domain error wrapping a cause", err: domain.ErrAlreadyExists.Wrap(errors.New("duplicate key")), expectedStatus: http.StatusConflict, expectedCode: "already_exists"},
+		{name: "test a validation error", err: domain.Validation("invalid_day", "day is invalid"), expectedStatus: http.StatusBadRequest, expectedCode: "invalid_day"},
+		{name: "test an error with a message of its own", err: domain.ErrInvalidTransition.WithMessage("cannot move a completed request to cancelled"), expectedStatus: http.StatusConflict, expectedCode: "invalid_transition"},
+		{name: "test a guard on ownership", err: domain.ErrOwnOffer, expectedStatus: http.StatusForbidden, expectedCode: "own_offer"},
+		{name: "test a rate limited error", err: domain.RateLimited("otp_limit", "too many codes"), expectedStatus: http.StatusTooManyRequests, expectedCode: "otp_limit"},
+		{name: "test a query out of time", err: context.DeadlineExceeded, expectedStatus: http.StatusGatewayTimeout, expectedCode: ErrCodeTimeout},
+		{name: "test a business rule", err: domain.ErrPayoutMinimum, expectedStatus: http.StatusUnprocessableEntity, expectedCode: "payout_too_small"},
+		{name: "test any other error", err: errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`), expectedStatus: http.StatusInternalServerError, expectedCode: ErrCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := ErrorStatus(tt.err)
+
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedCode, code)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position of a row in a list ordered by created_at, id
+type Cursor struct {
+	Id        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CursorCodec turns cursors into opaque tokens for clients and back. Tokens
+// are signed so a client cannot forge a position it was never handed.
+type CursorCodec interface {
+	Encode(cursor Cursor) (string, error)
+	Decode(token string) (Cursor, error)
+}
+
+type cursorCodec struct {
+	secret []byte
+}
+
+// Encode implements CursorCodec
+func (c *cursorCodec) Encode(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+// Decode implements CursorCodec
+func (c *cursorCodec) Decode(token string) (Cursor, error) {
+	var cursor Cursor
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return cursor, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, c.sign(parts[0])) {
+		return cursor, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func (c *cursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// NewCursorCodec signs cursors with CURSOR_SECRET, which has to be set, as
+// cursors signed with an empty key could be forged by anyone
+func NewCursorCodec(cfg config.Config) (CursorCodec, error) {
+	if cfg.CursorSecret == "" {
+		return nil, errors.New("CURSOR_SECRET is not set, cursors could be forged")
+	}
+	return &cursorCodec{
+		secret: []byte(cfg.CursorSecret),
+	}, nil
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Formats logs can be written in
+const (
+	LogJSON = "json"
+	LogText = "text"
+)
+
+// Redacted stands in for what logs must not show
+const Redacted = "[REDACTED]"
+
+// RequestIDHeader carries the id of a request, both ways
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// requestIDPattern is what a request id given by a client has to look like to
+// be kept, so ids can't be used to forge or flood log lines
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// sensitiveKeys are parts of the names of fields whose values are never
+// logged
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "otp", "authorization", "cookie", "signature", "apikey", "api_key", "private_key"}
+
+var (
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`)
+	secretPattern = regexp.MustCompile(`(?i)\b(password|passwd|secret|token|otp|api_?key)(\s*[:=]\s*)[^\s,;&]+`)
+	logEmail      = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+	// logPhone is a number with its calling code or a run of digits as long as
+	// a phone number, which leaves dates, amounts and ids alone
+	logPhone = regexp.MustCompile(`\+[0-9][0-9 -]{6,}[0-9]|\b[0-9]{10,15}\b`)
+)
+
+// NewLogger is the logger of the application, leveled by LOG_LEVEL and
+// written as JSON or text by LOG_FORMAT. Entries logged with a request's
+// context carry its id, and secrets, tokens, OTPs, phone numbers and emails
+// are redacted from every entry.
+func NewLogger(cfg config.Config) (*logrus.Logger, error) {
+	logger := logrus.New()
+	logger.SetOutput(os.Stdout)
+
+	switch strings.ToLower(cfg.LogFormat) {
+	case "", LogJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case LogText:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return nil, fmt.Errorf("unknown log format %q, use json or text", cfg.LogFormat)
+	}
+
+	level := logrus.InfoLevel
+	if cfg.LogLevel != "" {
+		var err error
+		if level, err = logrus.ParseLevel(cfg.LogLevel); err != nil {
+			return nil, err
+		}
+	}
+	logger.SetLevel(level)
+
+	logger.AddHook(requestIDHook{})
+	logger.AddHook(redactHook{})
+	return logger, nil
+}
+
+// WithRequestID is ctx carrying the id of the request it serves
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// NewRequestID is the id of a request that came without a usable one
+func NewRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+// ValidRequestID tells whether a client given request id can be kept
+func ValidRequestID(id string) bool {
+	return requestIDPattern.MatchString(id)
+}
+
+// RequestID is the id of the request ctx serves, empty outside of one
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Redact hides the secrets, tokens, phone numbers and emails in text. Emails
+// keep their first letter and domain, so entries can still be told apart.
+func Redact(text string) string {
+	text = jwtPattern.ReplaceAllString(text, Redacted)
+	text = bearerPattern.ReplaceAllString(text, "Bearer "+Redacted)
+	text = secretPattern.ReplaceAllString(text, "$1$2"+Redacted)
+	text = logEmail.ReplaceAllString(text, "$1***@$2")
+	return logPhone.ReplaceAllString(text, Redacted)
+}
+
+// requestIDHook adds the id of the request to entries logged with its context
+type requestIDHook struct{}
+
+func (requestIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (requestIDHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if id := RequestID(entry.Context); id != "" {
+		entry.Data["request_id"] = id
+	}
+	return nil
+}
+
+// redactHook redacts the message and fields of entries. Each entry has its
+// own copy of the fields, so changing them leaves the logger's alone.
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for key, value := range entry.Data {
+		if sensitiveKey(key) {
+			entry.Data[key] = Redacted
+			continue
+		}
+		switch value := value.(type) {
+		case string:
+			entry.Data[key] = Redact(value)
+		case error:
+			entry.Data[key] = Redact(value.Error())
+		case fmt.Stringer:
+			entry.Data[key] = Redact(value.String())
+		}
+	}
+	return nil
+}
+
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/mail/*.tmpl
+var mailTemplates embed.FS
+
+const fallbackLocale = "en"
+
+var ErrUnknownMailTemplate = errors.New("unknown mail template")
+
+// RenderedMail is a mail template executed for one locale
+type RenderedMail struct {
+	Locale  string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// RenderMail executes the subject, text and html blocks of the named template
+// in the closest locale available: "hi-IN" tries hi-IN, then hi, then en.
+func RenderMail(name string, locale string, data interface{}) (RenderedMail, error) {
+	for _, candidate := range localeChain(locale) {
+		source, err := mailTemplates.ReadFile("templates/mail/" + name + "." + candidate + ".tmpl")
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return RenderedMail{}, err
+		}
+		return renderMail(name, candidate, string(source), data)
+	}
+	return RenderedMail{}, fmt.Errorf("%w %s", ErrUnknownMailTemplate, name)
+}
+
+func renderMail(name string, locale string, source string, data interface{}) (RenderedMail, error) {
+	mail := RenderedMail{Locale: locale}
+
+	text, err := texttemplate.New(name).Parse(source)
+	if err != nil {
+		return mail, err
+	}
+	html, err := htmltemplate.New(name).Parse(source)
+	if err != nil {
+		return mail, err
+	}
+
+	var buf bytes.Buffer
+	if err = text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return mail, err
+	}
+	mail.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err = text.ExecuteTemplate(&buf, "text", data); err != nil {
+		return mail, err
+	}
+	mail.Text = buf.String()
+
+	buf.Reset()
+	if err = html.ExecuteTemplate(&buf, "html", data); err != nil {
+		return mail, err
+	}
+	mail.HTML = buf.String()
+
+	return mail, nil
+}
+
+func localeChain(locale string) []string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	var chain []string
+	if locale != "" {
+		chain = append(chain, locale)
+		if base, _, found := strings.Cut(locale, "-"); found {
+			chain = append(chain, strings.ToLower(base))
+		}
+	}
+	return append(chain, fallbackLocale)
+}
+
+// BuildMail encodes a rendered mail as a multipart/alternative message ready
+// to be handed to an SMTP server
+func BuildMail(from string, to string, subject string, text string, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err = qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err = qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMail(t *testing.T) {
+	data := struct {
+		Title string
+		Body  string
+	}{Title: "Booking accepted", Body: "Your request <b>#4</b> was accepted."}
+
+	tests := []struct {
+		name           string
+		template       string
+		locale         string
+		expectedLocale string
+		expectedErr    error
+	}{
+		{name: "test exact locale", template: "notification", locale: "hi", expectedLocale: "hi"},
+		{name: "test region falls back to language", template: "notification", locale: "hi-IN", expectedLocale: "hi"},
+		{name: "test unknown locale falls back to english", template: "notification", locale: "fr", expectedLocale: "en"},
+		{name: "test empty locale", template: "notification", locale: "", expectedLocale: "en"},
+		{name: "test unknown template", template: "missing", locale: "en", expectedErr: ErrUnknownMailTemplate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mail, err := RenderMail(tt.template, tt.locale, data)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLocale, mail.Locale)
+			assert.Equal(t, "Booking accepted", mail.Subject)
+			assert.Contains(t, mail.Text, "Your request <b>#4</b> was accepted.")
+			assert.Contains(t, mail.HTML, "Your request &lt;b&gt;#4&lt;/b&gt; was accepted.")
+		})
+	}
+}
+
+func TestBuildMail(t *testing.T) {
+	message, err := BuildMail("noreply@workey.in", "user@example.com", "बुकिंग", "plain body", "<p>html body</p>")
+	assert.NoError(t, err)
+
+	raw := string(message)
+	assert.Contains(t, raw, "To: user@example.com\r\n")
+	assert.Contains(t, raw, "Subject: =?utf-8?q?")
+	assert.Contains(t, raw, "Content-Type: multipart/alternative; boundary=")
+	assert.Contains(t, raw, "plain body")
+	assert.Contains(t, raw, "<p>html body</p>")
+	assert.True(t, strings.Index(raw, "text/plain") < strings.Index(raw, "text/html"))
+}
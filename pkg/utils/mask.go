@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+\s*(@|\(at\)|\[at\])\s*[A-Za-z0-9.\-]+\s*(\.|\(dot\)|\[dot\])\s*[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-().]{5,}\d`)
+)
+
+// MaskContactDetails hides email addresses and phone numbers in free text so
+// users and workers cannot move a booking off the platform
+func MaskContactDetails(text string) string {
+	text = emailPattern.ReplaceAllString(text, "[email hidden]")
+	return phonePattern.ReplaceAllStringFunc(text, func(match string) string {
+		digits := 0
+		for _, r := range match {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		// short numbers like prices, dates and house numbers are left alone
+		if digits < 7 {
+			return match
+		}
+		return strings.Repeat("*", digits)
+	})
+}
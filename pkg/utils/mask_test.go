@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskContactDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "test plain text", text: "I can come at 10 tomorrow", expected: "I can come at 10 tomorrow"},
+		{name: "test price is kept", text: "can you do it for 1500?", expected: "can you do it for 1500?"},
+		{name: "test phone number", text: "call me on +91 98765 43210", expected: "call me on ************"},
+		{name: "test dashed phone number", text: "9876-543-210 is my number", expected: "********** is my number"},
+		{name: "test email", text: "mail test.user@example.com", expected: "mail [email hidden]"},
+		{name: "test spelled out email", text: "mail test (at) example (dot) com", expected: "mail [email hidden]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MaskContactDetails(tt.text))
+		})
+	}
+}
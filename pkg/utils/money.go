@@ -0,0 +1,13 @@
+package utils
+
+import "fmt"
+
+// FormatMinor writes an amount held in minor units, paise or cents, as a
+// decimal with two places, e.g. 123456 as 1234.56 and -5 as -0.05
+func FormatMinor(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMinor(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		expected string
+	}{
+		{amount: 0, expected: "0.00"},
+		{amount: 5, expected: "0.05"},
+		{amount: 123456, expected: "1234.56"},
+		{amount: -5, expected: "-0.05"},
+		{amount: -250000, expected: "-2500.00"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, FormatMinor(tt.amount))
+	}
+}
@@ -1,18 +1,41 @@
 package utils
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"strconv"
 
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	PageModeOffset = "offset"
+	PageModeCursor = "cursor"
+
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// Filter carries the paging request of a list endpoint. In offset mode Page
+// and PageSize are used, in cursor mode After holds the decoded keyset
+// position of the last row the client has already seen.
 type Filter struct {
 	Page     int
 	PageSize int
+	Mode     string
+	After    *Cursor
 }
 
 type Metadata struct {
-	CurrentPage  int
-	PageSize     int
-	FirstPage    int
-	LastPage     int
-	TotalRecords int
+	CurrentPage  int     `json:"current_page,omitempty"`
+	PageSize     int     `json:"page_size"`
+	FirstPage    int     `json:"first_page,omitempty"`
+	LastPage     int     `json:"last_page,omitempty"`
+	TotalRecords int     `json:"total_records,omitempty"`
+	HasMore      bool    `json:"has_more"`
+	NextCursor   string  `json:"next_cursor,omitempty"`
+	NextKey      *Cursor `json:"-"`
 }
 
 func (f Filter) Limit() int {
@@ -23,6 +46,22 @@ func (f Filter) Offset() int {
 	return (f.Page - 1) * f.PageSize
 }
 
+// IsCursor reports whether the list should be paged by keyset instead of offset
+func (f Filter) IsCursor() bool {
+	return f.Mode == PageModeCursor
+}
+
+// KeysetCondition returns the where clause fragment selecting rows older than
+// the cursor for lists ordered by createdAt DESC, id DESC. argIndex is the
+// position of the first placeholder the fragment may use.
+func (f Filter) KeysetCondition(createdAtColumn, idColumn string, argIndex int) (string, []interface{}) {
+	if !f.IsCursor() || f.After == nil {
+		return "", nil
+	}
+	clause := fmt.Sprintf(" AND (%s, %s) < ($%d, $%d)", createdAtColumn, idColumn, argIndex, argIndex+1)
+	return clause, []interface{}{f.After.CreatedAt, f.After.Id}
+}
+
 func ComputeMetaData(totalRecords, currentPage, pageSize int) Metadata {
 
 	if totalRecords == 0 {
@@ -35,6 +74,82 @@ func ComputeMetaData(totalRecords, currentPage, pageSize int) Metadata {
 		FirstPage:    1,
 		LastPage:     int(math.Ceil(float64(totalRecords) / float64(pageSize))),
 		TotalRecords: totalRecords,
+		HasMore:      currentPage*pageSize < totalRecords,
+	}
+
+}
+
+// ComputeCursorMetaData builds the metadata of a keyset page. Repositories
+// fetch one row more than the page size, fetched is the number of rows the
+// query returned and last is the key of the last row kept on the page.
+func ComputeCursorMetaData(fetched, pageSize int, last Cursor) Metadata {
+	meta := Metadata{
+		PageSize: pageSize,
+		HasMore:  fetched > pageSize,
+	}
+	if meta.HasMore {
+		meta.NextKey = &last
+	}
+	return meta
+}
+
+// ParsePageQuery reads page, page_size, mode and cursor from the query string
+// and validates them. A cursor implies cursor mode and cannot be combined
+// with page.
+func ParsePageQuery(ctx *gin.Context, codec CursorCodec) (Filter, error) {
+	filter := Filter{
+		Page:     1,
+		PageSize: DefaultPageSize,
+		Mode:     PageModeOffset,
+	}
+
+	if size := ctx.Query("page_size"); size != "" {
+		pageSize, err := strconv.Atoi(size)
+		if err != nil || pageSize < 1 || pageSize > MaxPageSize {
+			return filter, InvalidField(err, "page_size", domain.FieldError{
+				Code:    "range",
+				Param:   fmt.Sprintf("1,%d", MaxPageSize),
+				Message: fmt.Sprintf("has to be a number between 1 and %d", MaxPageSize),
+			})
+		}
+		filter.PageSize = pageSize
+	}
+
+	mode := ctx.DefaultQuery("mode", PageModeOffset)
+	token := ctx.Query("cursor")
+	if token != "" {
+		mode = PageModeCursor
+	}
+
+	switch mode {
+	case PageModeOffset:
+		if page := ctx.Query("page"); page != "" {
+			pageNumber, err := strconv.Atoi(page)
+			if err != nil || pageNumber < 1 {
+				return filter, InvalidField(err, "page", domain.FieldError{Code: "min", Param: "1", Message: "has to be a positive number"})
+			}
+			filter.Page = pageNumber
+		}
+	case PageModeCursor:
+		if ctx.Query("page") != "" {
+			return filter, InvalidField(nil, "page", domain.FieldError{Code: "excluded_with", Param: "cursor", Message: "cannot be combined with cursor pagination"})
+		}
+		filter.Mode = PageModeCursor
+		filter.Page = 0
+		if token != "" {
+			cursor, err := codec.Decode(token)
+			if err != nil {
+				return filter, InvalidField(err, "cursor", domain.FieldError{Code: "cursor", Message: "is not a cursor of this list"})
+			}
+			filter.After = &cursor
+		}
+	default:
+		return filter, InvalidField(nil, "mode", domain.FieldError{
+			Code:    "oneof",
+			Param:   PageModeOffset + " " + PageModeCursor,
+			Message: "has to be one of " + PageModeOffset + ", " + PageModeCursor,
+		})
 	}
 
+	return filter, nil
 }
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/config"
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec, err := NewCursorCodec(config.Config{CursorSecret: "testsecret"})
+	assert.NoError(t, err)
+	cursor := Cursor{Id: 42, CreatedAt: time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)}
+
+	token, err := codec.Encode(cursor)
+	assert.NoError(t, err)
+
+	decoded, err := codec.Decode(token)
+	assert.NoError(t, err)
+	assert.Equal(t, cursor.Id, decoded.Id)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "test tampered signature", token: token + "x"},
+		{name: "test missing signature", token: "eyJpZCI6MX0"},
+		{name: "test signed with another secret", token: func() string {
+			otherCodec, _ := NewCursorCodec(config.Config{CursorSecret: "other"})
+			other, _ := otherCodec.Encode(cursor)
+			return other
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := codec.Decode(tt.token)
+			assert.Equal(t, ErrInvalidCursor, err)
+		})
+	}
+}
+
+func TestCursorCodecNeedsSecret(t *testing.T) {
+	codec, err := NewCursorCodec(config.Config{})
+	assert.Error(t, err)
+	assert.Nil(t, codec)
+}
+
+func TestParsePageQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	codec, _ := NewCursorCodec(config.Config{CursorSecret: "testsecret"})
+	token, _ := codec.Encode(Cursor{Id: 7, CreatedAt: time.Now()})
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedMode string
+		expectedPage int
+		expectedSize int
+		expectAfter  bool
+		invalidField string
+	}{
+		{name: "test defaults", query: "", expectedMode: PageModeOffset, expectedPage: 1, expectedSize: DefaultPageSize},
+		{name: "test offset page", query: "?page=3&page_size=20", expectedMode: PageModeOffset, expectedPage: 3, expectedSize: 20},
+		{name: "test first cursor page", query: "?mode=cursor", expectedMode: PageModeCursor, expectedSize: DefaultPageSize},
+		{name: "test cursor implies cursor mode", query: "?cursor=" + token, expectedMode: PageModeCursor, expectedSize: DefaultPageSize, expectAfter: true},
+		{name: "test page size too large", query: "?page_size=1000", invalidField: "page_size"},
+		{name: "test negative page", query: "?page=-1", invalidField: "page"},
+		{name: "test page with cursor", query: "?page=2&cursor=" + token, invalidField: "page"},
+		{name: "test unknown mode", query: "?mode=random", invalidField: "mode"},
+		{name: "test forged cursor", query: "?cursor=abc.def", invalidField: "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest("GET", "/list"+tt.query, nil)
+
+			filter, err := ParsePageQuery(ctx, codec)
+			if tt.invalidField != "" {
+				assert.ErrorIs(t, err, domain.ErrInvalidRequest)
+				assert.Contains(t, ErrorFields(err), tt.invalidField)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMode, filter.Mode)
+			assert.Equal(t, tt.expectedPage, filter.Page)
+			assert.Equal(t, tt.expectedSize, filter.PageSize)
+			assert.Equal(t, tt.expectAfter, filter.After != nil)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page size of an A4 sheet in PDF points
+const (
+	PDFPageWidth  = 595.0
+	PDFPageHeight = 842.0
+)
+
+// Fonts a PDF can write with. They are the standard fonts every reader has,
+// so nothing needs to be embedded.
+const (
+	FontRegular = "F1"
+	FontBold    = "F2"
+	// FontMono has fixed width glyphs, which lets TextRight line up amounts
+	FontMono = "F3"
+)
+
+var pdfFonts = []string{"Helvetica", "Helvetica-Bold", "Courier"}
+
+// PDF lays out a single page of text and rules. Coordinates are in points
+// from the bottom left corner of the page.
+type PDF struct {
+	content bytes.Buffer
+}
+
+func NewPDF() *PDF {
+	return &PDF{}
+}
+
+// Text writes text with its baseline starting at x, y
+func (p *PDF) Text(x, y float64, font string, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// TextRight writes text in FontMono so that it ends at x
+func (p *PDF) TextRight(x, y float64, size float64, text string) {
+	width := float64(len([]rune(text))) * size * 0.6
+	p.Text(x-width, y, FontMono, size, text)
+}
+
+// Rule draws a thin line between two points
+func (p *PDF) Rule(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// Bytes renders the document
+func (p *PDF) Bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Contents 4 0 R /Resources << /Font << /F1 5 0 R /F2 6 0 R /F3 7 0 R >> >> >>", PDFPageWidth, PDFPageHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+	for _, font := range pdfFonts {
+		objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /"+font+" /Encoding /WinAnsiEncoding >>")
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfString escapes text for a PDF string literal. The standard fonts only
+// cover Latin-1, anything outside it is written as a question mark.
+func pdfString(text string) string {
+	var out strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r < 32:
+			out.WriteByte(' ')
+		case r > 255:
+			out.WriteByte('?')
+		default:
+			out.WriteByte(byte(r))
+		}
+	}
+	return out.String()
+}
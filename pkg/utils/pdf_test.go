@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPDF(t *testing.T) {
+	pdf := NewPDF()
+	pdf.Text(40, 800, FontBold, 18, "Invoice (INV-2026-000001)")
+	pdf.TextRight(555, 780, 10, "1234.56")
+	pdf.Rule(40, 770, 555, 770)
+	document := pdf.Bytes()
+
+	assert.True(t, bytes.HasPrefix(document, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(document, []byte("%%EOF\n")))
+	assert.Contains(t, string(document), `(Invoice \(INV-2026-000001\)) Tj`)
+
+	// Every xref offset has to point at the object it names
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(document)
+	if assert.NotNil(t, startxref) {
+		xref, _ := strconv.Atoi(string(startxref[1]))
+		assert.True(t, bytes.HasPrefix(document[xref:], []byte("xref\n")))
+
+		offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(document[xref:], -1)
+		assert.Len(t, offsets, 7)
+		for i, offset := range offsets {
+			at, _ := strconv.Atoi(string(offset[1]))
+			assert.True(t, bytes.HasPrefix(document[at:], []byte(strconv.Itoa(i+1)+" 0 obj\n")))
+		}
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	assert.Equal(t, `a\\b \(c\) d`, pdfString("a\\b (c)\td"))
+	assert.Equal(t, "caf\xe9 ?", pdfString("café ₹"))
+}
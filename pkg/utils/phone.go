@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+)
+
+// phoneRegion is what it takes to read the numbers of a region, after the
+// metadata of libphonenumber. The patterns match national numbers, the number
+// without the calling code and trunk prefix, and a region whose mobile and
+// fixed line numbers look alike has no fixed line pattern.
+type phoneRegion struct {
+	Region      string
+	CallingCode string
+	TrunkPrefix string
+	Mobile      *regexp.Regexp
+	FixedLine   *regexp.Regexp
+}
+
+// phoneRegions are the regions phone numbers are read for. +1 is read as the
+// US, which shares its numbering plan with the rest of North America.
+var phoneRegions = []phoneRegion{
+	{Region: "IN", CallingCode: "91", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^[6-9][0-9]{9}$`), FixedLine: regexp.MustCompile(`^[1-5][0-9]{9}$`)},
+	{Region: "US", CallingCode: "1", TrunkPrefix: "1", Mobile: regexp.MustCompile(`^[2-9][0-9]{2}[2-9][0-9]{6}$`)},
+	{Region: "GB", CallingCode: "44", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^7[1-57-9][0-9]{8}$`), FixedLine: regexp.MustCompile(`^[12][0-9]{8,9}$`)},
+	{Region: "AE", CallingCode: "971", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^5[024-8][0-9]{7}$`), FixedLine: regexp.MustCompile(`^[2-4679][0-9]{7}$`)},
+	{Region: "SA", CallingCode: "966", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^5[0-9]{8}$`), FixedLine: regexp.MustCompile(`^1[0-9]{7,8}$`)},
+	{Region: "QA", CallingCode: "974", Mobile: regexp.MustCompile(`^[3567][0-9]{7}$`), FixedLine: regexp.MustCompile(`^4[0-9]{7}$`)},
+	{Region: "KW", CallingCode: "965", Mobile: regexp.MustCompile(`^[569][0-9]{7}$`), FixedLine: regexp.MustCompile(`^2[0-9]{7}$`)},
+	{Region: "OM", CallingCode: "968", Mobile: regexp.MustCompile(`^[79][0-9]{7}$`), FixedLine: regexp.MustCompile(`^2[0-9]{7}$`)},
+	{Region: "BH", CallingCode: "973", Mobile: regexp.MustCompile(`^3[0-9]{7}$`), FixedLine: regexp.MustCompile(`^1[0-9]{7}$`)},
+	{Region: "SG", CallingCode: "65", Mobile: regexp.MustCompile(`^[89][0-9]{7}$`), FixedLine: regexp.MustCompile(`^6[0-9]{7}$`)},
+	{Region: "AU", CallingCode: "61", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^4[0-9]{8}$`), FixedLine: regexp.MustCompile(`^[2378][0-9]{8}$`)},
+	{Region: "PK", CallingCode: "92", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^3[0-9]{9}$`), FixedLine: regexp.MustCompile(`^[2-9][0-9]{7,9}$`)},
+	{Region: "BD", CallingCode: "880", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^1[3-9][0-9]{8}$`), FixedLine: regexp.MustCompile(`^[2-9][0-9]{5,9}$`)},
+	{Region: "LK", CallingCode: "94", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^7[0-9]{8}$`), FixedLine: regexp.MustCompile(`^[1-68][0-9]{8}$`)},
+	{Region: "NP", CallingCode: "977", TrunkPrefix: "0", Mobile: regexp.MustCompile(`^9[678][0-9]{8}$`), FixedLine: regexp.MustCompile(`^[1-8][0-9]{6,7}$`)},
+}
+
+// phoneFormatting is what people write phone numbers with besides digits
+var phoneFormatting = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", " ", "")
+
+var phoneDigits = regexp.MustCompile(`^[0-9]+$`)
+
+// ParsePhone reads a phone number written the way people do, with spaces,
+// dashes and brackets, into E.164 format. A number starting with + or 00
+// carries its calling code, any other is read as a number of defaultRegion.
+// The calling code written again in front of a national number, as in
+// +91 919876543210, is dropped.
+func ParsePhone(number string, defaultRegion string) (domain.PhoneNumber, error) {
+	number = phoneFormatting.Replace(strings.TrimSpace(number))
+
+	var region phoneRegion
+	var national string
+	switch {
+	case strings.HasPrefix(number, "+"), strings.HasPrefix(number, "00"):
+		digits := strings.TrimPrefix(strings.TrimPrefix(number, "+"), "00")
+		if !phoneDigits.MatchString(digits) {
+			return domain.PhoneNumber{}, domain.ErrInvalidPhone
+		}
+		var ok bool
+		if region, ok = callingCodeRegion(digits); !ok {
+			return domain.PhoneNumber{}, domain.ErrPhoneRegion
+		}
+		national = digits[len(region.CallingCode):]
+	default:
+		if !phoneDigits.MatchString(number) {
+			return domain.PhoneNumber{}, domain.ErrInvalidPhone
+		}
+		var ok bool
+		if region, ok = phoneRegionOf(defaultRegion); !ok {
+			return domain.PhoneNumber{}, domain.ErrPhoneRegion
+		}
+		national = number
+	}
+
+	phoneType, national, ok := region.read(national)
+	if !ok {
+		return domain.PhoneNumber{}, domain.ErrInvalidPhone
+	}
+	return domain.PhoneNumber{
+		E164:   "+" + region.CallingCode + national,
+		Region: region.Region,
+		Type:   phoneType,
+	}, nil
+}
+
+// read tells the kind of a national number, trying it without the trunk
+// prefix and the calling code when it does not match as it is
+func (r phoneRegion) read(national string) (string, string, bool) {
+	candidates := []string{national}
+	if r.TrunkPrefix != "" && strings.HasPrefix(national, r.TrunkPrefix) {
+		candidates = append(candidates, national[len(r.TrunkPrefix):])
+	}
+	if strings.HasPrefix(national, r.CallingCode) {
+		candidates = append(candidates, national[len(r.CallingCode):])
+	}
+
+	for _, candidate := range candidates {
+		mobile := r.Mobile.MatchString(candidate)
+		switch {
+		case mobile && r.FixedLine == nil:
+			return domain.PhoneFixedLineOrMobile, candidate, true
+		case mobile:
+			return domain.PhoneMobile, candidate, true
+		case r.FixedLine != nil && r.FixedLine.MatchString(candidate):
+			return domain.PhoneFixedLine, candidate, true
+		}
+	}
+	return "", "", false
+}
+
+// CanReceiveSMS tells whether a number may be a mobile one, and so can be
+// sent codes to
+func CanReceiveSMS(phone domain.PhoneNumber) bool {
+	return phone.Type == domain.PhoneMobile || phone.Type == domain.PhoneFixedLineOrMobile
+}
+
+// callingCodeRegion is the region of the calling code digits start with.
+// Calling codes are prefix free, so there is at most one.
+func callingCodeRegion(digits string) (phoneRegion, bool) {
+	for _, region := range phoneRegions {
+		if strings.HasPrefix(digits, region.CallingCode) {
+			return region, true
+		}
+	}
+	return phoneRegion{}, false
+}
+
+func phoneRegionOf(code string) (phoneRegion, bool) {
+	for _, region := range phoneRegions {
+		if region.Region == strings.ToUpper(code) {
+			return region, true
+		}
+	}
+	return phoneRegion{}, false
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePhone(t *testing.T) {
+	indianMobile := domain.PhoneNumber{E164: "+919876543210", Region: "IN", Type: domain.PhoneMobile}
+
+	tests := []struct {
+		name          string
+		number        string
+		region        string
+		expectedPhone domain.PhoneNumber
+		expectedErr   error
+	}{
+		{name: "test a number in E.164 format", number: "+919876543210", region: "IN", expectedPhone: indianMobile},
+		{name: "test a number written with spaces", number: "+91 98765 43210", region: "IN", expectedPhone: indianMobile},
+		{name: "test a number written with dashes and brackets", number: "(+91) 98765-43210", region: "US", expectedPhone: indianMobile},
+		{name: "test a number with the calling code but no plus", number: "919876543210", region: "IN", expectedPhone: indianMobile},
+		{name: "test a number with the international prefix", number: "00919876543210", region: "GB", expectedPhone: indianMobile},
+		{name: "test a national number", number: "9876543210", region: "IN", expectedPhone: indianMobile},
+		{name: "test a national number with the trunk prefix", number: "09876543210", region: "IN", expectedPhone: indianMobile},
+		{name: "test a calling code given twice", number: "+91919876543210", region: "IN", expectedPhone: indianMobile},
+		{name: "test a region in lower case", number: "9876543210", region: "in", expectedPhone: indianMobile},
+		{name: "test a fixed line", number: "+91 44 2345 6789", region: "IN", expectedPhone: domain.PhoneNumber{E164: "+914423456789", Region: "IN", Type: domain.PhoneFixedLine}},
+		{name: "test a region that numbers lines alike", number: "(415) 555-2671", region: "US", expectedPhone: domain.PhoneNumber{E164: "+14155552671", Region: "US", Type: domain.PhoneFixedLineOrMobile}},
+		{name: "test a mobile of another region", number: "+971 50 123 4567", region: "IN", expectedPhone: domain.PhoneNumber{E164: "+971501234567", Region: "AE", Type: domain.PhoneMobile}},
+		{name: "test a number too short", number: "98765", region: "IN", expectedErr: domain.ErrInvalidPhone},
+		{name: "test a number too long", number: "+9198765432101", region: "IN", expectedErr: domain.ErrInvalidPhone},
+		{name: "test a number with letters", number: "98765abcde", region: "IN", expectedErr: domain.ErrInvalidPhone},
+		{name: "test a placeholder number", number: "testabcde", region: "IN", expectedErr: domain.ErrInvalidPhone},
+		{name: "test an empty number", number: "", region: "IN", expectedErr: domain.ErrInvalidPhone},
+		{name: "test a calling code not supported", number: "+33 6 12 34 56 78", region: "IN", expectedErr: domain.ErrPhoneRegion},
+		{name: "test a default region not supported", number: "0612345678", region: "FR", expectedErr: domain.ErrPhoneRegion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phone, err := ParsePhone(tt.number, tt.region)
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedPhone, phone)
+		})
+	}
+}
+
+func TestCanReceiveSMS(t *testing.T) {
+	assert.True(t, CanReceiveSMS(domain.PhoneNumber{Type: domain.PhoneMobile}))
+	assert.True(t, CanReceiveSMS(domain.PhoneNumber{Type: domain.PhoneFixedLineOrMobile}))
+	assert.False(t, CanReceiveSMS(domain.PhoneNumber{Type: domain.PhoneFixedLine}))
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRandommail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected bool
+	}{
+		{name: "test a placeholder address", email: Randommail(5), expected: true},
+		{name: "test an address a user gave", email: "someone@example.com", expected: false},
+		{name: "test a real address on the same domain", email: "support@test.com", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRandommail(tt.email))
+		})
+	}
+}
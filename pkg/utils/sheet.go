@@ -0,0 +1,411 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spreadsheet formats tables are exported in and imported from
+const (
+	SheetCSV  = "csv"
+	SheetXLSX = "xlsx"
+)
+
+// maxSheetPart caps what a part of an XLSX file may unpack to, so a small
+// upload cannot expand into gigabytes
+const maxSheetPart = 64 << 20
+
+// SheetWriter writes a table a row at a time, so a large table never has to
+// be held in memory. Numbers are written as numbers, times as RFC 3339 and
+// anything else as text.
+type SheetWriter interface {
+	WriteRow(cells ...interface{}) error
+	Close() error
+}
+
+// NewSheetWriter starts a table in format on w. name names the sheet of an
+// XLSX file.
+func NewSheetWriter(w io.Writer, format string, name string) (SheetWriter, error) {
+	switch format {
+	case SheetCSV:
+		return &csvSheet{writer: csv.NewWriter(w)}, nil
+	case SheetXLSX:
+		return newXLSXSheet(w, name)
+	}
+	return nil, fmt.Errorf("unknown format %q, use csv or xlsx", format)
+}
+
+// SheetContentType is the media type of a table in format
+func SheetContentType(format string) string {
+	if format == SheetXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+type csvSheet struct {
+	writer *csv.Writer
+}
+
+func (s *csvSheet) WriteRow(cells ...interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		text, _ := sheetCell(cell)
+		record[i] = csvText(text)
+	}
+	return s.writer.Write(record)
+}
+
+func (s *csvSheet) Close() error {
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// csvFormula matches text a spreadsheet would take for a formula, which a
+// leading quote keeps as text. Signed numbers and phone numbers are left be.
+var csvFormula = regexp.MustCompile(`^[=+\-@\t\r]`)
+var csvNumber = regexp.MustCompile(`^[+-]?[0-9][0-9 .]*$`)
+
+func csvText(text string) string {
+	if csvFormula.MatchString(text) && !csvNumber.MatchString(text) {
+		return "'" + text
+	}
+	return text
+}
+
+type xlsxSheet struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	row   int
+}
+
+const xlsxMain = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+const xlsxRelations = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + xlsxRelations + `/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + xlsxRelations + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+func newXLSXSheet(w io.Writer, name string) (*xlsxSheet, error) {
+	s := &xlsxSheet{zip: zip.NewWriter(w)}
+
+	var workbook bytes.Buffer
+	workbook.WriteString(`<workbook xmlns="` + xlsxMain + `" xmlns:r="` + xlsxRelations + `"><sheets><sheet name="`)
+	xml.EscapeText(&workbook, []byte(sheetName(name)))
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	for _, part := range xlsxParts {
+		if err := s.writePart(part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.writePart("xl/workbook.xml", workbook.String()); err != nil {
+		return nil, err
+	}
+
+	sheet, err := s.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	s.sheet = sheet
+	_, err = io.WriteString(s.sheet, xml.Header+`<worksheet xmlns="`+xlsxMain+`"><sheetData>`)
+	return s, err
+}
+
+func (s *xlsxSheet) writePart(name string, content string) error {
+	part, err := s.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, xml.Header+content)
+	return err
+}
+
+func (s *xlsxSheet) WriteRow(cells ...interface{}) error {
+	s.row++
+	var row bytes.Buffer
+	fmt.Fprintf(&row, `<row r="%d">`, s.row)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(s.row)
+		text, number := sheetCell(cell)
+		if number {
+			fmt.Fprintf(&row, `<c r="%s"><v>%s</v></c>`, ref, text)
+			continue
+		}
+		fmt.Fprintf(&row, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		xml.EscapeText(&row, []byte(text))
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString(`</row>`)
+	_, err := s.sheet.Write(row.Bytes())
+	return err
+}
+
+func (s *xlsxSheet) Close() error {
+	if _, err := io.WriteString(s.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return s.zip.Close()
+}
+
+// sheetCell is the text of a cell and whether it is a number
+func sheetCell(cell interface{}) (string, bool) {
+	switch value := cell.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, false
+	case int:
+		return strconv.Itoa(value), true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), false
+	case time.Time:
+		if value.IsZero() {
+			return "", false
+		}
+		return value.UTC().Format(time.RFC3339), false
+	case *time.Time:
+		if value == nil {
+			return "", false
+		}
+		return sheetCell(*value)
+	}
+	return fmt.Sprint(cell), false
+}
+
+// columnName is the letters of the column at index, A to Z then AA on
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName makes name fit as an XLSX sheet name
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		return string(runes[:31])
+	}
+	return name
+}
+
+// ReadSheet reads the rows of a table in format. Of an XLSX file only the
+// first sheet is read, and every cell comes back as the text it holds.
+func ReadSheet(data []byte, format string) ([][]string, error) {
+	switch format {
+	case SheetCSV:
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		return reader.ReadAll()
+	case SheetXLSX:
+		return readXLSX(data)
+	}
+	return nil, fmt.Errorf("unknown format %q, use csv or xlsx", format)
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	text := t.Text
+	for _, run := range t.Runs {
+		text += run.Text
+	}
+	return text
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("the file is not an xlsx workbook")
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			RelationId string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err = readXLSXPart(archive, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, errors.New("the workbook has no sheets")
+	}
+	var relationships xlsxRelationships
+	if err = readXLSXPart(archive, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return nil, err
+	}
+
+	sheetPath, sharedPath := "", ""
+	for _, relationship := range relationships.Relationships {
+		target := relationship.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		if relationship.Id == workbook.Sheets[0].RelationId {
+			sheetPath = target
+		}
+		if strings.HasSuffix(relationship.Type, "/sharedStrings") {
+			sharedPath = target
+		}
+	}
+	if sheetPath == "" {
+		return nil, errors.New("the first sheet of the workbook is missing")
+	}
+
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if sharedPath != "" {
+		if err = readXLSXPart(archive, sharedPath, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Index int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err = readXLSXPart(archive, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		index := len(rows)
+		if row.Index > 0 {
+			index = row.Index - 1
+		}
+		for len(rows) <= index {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for _, cell := range row.Cells {
+			column := columnIndex(cell.Ref)
+			if column < 0 {
+				column = len(cells)
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				item, err := strconv.Atoi(cell.Value)
+				if err != nil || item < 0 || item >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s refers to a missing string", cell.Ref)
+				}
+				cells[column] = shared.Items[item].String()
+			case "inlineStr":
+				cells[column] = cell.Inline.String()
+			case "", "n":
+				cells[column] = xlsxNumber(cell.Value)
+			default:
+				cells[column] = cell.Value
+			}
+		}
+		rows[index] = cells
+	}
+	return rows, nil
+}
+
+func readXLSXPart(archive *zip.Reader, name string, v interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		if file.UncompressedSize64 > maxSheetPart {
+			return errors.New("the workbook is too large")
+		}
+		part, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer part.Close()
+		return xml.NewDecoder(io.LimitReader(part, maxSheetPart)).Decode(v)
+	}
+	return fmt.Errorf("the workbook has no %s", name)
+}
+
+// xlsxNumber writes a stored number out in full, as spreadsheets keep large
+// ones such as phone numbers in exponent form
+func xlsxNumber(value string) string {
+	if !strings.ContainsAny(value, "Ee") {
+		return value
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// columnIndex is the index of the column a cell reference such as AB12 is in
+func columnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+	}
+	return index - 1
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSheetRoundTrip(t *testing.T) {
+	made := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{"id", "name", "phone", "amount", "created_at"},
+		{7, "Anu <& Co>", "+919876543210", int64(125000), made},
+		{8, "=HYPERLINK(\"x\")", "", 0.5, nil},
+	}
+
+	for _, format := range []string{SheetCSV, SheetXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var file bytes.Buffer
+			sheet, err := NewSheetWriter(&file, format, "users")
+			assert.NoError(t, err)
+			for _, row := range rows {
+				assert.NoError(t, sheet.WriteRow(row...))
+			}
+			assert.NoError(t, sheet.Close())
+
+			read, err := ReadSheet(file.Bytes(), format)
+			assert.NoError(t, err)
+			formula := "=HYPERLINK(\"x\")"
+			if format == SheetCSV {
+				formula = "'" + formula
+			}
+			assert.Equal(t, [][]string{
+				{"id", "name", "phone", "amount", "created_at"},
+				{"7", "Anu <& Co>", "+919876543210", "125000", "2026-10-01T09:30:00Z"},
+				{"8", formula, "", "0.5", ""},
+			}, read)
+		})
+	}
+}
+
+func TestReadSheetFormats(t *testing.T) {
+	rows, err := ReadSheet([]byte("\xef\xbb\xbfcategory,categoryicon\nPlumbing,plumbing.png\n"), SheetCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"category", "categoryicon"}, {"Plumbing", "plumbing.png"}}, rows)
+
+	_, err = ReadSheet([]byte("not a zip"), SheetXLSX)
+	assert.EqualError(t, err, "the file is not an xlsx workbook")
+
+	_, err = ReadSheet(nil, "ods")
+	assert.Error(t, err)
+}
+
+func TestSheetHelpers(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, 27, columnIndex("AB12"))
+	assert.Equal(t, -1, columnIndex(""))
+	assert.Equal(t, "919876543210", xlsxNumber("9.1987654321E11"))
+	assert.Equal(t, "requests_2026_", sheetName("requests/2026?"))
+}
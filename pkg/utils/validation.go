@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// phoneSyntax is what a phone number can look like once spaces, dashes
+	// and brackets are dropped. ParsePhone tells whether it is one.
+	phoneSyntax        = regexp.MustCompile(`^(\+|00)?[0-9]{4,17}$`)
+	callingCodePattern = regexp.MustCompile(`^\+[1-9][0-9]{0,2}$`)
+	otpPattern         = regexp.MustCompile(`^[0-9]{4,10}$`)
+)
+
+// dateLayout is how dates are written in requests
+const dateLayout = "2006-01-02"
+
+// RegisterValidators adds the validation rules of the application to v and
+// has the errors it reports name fields the way requests do:
+//
+//   - phone is what can be a phone number, written with or without its
+//     calling code and with spaces, dashes or brackets.
+//   - callingcode is a country calling code like +91.
+//   - otp is a one time password of 4 to 10 digits.
+//   - date is a date written as YYYY-MM-DD.
+//   - after=From is a date or time after the one in the From field, either
+//     being empty passes.
+//   - coordinates is a latitude and a longitude separated by a comma.
+func RegisterValidators(v *validator.Validate) error {
+	v.RegisterTagNameFunc(requestFieldName)
+
+	validations := []struct {
+		tag string
+		fn  validator.Func
+	}{
+		{"phone", isPhone},
+		{"callingcode", isCallingCode},
+		{"otp", isOTP},
+		{"date", isDate},
+		{"after", isAfter},
+		{"coordinates", isCoordinates},
+	}
+	for _, validation := range validations {
+		if err := v.RegisterValidation(validation.tag, validation.fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BindError turns an error binding a request into obj into a validation error
+// that carries what is wrong with each field. A body that does not parse is
+// put down against the field it broke on, or against body as a whole.
+func BindError(err error, obj interface{}) error {
+	fields := make(map[string]domain.FieldError)
+
+	var invalid validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &invalid):
+		for _, fieldErr := range invalid {
+			name := fieldPath(fieldErr.Namespace())
+			if _, ok := fields[name]; !ok {
+				fields[name] = fieldError(fieldErr, obj)
+			}
+		}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		fields[typeErr.Field] = domain.FieldError{
+			Code:    "type",
+			Param:   typeErr.Type.String(),
+			Message: "has to be of type " + typeErr.Type.String(),
+		}
+	case errors.As(err, &numErr):
+		fields["query"] = domain.FieldError{Code: "type", Message: fmt.Sprintf("%q is not a number", numErr.Num)}
+	default:
+		fields["body"] = domain.FieldError{Code: "malformed", Message: err.Error()}
+	}
+
+	bindErr := domain.ErrInvalidRequest.Wrap(err)
+	bindErr.Fields = fields
+	return bindErr
+}
+
+// InvalidField is the validation error of a request with one field that is
+// wrong, err being why when there is an error behind it
+func InvalidField(err error, name string, field domain.FieldError) error {
+	return domain.ErrInvalidRequest.Wrap(err).WithFields(map[string]domain.FieldError{name: field})
+}
+
+// fieldError describes a broken rule of a field of obj in words. The code is
+// the rule's tag.
+func fieldError(fieldErr validator.FieldError, obj interface{}) domain.FieldError {
+	param := fieldErr.Param()
+	lengthOf := fieldErr.Kind() == reflect.String || fieldErr.Kind() == reflect.Slice || fieldErr.Kind() == reflect.Map
+
+	var message string
+	switch fieldErr.Tag() {
+	case "required":
+		message = "is required"
+	case "min":
+		message = "has to be at least " + param
+		if lengthOf {
+			message = "has to have at least " + param + " characters or items"
+		}
+	case "max":
+		message = "has to be at most " + param
+		if lengthOf {
+			message = "has to have at most " + param + " characters or items"
+		}
+	case "len":
+		message = "has to have exactly " + param + " characters or items"
+	case "oneof":
+		message = "has to be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "email":
+		message = "has to be an email address"
+	case "alphanum":
+		message = "can only have letters and digits"
+	case "numeric":
+		message = "has to be a number"
+	case "iso4217":
+		message = "has to be a currency code like INR"
+	case "timezone":
+		message = "has to be a time zone like Asia/Kolkata"
+	case "gtfield", "after":
+		param = paramName(fieldErr, obj)
+		message = "has to be after " + param
+	case "phone":
+		message = "has to be a phone number"
+	case "callingcode":
+		message = "has to be a country calling code like +91"
+	case "otp":
+		message = "has to be a code of 4 to 10 digits"
+	case "date":
+		message = "has to be a date in YYYY-MM-DD format"
+	case "coordinates":
+		message = "has to be a latitude and a longitude separated by a comma"
+	default:
+		message = "is invalid"
+	}
+	return domain.FieldError{Code: fieldErr.Tag(), Param: param, Message: message}
+}
+
+// requestFieldName is the name a request gives a field, its json or form
+// key. Fields left out of requests keep the name of the struct field.
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// paramName is the request name of the field a rule like gtfield=StartsAt
+// compares with. It sits next to the field that broke the rule in obj.
+func paramName(fieldErr validator.FieldError, obj interface{}) string {
+	if obj == nil {
+		return fieldErr.Param()
+	}
+	parent := reflect.TypeOf(obj)
+	path := strings.Split(fieldErr.StructNamespace(), ".")
+	for _, name := range path[1 : len(path)-1] {
+		parent = elemType(parent)
+		if parent.Kind() != reflect.Struct {
+			return fieldErr.Param()
+		}
+		field, ok := parent.FieldByName(strings.SplitN(name, "[", 2)[0])
+		if !ok {
+			return fieldErr.Param()
+		}
+		parent = field.Type
+	}
+	parent = elemType(parent)
+	if parent.Kind() != reflect.Struct {
+		return fieldErr.Param()
+	}
+	field, ok := parent.FieldByName(fieldErr.Param())
+	if !ok || requestFieldName(field) == "" {
+		return fieldErr.Param()
+	}
+	return requestFieldName(field)
+}
+
+// elemType is the type a pointer, slice, array or map holds
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t
+}
+
+// fieldPath drops the struct the namespace of a field starts with
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func isPhone(fl validator.FieldLevel) bool {
+	return phoneSyntax.MatchString(phoneFormatting.Replace(strings.TrimSpace(fl.Field().String())))
+}
+
+func isCallingCode(fl validator.FieldLevel) bool {
+	return callingCodePattern.MatchString(fl.Field().String())
+}
+
+func isOTP(fl validator.FieldLevel) bool {
+	return otpPattern.MatchString(fl.Field().String())
+}
+
+func isDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(dateLayout, fl.Field().String())
+	return err == nil
+}
+
+func isAfter(fl validator.FieldLevel) bool {
+	from, kind, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if !found {
+		return false
+	}
+	start, ok := fieldTime(from, kind)
+	if !ok {
+		return true
+	}
+	end, ok := fieldTime(fl.Field(), fl.Field().Kind())
+	if !ok {
+		return true
+	}
+	return end.After(start)
+}
+
+// fieldTime reads a time or a YYYY-MM-DD date, reporting false when the field
+// is empty or holds neither
+func fieldTime(field reflect.Value, kind reflect.Kind) (time.Time, bool) {
+	switch kind {
+	case reflect.String:
+		day, err := time.Parse(dateLayout, field.String())
+		return day, err == nil
+	case reflect.Struct:
+		at, ok := field.Interface().(time.Time)
+		return at, ok && !at.IsZero()
+	}
+	return time.Time{}, false
+}
+
+func isCoordinates(fl validator.FieldLevel) bool {
+	parts := strings.Split(fl.Field().String(), ",")
+	if len(parts) != 2 {
+		return false
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return false
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	return err == nil && longitude >= -180 && longitude <= 180
+}
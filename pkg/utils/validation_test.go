@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fazilnbr/project-workey/pkg/domain"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type phoneInput struct {
+	CountryCode string `json:"countrycode" binding:"omitempty,callingcode"`
+	PhoneNumber string `json:"phonenumber" binding:"required,phone"`
+	Otp         string `json:"otp" binding:"omitempty,otp"`
+}
+
+type rangeInput struct {
+	Day         string     `json:"day" binding:"omitempty,date"`
+	From        *time.Time `form:"from"`
+	To          *time.Time `form:"to" binding:"omitempty,after=From"`
+	Coordinates string     `json:"coordinates" binding:"omitempty,coordinates"`
+	Note        string     `json:"note" binding:"max=5"`
+}
+
+type shiftInput struct {
+	Shifts []shift `json:"shifts" binding:"dive"`
+}
+
+type shift struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at" binding:"gtfield=StartsAt"`
+}
+
+func newTestValidator(t *testing.T) *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	assert.NoError(t, RegisterValidators(v))
+	return v
+}
+
+func TestRegisterValidators(t *testing.T) {
+	v := newTestValidator(t)
+	day := func(value string) *time.Time {
+		at, _ := time.Parse(dateLayout, value)
+		return &at
+	}
+
+	tests := []struct {
+		name           string
+		input          interface{}
+		expectedFields []string
+	}{
+		{name: "test a valid phone number", input: phoneInput{CountryCode: "+91", PhoneNumber: "9876543210", Otp: "1234"}},
+		{name: "test a missing phone number", input: phoneInput{CountryCode: "+91"}, expectedFields: []string{"phonenumber"}},
+		{name: "test a phone number with letters", input: phoneInput{CountryCode: "+91", PhoneNumber: "98765abcde"}, expectedFields: []string{"phonenumber"}},
+		{name: "test a phone number written with spaces", input: phoneInput{PhoneNumber: "+91 98765 43210"}},
+		{name: "test a phone number too long", input: phoneInput{PhoneNumber: "98765432101234567890"}, expectedFields: []string{"phonenumber"}},
+		{name: "test a country code without a plus", input: phoneInput{CountryCode: "91", PhoneNumber: "9876543210"}, expectedFields: []string{"countrycode"}},
+		{name: "test an otp with letters", input: phoneInput{CountryCode: "+91", PhoneNumber: "9876543210", Otp: "12ab"}, expectedFields: []string{"otp"}},
+		{name: "test an empty range", input: rangeInput{}},
+		{name: "test a valid range", input: rangeInput{Day: "2024-02-29", From: day("2024-01-01"), To: day("2024-02-01"), Coordinates: "12.97, 77.59"}},
+		{name: "test a range only to a day", input: rangeInput{To: day("2024-02-01")}},
+		{name: "test a range ending before it starts", input: rangeInput{From: day("2024-02-01"), To: day("2024-01-01")}, expectedFields: []string{"to"}},
+		{name: "test a day that does not exist", input: rangeInput{Day: "2023-02-29"}, expectedFields: []string{"day"}},
+		{name: "test coordinates out of range", input: rangeInput{Coordinates: "91,77.59"}, expectedFields: []string{"coordinates"}},
+		{name: "test coordinates that are not numbers", input: rangeInput{Coordinates: "north"}, expectedFields: []string{"coordinates"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(tt.input)
+
+			var fields []string
+			var invalid validator.ValidationErrors
+			if errors.As(err, &invalid) {
+				for _, fieldErr := range invalid {
+					fields = append(fields, fieldErr.Field())
+				}
+			}
+			assert.Equal(t, tt.expectedFields, fields)
+		})
+	}
+}
+
+func TestBindError(t *testing.T) {
+	v := newTestValidator(t)
+	earlier := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	shifts := shiftInput{Shifts: []shift{{StartsAt: later, EndsAt: earlier}}}
+
+	tests := []struct {
+		name           string
+		obj            interface{}
+		err            error
+		expectedFields map[string]domain.FieldError
+	}{
+		{
+			name: "test fields breaking rules",
+			err:  v.Struct(rangeInput{Day: "tomorrow", Note: "too long"}),
+			expectedFields: map[string]domain.FieldError{
+				"day":  {Code: "date", Message: "has to be a date in YYYY-MM-DD format"},
+				"note": {Code: "max", Param: "5", Message: "has to have at most 5 characters or items"},
+			},
+		},
+		{
+			name: "test a range ending before it starts",
+			obj:  &rangeInput{},
+			err:  v.Struct(rangeInput{From: &later, To: &earlier}),
+			expectedFields: map[string]domain.FieldError{
+				"to": {Code: "after", Param: "from", Message: "has to be after from"},
+			},
+		},
+		{
+			name: "test a field compared with one named apart from it",
+			obj:  &shifts,
+			err:  v.Struct(shifts),
+			expectedFields: map[string]domain.FieldError{
+				"shifts[0].ends_at": {Code: "gtfield", Param: "starts_at", Message: "has to be after starts_at"},
+			},
+		},
+		{
+			name: "test a field of the wrong type",
+			err:  json.Unmarshal([]byte(`{"day": 5}`), &rangeInput{}),
+			expectedFields: map[string]domain.FieldError{
+				"day": {Code: "type", Param: "string", Message: "has to be of type string"},
+			},
+		},
+		{
+			name: "test a body that does not parse",
+			err:  errors.New("unexpected EOF"),
+			expectedFields: map[string]domain.FieldError{
+				"body": {Code: "malformed", Message: "unexpected EOF"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BindError(tt.err, tt.obj)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidRequest)
+			assert.Equal(t, tt.expectedFields, ErrorFields(err))
+		})
+	}
+}